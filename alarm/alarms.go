@@ -0,0 +1,160 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package alarm keeps the alarms raised for the members of a cluster.
+package alarm
+
+import (
+	"sync"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/coreos/pkg/capnslog"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/pkg/types"
+	"github.com/coreos/etcd/storage/backend"
+)
+
+var (
+	alarmBucketName = []byte("alarm")
+	plog            = capnslog.NewPackageLogger("github.com/coreos/etcd", "alarm")
+)
+
+type alarmSet map[types.ID]*pb.AlarmMember
+
+// AlarmStore keeps the active alarms of the members. The alarms are
+// persisted in the backend, so they survive restarts.
+type AlarmStore struct {
+	mu    sync.Mutex
+	types map[pb.AlarmType]alarmSet
+
+	b backend.Backend
+}
+
+func NewAlarmStore(b backend.Backend) *AlarmStore {
+	a := &AlarmStore{
+		types: make(map[pb.AlarmType]alarmSet),
+		b:     b,
+	}
+	a.restore()
+	return a
+}
+
+// Activate activates the alarm of the given type for the given member.
+// It returns the activated alarm, or nil if the alarm is already active.
+func (a *AlarmStore) Activate(id types.ID, at pb.AlarmType) *pb.AlarmMember {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	m := a.addToMap(&pb.AlarmMember{MemberId: uint64(id), Alarm: at})
+	if m == nil {
+		return nil
+	}
+	v, err := m.Marshal()
+	if err != nil {
+		plog.Panicf("failed to marshal alarm member")
+	}
+
+	tx := a.b.BatchTx()
+	tx.Lock()
+	tx.UnsafePut(alarmBucketName, v, nil)
+	tx.Unlock()
+	return m
+}
+
+// Deactivate deactivates the alarm of the given type for the given
+// member. It returns the deactivated alarm, or nil if the alarm is not
+// active.
+func (a *AlarmStore) Deactivate(id types.ID, at pb.AlarmType) *pb.AlarmMember {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	t := a.types[at]
+	m := t[id]
+	if m == nil {
+		return nil
+	}
+	delete(t, id)
+
+	v, err := m.Marshal()
+	if err != nil {
+		plog.Panicf("failed to marshal alarm member")
+	}
+
+	tx := a.b.BatchTx()
+	tx.Lock()
+	tx.UnsafeDelete(alarmBucketName, v)
+	tx.Unlock()
+	return m
+}
+
+// Get returns the active alarms of the given type. It returns the active
+// alarms of all types if the type is NONE.
+func (a *AlarmStore) Get(at pb.AlarmType) (ret []*pb.AlarmMember) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if at == pb.AlarmType_NONE {
+		for _, t := range a.types {
+			for _, m := range t {
+				ret = append(ret, m)
+			}
+		}
+		return ret
+	}
+	for _, m := range a.types[at] {
+		ret = append(ret, m)
+	}
+	return ret
+}
+
+// Recover replaces the active alarms with the alarms in the backend. It
+// is called after the backend is replaced by a snapshot.
+func (a *AlarmStore) Recover() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.types = make(map[pb.AlarmType]alarmSet)
+	a.restore()
+}
+
+func (a *AlarmStore) restore() {
+	tx := a.b.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+
+	tx.UnsafeCreateBucket(alarmBucketName)
+	ks, _ := tx.UnsafeRange(alarmBucketName, []byte{0}, []byte{0xff}, 0)
+	for _, k := range ks {
+		var m pb.AlarmMember
+		if err := m.Unmarshal(k); err != nil {
+			plog.Panicf("failed to unmarshal alarm member: %v", err)
+		}
+		a.addToMap(&m)
+	}
+}
+
+// addToMap adds the given alarm to the active alarms. It returns nil if
+// the alarm is already active.
+func (a *AlarmStore) addToMap(newAlarm *pb.AlarmMember) *pb.AlarmMember {
+	t := a.types[newAlarm.Alarm]
+	if t == nil {
+		t = make(alarmSet)
+		a.types[newAlarm.Alarm] = t
+	}
+	id := types.ID(newAlarm.MemberId)
+	if _, ok := t[id]; ok {
+		return nil
+	}
+	t[id] = newAlarm
+	return newAlarm
+}
@@ -0,0 +1,67 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alarm
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/pkg/types"
+	"github.com/coreos/etcd/storage/backend"
+)
+
+func TestAlarm(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "alarm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	be := backend.NewDefaultBackend(path.Join(dir, "db"))
+	defer be.Close()
+
+	a := NewAlarmStore(be)
+	wm := &pb.AlarmMember{MemberId: 1, Alarm: pb.AlarmType_NOSPACE}
+	if m := a.Activate(types.ID(1), pb.AlarmType_NOSPACE); !reflect.DeepEqual(m, wm) {
+		t.Errorf("activated = %+v, want %+v", m, wm)
+	}
+	if m := a.Activate(types.ID(1), pb.AlarmType_NOSPACE); m != nil {
+		t.Errorf("activated = %+v, want nil for an active alarm", m)
+	}
+	a.Activate(types.ID(2), pb.AlarmType_NOSPACE)
+	if ms := a.Get(pb.AlarmType_NOSPACE); len(ms) != 2 {
+		t.Errorf("len(alarms) = %d, want 2", len(ms))
+	}
+	if ms := a.Get(pb.AlarmType_NONE); len(ms) != 2 {
+		t.Errorf("len(all alarms) = %d, want 2", len(ms))
+	}
+
+	if m := a.Deactivate(types.ID(2), pb.AlarmType_NOSPACE); m == nil || m.MemberId != 2 {
+		t.Errorf("deactivated = %+v, want alarm of member 2", m)
+	}
+	if m := a.Deactivate(types.ID(2), pb.AlarmType_NOSPACE); m != nil {
+		t.Errorf("deactivated = %+v, want nil for an inactive alarm", m)
+	}
+
+	// the active alarms are recovered from the backend
+	na := NewAlarmStore(be)
+	ms := na.Get(pb.AlarmType_NONE)
+	if len(ms) != 1 || !reflect.DeepEqual(ms[0], wm) {
+		t.Errorf("recovered alarms = %+v, want [%+v]", ms, wm)
+	}
+}
@@ -45,6 +45,10 @@ type Member struct {
 	// ClientURLs represents the HTTP(S) endpoints on which this Member
 	// serves it's client-facing APIs.
 	ClientURLs []string `json:"clientURLs"`
+
+	// IsLearner indicates if this Member is a learner, which receives
+	// the replicated log but does not vote.
+	IsLearner bool `json:"isLearner,omitempty"`
 }
 
 type memberCollection []Member
@@ -68,14 +72,17 @@ func (c *memberCollection) UnmarshalJSON(data []byte) error {
 }
 
 type memberCreateOrUpdateRequest struct {
-	PeerURLs types.URLs
+	PeerURLs  types.URLs
+	IsLearner bool
 }
 
 func (m *memberCreateOrUpdateRequest) MarshalJSON() ([]byte, error) {
 	s := struct {
-		PeerURLs []string `json:"peerURLs"`
+		PeerURLs  []string `json:"peerURLs"`
+		IsLearner bool     `json:"isLearner,omitempty"`
 	}{
-		PeerURLs: make([]string, len(m.PeerURLs)),
+		PeerURLs:  make([]string, len(m.PeerURLs)),
+		IsLearner: m.IsLearner,
 	}
 
 	for i, u := range m.PeerURLs {
@@ -100,6 +107,20 @@ type MembersAPI interface {
 	// Add instructs etcd to accept a new Member into the cluster.
 	Add(ctx context.Context, peerURL string) (*Member, error)
 
+	// AddLearner instructs etcd to accept a new learner Member into the
+	// cluster. A learner does not vote until it is promoted.
+	AddLearner(ctx context.Context, peerURL string) (*Member, error)
+
+	// Promote instructs etcd to promote an existing learner Member to a
+	// voting Member. It fails if the learner has not caught up with the
+	// leader.
+	Promote(ctx context.Context, mID string) error
+
+	// Replace instructs etcd to replace an existing Member with a new
+	// Member in a single step. The new Member may reuse the peer URL of
+	// the replaced Member.
+	Replace(ctx context.Context, mID string, peerURL string) (*Member, error)
+
 	// Remove demotes an existing Member out of the cluster.
 	Remove(ctx context.Context, mID string) error
 
@@ -131,18 +152,41 @@ func (m *httpMembersAPI) List(ctx context.Context) ([]Member, error) {
 }
 
 func (m *httpMembersAPI) Add(ctx context.Context, peerURL string) (*Member, error) {
+	return m.add(ctx, peerURL, false)
+}
+
+func (m *httpMembersAPI) AddLearner(ctx context.Context, peerURL string) (*Member, error) {
+	return m.add(ctx, peerURL, true)
+}
+
+func (m *httpMembersAPI) add(ctx context.Context, peerURL string, isLearner bool) (*Member, error) {
+	urls, err := types.NewURLs([]string{peerURL})
+	if err != nil {
+		return nil, err
+	}
+
+	return m.create(ctx, &membersAPIActionAdd{peerURLs: urls, isLearner: isLearner}, http.StatusConflict)
+}
+
+func (m *httpMembersAPI) Replace(ctx context.Context, memberID string, peerURL string) (*Member, error) {
 	urls, err := types.NewURLs([]string{peerURL})
 	if err != nil {
 		return nil, err
 	}
 
-	req := &membersAPIActionAdd{peerURLs: urls}
+	req := &membersAPIActionReplace{memberID: memberID, peerURLs: urls}
+	return m.create(ctx, req, http.StatusNotFound, http.StatusGone, http.StatusConflict)
+}
+
+// create sends the given request that creates a member, and returns the
+// created member. The given status codes are the expected failures.
+func (m *httpMembersAPI) create(ctx context.Context, req httpAction, failures ...int) (*Member, error) {
 	resp, body, err := m.client.Do(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := assertStatusCode(resp.StatusCode, http.StatusCreated, http.StatusConflict); err != nil {
+	if err := assertStatusCode(resp.StatusCode, append([]int{http.StatusCreated}, failures...)...); err != nil {
 		return nil, err
 	}
 
@@ -199,6 +243,28 @@ func (m *httpMembersAPI) Remove(ctx context.Context, memberID string) error {
 	return assertStatusCode(resp.StatusCode, http.StatusNoContent, http.StatusGone)
 }
 
+func (m *httpMembersAPI) Promote(ctx context.Context, memberID string) error {
+	req := &membersAPIActionPromote{memberID: memberID}
+	resp, body, err := m.client.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if err := assertStatusCode(resp.StatusCode, http.StatusNoContent, http.StatusNotFound, http.StatusPreconditionFailed); err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusNoContent {
+		var merr membersError
+		if err := json.Unmarshal(body, &merr); err != nil {
+			return err
+		}
+		return merr
+	}
+
+	return nil
+}
+
 type membersAPIActionList struct{}
 
 func (l *membersAPIActionList) HTTPRequest(ep url.URL) *http.Request {
@@ -219,12 +285,39 @@ func (d *membersAPIActionRemove) HTTPRequest(ep url.URL) *http.Request {
 }
 
 type membersAPIActionAdd struct {
-	peerURLs types.URLs
+	peerURLs  types.URLs
+	isLearner bool
 }
 
 func (a *membersAPIActionAdd) HTTPRequest(ep url.URL) *http.Request {
+	u := v2MembersURL(ep)
+	m := memberCreateOrUpdateRequest{PeerURLs: a.peerURLs, IsLearner: a.isLearner}
+	b, _ := json.Marshal(&m)
+	req, _ := http.NewRequest("POST", u.String(), bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+type membersAPIActionPromote struct {
+	memberID string
+}
+
+func (a *membersAPIActionPromote) HTTPRequest(ep url.URL) *http.Request {
+	u := v2MembersURL(ep)
+	u.Path = path.Join(u.Path, a.memberID, "promote")
+	req, _ := http.NewRequest("POST", u.String(), nil)
+	return req
+}
+
+type membersAPIActionReplace struct {
+	memberID string
+	peerURLs types.URLs
+}
+
+func (a *membersAPIActionReplace) HTTPRequest(ep url.URL) *http.Request {
 	u := v2MembersURL(ep)
 	m := memberCreateOrUpdateRequest{PeerURLs: a.peerURLs}
+	u.Path = path.Join(u.Path, a.memberID, "replace")
 	b, _ := json.Marshal(&m)
 	req, _ := http.NewRequest("POST", u.String(), bytes.NewReader(b))
 	req.Header.Set("Content-Type", "application/json")
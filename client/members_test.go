@@ -114,6 +114,49 @@ func TestMembersAPIActionRemove(t *testing.T) {
 	}
 }
 
+func TestMembersAPIActionPromote(t *testing.T) {
+	ep := url.URL{Scheme: "http", Host: "example.com"}
+	act := &membersAPIActionPromote{memberID: "XXX"}
+
+	wantURL := &url.URL{
+		Scheme: "http",
+		Host:   "example.com",
+		Path:   "/v2/members/XXX/promote",
+	}
+
+	got := *act.HTTPRequest(ep)
+	err := assertRequest(got, "POST", wantURL, http.Header{}, nil)
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestMembersAPIActionReplace(t *testing.T) {
+	ep := url.URL{Scheme: "http", Host: "example.com"}
+	act := &membersAPIActionReplace{
+		memberID: "XXX",
+		peerURLs: types.URLs([]url.URL{
+			url.URL{Scheme: "http", Host: "127.0.0.1:8080"},
+		}),
+	}
+
+	wantURL := &url.URL{
+		Scheme: "http",
+		Host:   "example.com",
+		Path:   "/v2/members/XXX/replace",
+	}
+	wantHeader := http.Header{
+		"Content-Type": []string{"application/json"},
+	}
+	wantBody := []byte(`{"peerURLs":["http://127.0.0.1:8080"]}`)
+
+	got := *act.HTTPRequest(ep)
+	err := assertRequest(got, "POST", wantURL, wantHeader, wantBody)
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
 func TestAssertStatusCode(t *testing.T) {
 	if err := assertStatusCode(404, 400); err == nil {
 		t.Errorf("assertStatusCode failed to detect conflict in 400 vs 404")
@@ -305,6 +348,25 @@ func TestMemberCreateRequestMarshal(t *testing.T) {
 	}
 }
 
+func TestMemberCreateLearnerRequestMarshal(t *testing.T) {
+	req := memberCreateOrUpdateRequest{
+		PeerURLs: types.URLs([]url.URL{
+			url.URL{Scheme: "http", Host: "127.0.0.1:8081"},
+		}),
+		IsLearner: true,
+	}
+	want := []byte(`{"peerURLs":["http://127.0.0.1:8081"],"isLearner":true}`)
+
+	got, err := json.Marshal(&req)
+	if err != nil {
+		t.Fatalf("Marshal returned unexpected err=%v", err)
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf("Failed to marshal memberCreateRequest: want=%s, got=%s", want, got)
+	}
+}
+
 func TestHTTPMembersAPIAddSuccess(t *testing.T) {
 	wantAction := &membersAPIActionAdd{
 		peerURLs: types.URLs([]url.URL{
@@ -459,6 +521,153 @@ func TestHTTPMembersAPIRemoveFail(t *testing.T) {
 	}
 }
 
+func TestHTTPMembersAPIPromoteSuccess(t *testing.T) {
+	wantAction := &membersAPIActionPromote{
+		memberID: "94088180e21eb87b",
+	}
+
+	mAPI := &httpMembersAPI{
+		client: &actionAssertingHTTPClient{
+			t:   t,
+			act: wantAction,
+			resp: http.Response{
+				StatusCode: http.StatusNoContent,
+			},
+		},
+	}
+
+	if err := mAPI.Promote(context.Background(), "94088180e21eb87b"); err != nil {
+		t.Errorf("got non-nil err: %#v", err)
+	}
+}
+
+func TestHTTPMembersAPIPromoteError(t *testing.T) {
+	tests := []struct {
+		client  httpClient
+		wantErr error
+	}{
+		// generic error
+		{
+			client: &staticHTTPClient{
+				err: errors.New("fail!"),
+			},
+		},
+
+		// unexpected HTTP status code
+		{
+			client: &staticHTTPClient{
+				resp: http.Response{
+					StatusCode: http.StatusInternalServerError,
+				},
+			},
+		},
+
+		// learner not ready
+		{
+			client: &staticHTTPClient{
+				resp: http.Response{
+					StatusCode: http.StatusPreconditionFailed,
+				},
+				body: []byte(`{"message":"etcdserver: can only promote a learner member which is in sync with leader"}`),
+			},
+			wantErr: membersError{Message: "etcdserver: can only promote a learner member which is in sync with leader"},
+		},
+	}
+
+	for i, tt := range tests {
+		mAPI := &httpMembersAPI{client: tt.client}
+		err := mAPI.Promote(context.Background(), "94088180e21eb87b")
+		if err == nil {
+			t.Errorf("#%d: got nil err", i)
+		}
+		if tt.wantErr != nil && !reflect.DeepEqual(tt.wantErr, err) {
+			t.Errorf("#%d: incorrect error: want=%#v got=%#v", i, tt.wantErr, err)
+		}
+	}
+}
+
+func TestHTTPMembersAPIReplaceSuccess(t *testing.T) {
+	wantAction := &membersAPIActionReplace{
+		memberID: "94088180e21eb87b",
+		peerURLs: types.URLs([]url.URL{
+			url.URL{Scheme: "http", Host: "127.0.0.1:7002"},
+		}),
+	}
+
+	mAPI := &httpMembersAPI{
+		client: &actionAssertingHTTPClient{
+			t:   t,
+			act: wantAction,
+			resp: http.Response{
+				StatusCode: http.StatusCreated,
+			},
+			body: []byte(`{"id":"c4a3d2f5b1e0a987","peerURLs":["http://127.0.0.1:7002"]}`),
+		},
+	}
+
+	wantResponseMember := &Member{
+		ID:       "c4a3d2f5b1e0a987",
+		PeerURLs: []string{"http://127.0.0.1:7002"},
+	}
+
+	m, err := mAPI.Replace(context.Background(), "94088180e21eb87b", "http://127.0.0.1:7002")
+	if err != nil {
+		t.Errorf("got non-nil err: %#v", err)
+	}
+	if !reflect.DeepEqual(wantResponseMember, m) {
+		t.Errorf("incorrect Member: want=%#v got=%#v", wantResponseMember, m)
+	}
+}
+
+func TestHTTPMembersAPIReplaceError(t *testing.T) {
+	tests := []struct {
+		peerURL string
+		client  httpClient
+		wantErr error
+	}{
+		// malformed peer URL
+		{
+			peerURL: ":",
+		},
+
+		// unexpected HTTP status code
+		{
+			peerURL: "http://example.com:2380",
+			client: &staticHTTPClient{
+				resp: http.Response{
+					StatusCode: http.StatusInternalServerError,
+				},
+			},
+		},
+
+		// another membership change is in progress
+		{
+			peerURL: "http://example.com:2380",
+			client: &staticHTTPClient{
+				resp: http.Response{
+					StatusCode: http.StatusConflict,
+				},
+				body: []byte(`{"message":"etcdserver: another membership change is in progress"}`),
+			},
+			wantErr: membersError{Message: "etcdserver: another membership change is in progress"},
+		},
+	}
+
+	for i, tt := range tests {
+		mAPI := &httpMembersAPI{client: tt.client}
+		m, err := mAPI.Replace(context.Background(), "94088180e21eb87b", tt.peerURL)
+		if err == nil {
+			t.Errorf("#%d: got nil err", i)
+		}
+		if tt.wantErr != nil && !reflect.DeepEqual(tt.wantErr, err) {
+			t.Errorf("#%d: incorrect error: want=%#v got=%#v", i, tt.wantErr, err)
+		}
+		if m != nil {
+			t.Errorf("#%d: got non-nil Member", i)
+		}
+	}
+}
+
 func TestHTTPMembersAPIListSuccess(t *testing.T) {
 	wantAction := &membersAPIActionList{}
 	mAPI := &httpMembersAPI{
@@ -0,0 +1,101 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compactor compacts the revision history of the v3 storage
+// automatically.
+package compactor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/coreos/pkg/capnslog"
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/storage"
+)
+
+const (
+	// ModePeriodic retains the revisions of the last given hours.
+	ModePeriodic = "periodic"
+	// ModeRevision retains the last given number of revisions.
+	ModeRevision = "revision"
+)
+
+var (
+	plog = capnslog.NewPackageLogger("github.com/coreos/etcd", "compactor")
+
+	// checkCompactionInterval is the interval to check if the storage
+	// should be compacted.
+	checkCompactionInterval = 5 * time.Minute
+)
+
+// Compactor compacts the storage in the background. Only the compactor of
+// the leader should compact the storage, so a compactor can be paused and
+// resumed as the leadership changes.
+type Compactor interface {
+	// Run starts the compactor in the background.
+	Run()
+	// Stop stops the compactor.
+	Stop()
+	// Pause pauses the compactor. It keeps tracking the revisions of
+	// the storage, but does not compact it.
+	Pause()
+	// Resume resumes the paused compactor.
+	Resume()
+}
+
+// Compactable compacts the storage through consensus.
+type Compactable interface {
+	Compact(ctx context.Context, r *pb.CompactionRequest) (*pb.CompactionResponse, error)
+}
+
+// RevGetter returns the current revision of the storage.
+type RevGetter interface {
+	Rev() int64
+}
+
+// New creates a paused compactor of the given mode. The retention is
+// the number of hours in the periodic mode, and the number of revisions
+// in the revision mode.
+func New(mode string, retention int, rg RevGetter, c Compactable) (Compactor, error) {
+	switch mode {
+	case ModePeriodic:
+		return NewPeriodic(retention, rg, c), nil
+	case ModeRevision:
+		return NewRevision(int64(retention), rg, c), nil
+	default:
+		return nil, fmt.Errorf("unsupported compaction mode %q", mode)
+	}
+}
+
+// compact compacts the storage to the given revision, and reports the
+// result through the metrics. It returns true if the storage has been
+// compacted to the revision.
+func compact(ctx context.Context, c Compactable, mode string, rev int64) bool {
+	compactions.WithLabelValues(mode).Inc()
+	_, err := c.Compact(ctx, &pb.CompactionRequest{Index: rev})
+	switch err {
+	case nil:
+		plog.Infof("finished auto-compaction at revision %d (mode %s)", rev, mode)
+	case storage.ErrCompacted:
+		// the storage has been compacted beyond rev by others
+	default:
+		plog.Warningf("failed auto-compaction at revision %d (%v)", rev, err)
+		compactionFailures.WithLabelValues(mode).Inc()
+		return false
+	}
+	compactedRev.WithLabelValues(mode).Set(float64(rev))
+	return true
+}
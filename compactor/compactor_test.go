@@ -0,0 +1,195 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compactor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/jonboulle/clockwork"
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+)
+
+// TestPeriodic ensures Periodic compacts the storage to the revision
+// sampled a retention period ago.
+func TestPeriodic(t *testing.T) {
+	fc := clockwork.NewFakeClock()
+	rg := &fakeRevGetter{rev: 1}
+	compactable := &fakeCompactable{reqc: make(chan *pb.CompactionRequest, 1)}
+	tb := NewPeriodic(1, rg, compactable)
+	tb.clock = fc
+
+	tb.Run()
+	defer tb.Stop()
+	tb.Resume()
+
+	n := int(time.Hour / checkCompactionInterval)
+	for i := 0; i < n; i++ {
+		fc.BlockUntil(1)
+		rg.setRev(int64(i + 2))
+		fc.Advance(checkCompactionInterval)
+	}
+	// revision 1 was sampled an hour ago
+	r := compactable.recv(t)
+	if r.Index != 1 {
+		t.Errorf("compact index = %d, want 1", r.Index)
+	}
+
+	fc.BlockUntil(1)
+	rg.setRev(int64(n + 2))
+	fc.Advance(checkCompactionInterval)
+	r = compactable.recv(t)
+	if r.Index != 2 {
+		t.Errorf("compact index = %d, want 2", r.Index)
+	}
+}
+
+// TestPeriodicPause ensures a paused Periodic does not compact the storage,
+// but compacts it with the revisions sampled during the pause after resumed.
+func TestPeriodicPause(t *testing.T) {
+	fc := clockwork.NewFakeClock()
+	rg := &fakeRevGetter{rev: 1}
+	compactable := &fakeCompactable{reqc: make(chan *pb.CompactionRequest, 1)}
+	tb := NewPeriodic(1, rg, compactable)
+	tb.clock = fc
+
+	tb.Run()
+	defer tb.Stop()
+
+	n := int(time.Hour / checkCompactionInterval)
+	for i := 0; i < 2*n; i++ {
+		fc.BlockUntil(1)
+		rg.setRev(int64(i + 2))
+		fc.Advance(checkCompactionInterval)
+	}
+	fc.BlockUntil(1)
+	compactable.expectNone(t)
+
+	tb.Resume()
+	rg.setRev(int64(2*n + 2))
+	fc.Advance(checkCompactionInterval)
+	r := compactable.recv(t)
+	if w := int64(n + 2); r.Index != w {
+		t.Errorf("compact index = %d, want %d", r.Index, w)
+	}
+}
+
+// TestRevision ensures Revision compacts the storage to keep the last
+// retention revisions, and does not compact it twice at a revision.
+func TestRevision(t *testing.T) {
+	fc := clockwork.NewFakeClock()
+	rg := &fakeRevGetter{}
+	compactable := &fakeCompactable{reqc: make(chan *pb.CompactionRequest, 1)}
+	tb := NewRevision(10, rg, compactable)
+	tb.clock = fc
+
+	tb.Run()
+	defer tb.Stop()
+	tb.Resume()
+
+	rg.setRev(5)
+	fc.BlockUntil(1)
+	fc.Advance(checkCompactionInterval)
+	fc.BlockUntil(1)
+	compactable.expectNone(t)
+
+	rg.setRev(15)
+	fc.Advance(checkCompactionInterval)
+	r := compactable.recv(t)
+	if r.Index != 5 {
+		t.Errorf("compact index = %d, want 5", r.Index)
+	}
+
+	fc.BlockUntil(1)
+	fc.Advance(checkCompactionInterval)
+	fc.BlockUntil(1)
+	compactable.expectNone(t)
+
+	tb.Pause()
+	rg.setRev(30)
+	fc.Advance(checkCompactionInterval)
+	fc.BlockUntil(1)
+	compactable.expectNone(t)
+
+	tb.Resume()
+	fc.Advance(checkCompactionInterval)
+	r = compactable.recv(t)
+	if r.Index != 20 {
+		t.Errorf("compact index = %d, want 20", r.Index)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		mode string
+		werr bool
+	}{
+		{ModePeriodic, false},
+		{ModeRevision, false},
+		{"unknown", true},
+	}
+	for i, tt := range tests {
+		_, err := New(tt.mode, 1, &fakeRevGetter{}, &fakeCompactable{})
+		if (err != nil) != tt.werr {
+			t.Errorf("#%d: err = %v, want error %v", i, err, tt.werr)
+		}
+	}
+}
+
+type fakeRevGetter struct {
+	mu  sync.Mutex
+	rev int64
+}
+
+func (g *fakeRevGetter) Rev() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.rev
+}
+
+func (g *fakeRevGetter) setRev(rev int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.rev = rev
+}
+
+type fakeCompactable struct {
+	reqc chan *pb.CompactionRequest
+}
+
+func (c *fakeCompactable) Compact(ctx context.Context, r *pb.CompactionRequest) (*pb.CompactionResponse, error) {
+	c.reqc <- r
+	return &pb.CompactionResponse{}, nil
+}
+
+func (c *fakeCompactable) recv(t *testing.T) *pb.CompactionRequest {
+	select {
+	case r := <-c.reqc:
+		return r
+	case <-time.After(time.Second):
+		t.Fatalf("failed to receive compaction request")
+	}
+	return nil
+}
+
+func (c *fakeCompactable) expectNone(t *testing.T) {
+	select {
+	case r := <-c.reqc:
+		t.Fatalf("unexpected compaction request %+v", r)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
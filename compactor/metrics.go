@@ -0,0 +1,46 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compactor
+
+import (
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	compactions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "compactor",
+		Name:      "compactions_total",
+		Help:      "The total number of compactions proposed by the auto compactor.",
+	}, []string{"mode"})
+	compactionFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "compactor",
+		Name:      "compactions_failed_total",
+		Help:      "The total number of failed compactions proposed by the auto compactor.",
+	}, []string{"mode"})
+	compactedRev = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "compactor",
+		Name:      "compacted_revision",
+		Help:      "The revision the auto compactor last compacted the storage to.",
+	}, []string{"mode"})
+)
+
+func init() {
+	prometheus.MustRegister(compactions)
+	prometheus.MustRegister(compactionFailures)
+	prometheus.MustRegister(compactedRev)
+}
@@ -0,0 +1,123 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compactor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/jonboulle/clockwork"
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+)
+
+// Periodic compacts the revisions that are older than the retention
+// period. It samples the revision of the storage every
+// checkCompactionInterval, and compacts the storage to the newest sample
+// taken before the retention period.
+type Periodic struct {
+	clock     clockwork.Clock
+	retention time.Duration
+
+	rg RevGetter
+	c  Compactable
+
+	// samples are the revisions sampled in the retention period, in the
+	// order of time. They are only accessed by the run loop.
+	samples []revSample
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	paused bool
+}
+
+type revSample struct {
+	t   time.Time
+	rev int64
+}
+
+// NewPeriodic creates a paused Periodic compactor that retains the
+// revisions of the last h hours.
+func NewPeriodic(h int, rg RevGetter, c Compactable) *Periodic {
+	return &Periodic{
+		clock:     clockwork.NewRealClock(),
+		retention: time.Duration(h) * time.Hour,
+		rg:        rg,
+		c:         c,
+		paused:    true,
+	}
+}
+
+func (t *Periodic) Run() {
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+	clock := t.clock
+
+	go func() {
+		for {
+			t.samples = append(t.samples, revSample{t: clock.Now(), rev: t.rg.Rev()})
+
+			select {
+			case <-t.ctx.Done():
+				return
+			case <-clock.After(checkCompactionInterval):
+			}
+
+			// find the newest sample that is out of the retention period
+			i := -1
+			now := clock.Now()
+			for j, s := range t.samples {
+				if now.Sub(s.t) < t.retention {
+					break
+				}
+				i = j
+			}
+			if i < 0 {
+				continue
+			}
+
+			t.mu.Lock()
+			p := t.paused
+			t.mu.Unlock()
+			if p {
+				// drop the older samples that are no longer needed
+				t.samples = t.samples[i:]
+				continue
+			}
+			if t.samples[i].rev <= 0 {
+				continue
+			}
+			if compact(t.ctx, t.c, ModePeriodic, t.samples[i].rev) {
+				t.samples = t.samples[i+1:]
+			}
+		}
+	}()
+}
+
+func (t *Periodic) Stop() {
+	t.cancel()
+}
+
+func (t *Periodic) Pause() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.paused = true
+}
+
+func (t *Periodic) Resume() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.paused = false
+}
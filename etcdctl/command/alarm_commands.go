@@ -0,0 +1,105 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/codegangsta/cli"
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	"github.com/coreos/etcd/client"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/pkg/types"
+)
+
+func NewAlarmCommand() cli.Command {
+	return cli.Command{
+		Name:  "alarm",
+		Usage: "alarm list and disarm subcommands of the v3 storage",
+		Subcommands: []cli.Command{
+			cli.Command{
+				Name:   "list",
+				Usage:  "list all the active alarms of the cluster",
+				Action: actionAlarmList,
+			},
+			cli.Command{
+				Name:   "disarm",
+				Usage:  "disarm all the active alarms of the cluster",
+				Action: actionAlarmDisarm,
+			},
+		},
+	}
+}
+
+func actionAlarmList(c *cli.Context) {
+	if len(c.Args()) != 0 {
+		fmt.Fprintln(os.Stderr, "No arguments accepted")
+		os.Exit(1)
+	}
+	mc := mustNewMaintenanceClient(c)
+	for _, m := range mustGetAlarms(mc) {
+		fmt.Printf("memberID:%s alarm:%v\n", types.ID(m.MemberId), m.Alarm)
+	}
+}
+
+func actionAlarmDisarm(c *cli.Context) {
+	if len(c.Args()) != 0 {
+		fmt.Fprintln(os.Stderr, "No arguments accepted")
+		os.Exit(1)
+	}
+	mc := mustNewMaintenanceClient(c)
+	for _, m := range mustGetAlarms(mc) {
+		req := &pb.AlarmRequest{
+			Action:   pb.AlarmRequest_DEACTIVATE,
+			MemberId: m.MemberId,
+			Alarm:    m.Alarm,
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), client.DefaultRequestTimeout)
+		_, err := mc.Alarm(ctx, req)
+		cancel()
+		if err != nil {
+			handleError(ExitServerError, err)
+		}
+		fmt.Printf("Disarmed alarm %v of member %s\n", m.Alarm, types.ID(m.MemberId))
+	}
+}
+
+func mustGetAlarms(mc pb.MaintenanceClient) []*pb.AlarmMember {
+	ctx, cancel := context.WithTimeout(context.Background(), client.DefaultRequestTimeout)
+	resp, err := mc.Alarm(ctx, &pb.AlarmRequest{Action: pb.AlarmRequest_GET})
+	cancel()
+	if err != nil {
+		handleError(ExitServerError, err)
+	}
+	return resp.Alarms
+}
+
+// mustNewMaintenanceClient connects to the maintenance service at the
+// first endpoint that can be connected.
+func mustNewMaintenanceClient(c *cli.Context) pb.MaintenanceClient {
+	eps, err := getEndpoints(c)
+	if err != nil {
+		handleError(ExitBadArgs, err)
+	}
+	for _, ep := range eps {
+		conn, err := dialGRPC(c, ep)
+		if err == nil {
+			return pb.NewMaintenanceClient(conn)
+		}
+	}
+	handleError(ExitBadConnection, fmt.Errorf("cannot connect to any of %v", eps))
+	return nil
+}
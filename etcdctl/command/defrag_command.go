@@ -0,0 +1,70 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/codegangsta/cli"
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+)
+
+func NewDefragCommand() cli.Command {
+	return cli.Command{
+		Name:  "defrag",
+		Usage: "defragment the v3 storage backend of the members at the given endpoints",
+		Flags: []cli.Flag{
+			cli.DurationFlag{Name: "timeout", Value: 5 * time.Minute, Usage: "timeout of defragmenting one member"},
+		},
+		Action: handleDefrag,
+	}
+}
+
+// handleDefrag defragments the members at the endpoints one by one. The
+// member being defragmented blocks its writes, so the members are not
+// defragmented at the same time.
+func handleDefrag(c *cli.Context) {
+	eps, err := getEndpoints(c)
+	if err != nil {
+		handleError(ExitBadArgs, err)
+	}
+
+	failed := false
+	for _, ep := range eps {
+		conn, err := dialGRPC(c, ep)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to connect to %s: %v\n", ep, err)
+			failed = true
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.Duration("timeout"))
+		_, err = pb.NewMaintenanceClient(conn).Defragment(ctx, &pb.DefragmentRequest{})
+		cancel()
+		conn.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to defragment the member at %s: %v\n", ep, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("Finished defragmenting the member at %s\n", ep)
+	}
+	if failed {
+		os.Exit(ExitServerError)
+	}
+}
@@ -27,7 +27,7 @@ import (
 func NewMemberCommand() cli.Command {
 	return cli.Command{
 		Name:  "member",
-		Usage: "member add, remove and list subcommands",
+		Usage: "member add, remove, promote, replace and list subcommands",
 		Subcommands: []cli.Command{
 			cli.Command{
 				Name:   "list",
@@ -35,8 +35,11 @@ func NewMemberCommand() cli.Command {
 				Action: actionMemberList,
 			},
 			cli.Command{
-				Name:   "add",
-				Usage:  "add a new member to the etcd cluster",
+				Name:  "add",
+				Usage: "add a new member to the etcd cluster",
+				Flags: []cli.Flag{
+					cli.BoolFlag{Name: "learner", Usage: "add the member as a learner, which does not vote until it is promoted"},
+				},
 				Action: actionMemberAdd,
 			},
 			cli.Command{
@@ -49,6 +52,16 @@ func NewMemberCommand() cli.Command {
 				Usage:  "update an existing member in the etcd cluster",
 				Action: actionMemberUpdate,
 			},
+			cli.Command{
+				Name:   "promote",
+				Usage:  "promote a learner member in the etcd cluster to a voting member",
+				Action: actionMemberPromote,
+			},
+			cli.Command{
+				Name:   "replace",
+				Usage:  "replace an existing member in the etcd cluster with a new member in a single step",
+				Action: actionMemberReplace,
+			},
 		},
 	}
 }
@@ -68,10 +81,14 @@ func actionMemberList(c *cli.Context) {
 	}
 
 	for _, m := range members {
+		id := m.ID
+		if m.IsLearner {
+			id += "[learner]"
+		}
 		if len(m.Name) == 0 {
-			fmt.Printf("%s[unstarted]: peerURLs=%s\n", m.ID, strings.Join(m.PeerURLs, ","))
+			fmt.Printf("%s[unstarted]: peerURLs=%s\n", id, strings.Join(m.PeerURLs, ","))
 		} else {
-			fmt.Printf("%s: name=%s peerURLs=%s clientURLs=%s\n", m.ID, m.Name, strings.Join(m.PeerURLs, ","), strings.Join(m.ClientURLs, ","))
+			fmt.Printf("%s: name=%s peerURLs=%s clientURLs=%s\n", id, m.Name, strings.Join(m.PeerURLs, ","), strings.Join(m.ClientURLs, ","))
 		}
 	}
 }
@@ -87,7 +104,15 @@ func actionMemberAdd(c *cli.Context) {
 
 	url := args[1]
 	ctx, cancel := context.WithTimeout(context.Background(), client.DefaultRequestTimeout)
-	m, err := mAPI.Add(ctx, url)
+	var (
+		m   *client.Member
+		err error
+	)
+	if c.Bool("learner") {
+		m, err = mAPI.AddLearner(ctx, url)
+	} else {
+		m, err = mAPI.Add(ctx, url)
+	}
 	cancel()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
@@ -96,9 +121,19 @@ func actionMemberAdd(c *cli.Context) {
 
 	newID := m.ID
 	newName := args[0]
-	fmt.Printf("Added member named %s with ID %s to cluster\n", newName, newID)
+	if m.IsLearner {
+		fmt.Printf("Added learner member named %s with ID %s to cluster\n", newName, newID)
+	} else {
+		fmt.Printf("Added member named %s with ID %s to cluster\n", newName, newID)
+	}
+	printNewMemberEnv(mAPI, newID, newName, "")
+}
 
-	ctx, cancel = context.WithTimeout(context.Background(), client.DefaultRequestTimeout)
+// printNewMemberEnv prints the environment variables to start the new member
+// of the given ID and name. The member of the given replaced ID, if any, is
+// left out of the initial cluster.
+func printNewMemberEnv(mAPI client.MembersAPI, newID, newName, replacedID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), client.DefaultRequestTimeout)
 	members, err := mAPI.List(ctx)
 	cancel()
 	if err != nil {
@@ -108,6 +143,9 @@ func actionMemberAdd(c *cli.Context) {
 
 	conf := []string{}
 	for _, memb := range members {
+		if memb.ID == replacedID {
+			continue
+		}
 		for _, u := range memb.PeerURLs {
 			n := memb.Name
 			if memb.ID == newID {
@@ -190,3 +228,45 @@ func actionMemberUpdate(c *cli.Context) {
 
 	fmt.Printf("Updated member with ID %s in cluster\n", mid)
 }
+
+func actionMemberPromote(c *cli.Context) {
+	args := c.Args()
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Provide a single member ID")
+		os.Exit(1)
+	}
+	mid := args[0]
+
+	mAPI := mustNewMembersAPI(c)
+	ctx, cancel := context.WithTimeout(context.Background(), client.DefaultRequestTimeout)
+	err := mAPI.Promote(ctx, mid)
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Recieved an error trying to promote member %s: %s\n", mid, err.Error())
+		os.Exit(1)
+	}
+
+	fmt.Printf("Promoted member %s to a voting member in cluster\n", mid)
+}
+
+func actionMemberReplace(c *cli.Context) {
+	args := c.Args()
+	if len(args) != 3 {
+		fmt.Fprintln(os.Stderr, "Provide the ID of the replaced member, and a name and a single peerURL of the new member")
+		os.Exit(1)
+	}
+	mid := args[0]
+
+	mAPI := mustNewMembersAPI(c)
+	ctx, cancel := context.WithTimeout(context.Background(), client.DefaultRequestTimeout)
+	m, err := mAPI.Replace(ctx, mid, args[2])
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Recieved an error trying to replace member %s: %s\n", mid, err.Error())
+		os.Exit(1)
+	}
+
+	newName := args[1]
+	fmt.Printf("Replaced member %s with member named %s with ID %s in cluster\n", mid, newName, m.ID)
+	printNewMemberEnv(mAPI, m.ID, newName, mid)
+}
@@ -0,0 +1,77 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/codegangsta/cli"
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	"github.com/coreos/etcd/client"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/pkg/types"
+)
+
+func NewMoveLeaderCommand() cli.Command {
+	return cli.Command{
+		Name:  "move-leader",
+		Usage: "transfer the leadership of the cluster to the member with the given ID or name",
+		Flags: []cli.Flag{
+			cli.DurationFlag{Name: "timeout", Value: 10 * time.Second, Usage: "timeout of the leadership transfer"},
+		},
+		Action: handleMoveLeader,
+	}
+}
+
+func handleMoveLeader(c *cli.Context) {
+	args := c.Args()
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Provide a single member ID or name")
+		os.Exit(1)
+	}
+	target := args[0]
+
+	mAPI := mustNewMembersAPI(c)
+	listctx, listCancel := context.WithTimeout(context.Background(), client.DefaultRequestTimeout)
+	members, err := mAPI.List(listctx)
+	listCancel()
+	if err != nil {
+		handleError(ExitServerError, err)
+	}
+	var id types.ID
+	for _, m := range members {
+		if m.ID == target || m.Name == target {
+			if id, err = types.IDFromString(m.ID); err != nil {
+				handleError(ExitServerError, err)
+			}
+			break
+		}
+	}
+	if id == 0 {
+		fmt.Fprintf(os.Stderr, "Couldn't find a member in the cluster with an ID or name of %s.\n", target)
+		os.Exit(1)
+	}
+
+	mc := mustNewMaintenanceClient(c)
+	ctx, cancel := context.WithTimeout(context.Background(), c.Duration("timeout"))
+	_, err = mc.MoveLeader(ctx, &pb.MoveLeaderRequest{TargetId: uint64(id)})
+	cancel()
+	if err != nil {
+		handleError(ExitServerError, err)
+	}
+	fmt.Printf("Moved leadership to member %s\n", id)
+}
@@ -23,10 +23,13 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/bgentry/speakeasy"
 	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/codegangsta/cli"
 	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	"github.com/coreos/etcd/Godeps/_workspace/src/google.golang.org/grpc"
+	"github.com/coreos/etcd/Godeps/_workspace/src/google.golang.org/grpc/credentials"
 	"github.com/coreos/etcd/client"
 	"github.com/coreos/etcd/pkg/transport"
 )
@@ -226,3 +229,20 @@ func mustNewClient(c *cli.Context) client.Client {
 
 	return hc
 }
+
+// dialGRPC connects to the v3 gRPC service at the given endpoint.
+func dialGRPC(c *cli.Context, ep string) (*grpc.ClientConn, error) {
+	u, err := url.Parse(ep)
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{grpc.WithTimeout(time.Second)}
+	if u.Scheme == "https" {
+		tr, err := getTransport(c)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tr.TLSClientConfig)))
+	}
+	return grpc.Dial(u.Host, opts...)
+}
@@ -40,6 +40,7 @@ func main() {
 	}
 	app.Commands = []cli.Command{
 		command.NewBackupCommand(),
+		command.NewDefragCommand(),
 		command.NewClusterHealthCommand(),
 		command.NewMakeCommand(),
 		command.NewMakeDirCommand(),
@@ -58,6 +59,8 @@ func main() {
 		command.NewUserCommands(),
 		command.NewRoleCommands(),
 		command.NewAuthCommands(),
+		command.NewAlarmCommand(),
+		command.NewMoveLeaderCommand(),
 	}
 
 	app.Run(os.Args)
@@ -19,9 +19,11 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"path"
 	"runtime"
 	"strings"
 
+	"github.com/coreos/etcd/compactor"
 	"github.com/coreos/etcd/etcdserver"
 	"github.com/coreos/etcd/pkg/cors"
 	"github.com/coreos/etcd/pkg/flags"
@@ -111,6 +113,22 @@ type config struct {
 
 	printVersion bool
 
+	preVote             bool
+	checkQuorum         bool
+	raftMaxSizePerMsg   uint64
+	raftMaxInflightMsgs int
+
+	v3demo                  bool
+	quotaBackendBytes       int64
+	autoCompactionMode      *flags.StringsFlag
+	autoCompactionRetention int
+
+	peerGRPC          bool
+	peerCompression   *flags.StringsFlag
+	snapshotBandwidth int64
+	peerCertIDsStr    string
+	peerCertIDs       map[string]string
+
 	ignored []string
 }
 
@@ -131,6 +149,15 @@ func NewConfig() *config {
 			proxyFlagReadonly,
 			proxyFlagOn,
 		),
+		autoCompactionMode: flags.NewStringsFlag(
+			compactor.ModePeriodic,
+			compactor.ModeRevision,
+		),
+		peerCompression: flags.NewStringsFlag(
+			"none",
+			"snappy",
+			"gzip",
+		),
 	}
 
 	cfg.FlagSet = flag.NewFlagSet("etcd", flag.ContinueOnError)
@@ -204,6 +231,28 @@ func NewConfig() *config {
 	// version
 	fs.BoolVar(&cfg.printVersion, "version", false, "Print the version and exit")
 
+	// demo flag
+	fs.BoolVar(&cfg.preVote, "experimental-pre-vote", false, "Enable the pre-vote phase of raft elections to prevent partitioned members from disrupting the cluster")
+	fs.BoolVar(&cfg.checkQuorum, "experimental-check-quorum", false, "Make the leader step down when it loses contact with a quorum of the cluster")
+	fs.Uint64Var(&cfg.raftMaxSizePerMsg, "experimental-raft-max-size-per-msg", 0, "Maximum size in bytes of the entries carried by a single raft append message. 0 means the default size.")
+	fs.IntVar(&cfg.raftMaxInflightMsgs, "experimental-raft-max-inflight-msgs", 0, "Maximum number of in-flight raft append messages to a single follower. 0 means the default number.")
+	fs.BoolVar(&cfg.v3demo, "experimental-v3demo", false, "Enable experimental v3 demo API")
+	fs.Int64Var(&cfg.quotaBackendBytes, "experimental-quota-backend-bytes", 0, "Raise alarms when the v3 storage backend size exceeds the given quota. 0 means the default quota.")
+	fs.Var(cfg.autoCompactionMode, "experimental-auto-compaction-mode", fmt.Sprintf("Mode of the v3 storage auto compaction. Valid values include %s", strings.Join(cfg.autoCompactionMode.Values, ", ")))
+	if err := cfg.autoCompactionMode.Set(compactor.ModePeriodic); err != nil {
+		// Should never happen.
+		plog.Panicf("unexpected error setting up experimental-auto-compaction-mode flag: %v", err)
+	}
+	fs.IntVar(&cfg.autoCompactionRetention, "experimental-auto-compaction-retention", 0, "Retention of the v3 storage auto compaction: hours in the periodic mode, revisions in the revision mode. 0 means disable auto compaction.")
+	fs.BoolVar(&cfg.peerGRPC, "experimental-peer-grpc", false, "Send raft messages and snapshots over gRPC to the peers that support it")
+	fs.Var(cfg.peerCompression, "experimental-peer-compression", fmt.Sprintf("Compression of the raft streams and snapshots sent to the peers that accept it. Valid values include %s", strings.Join(cfg.peerCompression.Values, ", ")))
+	if err := cfg.peerCompression.Set("none"); err != nil {
+		// Should never happen.
+		plog.Panicf("unexpected error setting up experimental-peer-compression flag: %v", err)
+	}
+	fs.Int64Var(&cfg.snapshotBandwidth, "experimental-snapshot-bandwidth", 0, "Maximum bandwidth in bytes per second of the snapshots sent to each peer. 0 means unlimited.")
+	fs.StringVar(&cfg.peerCertIDsStr, "experimental-peer-cert-identities", "", "Comma-separated member name to CN or DNS SAN pattern pairs that the peer certificates of the members must match. The pattern of name '*' applies to the members without one.")
+
 	// backwards-compatibility with v0.4.6
 	fs.Var(&flags.IPAddressPort{}, "addr", "DEPRECATED: Use -advertise-client-urls instead.")
 	fs.Var(&flags.IPAddressPort{}, "bind-addr", "DEPRECATED: Use -listen-client-urls instead.")
@@ -294,9 +343,46 @@ func (cfg *config) Parse(arguments []string) error {
 		return fmt.Errorf("-election-timeout[%vms] should be at least as 5 times as -heartbeat-interval[%vms]", cfg.ElectionMs, cfg.TickMs)
 	}
 
+	if cfg.peerCertIDs, err = parsePeerCertIdentities(cfg.peerCertIDsStr); err != nil {
+		return err
+	}
+	if len(cfg.peerCertIDs) != 0 && !cfg.peerTLSInfo.ClientCertAuth && cfg.peerTLSInfo.CAFile == "" {
+		return fmt.Errorf("-experimental-peer-cert-identities requires -peer-client-cert-auth")
+	}
+	if len(cfg.peerCertIDs) != 0 && cfg.peerGRPC {
+		return fmt.Errorf("-experimental-peer-cert-identities cannot be used with -experimental-peer-grpc, since the peer certificates cannot be verified over gRPC")
+	}
+
+	if cfg.raftMaxInflightMsgs < 0 || cfg.raftMaxInflightMsgs > etcdserver.MaxRaftInflightMsgs {
+		return fmt.Errorf("-experimental-raft-max-inflight-msgs[%v] should be between 0 and %v", cfg.raftMaxInflightMsgs, etcdserver.MaxRaftInflightMsgs)
+	}
+
 	return nil
 }
 
+// parsePeerCertIdentities parses the comma-separated name=pattern pairs of
+// the peer certificate identities.
+func parsePeerCertIdentities(s string) (map[string]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	ids := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, fmt.Errorf("invalid peer cert identity %q, want name=pattern", pair)
+		}
+		if _, ok := ids[kv[0]]; ok {
+			return nil, fmt.Errorf("duplicate peer cert identity of member %q", kv[0])
+		}
+		if _, err := path.Match(kv[1], ""); err != nil {
+			return nil, fmt.Errorf("invalid peer cert identity pattern %q (%v)", kv[1], err)
+		}
+		ids[kv[0]] = kv[1]
+	}
+	return ids, nil
+}
+
 func initialClusterFromName(name string) string {
 	n := name
 	if name == "" {
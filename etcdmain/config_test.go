@@ -277,6 +277,120 @@ func TestConfigParsingMissedAdvertiseClientURLsFlag(t *testing.T) {
 	}
 }
 
+func TestConfigParsingPeerCertIdentities(t *testing.T) {
+	tests := []struct {
+		args []string
+
+		wids map[string]string
+		werr bool
+	}{
+		{
+			[]string{},
+			nil,
+			false,
+		},
+		{
+			[]string{
+				"-peer-client-cert-auth",
+				"-experimental-peer-cert-identities=infra1=infra1.example.com,*=*.example.com",
+			},
+			map[string]string{"infra1": "infra1.example.com", "*": "*.example.com"},
+			false,
+		},
+		{
+			[]string{
+				"-peer-ca-file=ca.crt",
+				"-experimental-peer-cert-identities=infra1=infra1.example.com",
+			},
+			map[string]string{"infra1": "infra1.example.com"},
+			false,
+		},
+		// client cert auth is not required
+		{
+			[]string{
+				"-experimental-peer-cert-identities=infra1=infra1.example.com",
+			},
+			nil,
+			true,
+		},
+		// no pattern
+		{
+			[]string{
+				"-peer-client-cert-auth",
+				"-experimental-peer-cert-identities=infra1",
+			},
+			nil,
+			true,
+		},
+		// duplicate member
+		{
+			[]string{
+				"-peer-client-cert-auth",
+				"-experimental-peer-cert-identities=infra1=a.example.com,infra1=b.example.com",
+			},
+			nil,
+			true,
+		},
+		// bad pattern
+		{
+			[]string{
+				"-peer-client-cert-auth",
+				"-experimental-peer-cert-identities=infra1=[a.example.com",
+			},
+			nil,
+			true,
+		},
+		// gRPC peer transport
+		{
+			[]string{
+				"-peer-client-cert-auth",
+				"-experimental-peer-grpc",
+				"-experimental-peer-cert-identities=infra1=a.example.com",
+			},
+			nil,
+			true,
+		},
+	}
+
+	for i, tt := range tests {
+		cfg := NewConfig()
+		err := cfg.Parse(tt.args)
+		if (err != nil) != tt.werr {
+			t.Errorf("%d: err = %v, want error %v", i, err, tt.werr)
+		}
+		if err == nil && !reflect.DeepEqual(cfg.peerCertIDs, tt.wids) {
+			t.Errorf("%d: peerCertIDs = %v, want %v", i, cfg.peerCertIDs, tt.wids)
+		}
+	}
+}
+
+func TestConfigParsingRaftMaxInflightMsgs(t *testing.T) {
+	tests := []struct {
+		args []string
+
+		winflight int
+		werr      bool
+	}{
+		{[]string{}, 0, false},
+		{[]string{"-experimental-raft-max-inflight-msgs=256"}, 256, false},
+		{[]string{"-experimental-raft-max-inflight-msgs=512"}, 512, false},
+		// the window could fill the rafthttp buffer
+		{[]string{"-experimental-raft-max-inflight-msgs=513"}, 0, true},
+		{[]string{"-experimental-raft-max-inflight-msgs=-1"}, 0, true},
+	}
+
+	for i, tt := range tests {
+		cfg := NewConfig()
+		err := cfg.Parse(tt.args)
+		if (err != nil) != tt.werr {
+			t.Errorf("%d: err = %v, want error %v", i, err, tt.werr)
+		}
+		if err == nil && cfg.raftMaxInflightMsgs != tt.winflight {
+			t.Errorf("%d: raftMaxInflightMsgs = %d, want %d", i, cfg.raftMaxInflightMsgs, tt.winflight)
+		}
+	}
+}
+
 func TestConfigIsNewCluster(t *testing.T) {
 	tests := []struct {
 		state  string
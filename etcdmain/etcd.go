@@ -21,10 +21,12 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path"
 	"reflect"
 	"runtime"
 	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/coreos/etcd/discovery"
@@ -49,6 +51,10 @@ var plog = capnslog.NewPackageLogger("github.com/coreos/etcd", "etcdmain")
 const (
 	// the owner can make/remove files inside the directory
 	privateDirMode = 0700
+
+	// tlsReloadInterval is the interval to check whether the TLS
+	// certificate, key and CA files changed.
+	tlsReloadInterval = 10 * time.Second
 )
 
 var (
@@ -70,6 +76,9 @@ func Main() {
 		os.Exit(1)
 	}
 	setupLogging(cfg)
+	if err = setupTLSReloaders(cfg); err != nil {
+		plog.Fatalf("error loading TLS files, %v", err)
+	}
 
 	var stopped <-chan struct{}
 
@@ -138,6 +147,49 @@ func Main() {
 	osutil.Exit(0)
 }
 
+// setupTLSReloaders makes the listeners and transports created from the
+// client and peer TLS info pick up the changed certificate, key and CA
+// files. The files are checked every tlsReloadInterval, and reloaded on
+// SIGHUP.
+func setupTLSReloaders(cfg *config) error {
+	var rs []*transport.TLSReloader
+	for _, t := range []struct {
+		name string
+		info *transport.TLSInfo
+	}{
+		{"client", &cfg.clientTLSInfo},
+		{"peer", &cfg.peerTLSInfo},
+	} {
+		if t.info.Empty() && t.info.CAFile == "" && t.info.TrustedCAFile == "" {
+			continue
+		}
+		r, err := transport.NewTLSReloader(t.name, *t.info)
+		if err != nil {
+			return err
+		}
+		t.info.Reloader = r
+		go r.Watch(tlsReloadInterval, nil)
+		rs = append(rs, r)
+	}
+	if len(rs) == 0 {
+		return nil
+	}
+
+	hupc := make(chan os.Signal, 1)
+	signal.Notify(hupc, syscall.SIGHUP)
+	go func() {
+		for range hupc {
+			plog.Infof("received SIGHUP, reloading TLS files")
+			for _, r := range rs {
+				if err := r.Reload(); err != nil {
+					plog.Warningf("failed to reload TLS files (%v)", err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
 // startEtcd launches the etcd server and HTTP handlers for client/server communication.
 func startEtcd(cfg *config) (<-chan struct{}, error) {
 	urlsmap, token, err := getPeerURLsMapAndToken(cfg)
@@ -201,22 +253,34 @@ func startEtcd(cfg *config) (<-chan struct{}, error) {
 	}
 
 	srvcfg := &etcdserver.ServerConfig{
-		Name:                cfg.name,
-		ClientURLs:          cfg.acurls,
-		PeerURLs:            cfg.apurls,
-		DataDir:             cfg.dir,
-		SnapCount:           cfg.snapCount,
-		MaxSnapFiles:        cfg.maxSnapFiles,
-		MaxWALFiles:         cfg.maxWalFiles,
-		InitialPeerURLsMap:  urlsmap,
-		InitialClusterToken: token,
-		DiscoveryURL:        cfg.durl,
-		DiscoveryProxy:      cfg.dproxy,
-		NewCluster:          cfg.isNewCluster(),
-		ForceNewCluster:     cfg.forceNewCluster,
-		Transport:           pt,
-		TickMs:              cfg.TickMs,
-		ElectionTicks:       cfg.electionTicks(),
+		Name:                    cfg.name,
+		ClientURLs:              cfg.acurls,
+		PeerURLs:                cfg.apurls,
+		DataDir:                 cfg.dir,
+		SnapCount:               cfg.snapCount,
+		MaxSnapFiles:            cfg.maxSnapFiles,
+		MaxWALFiles:             cfg.maxWalFiles,
+		InitialPeerURLsMap:      urlsmap,
+		InitialClusterToken:     token,
+		DiscoveryURL:            cfg.durl,
+		DiscoveryProxy:          cfg.dproxy,
+		NewCluster:              cfg.isNewCluster(),
+		ForceNewCluster:         cfg.forceNewCluster,
+		Transport:               pt,
+		TickMs:                  cfg.TickMs,
+		ElectionTicks:           cfg.electionTicks(),
+		PreVote:                 cfg.preVote,
+		CheckQuorum:             cfg.checkQuorum,
+		MaxSizePerMsg:           cfg.raftMaxSizePerMsg,
+		MaxInflightMsgs:         cfg.raftMaxInflightMsgs,
+		V3demo:                  cfg.v3demo,
+		QuotaBackendBytes:       cfg.quotaBackendBytes,
+		AutoCompactionMode:      cfg.autoCompactionMode.String(),
+		AutoCompactionRetention: cfg.autoCompactionRetention,
+		PeerGRPC:                cfg.peerGRPC,
+		PeerCompression:         cfg.peerCompression.String(),
+		SnapshotBandwidth:       cfg.snapshotBandwidth,
+		PeerCertIdentities:      cfg.peerCertIDs,
 	}
 	var s *etcdserver.EtcdServer
 	s, err = etcdserver.NewServer(srvcfg)
@@ -233,15 +297,33 @@ func startEtcd(cfg *config) (<-chan struct{}, error) {
 		Handler: etcdhttp.NewClientHandler(s),
 		Info:    cfg.corsInfo,
 	}
-	ph := etcdhttp.NewPeerHandler(s.Cluster(), s.RaftHandler())
+	ph := etcdhttp.NewPeerHandler(s.Cluster(), s, s.RaftHandler())
 	// Start the peer server in a goroutine
 	for _, l := range plns {
+		if cfg.peerGRPC {
+			// gRPC peers talk HTTP/2 on the same listener as
+			// the HTTP peers
+			var gl net.Listener
+			gl, l = transport.SplitHTTP2Listener(l)
+			go func(l net.Listener) {
+				plog.Fatal(s.ServePeerGRPC(l))
+			}(gl)
+		}
 		go func(l net.Listener) {
 			plog.Fatal(serveHTTP(l, ph, 5*time.Minute))
 		}(l)
 	}
 	// Start a client server goroutine for each listen address
 	for _, l := range clns {
+		if cfg.v3demo {
+			// gRPC clients talk HTTP/2 on the same listener as
+			// the HTTP clients
+			var gl net.Listener
+			gl, l = transport.SplitHTTP2Listener(l)
+			go func(l net.Listener) {
+				plog.Fatal(serveGRPC(l, s))
+			}(gl)
+		}
 		go func(l net.Listener) {
 			// read timeout does not work with http close notify
 			// TODO: https://github.com/golang/go/issues/9524
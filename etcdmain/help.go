@@ -121,5 +121,32 @@ given by the consensus protocol.
 
 	--force-new-cluster 'false'
 		force to create a new one-member cluster.
+
+experimental flags:
+
+	--experimental-pre-vote 'false'
+		enable the pre-vote phase of raft elections to prevent partitioned members from disrupting the cluster.
+	--experimental-check-quorum 'false'
+		make the leader step down when it loses contact with a quorum of the cluster.
+	--experimental-raft-max-size-per-msg '0'
+		maximum size in bytes of the entries carried by a single raft append message. 0 means the default size of 1MB.
+	--experimental-raft-max-inflight-msgs '0'
+		maximum number of in-flight raft append messages to a single follower. It cannot exceed 512, which is also the default number used for 0.
+	--experimental-v3demo 'false'
+		enable experimental v3 demo API. The v3 gRPC service is served on the client urls.
+	--experimental-quota-backend-bytes '0'
+		raise alarms when the v3 storage backend size exceeds the given quota. 0 means the default quota of 2GB.
+	--experimental-auto-compaction-mode 'periodic'
+		mode of the v3 storage auto compaction: 'periodic' or 'revision'.
+	--experimental-auto-compaction-retention '0'
+		retention of the v3 storage auto compaction: hours in the periodic mode, revisions in the revision mode. 0 means disable auto compaction.
+	--experimental-peer-grpc 'false'
+		send raft messages and snapshots over gRPC to the peers that support it. The gRPC transport is served on the peer urls.
+	--experimental-peer-compression 'none'
+		compression of the raft streams and snapshots sent to the peers that accept it: 'none', 'snappy' or 'gzip'.
+	--experimental-snapshot-bandwidth '0'
+		maximum bandwidth in bytes per second of the snapshots sent to each peer. 0 means unlimited.
+	--experimental-peer-cert-identities ''
+		comma-separated member name to CN or DNS SAN pattern pairs that the peer certificates of the members must match, e.g. 'infra0=infra0.example.com,*=*.example.com'.
 `
 )
@@ -20,6 +20,11 @@ import (
 	"net"
 	"net/http"
 	"time"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/google.golang.org/grpc"
+	"github.com/coreos/etcd/etcdserver"
+	"github.com/coreos/etcd/etcdserver/api/v3rpc"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
 )
 
 // serveHTTP accepts incoming HTTP connections on the listener l,
@@ -35,3 +40,12 @@ func serveHTTP(l net.Listener, handler http.Handler, readTimeout time.Duration)
 	}
 	return srv.Serve(l)
 }
+
+// serveGRPC accepts incoming gRPC connections on the listener l and
+// serves the v3 etcdserverpb services of s on them.
+func serveGRPC(l net.Listener, s etcdserver.V3DemoServer) error {
+	srv := grpc.NewServer()
+	pb.RegisterEtcdServer(srv, v3rpc.New(s))
+	pb.RegisterMaintenanceServer(srv, v3rpc.NewMaintenance(s))
+	return srv.Serve(l)
+}
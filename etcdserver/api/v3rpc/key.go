@@ -0,0 +1,160 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v3rpc implements the v3 etcdserverpb gRPC service on top of
+// an etcd server.
+package v3rpc
+
+import (
+	"io"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	"github.com/coreos/etcd/Godeps/_workspace/src/google.golang.org/grpc"
+	"github.com/coreos/etcd/Godeps/_workspace/src/google.golang.org/grpc/codes"
+	"github.com/coreos/etcd/etcdserver"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/lease"
+	"github.com/coreos/etcd/storage"
+)
+
+type handler struct {
+	server etcdserver.V3DemoServer
+}
+
+func New(s etcdserver.V3DemoServer) pb.EtcdServer {
+	return &handler{s}
+}
+
+func (h *handler) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, error) {
+	resp, err := h.server.V3DemoRange(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (h *handler) Put(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse, error) {
+	resp, err := h.server.V3DemoDo(ctx, pb.InternalRaftRequest{Put: r})
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp.(*pb.PutResponse), nil
+}
+
+func (h *handler) DeleteRange(ctx context.Context, r *pb.DeleteRangeRequest) (*pb.DeleteRangeResponse, error) {
+	resp, err := h.server.V3DemoDo(ctx, pb.InternalRaftRequest{DeleteRange: r})
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp.(*pb.DeleteRangeResponse), nil
+}
+
+func (h *handler) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse, error) {
+	resp, err := h.server.V3DemoDo(ctx, pb.InternalRaftRequest{Txn: r})
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp.(*pb.TxnResponse), nil
+}
+
+func (h *handler) Compact(ctx context.Context, r *pb.CompactionRequest) (*pb.CompactionResponse, error) {
+	resp, err := h.server.V3DemoDo(ctx, pb.InternalRaftRequest{Compaction: r})
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp.(*pb.CompactionResponse), nil
+}
+
+func (h *handler) LeaseCreate(ctx context.Context, r *pb.LeaseCreateRequest) (*pb.LeaseCreateResponse, error) {
+	resp, err := h.server.V3DemoDo(ctx, pb.InternalRaftRequest{LeaseCreate: r})
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp.(*pb.LeaseCreateResponse), nil
+}
+
+func (h *handler) LeaseRevoke(ctx context.Context, r *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error) {
+	resp, err := h.server.V3DemoDo(ctx, pb.InternalRaftRequest{LeaseRevoke: r})
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp.(*pb.LeaseRevokeResponse), nil
+}
+
+func (h *handler) LeaseKeepAlive(stream pb.Etcd_LeaseKeepAliveServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		ttl, err := h.server.V3DemoLeaseRenew(lease.LeaseID(req.ID))
+		switch err {
+		case nil:
+		case lease.ErrLeaseNotFound:
+			// a TTL of zero tells the client that the lease has been
+			// revoked or has expired.
+			ttl = 0
+		default:
+			return togRPCError(err)
+		}
+
+		resp := &pb.LeaseKeepAliveResponse{
+			Header: h.server.V3DemoHeader(),
+			ID:     req.ID,
+			TTL:    ttl,
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func togRPCError(err error) error {
+	switch err {
+	case storage.ErrCompacted, storage.ErrFutureRev:
+		return grpc.Errorf(codes.OutOfRange, "%v", err)
+	case etcdserver.ErrTimeout:
+		return grpc.Errorf(codes.DeadlineExceeded, "%v", err)
+	case etcdserver.ErrCanceled:
+		return grpc.Errorf(codes.Canceled, "%v", err)
+	case etcdserver.ErrStopped:
+		return grpc.Errorf(codes.Unavailable, "%v", err)
+	case etcdserver.ErrV3NotEnabled:
+		return grpc.Errorf(codes.Unimplemented, "%v", err)
+	case etcdserver.ErrInvalidConsistentToken:
+		return grpc.Errorf(codes.InvalidArgument, "%v", err)
+	case etcdserver.ErrRevisionBeforeToken:
+		return grpc.Errorf(codes.OutOfRange, "%v", err)
+	case etcdserver.ErrNoSpace:
+		return grpc.Errorf(codes.ResourceExhausted, "%v", err)
+	case etcdserver.ErrNotLeader:
+		return grpc.Errorf(codes.FailedPrecondition, "%v", err)
+	case etcdserver.ErrNoLeader:
+		return grpc.Errorf(codes.Unavailable, "%v", err)
+	case etcdserver.ErrTimeoutLeaderTransfer:
+		return grpc.Errorf(codes.DeadlineExceeded, "%v", err)
+	case etcdserver.ErrIDNotFound:
+		return grpc.Errorf(codes.NotFound, "%v", err)
+	case lease.ErrLeaseNotFound:
+		return grpc.Errorf(codes.NotFound, "%v", err)
+	case lease.ErrLeaseExists:
+		return grpc.Errorf(codes.AlreadyExists, "%v", err)
+	default:
+		return grpc.Errorf(codes.Unknown, "%v", err)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	"github.com/coreos/etcd/etcdserver"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+)
+
+type maintenanceServer struct {
+	server etcdserver.V3DemoServer
+}
+
+func NewMaintenance(s etcdserver.V3DemoServer) pb.MaintenanceServer {
+	return &maintenanceServer{s}
+}
+
+func (ms *maintenanceServer) Defragment(ctx context.Context, r *pb.DefragmentRequest) (*pb.DefragmentResponse, error) {
+	if err := ms.server.V3DemoDefragment(); err != nil {
+		return nil, togRPCError(err)
+	}
+	return &pb.DefragmentResponse{Header: ms.server.V3DemoHeader()}, nil
+}
+
+func (ms *maintenanceServer) Alarm(ctx context.Context, r *pb.AlarmRequest) (*pb.AlarmResponse, error) {
+	resp, err := ms.server.V3DemoDo(ctx, pb.InternalRaftRequest{Alarm: r})
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp.(*pb.AlarmResponse), nil
+}
+
+func (ms *maintenanceServer) MoveLeader(ctx context.Context, r *pb.MoveLeaderRequest) (*pb.MoveLeaderResponse, error) {
+	if err := ms.server.V3DemoMoveLeader(ctx, r.TargetId); err != nil {
+		return nil, togRPCError(err)
+	}
+	return &pb.MoveLeaderResponse{Header: ms.server.V3DemoHeader()}, nil
+}
@@ -0,0 +1,179 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"io"
+	"sync"
+
+	"github.com/coreos/etcd/etcdserver"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/storage"
+	"github.com/coreos/etcd/storage/storagepb"
+)
+
+func (h *handler) Watch(stream pb.Etcd_WatchServer) error {
+	ws := &watchStream{
+		server:   h.server,
+		watchers: make(map[int64]storage.Watcher),
+		respc:    make(chan *pb.WatchResponse),
+		stopc:    make(chan struct{}),
+	}
+	defer ws.close()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- ws.recvLoop(stream)
+	}()
+
+	for {
+		select {
+		case resp := <-ws.respc:
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		case err := <-errc:
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// watchStream multiplexes the watchers created through one watch stream.
+// All the responses of the watchers are sent by the stream handler, since
+// a gRPC stream does not support concurrent sends.
+type watchStream struct {
+	server etcdserver.V3DemoServer
+	// nextID is the ID of the next created watcher. It is only accessed
+	// by the receive loop.
+	nextID int64
+
+	mu       sync.Mutex
+	watchers map[int64]storage.Watcher
+
+	respc chan *pb.WatchResponse
+	stopc chan struct{}
+}
+
+func (ws *watchStream) recvLoop(stream pb.Etcd_WatchServer) error {
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		switch {
+		case req.CreateRequest != nil:
+			if err := ws.create(req.CreateRequest); err != nil {
+				return err
+			}
+		case req.CancelRequest != nil:
+			ws.cancel(req.CancelRequest.WatchId)
+		}
+	}
+}
+
+func (ws *watchStream) create(r *pb.WatchCreateRequest) error {
+	id := ws.nextID
+	ws.nextID++
+
+	w, err := ws.server.V3DemoWatch(r.Key, r.RangeEnd, r.StartRevision)
+	resp := &pb.WatchResponse{
+		Header:  ws.server.V3DemoHeader(),
+		WatchId: id,
+		Created: true,
+	}
+	switch err {
+	case nil:
+	case storage.ErrCompacted:
+		resp.Canceled = true
+		resp.Compacted = true
+		ws.send(resp)
+		return nil
+	default:
+		return togRPCError(err)
+	}
+
+	ws.mu.Lock()
+	ws.watchers[id] = w
+	ws.mu.Unlock()
+
+	// the created response is sent before any event of the watcher.
+	ws.send(resp)
+	go ws.forward(id, w)
+	return nil
+}
+
+func (ws *watchStream) cancel(id int64) {
+	ws.mu.Lock()
+	w, ok := ws.watchers[id]
+	ws.mu.Unlock()
+	if ok {
+		w.Cancel()
+	}
+}
+
+// forward sends the events received by the given watcher to the stream
+// until the watcher is stopped.
+func (ws *watchStream) forward(id int64, w storage.Watcher) {
+	for evs := range w.Event() {
+		resp := &pb.WatchResponse{
+			Header:  ws.server.V3DemoHeader(),
+			WatchId: id,
+			Events:  make([]*storagepb.Event, len(evs)),
+		}
+		// all the events share the same revision
+		resp.Header.Index = evs[0].Kv.ModIndex
+		for i := range evs {
+			resp.Events[i] = &evs[i]
+		}
+		if !ws.send(resp) {
+			return
+		}
+	}
+
+	ws.mu.Lock()
+	delete(ws.watchers, id)
+	ws.mu.Unlock()
+
+	ws.send(&pb.WatchResponse{
+		Header:    ws.server.V3DemoHeader(),
+		WatchId:   id,
+		Canceled:  true,
+		Compacted: w.Err() == storage.ErrCompacted,
+	})
+}
+
+// send sends the given response to the stream. It returns false if the
+// stream has been closed.
+func (ws *watchStream) send(resp *pb.WatchResponse) bool {
+	select {
+	case ws.respc <- resp:
+		return true
+	case <-ws.stopc:
+		return false
+	}
+}
+
+func (ws *watchStream) close() {
+	close(ws.stopc)
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	for _, w := range ws.watchers {
+		w.Cancel()
+	}
+}
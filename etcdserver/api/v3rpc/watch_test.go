@@ -0,0 +1,172 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/gogo/protobuf/proto"
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	"github.com/coreos/etcd/Godeps/_workspace/src/google.golang.org/grpc"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/lease"
+	"github.com/coreos/etcd/storage"
+)
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "v3rpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	kv := storage.NewWatchable(path.Join(dir, "db"))
+	defer kv.Close()
+
+	kv.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	kv.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	kv.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	kv.Compact(1)
+
+	stream := &fakeWatchStream{
+		reqc:  make(chan *pb.WatchRequest),
+		respc: make(chan *pb.WatchResponse),
+	}
+	h := New(&fakeServer{kv: kv})
+	errc := make(chan error, 1)
+	go func() {
+		errc <- h.Watch(stream)
+	}()
+
+	// watch a key from now on
+	stream.reqc <- &pb.WatchRequest{CreateRequest: &pb.WatchCreateRequest{Key: []byte("foo")}}
+	resp := stream.recv(t)
+	if !resp.Created || resp.WatchId != 0 || resp.Header.Index != 3 {
+		t.Fatalf("resp = %+v, want created watch 0 at index 3", resp)
+	}
+
+	// watch a range from the history
+	stream.reqc <- &pb.WatchRequest{CreateRequest: &pb.WatchCreateRequest{Key: []byte("foo"), RangeEnd: []byte("fop"), StartRevision: 3}}
+	resp = stream.recv(t)
+	if !resp.Created || resp.WatchId != 1 {
+		t.Fatalf("resp = %+v, want created watch 1", resp)
+	}
+	resp = stream.recv(t)
+	if resp.WatchId != 1 || resp.Header.Index != 3 || len(resp.Events) != 1 {
+		t.Fatalf("resp = %+v, want one event of watch 1 at index 3", resp)
+	}
+
+	// watch a compacted revision
+	stream.reqc <- &pb.WatchRequest{CreateRequest: &pb.WatchCreateRequest{Key: []byte("foo"), StartRevision: 1}}
+	resp = stream.recv(t)
+	if !resp.Created || !resp.Canceled || !resp.Compacted || resp.WatchId != 2 {
+		t.Fatalf("resp = %+v, want compacted watch 2", resp)
+	}
+
+	// both watches receive the new event
+	kv.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	got := make(map[int64]bool)
+	for i := 0; i < 2; i++ {
+		resp = stream.recv(t)
+		if resp.Header.Index != 4 || len(resp.Events) != 1 {
+			t.Fatalf("resp = %+v, want one event at index 4", resp)
+		}
+		got[resp.WatchId] = true
+	}
+	if !got[0] || !got[1] {
+		t.Errorf("watches with events = %v, want 0 and 1", got)
+	}
+
+	stream.reqc <- &pb.WatchRequest{CancelRequest: &pb.WatchCancelRequest{WatchId: 0}}
+	resp = stream.recv(t)
+	if !resp.Canceled || resp.Compacted || resp.WatchId != 0 {
+		t.Fatalf("resp = %+v, want canceled watch 0", resp)
+	}
+
+	close(stream.reqc)
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Errorf("watch error = %v, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("watch does not return after the stream is closed")
+	}
+}
+
+type fakeServer struct {
+	kv storage.WatchableKV
+}
+
+func (s *fakeServer) V3DemoDo(ctx context.Context, r pb.InternalRaftRequest) (proto.Message, error) {
+	return nil, nil
+}
+
+func (s *fakeServer) V3DemoRange(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, error) {
+	return nil, nil
+}
+
+func (s *fakeServer) V3DemoWatch(key, end []byte, startRev int64) (storage.Watcher, error) {
+	return s.kv.Watch(key, end, startRev)
+}
+
+func (s *fakeServer) V3DemoHeader() *pb.ResponseHeader {
+	return &pb.ResponseHeader{Index: s.kv.Rev()}
+}
+
+func (s *fakeServer) V3DemoLeaseRenew(id lease.LeaseID) (int64, error) {
+	return -1, lease.ErrLeaseNotFound
+}
+
+func (s *fakeServer) V3DemoDefragment() error {
+	return nil
+}
+
+func (s *fakeServer) V3DemoMoveLeader(ctx context.Context, transferee uint64) error {
+	return nil
+}
+
+type fakeWatchStream struct {
+	grpc.ServerStream
+	reqc  chan *pb.WatchRequest
+	respc chan *pb.WatchResponse
+}
+
+func (s *fakeWatchStream) Send(resp *pb.WatchResponse) error {
+	s.respc <- resp
+	return nil
+}
+
+func (s *fakeWatchStream) Recv() (*pb.WatchRequest, error) {
+	req, ok := <-s.reqc
+	if !ok {
+		return nil, io.EOF
+	}
+	return req, nil
+}
+
+func (s *fakeWatchStream) recv(t *testing.T) *pb.WatchResponse {
+	select {
+	case resp := <-s.respc:
+		return resp
+	case <-time.After(time.Second):
+		t.Fatalf("failed to receive watch response")
+	}
+	return nil
+}
@@ -232,8 +232,18 @@ func (c *cluster) ValidateConfigurationChange(cc raftpb.ConfChange) error {
 		return ErrIDRemoved
 	}
 	switch cc.Type {
-	case raftpb.ConfChangeAddNode:
-		if members[id] != nil {
+	case raftpb.ConfChangeAddNode, raftpb.ConfChangeAddLearnerNode:
+		if em := members[id]; em != nil {
+			if cc.Type == raftpb.ConfChangeAddNode && em.IsLearner {
+				// adding an existing learner as a voter promotes it.
+				m := new(Member)
+				if err := json.Unmarshal(cc.Context, m); err != nil {
+					plog.Panicf("unmarshal member should never fail: %v", err)
+				}
+				if !m.IsLearner {
+					return nil
+				}
+			}
 			return ErrIDExists
 		}
 		urls := make(map[string]bool)
@@ -278,7 +288,71 @@ func (c *cluster) ValidateConfigurationChange(cc raftpb.ConfChange) error {
 			}
 		}
 	default:
-		plog.Panicf("ConfChange type should be either AddNode, RemoveNode or UpdateNode")
+		plog.Panicf("ConfChange type should be either AddNode, AddLearnerNode, RemoveNode or UpdateNode")
+	}
+	return nil
+}
+
+// ValidateConfigurationChangeV2 takes a proposed joint ConfChangeV2 and
+// ensures that it is still valid. The Context of the change holds the
+// added members. An added member may reuse the peer URLs of a member that
+// the same change removes.
+func (c *cluster) ValidateConfigurationChangeV2(cc raftpb.ConfChangeV2) error {
+	members, removed := membersFromStore(c.store)
+	var added []*Member
+	if err := json.Unmarshal(cc.Context, &added); err != nil {
+		plog.Panicf("unmarshal members should never fail: %v", err)
+	}
+	addedm := make(map[types.ID]*Member)
+	for _, m := range added {
+		addedm[m.ID] = m
+	}
+
+	seen := make(map[types.ID]bool)
+	leaving := make(map[types.ID]bool)
+	for _, sc := range cc.Changes {
+		id := types.ID(sc.NodeID)
+		if removed[id] {
+			return ErrIDRemoved
+		}
+		if seen[id] {
+			return ErrIDExists
+		}
+		seen[id] = true
+		switch sc.Type {
+		case raftpb.ConfChangeAddNode:
+			if members[id] != nil {
+				return ErrIDExists
+			}
+			if addedm[id] == nil {
+				plog.Panicf("added member %s should be in the context of the change", id)
+			}
+		case raftpb.ConfChangeRemoveNode:
+			if members[id] == nil {
+				return ErrIDNotFound
+			}
+			leaving[id] = true
+		default:
+			plog.Panicf("ConfChangeV2 type should be either AddNode or RemoveNode")
+		}
+	}
+
+	urls := make(map[string]bool)
+	for _, m := range members {
+		if leaving[m.ID] {
+			continue
+		}
+		for _, u := range m.PeerURLs {
+			urls[u] = true
+		}
+	}
+	for _, m := range added {
+		for _, u := range m.PeerURLs {
+			if urls[u] {
+				return ErrPeerURLexists
+			}
+			urls[u] = true
+		}
 	}
 	return nil
 }
@@ -345,6 +419,24 @@ func (c *cluster) UpdateRaftAttributes(id types.ID, raftAttr RaftAttributes) {
 	c.members[id].RaftAttributes = raftAttr
 }
 
+// PromoteMember marks the given learner member as a voting member, and
+// saves its raftAttributes into the store.
+func (c *cluster) PromoteMember(id types.ID) {
+	c.Lock()
+	defer c.Unlock()
+	raftAttr := c.members[id].RaftAttributes
+	raftAttr.IsLearner = false
+	b, err := json.Marshal(raftAttr)
+	if err != nil {
+		plog.Panicf("marshal raftAttributes should never fail: %v", err)
+	}
+	p := path.Join(memberStoreKey(id), raftAttributesSuffix)
+	if _, err := c.store.Update(p, string(b), store.Permanent); err != nil {
+		plog.Panicf("update raftAttributes should never fail: %v", err)
+	}
+	c.members[id].RaftAttributes = raftAttr
+}
+
 func (c *cluster) Version() *semver.Version {
 	c.Lock()
 	defer c.Unlock()
@@ -280,6 +280,7 @@ func TestClusterValidateConfigurationChange(t *testing.T) {
 		cl.AddMember(&Member{ID: types.ID(i), RaftAttributes: attr})
 	}
 	cl.RemoveMember(4)
+	cl.AddMember(&Member{ID: types.ID(6), RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:6"}, IsLearner: true}})
 
 	attr := RaftAttributes{PeerURLs: []string{fmt.Sprintf("http://127.0.0.1:%d", 1)}}
 	ctx, err := json.Marshal(&Member{ID: types.ID(5), RaftAttributes: attr})
@@ -305,6 +306,24 @@ func TestClusterValidateConfigurationChange(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	attr = RaftAttributes{PeerURLs: []string{fmt.Sprintf("http://127.0.0.1:%d", 7)}, IsLearner: true}
+	ctx7, err := json.Marshal(&Member{ID: types.ID(7), RaftAttributes: attr})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attr = RaftAttributes{PeerURLs: []string{fmt.Sprintf("http://127.0.0.1:%d", 6)}}
+	ctx6, err := json.Marshal(&Member{ID: types.ID(6), RaftAttributes: attr})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attr = RaftAttributes{PeerURLs: []string{fmt.Sprintf("http://127.0.0.1:%d", 6)}, IsLearner: true}
+	ctx6learner, err := json.Marshal(&Member{ID: types.ID(6), RaftAttributes: attr})
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	tests := []struct {
 		cc   raftpb.ConfChange
 		werr error
@@ -385,6 +404,38 @@ func TestClusterValidateConfigurationChange(t *testing.T) {
 			},
 			nil,
 		},
+		{
+			raftpb.ConfChange{
+				Type:    raftpb.ConfChangeAddLearnerNode,
+				NodeID:  7,
+				Context: ctx7,
+			},
+			nil,
+		},
+		{
+			raftpb.ConfChange{
+				Type:   raftpb.ConfChangeAddLearnerNode,
+				NodeID: 1,
+			},
+			ErrIDExists,
+		},
+		// promote the learner 6
+		{
+			raftpb.ConfChange{
+				Type:    raftpb.ConfChangeAddNode,
+				NodeID:  6,
+				Context: ctx6,
+			},
+			nil,
+		},
+		{
+			raftpb.ConfChange{
+				Type:    raftpb.ConfChangeAddLearnerNode,
+				NodeID:  6,
+				Context: ctx6learner,
+			},
+			ErrIDExists,
+		},
 	}
 	for i, tt := range tests {
 		err := cl.ValidateConfigurationChange(tt.cc)
@@ -506,6 +557,24 @@ func TestClusterRemoveMember(t *testing.T) {
 	}
 }
 
+func TestClusterPromoteMember(t *testing.T) {
+	st := store.New()
+	cl := newTestCluster(nil)
+	cl.SetStore(st)
+	cl.AddMember(&Member{ID: 1, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:1"}, IsLearner: true}})
+
+	cl.PromoteMember(1)
+
+	if cl.Member(1).IsLearner {
+		t.Errorf("member 1 is still a learner")
+	}
+	// the promotion is persisted in the store
+	members, _ := membersFromStore(st)
+	if members[1].IsLearner {
+		t.Errorf("member 1 in store is still a learner")
+	}
+}
+
 func TestClusterUpdateAttributes(t *testing.T) {
 	name := "etcd"
 	clientURLs := []string{"http://127.0.0.1:4001"}
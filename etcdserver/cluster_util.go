@@ -20,9 +20,11 @@ import (
 	"io/ioutil"
 	"net/http"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/coreos/go-semver/semver"
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
 	"github.com/coreos/etcd/pkg/types"
 	"github.com/coreos/etcd/version"
 )
@@ -252,3 +254,40 @@ func getVersion(m *Member, tr *http.Transport) (*version.Versions, error) {
 	}
 	return nil, err
 }
+
+// promoteMemberHTTP forwards the promotion of the given learner member to
+// the leader m via its peerURLs. It returns the error that the leader
+// reports, or the last error if it fails to reach the leader.
+func promoteMemberHTTP(ctx context.Context, m *Member, id uint64, tr *http.Transport) error {
+	cc := &http.Client{Transport: tr}
+	if d, ok := ctx.Deadline(); ok {
+		cc.Timeout = d.Sub(time.Now())
+	}
+	var err error
+	for _, u := range m.PeerURLs {
+		var resp *http.Response
+		resp, err = cc.Post(u+"/members/promote/"+types.ID(id).String(), "text/plain", nil)
+		if err != nil {
+			plog.Warningf("failed to reach the peerURL(%s) of member %s (%v)", u, m.ID, err)
+			continue
+		}
+		b, rerr := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNoContent {
+			return nil
+		}
+		if rerr != nil {
+			err = rerr
+			plog.Warningf("failed to read out the response body from the peerURL(%s) of member %s (%v)", u, m.ID, err)
+			continue
+		}
+		msg := strings.TrimSpace(string(b))
+		for _, perr := range []error{ErrIDNotFound, ErrMemberNotLearner, ErrLearnerNotReady, ErrNotLeader, ErrMemberChangeInProgress, ErrTimeout} {
+			if msg == perr.Error() {
+				return perr
+			}
+		}
+		return fmt.Errorf("etcdserver: failed to promote member %s on the leader (%s)", types.ID(id), msg)
+	}
+	return err
+}
@@ -20,6 +20,7 @@ import (
 	"path"
 	"reflect"
 	"sort"
+	"time"
 
 	"github.com/coreos/etcd/pkg/types"
 )
@@ -43,6 +44,49 @@ type ServerConfig struct {
 
 	TickMs        uint
 	ElectionTicks int
+	// PreVote enables the pre-vote phase of raft elections.
+	PreVote bool
+	// CheckQuorum makes the leader step down when it loses contact with
+	// a quorum of the cluster.
+	CheckQuorum bool
+	// MaxSizePerMsg is the maximum size in bytes of the entries carried by
+	// a single raft append message. If it is 0, the default size is used.
+	MaxSizePerMsg uint64
+	// MaxInflightMsgs is the maximum number of in-flight raft append
+	// messages to a single follower. If it is 0, the default number is used.
+	MaxInflightMsgs int
+
+	// V3demo enables the v3 storage and the v3 gRPC service.
+	V3demo bool
+	// QuotaBackendBytes is the quota of the v3 storage backend in bytes.
+	// If it is 0, the default quota is used.
+	QuotaBackendBytes int64
+	// AutoCompactionMode is the mode of the auto compaction of the v3
+	// storage, either "periodic" or "revision".
+	AutoCompactionMode string
+	// AutoCompactionRetention is the retention of the auto compaction:
+	// hours in the periodic mode, and revisions in the revision mode.
+	// If it is 0, auto compaction is disabled.
+	AutoCompactionRetention int
+	// PeerGRPC enables the gRPC transport to the peers that support it.
+	// The gRPC transport MUST be served on the peer listeners through
+	// EtcdServer.ServePeerGRPC.
+	PeerGRPC bool
+	// PeerCompression is the compression of the raft streams and
+	// snapshots sent to the peers that accept it: "none", "snappy" or
+	// "gzip". If it is empty, the data is not compressed.
+	PeerCompression string
+	// SnapshotBandwidth is the maximum bandwidth in bytes per second of
+	// the snapshots sent to each peer. If it is 0, the bandwidth is not
+	// limited.
+	SnapshotBandwidth int64
+	// PeerCertIdentities maps the member names to the patterns of the
+	// identities allowed in the peer certificates of the members, in the
+	// syntax of path.Match. The CN or one of the DNS SANs of the
+	// certificate presented by a peer must match the pattern of its
+	// member, or the pattern of "*" for the members without one. If it
+	// is empty, the peer certificates are not verified against members.
+	PeerCertIdentities map[string]string
 }
 
 // VerifyBootstrapConfig sanity-checks the initial config for bootstrap case
@@ -106,6 +150,38 @@ func (c *ServerConfig) WALDir() string { return path.Join(c.MemberDir(), "wal")
 
 func (c *ServerConfig) SnapDir() string { return path.Join(c.MemberDir(), "snap") }
 
+// backendQuota returns the quota of the v3 storage backend in bytes.
+func (c *ServerConfig) backendQuota() int64 {
+	if c.QuotaBackendBytes == 0 {
+		return DefaultQuotaBytes
+	}
+	return c.QuotaBackendBytes
+}
+
+// raftMaxSizePerMsg returns the maximum size of a raft append message.
+func (c *ServerConfig) raftMaxSizePerMsg() uint64 {
+	if c.MaxSizePerMsg == 0 {
+		return maxSizePerMsg
+	}
+	return c.MaxSizePerMsg
+}
+
+// raftMaxInflightMsgs returns the maximum number of in-flight raft append
+// messages to a single follower.
+func (c *ServerConfig) raftMaxInflightMsgs() int {
+	if c.MaxInflightMsgs == 0 {
+		return maxInflightMsgs
+	}
+	return c.MaxInflightMsgs
+}
+
+func (c *ServerConfig) StorageDir() string { return path.Join(c.MemberDir(), "v3demo") }
+
+// electionTimeout returns the election timeout of raft.
+func (c *ServerConfig) electionTimeout() time.Duration {
+	return time.Duration(c.ElectionTicks) * time.Duration(c.TickMs) * time.Millisecond
+}
+
 func (c *ServerConfig) ShouldDiscover() bool { return c.DiscoveryURL != "" }
 
 func (c *ServerConfig) PrintWithInitial() { c.print(true) }
@@ -121,7 +197,23 @@ func (c *ServerConfig) print(initial bool) {
 	plog.Infof("member dir = %s", c.MemberDir())
 	plog.Infof("heartbeat = %dms", c.TickMs)
 	plog.Infof("election = %dms", c.ElectionTicks*int(c.TickMs))
+	if c.PreVote {
+		plog.Infof("pre-vote = enabled")
+	}
+	if c.CheckQuorum {
+		plog.Infof("check quorum = enabled")
+	}
+	if c.MaxSizePerMsg != 0 || c.MaxInflightMsgs != 0 {
+		plog.Infof("raft max size per msg = %d bytes, max inflight msgs = %d", c.raftMaxSizePerMsg(), c.raftMaxInflightMsgs())
+	}
 	plog.Infof("snapshot count = %d", c.SnapCount)
+	if c.V3demo {
+		plog.Infof("v3 storage = %s", c.StorageDir())
+		plog.Infof("v3 storage quota = %d bytes", c.backendQuota())
+		if c.AutoCompactionRetention != 0 {
+			plog.Infof("v3 storage auto compaction = %s (retention %d)", c.AutoCompactionMode, c.AutoCompactionRetention)
+		}
+	}
 	if len(c.DiscoveryURL) != 0 {
 		plog.Infof("discovery URL= %s", c.DiscoveryURL)
 		if len(c.DiscoveryProxy) != 0 {
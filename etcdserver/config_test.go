@@ -192,3 +192,23 @@ func TestShouldDiscover(t *testing.T) {
 		}
 	}
 }
+
+func TestRaftFlowControlConfig(t *testing.T) {
+	tests := []struct {
+		cfg ServerConfig
+
+		wsize     uint64
+		winflight int
+	}{
+		{ServerConfig{}, maxSizePerMsg, maxInflightMsgs},
+		{ServerConfig{MaxSizePerMsg: 4096, MaxInflightMsgs: 16}, 4096, 16},
+	}
+	for i, tt := range tests {
+		if g := tt.cfg.raftMaxSizePerMsg(); g != tt.wsize {
+			t.Errorf("#%d: raftMaxSizePerMsg()=%d, want=%d", i, g, tt.wsize)
+		}
+		if g := tt.cfg.raftMaxInflightMsgs(); g != tt.winflight {
+			t.Errorf("#%d: raftMaxInflightMsgs()=%d, want=%d", i, g, tt.winflight)
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"errors"
 
 	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/raft"
 
 	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
 )
@@ -31,6 +32,24 @@ var (
 	ErrPeerURLexists = errors.New("etcdserver: peerURL exists")
 	ErrCanceled      = errors.New("etcdserver: request cancelled")
 	ErrTimeout       = errors.New("etcdserver: request timed out")
+	ErrV3NotEnabled  = errors.New("etcdserver: v3 storage is not enabled")
+	ErrNotLeader     = errors.New("etcdserver: not leader")
+	ErrNoLeader      = errors.New("etcdserver: no leader")
+	ErrNoSpace       = errors.New("etcdserver: database space exceeded")
+	ErrNoPeerGRPC    = errors.New("etcdserver: peer gRPC transport is not enabled")
+
+	ErrPeerGRPCCertIdentities = errors.New("etcdserver: peer certificate identities cannot be verified over the peer gRPC transport")
+
+	ErrMemberNotLearner = errors.New("etcdserver: can only promote a learner member")
+	ErrLearnerNotReady  = errors.New("etcdserver: can only promote a learner member which is in sync with leader")
+	ErrMemberIsLearner  = errors.New("etcdserver: learner member cannot become the leader")
+
+	ErrMemberChangeInProgress = errors.New("etcdserver: another membership change is in progress")
+
+	ErrTimeoutLeaderTransfer = errors.New("etcdserver: request timed out, leader transfer took too long")
+
+	ErrInvalidConsistentToken = errors.New("etcdserver: invalid consistent token")
+	ErrRevisionBeforeToken    = errors.New("etcdserver: revision is older than the consistent token")
 )
 
 func parseCtxErr(err error) error {
@@ -44,6 +63,23 @@ func parseCtxErr(err error) error {
 	}
 }
 
+// parseProposeErr converts the error of a raft proposal. A proposal
+// dropped by raft fails with ErrNoLeader, so that the client can retry it
+// on another member, and a configuration change dropped while another one
+// is in progress fails with ErrMemberChangeInProgress.
+func parseProposeErr(err error) error {
+	switch err {
+	case raft.ErrProposalDropped:
+		return ErrNoLeader
+	case raft.ErrConfChangeInProgress:
+		return ErrMemberChangeInProgress
+	case raft.ErrStopped:
+		return ErrStopped
+	default:
+		return parseCtxErr(err)
+	}
+}
+
 func isKeyNotFound(err error) bool {
 	e, ok := err.(*etcdErr.Error)
 	return ok && e.ErrorCode == etcdErr.EcodeKeyNotFound
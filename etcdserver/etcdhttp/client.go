@@ -213,12 +213,21 @@ func (h *membersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			writeError(w, httptypes.NewHTTPError(http.StatusNotFound, "Not found"))
 		}
 	case "POST":
+		if p := trimPrefix(r.URL.Path, membersPrefix); p != "" {
+			if path.Base(p) == "replace" {
+				h.serveReplace(ctx, w, r)
+				return
+			}
+			h.servePromote(ctx, w, r)
+			return
+		}
 		req := httptypes.MemberCreateRequest{}
 		if ok := unmarshalRequest(r, &req, w); !ok {
 			return
 		}
 		now := h.clock.Now()
 		m := etcdserver.NewMember("", req.PeerURLs, "", &now)
+		m.IsLearner = req.IsLearner
 		err := h.server.AddMember(ctx, *m)
 		switch {
 		case err == etcdserver.ErrIDExists || err == etcdserver.ErrPeerURLexists:
@@ -280,6 +289,79 @@ func (h *membersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// servePromote promotes the learner member given by the request path,
+// which is in the form of "<id>/promote".
+func (h *membersHandler) servePromote(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	p := trimPrefix(r.URL.Path, membersPrefix)
+	if path.Base(p) != "promote" || path.Dir(p) == "." {
+		writeError(w, httptypes.NewHTTPError(http.StatusNotFound, "Not found"))
+		return
+	}
+	id, err := types.IDFromString(path.Dir(p))
+	if err != nil {
+		writeError(w, httptypes.NewHTTPError(http.StatusNotFound, fmt.Sprintf("No such member: %s", path.Dir(p))))
+		return
+	}
+	err = h.server.PromoteMember(ctx, uint64(id))
+	switch {
+	case err == etcdserver.ErrIDNotFound:
+		writeError(w, httptypes.NewHTTPError(http.StatusNotFound, fmt.Sprintf("No such member: %s", id)))
+	case err == etcdserver.ErrMemberNotLearner || err == etcdserver.ErrLearnerNotReady:
+		writeError(w, httptypes.NewHTTPError(http.StatusPreconditionFailed, err.Error()))
+	case err == etcdserver.ErrNotLeader || err == etcdserver.ErrNoLeader:
+		// the client retries the request on the other members
+		writeError(w, httptypes.NewHTTPError(http.StatusServiceUnavailable, err.Error()))
+	case err != nil:
+		plog.Errorf("error promoting member %s (%v)", id, err)
+		writeError(w, err)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// serveReplace replaces the member given by the request path, which is in
+// the form of "<id>/replace", with the new member in the request body.
+func (h *membersHandler) serveReplace(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	p := trimPrefix(r.URL.Path, membersPrefix)
+	if path.Dir(p) == "." {
+		writeError(w, httptypes.NewHTTPError(http.StatusNotFound, "Not found"))
+		return
+	}
+	id, err := types.IDFromString(path.Dir(p))
+	if err != nil {
+		writeError(w, httptypes.NewHTTPError(http.StatusNotFound, fmt.Sprintf("No such member: %s", path.Dir(p))))
+		return
+	}
+	req := httptypes.MemberCreateRequest{}
+	if ok := unmarshalRequest(r, &req, w); !ok {
+		return
+	}
+	now := h.clock.Now()
+	m := etcdserver.NewMember("", req.PeerURLs, "", &now)
+	err = h.server.ReplaceMember(ctx, uint64(id), *m)
+	switch {
+	case err == etcdserver.ErrIDRemoved:
+		writeError(w, httptypes.NewHTTPError(http.StatusGone, fmt.Sprintf("Member permanently removed: %s", id)))
+		return
+	case err == etcdserver.ErrIDNotFound:
+		writeError(w, httptypes.NewHTTPError(http.StatusNotFound, fmt.Sprintf("No such member: %s", id)))
+		return
+	case err == etcdserver.ErrIDExists || err == etcdserver.ErrPeerURLexists || err == etcdserver.ErrMemberChangeInProgress:
+		writeError(w, httptypes.NewHTTPError(http.StatusConflict, err.Error()))
+		return
+	case err != nil:
+		plog.Errorf("error replacing member %s with %s (%v)", id, m.ID, err)
+		writeError(w, err)
+		return
+	}
+	res := newMember(m)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		plog.Warningf("failed to encode members response (%v)", err)
+	}
+}
+
 type statsHandler struct {
 	stats stats.Stats
 }
@@ -703,6 +785,7 @@ func newMember(m *etcdserver.Member) httptypes.Member {
 		Name:       m.Name,
 		PeerURLs:   make([]string, len(m.PeerURLs)),
 		ClientURLs: make([]string, len(m.ClientURLs)),
+		IsLearner:  m.IsLearner,
 	}
 
 	copy(tm.PeerURLs, m.PeerURLs)
@@ -117,6 +117,16 @@ func (s *serverRecorder) UpdateMember(_ context.Context, m etcdserver.Member) er
 	return nil
 }
 
+func (s *serverRecorder) PromoteMember(_ context.Context, id uint64) error {
+	s.actions = append(s.actions, action{name: "PromoteMember", params: []interface{}{id}})
+	return nil
+}
+
+func (s *serverRecorder) ReplaceMember(_ context.Context, id uint64, m etcdserver.Member) error {
+	s.actions = append(s.actions, action{name: "ReplaceMember", params: []interface{}{id, m}})
+	return nil
+}
+
 func (s *serverRecorder) ClusterVersion() *semver.Version { return nil }
 
 type action struct {
@@ -152,7 +162,11 @@ func (rs *resServer) Process(_ context.Context, _ raftpb.Message) error
 func (rs *resServer) AddMember(_ context.Context, _ etcdserver.Member) error    { return nil }
 func (rs *resServer) RemoveMember(_ context.Context, _ uint64) error            { return nil }
 func (rs *resServer) UpdateMember(_ context.Context, _ etcdserver.Member) error { return nil }
-func (rs *resServer) ClusterVersion() *semver.Version                           { return nil }
+func (rs *resServer) PromoteMember(_ context.Context, _ uint64) error           { return nil }
+func (rs *resServer) ReplaceMember(_ context.Context, _ uint64, _ etcdserver.Member) error {
+	return nil
+}
+func (rs *resServer) ClusterVersion() *semver.Version { return nil }
 
 func boolp(b bool) *bool { return &b }
 
@@ -711,6 +725,229 @@ func TestServeMembersCreate(t *testing.T) {
 	}
 }
 
+func TestServeMembersCreateLearner(t *testing.T) {
+	u := testutil.MustNewURL(t, membersPrefix)
+	b := []byte(`{"peerURLs":["http://127.0.0.1:1"],"isLearner":true}`)
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s := &serverRecorder{}
+	h := &membersHandler{
+		server:  s,
+		clock:   clockwork.NewFakeClock(),
+		cluster: &fakeCluster{id: 1},
+	}
+	rw := httptest.NewRecorder()
+
+	h.ServeHTTP(rw, req)
+
+	wcode := http.StatusCreated
+	if rw.Code != wcode {
+		t.Errorf("code=%d, want %d", rw.Code, wcode)
+	}
+
+	wb := `{"id":"2a86a83729b330d5","name":"","peerURLs":["http://127.0.0.1:1"],"clientURLs":[],"isLearner":true}` + "\n"
+	g := rw.Body.String()
+	if g != wb {
+		t.Errorf("got body=%q, want %q", g, wb)
+	}
+
+	wm := etcdserver.Member{
+		ID: 3064321551348478165,
+		RaftAttributes: etcdserver.RaftAttributes{
+			PeerURLs:  []string{"http://127.0.0.1:1"},
+			IsLearner: true,
+		},
+	}
+
+	wactions := []action{{name: "AddMember", params: []interface{}{wm}}}
+	if !reflect.DeepEqual(s.actions, wactions) {
+		t.Errorf("actions = %+v, want %+v", s.actions, wactions)
+	}
+}
+
+func TestServeMembersPromote(t *testing.T) {
+	tests := []struct {
+		path   string
+		server etcdserver.Server
+
+		wcode    int
+		wactions []action
+	}{
+		{
+			path.Join(membersPrefix, "BEEF", "promote"),
+			&serverRecorder{},
+
+			http.StatusNoContent,
+			[]action{{name: "PromoteMember", params: []interface{}{uint64(0xBEEF)}}},
+		},
+		{
+			path.Join(membersPrefix, "BEEF", "demote"),
+			&serverRecorder{},
+
+			http.StatusNotFound,
+			nil,
+		},
+		{
+			path.Join(membersPrefix, "XXX", "promote"),
+			&serverRecorder{},
+
+			http.StatusNotFound,
+			nil,
+		},
+		{
+			path.Join(membersPrefix, "BEEF", "promote"),
+			&errServer{etcdserver.ErrIDNotFound},
+
+			http.StatusNotFound,
+			nil,
+		},
+		{
+			path.Join(membersPrefix, "BEEF", "promote"),
+			&errServer{etcdserver.ErrLearnerNotReady},
+
+			http.StatusPreconditionFailed,
+			nil,
+		},
+		{
+			path.Join(membersPrefix, "BEEF", "promote"),
+			&errServer{etcdserver.ErrMemberNotLearner},
+
+			http.StatusPreconditionFailed,
+			nil,
+		},
+		{
+			path.Join(membersPrefix, "BEEF", "promote"),
+			&errServer{etcdserver.ErrNotLeader},
+
+			http.StatusServiceUnavailable,
+			nil,
+		},
+	}
+	for i, tt := range tests {
+		req := &http.Request{
+			Method: "POST",
+			URL:    testutil.MustNewURL(t, tt.path),
+		}
+		h := &membersHandler{
+			server:  tt.server,
+			cluster: &fakeCluster{id: 1},
+		}
+		rw := httptest.NewRecorder()
+
+		h.ServeHTTP(rw, req)
+
+		if rw.Code != tt.wcode {
+			t.Errorf("#%d: code=%d, want %d", i, rw.Code, tt.wcode)
+		}
+		if s, ok := tt.server.(*serverRecorder); ok && !reflect.DeepEqual(s.actions, tt.wactions) {
+			t.Errorf("#%d: actions = %+v, want %+v", i, s.actions, tt.wactions)
+		}
+	}
+}
+
+func TestServeMembersReplace(t *testing.T) {
+	u := testutil.MustNewURL(t, path.Join(membersPrefix, "BEEF", "replace"))
+	b := []byte(`{"peerURLs":["http://127.0.0.1:1"]}`)
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s := &serverRecorder{}
+	h := &membersHandler{
+		server:  s,
+		clock:   clockwork.NewFakeClock(),
+		cluster: &fakeCluster{id: 1},
+	}
+	rw := httptest.NewRecorder()
+
+	h.ServeHTTP(rw, req)
+
+	wcode := http.StatusCreated
+	if rw.Code != wcode {
+		t.Errorf("code=%d, want %d", rw.Code, wcode)
+	}
+
+	wb := `{"id":"2a86a83729b330d5","name":"","peerURLs":["http://127.0.0.1:1"],"clientURLs":[]}` + "\n"
+	g := rw.Body.String()
+	if g != wb {
+		t.Errorf("got body=%q, want %q", g, wb)
+	}
+
+	wm := etcdserver.Member{
+		ID: 3064321551348478165,
+		RaftAttributes: etcdserver.RaftAttributes{
+			PeerURLs: []string{"http://127.0.0.1:1"},
+		},
+	}
+	wactions := []action{{name: "ReplaceMember", params: []interface{}{uint64(0xBEEF), wm}}}
+	if !reflect.DeepEqual(s.actions, wactions) {
+		t.Errorf("actions = %+v, want %+v", s.actions, wactions)
+	}
+}
+
+func TestServeMembersReplaceFail(t *testing.T) {
+	tests := []struct {
+		path   string
+		server etcdserver.Server
+
+		wcode int
+	}{
+		{
+			path.Join(membersPrefix, "XXX", "replace"),
+			&serverRecorder{},
+
+			http.StatusNotFound,
+		},
+		{
+			path.Join(membersPrefix, "BEEF", "replace"),
+			&errServer{etcdserver.ErrIDNotFound},
+
+			http.StatusNotFound,
+		},
+		{
+			path.Join(membersPrefix, "BEEF", "replace"),
+			&errServer{etcdserver.ErrIDRemoved},
+
+			http.StatusGone,
+		},
+		{
+			path.Join(membersPrefix, "BEEF", "replace"),
+			&errServer{etcdserver.ErrPeerURLexists},
+
+			http.StatusConflict,
+		},
+		{
+			path.Join(membersPrefix, "BEEF", "replace"),
+			&errServer{etcdserver.ErrMemberChangeInProgress},
+
+			http.StatusConflict,
+		},
+	}
+	for i, tt := range tests {
+		req, err := http.NewRequest("POST", testutil.MustNewURL(t, tt.path).String(), bytes.NewReader([]byte(`{"peerURLs":["http://127.0.0.1:1"]}`)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		h := &membersHandler{
+			server:  tt.server,
+			clock:   clockwork.NewFakeClock(),
+			cluster: &fakeCluster{id: 1},
+		}
+		rw := httptest.NewRecorder()
+
+		h.ServeHTTP(rw, req)
+
+		if rw.Code != tt.wcode {
+			t.Errorf("#%d: code=%d, want %d", i, rw.Code, tt.wcode)
+		}
+	}
+}
+
 func TestServeMembersDelete(t *testing.T) {
 	req := &http.Request{
 		Method: "DELETE",
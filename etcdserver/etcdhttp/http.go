@@ -23,6 +23,7 @@ import (
 
 	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/coreos/pkg/capnslog"
 	etcdErr "github.com/coreos/etcd/error"
+	"github.com/coreos/etcd/etcdserver"
 	"github.com/coreos/etcd/etcdserver/auth"
 	"github.com/coreos/etcd/etcdserver/etcdhttp/httptypes"
 )
@@ -59,9 +60,19 @@ func writeError(w http.ResponseWriter, err error) {
 		herr := httptypes.NewHTTPError(e.HTTPStatus(), e.Error())
 		herr.WriteTo(w)
 	default:
-		plog.Errorf("got unexpected response error (%v)", err)
-		herr := httptypes.NewHTTPError(http.StatusInternalServerError, "Internal Server Error")
-		herr.WriteTo(w)
+		switch err {
+		case etcdserver.ErrNoLeader:
+			// the client retries the request on the other members
+			herr := httptypes.NewHTTPError(http.StatusServiceUnavailable, err.Error())
+			herr.WriteTo(w)
+		case etcdserver.ErrMemberChangeInProgress:
+			herr := httptypes.NewHTTPError(http.StatusConflict, err.Error())
+			herr.WriteTo(w)
+		default:
+			plog.Errorf("got unexpected response error (%v)", err)
+			herr := httptypes.NewHTTPError(http.StatusInternalServerError, "Internal Server Error")
+			herr.WriteTo(w)
+		}
 	}
 }
 
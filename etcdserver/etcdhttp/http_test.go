@@ -75,6 +75,12 @@ func (fs *errServer) RemoveMember(ctx context.Context, id uint64) error {
 func (fs *errServer) UpdateMember(ctx context.Context, m etcdserver.Member) error {
 	return fs.err
 }
+func (fs *errServer) PromoteMember(ctx context.Context, id uint64) error {
+	return fs.err
+}
+func (fs *errServer) ReplaceMember(ctx context.Context, id uint64, m etcdserver.Member) error {
+	return fs.err
+}
 
 func (fs *errServer) ClusterVersion() *semver.Version { return nil }
 
@@ -110,6 +116,14 @@ func TestWriteError(t *testing.T) {
 			err:   errors.New("something went wrong"),
 			wcode: http.StatusInternalServerError,
 		},
+		{
+			err:   etcdserver.ErrNoLeader,
+			wcode: http.StatusServiceUnavailable,
+		},
+		{
+			err:   etcdserver.ErrMemberChangeInProgress,
+			wcode: http.StatusConflict,
+		},
 	}
 
 	for i, tt := range tests {
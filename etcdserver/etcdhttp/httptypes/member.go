@@ -25,10 +25,12 @@ type Member struct {
 	Name       string   `json:"name"`
 	PeerURLs   []string `json:"peerURLs"`
 	ClientURLs []string `json:"clientURLs"`
+	IsLearner  bool     `json:"isLearner,omitempty"`
 }
 
 type MemberCreateRequest struct {
-	PeerURLs types.URLs
+	PeerURLs  types.URLs
+	IsLearner bool
 }
 
 type MemberUpdateRequest struct {
@@ -37,7 +39,8 @@ type MemberUpdateRequest struct {
 
 func (m *MemberCreateRequest) UnmarshalJSON(data []byte) error {
 	s := struct {
-		PeerURLs []string `json:"peerURLs"`
+		PeerURLs  []string `json:"peerURLs"`
+		IsLearner bool     `json:"isLearner"`
 	}{}
 
 	err := json.Unmarshal(data, &s)
@@ -51,6 +54,7 @@ func (m *MemberCreateRequest) UnmarshalJSON(data []byte) error {
 	}
 
 	m.PeerURLs = urls
+	m.IsLearner = s.IsLearner
 	return nil
 }
 
@@ -18,25 +18,32 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
 	"github.com/coreos/etcd/etcdserver"
+	"github.com/coreos/etcd/pkg/types"
 	"github.com/coreos/etcd/rafthttp"
 )
 
 const (
-	peerMembersPrefix = "/members"
+	peerMembersPrefix       = "/members"
+	peerMemberPromotePrefix = "/members/promote/"
 )
 
 // NewPeerHandler generates an http.Handler to handle etcd peer (raft) requests.
-func NewPeerHandler(cluster etcdserver.Cluster, raftHandler http.Handler) http.Handler {
+func NewPeerHandler(cluster etcdserver.Cluster, server etcdserver.Server, raftHandler http.Handler) http.Handler {
 	mh := &peerMembersHandler{
 		cluster: cluster,
 	}
+	ph := &peerMemberPromoteHandler{
+		server: server,
+	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", http.NotFound)
 	mux.Handle(rafthttp.RaftPrefix, raftHandler)
 	mux.Handle(rafthttp.RaftPrefix+"/", raftHandler)
 	mux.Handle(peerMembersPrefix, mh)
+	mux.Handle(peerMemberPromotePrefix, ph)
 	mux.HandleFunc(versionPath, versionHandler(cluster, serveVersion))
 	return mux
 }
@@ -61,3 +68,45 @@ func (h *peerMembersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		plog.Warningf("failed to encode members response (%v)", err)
 	}
 }
+
+// peerMemberPromoteHandler promotes a learner member on the leader for a
+// follower, which does not know the progress of the learner.
+type peerMemberPromoteHandler struct {
+	server etcdserver.Server
+}
+
+func (h *peerMemberPromoteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r.Method, "POST") {
+		return
+	}
+	id, err := types.IDFromString(trimPrefix(r.URL.Path, peerMemberPromotePrefix))
+	if err != nil {
+		http.Error(w, "bad path", http.StatusBadRequest)
+		return
+	}
+	// never forward the promotion again, so that it cannot go back and
+	// forth between the members that disagree on the leader.
+	if h.server.Leader() != h.server.ID() {
+		http.Error(w, etcdserver.ErrNotLeader.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultServerTimeout)
+	defer cancel()
+	err = h.server.PromoteMember(ctx, uint64(id))
+	switch err {
+	case nil:
+		w.WriteHeader(http.StatusNoContent)
+	case etcdserver.ErrIDNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case etcdserver.ErrMemberNotLearner, etcdserver.ErrLearnerNotReady:
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	case etcdserver.ErrMemberChangeInProgress:
+		http.Error(w, err.Error(), http.StatusConflict)
+	case etcdserver.ErrNotLeader:
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		plog.Errorf("error promoting member %s (%v)", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
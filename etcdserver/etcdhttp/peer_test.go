@@ -24,6 +24,7 @@ import (
 
 	"github.com/coreos/etcd/etcdserver"
 	"github.com/coreos/etcd/pkg/testutil"
+	"github.com/coreos/etcd/pkg/types"
 	"github.com/coreos/etcd/rafthttp"
 )
 
@@ -33,7 +34,7 @@ func TestNewPeerHandlerOnRaftPrefix(t *testing.T) {
 	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("test data"))
 	})
-	ph := NewPeerHandler(&fakeCluster{}, h)
+	ph := NewPeerHandler(&fakeCluster{}, &resServer{}, h)
 	srv := httptest.NewServer(ph)
 	defer srv.Close()
 
@@ -132,3 +133,46 @@ func TestServeMembersGet(t *testing.T) {
 		}
 	}
 }
+
+// followerServer is a resServer that is not the leader.
+type followerServer struct {
+	resServer
+}
+
+func (fs *followerServer) Leader() types.ID { return types.ID(2) }
+
+func TestServePeerMemberPromote(t *testing.T) {
+	tests := []struct {
+		server etcdserver.Server
+		method string
+		path   string
+
+		wcode int
+		wbody string
+	}{
+		{&resServer{}, "POST", peerMemberPromotePrefix + "beef", http.StatusNoContent, ""},
+		{&resServer{}, "GET", peerMemberPromotePrefix + "beef", http.StatusMethodNotAllowed, "Method Not Allowed\n"},
+		{&resServer{}, "POST", peerMemberPromotePrefix + "nothex", http.StatusBadRequest, "bad path\n"},
+		// a follower does not forward the promotion again
+		{&followerServer{}, "POST", peerMemberPromotePrefix + "beef", http.StatusServiceUnavailable, etcdserver.ErrNotLeader.Error() + "\n"},
+		{&errServer{etcdserver.ErrIDNotFound}, "POST", peerMemberPromotePrefix + "beef", http.StatusNotFound, etcdserver.ErrIDNotFound.Error() + "\n"},
+		{&errServer{etcdserver.ErrLearnerNotReady}, "POST", peerMemberPromotePrefix + "beef", http.StatusPreconditionFailed, etcdserver.ErrLearnerNotReady.Error() + "\n"},
+		{&errServer{etcdserver.ErrMemberChangeInProgress}, "POST", peerMemberPromotePrefix + "beef", http.StatusConflict, etcdserver.ErrMemberChangeInProgress.Error() + "\n"},
+	}
+	for i, tt := range tests {
+		req, err := http.NewRequest(tt.method, testutil.MustNewURL(t, tt.path).String(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rw := httptest.NewRecorder()
+		h := &peerMemberPromoteHandler{server: tt.server}
+		h.ServeHTTP(rw, req)
+
+		if rw.Code != tt.wcode {
+			t.Errorf("#%d: code = %d, want %d", i, rw.Code, tt.wcode)
+		}
+		if rw.Body.String() != tt.wbody {
+			t.Errorf("#%d: body = %q, want %q", i, rw.Body.String(), tt.wbody)
+		}
+	}
+}
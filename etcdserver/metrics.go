@@ -19,6 +19,8 @@ import (
 
 	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/prometheus/client_golang/prometheus"
 	"github.com/coreos/etcd/pkg/runtime"
+	"github.com/coreos/etcd/pkg/types"
+	"github.com/coreos/etcd/raft"
 )
 
 var (
@@ -50,6 +52,15 @@ var (
 		Name:      "file_descriptors_used_total",
 		Help:      "The total number of file descriptors used.",
 	})
+
+	raftInflightAppends = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "raft_inflight_appends",
+		Help:      "The number of in-flight raft append messages from the leader to each follower.",
+	},
+		[]string{"remoteID"},
+	)
 )
 
 func init() {
@@ -57,6 +68,7 @@ func init() {
 	prometheus.MustRegister(proposePending)
 	prometheus.MustRegister(proposeFailed)
 	prometheus.MustRegister(fileDescriptorUsed)
+	prometheus.MustRegister(raftInflightAppends)
 }
 
 func monitorFileDescriptor(done <-chan struct{}) {
@@ -84,3 +96,38 @@ func monitorFileDescriptor(done <-chan struct{}) {
 		}
 	}
 }
+
+// monitorRaftInflights reports the number of in-flight append messages to
+// each follower while the local member is the leader.
+func (s *EtcdServer) monitorRaftInflights() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	// the followers that have been reported, whose labels are deleted
+	// once they are removed or the local member is no longer the leader.
+	reported := make(map[uint64]bool)
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+
+		st := s.r.Status()
+		if st.RaftState != raft.StateLeader {
+			st.Progress = nil
+		}
+		for id := range reported {
+			if _, ok := st.Progress[id]; !ok {
+				raftInflightAppends.DeleteLabelValues(types.ID(id).String())
+				delete(reported, id)
+			}
+		}
+		for id, pr := range st.Progress {
+			if id == st.ID {
+				continue
+			}
+			raftInflightAppends.WithLabelValues(types.ID(id).String()).Set(float64(pr.InflightCount()))
+			reported[id] = true
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/coreos/etcd/pkg/types"
+)
+
+// defaultPeerCertIdentity is the name of the identity pattern of the
+// members that have no pattern of their own.
+const defaultPeerCertIdentity = "*"
+
+// peerVerifier verifies that the certificate presented by a peer names
+// the identity allowed for its member: the CN or one of the DNS SANs of
+// the certificate must match the pattern of the member name.
+// The members without a pattern of their own, including the members that
+// have not published their name yet or are not known to the local member,
+// must match the default pattern. The peer is rejected if there is no
+// pattern to match.
+type peerVerifier struct {
+	cluster *cluster
+	// identities maps the member names to the patterns of their
+	// identities, in the syntax of path.Match.
+	identities map[string]string
+}
+
+func (v *peerVerifier) VerifyPeer(id types.ID, certs []*x509.Certificate) error {
+	if len(certs) == 0 {
+		return errors.New("no certificate presented")
+	}
+	var name string
+	if m := v.cluster.Member(id); m != nil {
+		name = m.Name
+	}
+	pattern, ok := v.identities[name]
+	if !ok || name == "" {
+		pattern, ok = v.identities[defaultPeerCertIdentity]
+	}
+	if !ok {
+		return fmt.Errorf("no identity allowed for member %q", name)
+	}
+
+	cert := certs[0]
+	for _, s := range append([]string{cert.Subject.CommonName}, cert.DNSNames...) {
+		if s == "" {
+			continue
+		}
+		if matched, _ := path.Match(pattern, s); matched {
+			return nil
+		}
+	}
+	return fmt.Errorf("certificate does not match identity %q of member %q", pattern, name)
+}
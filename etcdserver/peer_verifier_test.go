@@ -0,0 +1,74 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/coreos/etcd/pkg/types"
+)
+
+func TestPeerVerifierVerifyPeer(t *testing.T) {
+	cl := newTestCluster([]*Member{
+		newTestMember(1, nil, "node1", nil),
+		newTestMember(2, nil, "node2", nil),
+		newTestMember(3, nil, "", nil),
+	})
+	tests := []struct {
+		identities map[string]string
+		id         uint64
+		cn         string
+		dnsNames   []string
+
+		werr bool
+	}{
+		// CN matches
+		{map[string]string{"node1": "node1.example.com"}, 1, "node1.example.com", nil, false},
+		// DNS SAN matches the glob
+		{map[string]string{"node1": "*.node1.example.com"}, 1, "", []string{"foo", "peer.node1.example.com"}, false},
+		// CN does not match
+		{map[string]string{"node1": "node1.example.com"}, 1, "node2.example.com", nil, true},
+		// certificate of another member
+		{map[string]string{"node1": "node1.example.com", "node2": "node2.example.com"}, 1, "node2.example.com", nil, true},
+		// no pattern of the member
+		{map[string]string{"node1": "node1.example.com"}, 2, "node2.example.com", nil, true},
+		// default pattern
+		{map[string]string{"node1": "node1.example.com", "*": "*.example.com"}, 2, "node2.example.com", nil, false},
+		// unnamed member matches the default pattern
+		{map[string]string{"*": "new.example.com"}, 3, "new.example.com", nil, false},
+		// unknown member matches the default pattern
+		{map[string]string{"*": "new.example.com"}, 4, "new.example.com", nil, false},
+		// unknown member without default pattern
+		{map[string]string{"node1": "node1.example.com"}, 4, "node1.example.com", nil, true},
+	}
+	for i, tt := range tests {
+		v := &peerVerifier{cluster: cl, identities: tt.identities}
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: tt.cn}, DNSNames: tt.dnsNames}
+		err := v.VerifyPeer(types.ID(tt.id), []*x509.Certificate{cert})
+		if (err != nil) != tt.werr {
+			t.Errorf("#%d: err = %v, want error %v", i, err, tt.werr)
+		}
+	}
+}
+
+func TestPeerVerifierVerifyPeerNoCertificate(t *testing.T) {
+	cl := newTestCluster([]*Member{newTestMember(1, nil, "node1", nil)})
+	v := &peerVerifier{cluster: cl, identities: map[string]string{"*": "*"}}
+	if err := v.VerifyPeer(types.ID(1), nil); err == nil {
+		t.Errorf("err = nil, want not nil")
+	}
+}
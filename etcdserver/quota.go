@@ -0,0 +1,113 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+)
+
+const (
+	// DefaultQuotaBytes is the default quota of the v3 storage backend.
+	DefaultQuotaBytes = int64(2 * 1024 * 1024 * 1024) // 2GB
+
+	// leaseOverhead is the approximate number of bytes a lease takes in
+	// the backend.
+	leaseOverhead = 64
+	// kvOverhead is the approximate number of bytes a key-value takes in
+	// the backend besides its key and value.
+	kvOverhead = 256
+
+	alarmRaiseTimeout = 5 * time.Second
+)
+
+// quotaCost returns the approximate number of bytes the given request
+// adds to the backend. It returns 0 if the request does not grow the
+// backend.
+func quotaCost(r *pb.InternalRaftRequest) int64 {
+	switch {
+	case r.Put != nil:
+		return putCost(r.Put)
+	case r.Txn != nil:
+		var cost int64
+		for _, reqs := range [][]*pb.RequestUnion{r.Txn.Success, r.Txn.Failure} {
+			for _, req := range reqs {
+				if req.RequestPut != nil {
+					cost += putCost(req.RequestPut)
+				}
+			}
+		}
+		return cost
+	case r.LeaseCreate != nil:
+		return leaseOverhead
+	}
+	return 0
+}
+
+func putCost(r *pb.PutRequest) int64 {
+	return int64(len(r.Key)+len(r.Value)) + kvOverhead
+}
+
+// noSpace returns true if the NOSPACE alarm is active for any member of
+// the cluster.
+func (s *EtcdServer) noSpace() bool {
+	return len(s.alarmStore.Get(pb.AlarmType_NOSPACE)) != 0
+}
+
+// checkQuota returns ErrNoSpace if the given request grows the backend
+// while the NOSPACE alarm is active, or if the request would grow the
+// backend of the member beyond its quota. In the latter case, the NOSPACE
+// alarm of the member is raised.
+func (s *EtcdServer) checkQuota(r *pb.InternalRaftRequest) error {
+	cost := quotaCost(r)
+	if cost == 0 {
+		return nil
+	}
+	if s.noSpace() {
+		return ErrNoSpace
+	}
+	if s.be.Size()+cost <= s.quotaBytes {
+		return nil
+	}
+	plog.Warningf("v3 storage backend size %d exceeds the quota %d", s.be.Size(), s.quotaBytes)
+	s.raiseNoSpaceAlarm()
+	return ErrNoSpace
+}
+
+// raiseNoSpaceAlarm activates the NOSPACE alarm of the member through
+// consensus in the background.
+func (s *EtcdServer) raiseNoSpaceAlarm() {
+	if !atomic.CompareAndSwapInt32(&s.raisingNoSpace, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreInt32(&s.raisingNoSpace, 0)
+		r := pb.InternalRaftRequest{
+			Alarm: &pb.AlarmRequest{
+				Action:   pb.AlarmRequest_ACTIVATE,
+				MemberId: uint64(s.ID()),
+				Alarm:    pb.AlarmType_NOSPACE,
+			},
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), alarmRaiseTimeout)
+		defer cancel()
+		if _, err := s.V3DemoDo(ctx, r); err != nil {
+			plog.Warningf("failed to raise the NOSPACE alarm (%v)", err)
+		}
+	}()
+}
@@ -48,6 +48,18 @@ const (
 	// Never overflow the rafthttp buffer, which is 4096.
 	// TODO: a better const?
 	maxInflightMsgs = 4096 / 8
+	// MaxRaftInflightMsgs is the upper bound of the configurable number of
+	// in-flight append messages. It leaves the room in the rafthttp buffer
+	// for the heartbeats and the other messages, like maxInflightMsgs.
+	MaxRaftInflightMsgs = 4096 / 8
+
+	// maxInFlightMsgSnap is the max number of snapshot messages waiting
+	// to be merged with the v3 storage snapshot.
+	maxInFlightMsgSnap = 16
+
+	// readStateTimeout is the time to wait for the linearizable read
+	// loop to receive a read state.
+	readStateTimeout = 500 * time.Millisecond
 )
 
 var (
@@ -83,6 +95,11 @@ type raftNode struct {
 
 	// a chan to send out apply
 	applyc chan apply
+	// a chan to send out the read states of the read index requests
+	readStateC chan raft.ReadState
+	// a chan to send out the snapshot messages to be merged with the
+	// v3 storage snapshot
+	msgSnapC chan raftpb.Message
 
 	// TODO: remove the etcdserver related logic from raftNode
 	// TODO: add a state machine interface to apply the commit entries
@@ -110,6 +127,7 @@ type raftNode struct {
 
 func (r *raftNode) run() {
 	var syncC <-chan time.Time
+	var islead bool
 
 	defer r.stop()
 	for {
@@ -129,6 +147,37 @@ func (r *raftNode) run() {
 				} else {
 					syncC = nil
 				}
+				if lead := rd.RaftState == raft.StateLeader; lead != islead {
+					islead = lead
+					// only the lessor of the leader manages the expiry of leases
+					if r.s.lessor != nil {
+						if islead {
+							r.s.lessor.Promote()
+						} else {
+							r.s.lessor.Demote()
+						}
+					}
+					// only the leader compacts the v3 storage automatically
+					if r.s.compactor != nil {
+						if islead {
+							r.s.compactor.Resume()
+						} else {
+							r.s.compactor.Pause()
+						}
+					}
+				}
+			}
+
+			if len(rd.ReadStates) != 0 {
+				// only the last read state is interesting, since the
+				// server has at most one read index request in flight.
+				select {
+				case r.readStateC <- rd.ReadStates[len(rd.ReadStates)-1]:
+				case <-time.After(readStateTimeout):
+					plog.Warningf("timed out sending read state")
+				case <-r.stopped:
+					return
+				}
 			}
 
 			apply := apply{
@@ -226,8 +275,10 @@ func startNode(cfg *ServerConfig, cl *cluster, ids []types.ID) (id types.ID, n r
 		ElectionTick:    cfg.ElectionTicks,
 		HeartbeatTick:   1,
 		Storage:         s,
-		MaxSizePerMsg:   maxSizePerMsg,
-		MaxInflightMsgs: maxInflightMsgs,
+		MaxSizePerMsg:   cfg.raftMaxSizePerMsg(),
+		MaxInflightMsgs: cfg.raftMaxInflightMsgs(),
+		PreVote:         cfg.PreVote,
+		CheckQuorum:     cfg.CheckQuorum,
 	}
 	n = raft.StartNode(c, peers)
 	raftStatus = n.Status
@@ -255,8 +306,10 @@ func restartNode(cfg *ServerConfig, snapshot *raftpb.Snapshot) (types.ID, *clust
 		ElectionTick:    cfg.ElectionTicks,
 		HeartbeatTick:   1,
 		Storage:         s,
-		MaxSizePerMsg:   maxSizePerMsg,
-		MaxInflightMsgs: maxInflightMsgs,
+		MaxSizePerMsg:   cfg.raftMaxSizePerMsg(),
+		MaxInflightMsgs: cfg.raftMaxInflightMsgs(),
+		PreVote:         cfg.PreVote,
+		CheckQuorum:     cfg.CheckQuorum,
 	}
 	n := raft.RestartNode(c)
 	raftStatus = n.Status
@@ -306,8 +359,10 @@ func restartAsStandaloneNode(cfg *ServerConfig, snapshot *raftpb.Snapshot) (type
 		ElectionTick:    cfg.ElectionTicks,
 		HeartbeatTick:   1,
 		Storage:         s,
-		MaxSizePerMsg:   maxSizePerMsg,
-		MaxInflightMsgs: maxInflightMsgs,
+		MaxSizePerMsg:   cfg.raftMaxSizePerMsg(),
+		MaxInflightMsgs: cfg.raftMaxInflightMsgs(),
+		PreVote:         cfg.PreVote,
+		CheckQuorum:     cfg.CheckQuorum,
 	}
 	n := raft.RestartNode(c)
 	raftStatus = n.Status
@@ -315,30 +370,66 @@ func restartAsStandaloneNode(cfg *ServerConfig, snapshot *raftpb.Snapshot) (type
 }
 
 // getIDs returns an ordered set of IDs included in the given snapshot and
-// the entries. The given snapshot/entries can contain two kinds of
+// the entries. The given snapshot/entries can contain three kinds of
 // ID-related entry:
-// - ConfChangeAddNode, in which case the contained ID will be added into the set.
+// - ConfChangeAddNode and ConfChangeAddLearnerNode, in which case the contained ID will be added into the set.
 // - ConfChangeAddRemove, in which case the contained ID will be removed from the set.
+// - ConfChangeV2, in which case the added IDs are added into the set, and the
+//   removed IDs are removed from the set once the cluster leaves the joint consensus.
 func getIDs(snap *raftpb.Snapshot, ents []raftpb.Entry) []uint64 {
 	ids := make(map[uint64]bool)
+	// leaving contains the IDs removed by a joint configuration change
+	// that have not left the cluster yet.
+	var leaving []uint64
 	if snap != nil {
 		for _, id := range snap.Metadata.ConfState.Nodes {
 			ids[id] = true
 		}
+		for _, id := range snap.Metadata.ConfState.Learners {
+			ids[id] = true
+		}
+		for _, id := range snap.Metadata.ConfState.NodesOutgoing {
+			if !ids[id] {
+				ids[id] = true
+				leaving = append(leaving, id)
+			}
+		}
 	}
 	for _, e := range ents {
+		if e.Type == raftpb.EntryConfChangeV2 {
+			var cc raftpb.ConfChangeV2
+			pbutil.MustUnmarshal(&cc, e.Data)
+			if len(cc.Changes) == 0 {
+				for _, id := range leaving {
+					delete(ids, id)
+				}
+				leaving = nil
+				continue
+			}
+			for _, sc := range cc.Changes {
+				switch sc.Type {
+				case raftpb.ConfChangeAddNode:
+					ids[sc.NodeID] = true
+				case raftpb.ConfChangeRemoveNode:
+					leaving = append(leaving, sc.NodeID)
+				default:
+					plog.Panicf("ConfChangeV2 Type should be either ConfChangeAddNode or ConfChangeRemoveNode!")
+				}
+			}
+			continue
+		}
 		if e.Type != raftpb.EntryConfChange {
 			continue
 		}
 		var cc raftpb.ConfChange
 		pbutil.MustUnmarshal(&cc, e.Data)
 		switch cc.Type {
-		case raftpb.ConfChangeAddNode:
+		case raftpb.ConfChangeAddNode, raftpb.ConfChangeAddLearnerNode:
 			ids[cc.NodeID] = true
 		case raftpb.ConfChangeRemoveNode:
 			delete(ids, cc.NodeID)
 		default:
-			plog.Panicf("ConfChange Type should be either ConfChangeAddNode or ConfChangeRemoveNode!")
+			plog.Panicf("ConfChange Type should be either ConfChangeAddNode, ConfChangeAddLearnerNode or ConfChangeRemoveNode!")
 		}
 	}
 	sids := make(types.Uint64Slice, 0)
@@ -32,6 +32,12 @@ func TestGetIDs(t *testing.T) {
 	removecc := &raftpb.ConfChange{Type: raftpb.ConfChangeRemoveNode, NodeID: 2}
 	removeEntry := raftpb.Entry{Type: raftpb.EntryConfChange, Data: pbutil.MustMarshal(removecc)}
 	normalEntry := raftpb.Entry{Type: raftpb.EntryNormal}
+	jointcc := &raftpb.ConfChangeV2{Changes: []raftpb.ConfChangeSingle{
+		{Type: raftpb.ConfChangeRemoveNode, NodeID: 1},
+		{Type: raftpb.ConfChangeAddNode, NodeID: 3},
+	}}
+	jointEntry := raftpb.Entry{Type: raftpb.EntryConfChangeV2, Data: pbutil.MustMarshal(jointcc)}
+	leaveEntry := raftpb.Entry{Type: raftpb.EntryConfChangeV2, Data: pbutil.MustMarshal(&raftpb.ConfChangeV2{})}
 
 	tests := []struct {
 		confState *raftpb.ConfState
@@ -50,6 +56,14 @@ func TestGetIDs(t *testing.T) {
 			[]raftpb.Entry{addEntry, normalEntry}, []uint64{1, 2}},
 		{&raftpb.ConfState{Nodes: []uint64{1}},
 			[]raftpb.Entry{addEntry, removeEntry, normalEntry}, []uint64{1}},
+		{&raftpb.ConfState{Nodes: []uint64{1}},
+			[]raftpb.Entry{jointEntry}, []uint64{1, 3}},
+		{&raftpb.ConfState{Nodes: []uint64{1}},
+			[]raftpb.Entry{jointEntry, leaveEntry}, []uint64{3}},
+		{&raftpb.ConfState{Nodes: []uint64{3}, NodesOutgoing: []uint64{1}},
+			[]raftpb.Entry{}, []uint64{1, 3}},
+		{&raftpb.ConfState{Nodes: []uint64{3}, NodesOutgoing: []uint64{1}},
+			[]raftpb.Entry{leaveEntry}, []uint64{3}},
 	}
 
 	for i, tt := range tests {
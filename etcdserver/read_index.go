@@ -0,0 +1,153 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync/atomic"
+	"time"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	"github.com/coreos/etcd/raft"
+)
+
+// notifier notifies the linearizable reads waiting on it of the result
+// of a read index request.
+type notifier struct {
+	c   chan struct{}
+	err error
+}
+
+func newNotifier() *notifier {
+	return &notifier{c: make(chan struct{})}
+}
+
+func (nc *notifier) notify(err error) {
+	nc.err = err
+	close(nc.c)
+}
+
+func (s *EtcdServer) getAppliedIndex() uint64 {
+	return atomic.LoadUint64(&s.appliedIndex)
+}
+
+func (s *EtcdServer) setAppliedIndex(v uint64) {
+	atomic.StoreUint64(&s.appliedIndex, v)
+	s.applyWait.Trigger(v)
+}
+
+// readIndexTimeout returns the time to wait for the read state of a read
+// index request. The leader drops the request if it has not committed an
+// entry of its term yet, so the request is retried after the timeout.
+func (s *EtcdServer) readIndexTimeout() time.Duration {
+	return 2 * s.cfg.electionTimeout()
+}
+
+// linearizableReadLoop serves the pending linearizable reads in batches.
+// It confirms the commit index of the cluster through a read index
+// request, waits until the server applies the entries up to that index,
+// and then notifies all the reads that were pending before the request.
+func (s *EtcdServer) linearizableReadLoop() {
+	var rs raft.ReadState
+
+	for {
+		select {
+		case <-s.readwaitc:
+		case <-s.done:
+			return
+		}
+
+		ctx := make([]byte, 8)
+		binary.BigEndian.PutUint64(ctx, s.reqIDGen.Next())
+
+		nextnr := newNotifier()
+		s.readMu.Lock()
+		nr := s.readNotifier
+		s.readNotifier = nextnr
+		s.readMu.Unlock()
+
+		cctx, cancel := context.WithTimeout(context.Background(), s.readIndexTimeout())
+		if err := s.r.ReadIndex(cctx, ctx); err != nil {
+			cancel()
+			if err == raft.ErrStopped {
+				return
+			}
+			plog.Errorf("failed to get read index from raft: %v", err)
+			nr.notify(err)
+			continue
+		}
+		cancel()
+
+		var (
+			timeout bool
+			done    bool
+		)
+		for !timeout && !done {
+			select {
+			case rs = <-s.r.readStateC:
+				done = bytes.Equal(rs.RequestCtx, ctx)
+				if !done {
+					// a previous request timed out, and its read state
+					// arrives late.
+					plog.Warningf("ignored out-of-date read index response (want %v, got %v)", ctx, rs.RequestCtx)
+				}
+			case <-time.After(s.readIndexTimeout()):
+				plog.Warningf("timed out waiting for read index response")
+				nr.notify(ErrTimeout)
+				timeout = true
+			case <-s.done:
+				return
+			}
+		}
+		if !done {
+			continue
+		}
+
+		if ai := s.getAppliedIndex(); ai < rs.Index {
+			select {
+			case <-s.applyWait.Wait(rs.Index):
+			case <-s.done:
+				return
+			}
+		}
+		// unblock all the reads that were pending before the request
+		nr.notify(nil)
+	}
+}
+
+// linearizableReadNotify blocks until the server can serve a linearizable
+// read locally, i.e. it has applied all the entries committed in the
+// cluster before the call.
+func (s *EtcdServer) linearizableReadNotify(ctx context.Context) error {
+	s.readMu.RLock()
+	nc := s.readNotifier
+	s.readMu.RUnlock()
+
+	// signal the linearizable read loop to serve the pending reads
+	select {
+	case s.readwaitc <- struct{}{}:
+	default:
+	}
+
+	select {
+	case <-nc.c:
+		return nc.err
+	case <-ctx.Done():
+		return parseCtxErr(ctx.Err())
+	case <-s.done:
+		return ErrStopped
+	}
+}
@@ -19,19 +19,24 @@ import (
 	"expvar"
 	"fmt"
 	"math/rand"
+	"net"
 	"net/http"
 	"path"
 	"regexp"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/coreos/go-semver/semver"
 	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/coreos/pkg/capnslog"
 	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	"github.com/coreos/etcd/alarm"
+	"github.com/coreos/etcd/compactor"
 	"github.com/coreos/etcd/discovery"
 	"github.com/coreos/etcd/etcdserver/etcdhttp/httptypes"
 	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
 	"github.com/coreos/etcd/etcdserver/stats"
+	"github.com/coreos/etcd/lease"
 	"github.com/coreos/etcd/pkg/fileutil"
 	"github.com/coreos/etcd/pkg/idutil"
 	"github.com/coreos/etcd/pkg/pbutil"
@@ -43,6 +48,8 @@ import (
 	"github.com/coreos/etcd/raft/raftpb"
 	"github.com/coreos/etcd/rafthttp"
 	"github.com/coreos/etcd/snap"
+	dstorage "github.com/coreos/etcd/storage"
+	"github.com/coreos/etcd/storage/backend"
 	"github.com/coreos/etcd/store"
 	"github.com/coreos/etcd/version"
 	"github.com/coreos/etcd/wal"
@@ -63,6 +70,12 @@ const (
 	purgeFileInterval      = 30 * time.Second
 	monitorVersionInterval = 5 * time.Second
 	versionUpdateTimeout   = 1 * time.Second
+	leaseRevokeTimeout     = 5 * time.Second
+	autoCompactionTimeout  = 5 * time.Second
+
+	// learnerReadyPercent is the fraction of the leader's log that a
+	// learner must have replicated before it can be promoted.
+	learnerReadyPercent = 0.9
 )
 
 var (
@@ -122,6 +135,21 @@ type Server interface {
 	// return ErrIDNotFound if the member ID does not exist.
 	UpdateMember(ctx context.Context, updateMemb Member) error
 
+	// PromoteMember attempts to promote a learner member in the cluster to
+	// a voting member. It will return ErrIDNotFound if the member ID does
+	// not exist, ErrMemberNotLearner if the member is not a learner, or
+	// ErrLearnerNotReady if the learner has not caught up with the leader.
+	// A follower forwards the promotion to the leader.
+	PromoteMember(ctx context.Context, id uint64) error
+
+	// ReplaceMember attempts to replace the member of the given ID with a
+	// new member in a single joint configuration change. It will return
+	// ErrIDNotFound if the member ID does not exist, ErrIDExists if the
+	// new member ID exists, or ErrMemberChangeInProgress if the cluster
+	// has not finished the previous joint configuration change. It returns
+	// nil only after the cluster has left the joint consensus.
+	ReplaceMember(ctx context.Context, id uint64, memb Member) error
+
 	// ClusterVersion is the cluster-wide minimum major.minor version.
 	// Cluster version is set to the min version that a etcd member is
 	// compatible with when first bootstrap.
@@ -156,6 +184,29 @@ type EtcdServer struct {
 	cluster *cluster
 
 	store store.Store
+	// snapshotter saves the v3 storage snapshots received from the leader.
+	snapshotter *snap.Snapshotter
+
+	// be is the backend of the v3 storage. It is nil if v3 is not enabled.
+	be backend.Backend
+	// kv is the v3 storage. It is nil if v3 is not enabled.
+	kv dstorage.ConsistentWatchableKV
+	// consistIndex is the index of the last raft entry applied to kv.
+	consistIndex consistentIndex
+	// lessor is the owner of the leases of kv. It is nil if v3 is not
+	// enabled.
+	lessor lease.Lessor
+	// alarmStore keeps the alarms of the cluster. It is nil if v3 is not
+	// enabled.
+	alarmStore *alarm.AlarmStore
+	// quotaBytes is the quota of be in bytes.
+	quotaBytes int64
+	// raisingNoSpace is 1 if the NOSPACE alarm of the member is being
+	// raised. It is accessed atomically.
+	raisingNoSpace int32
+	// compactor compacts kv automatically when the member is the leader.
+	// It is nil if auto compaction is not enabled.
+	compactor compactor.Compactor
 
 	stats  *stats.ServerStats
 	lstats *stats.LeaderStats
@@ -164,20 +215,45 @@ type EtcdServer struct {
 
 	reqIDGen *idutil.Generator
 
+	// appliedIndex is the index of the last applied raft entry. It is
+	// accessed atomically.
+	appliedIndex uint64
+	// applyWait waits for the applied index to reach a given index.
+	applyWait wait.WaitIndex
+
+	// readwaitc triggers the linearizable read loop to serve the pending
+	// linearizable reads.
+	readwaitc chan struct{}
+	// readMu protects readNotifier.
+	readMu sync.RWMutex
+	// readNotifier is notified when the pending linearizable reads can be
+	// served locally.
+	readNotifier *notifier
+
 	// forceVersionC is used to force the version monitor loop
 	// to detect the cluster version immediately.
 	forceVersionC chan struct{}
+
+	// jointID is the ID of the joint configuration change that the
+	// cluster is in. Its waiter is triggered when the cluster leaves the
+	// joint consensus. It is only accessed by the apply loop.
+	jointID uint64
 }
 
 // NewServer creates a new EtcdServer from the supplied configuration. The
 // configuration is considered static for the lifetime of the EtcdServer.
 func NewServer(cfg *ServerConfig) (*EtcdServer, error) {
+	if cfg.PeerGRPC && len(cfg.PeerCertIdentities) != 0 {
+		return nil, ErrPeerGRPCCertIdentities
+	}
+
 	st := store.New(StoreClusterPrefix, StoreKeysPrefix)
 	var w *wal.WAL
 	var n raft.Node
 	var s *raft.MemoryStorage
 	var id types.ID
 	var cl *cluster
+	var snapshot *raftpb.Snapshot
 
 	// Run the migrations.
 	dataVer, err := version.DetectDataDir(cfg.DataDir)
@@ -260,7 +336,7 @@ func NewServer(cfg *ServerConfig) (*EtcdServer, error) {
 		if cfg.ShouldDiscover() {
 			plog.Warningf("discovery token ignored since a cluster has already been initialized. Valid log found at %q", cfg.WALDir())
 		}
-		snapshot, err := ss.Load()
+		snapshot, err = ss.Load()
 		if err != nil && err != snap.ErrNoSnapshot {
 			return nil, err
 		}
@@ -302,7 +378,9 @@ func NewServer(cfg *ServerConfig) (*EtcdServer, error) {
 			ticker:      time.Tick(time.Duration(cfg.TickMs) * time.Millisecond),
 			raftStorage: s,
 			storage:     NewStorage(w, ss),
+			msgSnapC:    make(chan raftpb.Message, maxInFlightMsgSnap),
 		},
+		snapshotter:   ss,
 		id:            id,
 		attributes:    Attributes{Name: cfg.Name, ClientURLs: cfg.ClientURLs.StringSlice()},
 		cluster:       cl,
@@ -313,8 +391,43 @@ func NewServer(cfg *ServerConfig) (*EtcdServer, error) {
 		forceVersionC: make(chan struct{}),
 	}
 
+	if cfg.V3demo {
+		srv.be = backend.NewDefaultBackend(cfg.StorageDir())
+		srv.lessor = lease.NewLessor(srv.be)
+		srv.kv = dstorage.NewConsistentWatchable(srv.be, srv.lessor, &srv.consistIndex)
+		srv.alarmStore = alarm.NewAlarmStore(srv.be)
+		srv.quotaBytes = cfg.backendQuota()
+		if err := srv.kv.Restore(); err != nil {
+			plog.Fatalf("v3 storage restore error: %v", err)
+		}
+		srv.consistIndex.setConsistentIndex(srv.kv.ConsistentIndex())
+		if snapshot != nil {
+			srv.recoverPendingV3(snapshot.Metadata.Index)
+		}
+		if cfg.AutoCompactionRetention != 0 {
+			srv.compactor, err = compactor.New(cfg.AutoCompactionMode, cfg.AutoCompactionRetention, srv.kv, &v3Compactable{srv})
+			if err != nil {
+				srv.kv.Close()
+				return nil, err
+			}
+		}
+	}
+
 	// TODO: move transport initialization near the definition of remote
-	tr := rafthttp.NewTransporter(cfg.Transport, id, cl.ID(), srv, srv.errorc, sstats, lstats)
+	var tr rafthttp.Transporter
+	if cfg.PeerGRPC {
+		tr = rafthttp.NewGRPCTransporter(cfg.Transport, id, cl.ID(), srv, ss, srv.errorc, sstats, lstats)
+	} else {
+		tr = rafthttp.NewTransporter(cfg.Transport, id, cl.ID(), srv, ss, srv.errorc, sstats, lstats)
+	}
+	if err := tr.(rafthttp.Compressible).SetCompression(cfg.PeerCompression); err != nil {
+		plog.Panicf("unexpected peer compression %q (%v)", cfg.PeerCompression, err)
+	}
+	tr.(rafthttp.BandwidthLimitable).SetSnapshotBandwidth(cfg.SnapshotBandwidth)
+	if len(cfg.PeerCertIdentities) != 0 {
+		plog.Infof("verifying peer certificate identities %v", cfg.PeerCertIdentities)
+		tr.(rafthttp.PeerVerifiable).SetPeerVerifier(&peerVerifier{cluster: cl, identities: cfg.PeerCertIdentities})
+	}
 	// add all remotes into transport
 	for _, m := range remotes {
 		if m.ID != id {
@@ -338,6 +451,7 @@ func (s *EtcdServer) Start() {
 	go s.publish(defaultPublishTimeout)
 	go s.purgeFile()
 	go monitorFileDescriptor(s.done)
+	go s.monitorRaftInflights()
 	go s.monitorVersions()
 }
 
@@ -350,6 +464,9 @@ func (s *EtcdServer) start() {
 		s.snapCount = DefaultSnapCount
 	}
 	s.w = wait.New()
+	s.applyWait = wait.NewIndexList()
+	s.readwaitc = make(chan struct{}, 1)
+	s.readNotifier = newNotifier()
 	s.done = make(chan struct{})
 	s.stop = make(chan struct{})
 	if s.ClusterVersion() != nil {
@@ -386,6 +503,16 @@ func (s *EtcdServer) Cluster() Cluster { return s.cluster }
 
 func (s *EtcdServer) RaftHandler() http.Handler { return s.r.transport.Handler() }
 
+// ServePeerGRPC serves the gRPC transport to the peers on the listener l.
+// It returns an error if the server is not configured with PeerGRPC.
+func (s *EtcdServer) ServePeerGRPC(l net.Listener) error {
+	tr, ok := s.r.transport.(rafthttp.GRPCTransporter)
+	if !ok {
+		return ErrNoPeerGRPC
+	}
+	return tr.ServeGRPC(l)
+}
+
 func (s *EtcdServer) Process(ctx context.Context, m raftpb.Message) error {
 	if s.cluster.IsIDRemoved(types.ID(m.From)) {
 		plog.Warningf("reject message from removed member %s", types.ID(m.From).String())
@@ -394,6 +521,16 @@ func (s *EtcdServer) Process(ctx context.Context, m raftpb.Message) error {
 	if m.Type == raftpb.MsgApp {
 		s.stats.RecvAppendReq(types.ID(m.From).String(), m.Size())
 	}
+	if m.Type == raftpb.MsgSnap && s.kv != nil {
+		// the v3 storage is recovered from the database snapshot when the
+		// snapshot is applied. A snapshot without one, e.g., sent over the
+		// pipeline by a leader that does not merge the snapshots, is
+		// rejected so the leader retries it.
+		if _, err := s.snapshotter.DBFilePath(m.Snapshot.Metadata.Index); err != nil {
+			plog.Warningf("reject snapshot at index %d from %s without the database snapshot (%v)", m.Snapshot.Metadata.Index, types.ID(m.From), err)
+			return httptypes.NewHTTPError(http.StatusBadRequest, "cannot process snapshot without the database snapshot")
+		}
+	}
 	return s.r.Step(ctx, m)
 }
 
@@ -413,15 +550,38 @@ func (s *EtcdServer) run() {
 	confState := snap.Metadata.ConfState
 	snapi := snap.Metadata.Index
 	appliedi := snapi
+	appliedt := snap.Metadata.Term
 	// TODO: get rid of the raft initialization in etcd server
 	s.r.s = s
 	s.r.applyc = make(chan apply)
+	s.r.readStateC = make(chan raft.ReadState, 1)
 	s.r.stopped = make(chan struct{})
 	s.r.done = make(chan struct{})
 	go s.r.run()
+	s.setAppliedIndex(appliedi)
+	go s.linearizableReadLoop()
+	var expiredLeaseC <-chan []*lease.Lease
+	if s.lessor != nil {
+		expiredLeaseC = s.lessor.ExpiredLeasesC()
+	}
+	if s.compactor != nil {
+		s.compactor.Run()
+	}
+
 	defer func() {
 		s.r.stopped <- struct{}{}
 		<-s.r.done
+		if s.lessor != nil {
+			s.lessor.Stop()
+		}
+		if s.compactor != nil {
+			s.compactor.Stop()
+		}
+		if s.kv != nil {
+			if err := s.kv.Close(); err != nil {
+				plog.Panicf("close v3 storage error: %v", err)
+			}
+		}
 		close(s.done)
 	}()
 
@@ -441,6 +601,10 @@ func (s *EtcdServer) run() {
 				}
 				s.cluster.Recover()
 
+				if s.kv != nil {
+					s.recoverV3(apply.snapshot.Metadata.Index)
+				}
+
 				// recover raft transport
 				s.r.transport.RemoveAllPeers()
 				for _, m := range s.cluster.Members() {
@@ -451,9 +615,11 @@ func (s *EtcdServer) run() {
 				}
 
 				appliedi = apply.snapshot.Metadata.Index
+				appliedt = apply.snapshot.Metadata.Term
 				snapi = appliedi
 				confState = apply.snapshot.Metadata.ConfState
 				plog.Infof("recovered from incoming snapshot at index %d", snapi)
+				s.purgeDBSnapshots(snapi)
 			}
 
 			// apply entries
@@ -466,11 +632,13 @@ func (s *EtcdServer) run() {
 				if appliedi+1-firsti < uint64(len(apply.entries)) {
 					ents = apply.entries[appliedi+1-firsti:]
 				}
-				if appliedi, shouldstop = s.apply(ents, &confState); shouldstop {
+				if appliedt, appliedi, shouldstop = s.apply(ents, &confState); shouldstop {
 					go s.stopWithDelay(10*100*time.Millisecond, fmt.Errorf("the member has been permanently removed from the cluster"))
 				}
 			}
 
+			s.setAppliedIndex(appliedi)
+
 			// wait for the raft routine to finish the disk writes before triggering a
 			// snapshot. or applied index might be greater than the last index in raft
 			// storage, since the raft routine might be slower than apply routine.
@@ -481,7 +649,12 @@ func (s *EtcdServer) run() {
 				plog.Infof("start to snapshot (applied: %d, lastsnap: %d)", appliedi, snapi)
 				s.snapshot(appliedi, confState)
 				snapi = appliedi
+				s.purgeDBSnapshots(snapi)
 			}
+		case m := <-s.r.msgSnapC:
+			s.sendMergedSnapshot(m, appliedt, appliedi, confState)
+		case leases := <-expiredLeaseC:
+			go s.revokeExpiredLeases(leases)
 		case err := <-s.errorc:
 			plog.Errorf("%s", err)
 			plog.Infof("the data-dir used by this member must be removed.")
@@ -495,6 +668,7 @@ func (s *EtcdServer) run() {
 // Stop stops the server gracefully, and shuts down the running goroutine.
 // Stop should be called after a Start(s), otherwise it will block forever.
 func (s *EtcdServer) Stop() {
+	s.transferLeadership()
 	select {
 	case s.stop <- struct{}{}:
 	case <-s.done:
@@ -503,6 +677,35 @@ func (s *EtcdServer) Stop() {
 	<-s.done
 }
 
+// transferLeadership transfers the leadership to the most up-to-date
+// member if the server is the leader, so that the cluster does not wait
+// for an election timeout to elect a new leader after the server stops.
+func (s *EtcdServer) transferLeadership() {
+	if lead := s.Lead(); lead == raft.None || lead != uint64(s.id) {
+		return
+	}
+	st := s.r.Status()
+	if st.RaftState != raft.StateLeader || len(st.Progress) < 2 {
+		return
+	}
+	var transferee, match uint64
+	for id, pr := range st.Progress {
+		if id == st.ID || pr.IsLearner {
+			continue
+		}
+		if transferee == raft.None || pr.Match > match {
+			transferee, match = id, pr.Match
+		}
+	}
+
+	// raft aborts the transfer after an election timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 2*s.cfg.electionTimeout())
+	defer cancel()
+	if err := s.MoveLeader(ctx, transferee); err != nil {
+		plog.Warningf("failed to transfer leadership to %s before stopping (%v)", types.ID(transferee), err)
+	}
+}
+
 func (s *EtcdServer) stopWithDelay(d time.Duration, err error) {
 	time.Sleep(d)
 	select {
@@ -516,17 +719,20 @@ func (s *EtcdServer) stopWithDelay(d time.Duration, err error) {
 func (s *EtcdServer) StopNotify() <-chan struct{} { return s.done }
 
 // Do interprets r and performs an operation on s.store according to r.Method
-// and other fields. If r.Method is "POST", "PUT", "DELETE", or a "GET" with
-// Quorum == true, r will be sent through consensus before performing its
-// respective operation. Do will block until an action is performed or there is
-// an error.
+// and other fields. If r.Method is "POST", "PUT" or "DELETE", r will be sent
+// through consensus before performing its respective operation. A "GET" with
+// Quorum == true is performed locally after the server has applied all the
+// entries committed before the request. Do will block until an action is
+// performed or there is an error.
 func (s *EtcdServer) Do(ctx context.Context, r pb.Request) (Response, error) {
 	r.ID = s.reqIDGen.Next()
 	if r.Method == "GET" && r.Quorum {
-		r.Method = "QGET"
+		if err := s.linearizableReadNotify(ctx); err != nil {
+			return Response{}, err
+		}
 	}
 	switch r.Method {
-	case "POST", "PUT", "DELETE", "QGET":
+	case "POST", "PUT", "DELETE":
 		data, err := r.Marshal()
 		if err != nil {
 			return Response{}, err
@@ -536,7 +742,11 @@ func (s *EtcdServer) Do(ctx context.Context, r pb.Request) (Response, error) {
 		// TODO: benchmark the cost of time.Now()
 		// might be sampling?
 		start := time.Now()
-		s.r.Propose(ctx, data)
+		if err := s.r.Propose(ctx, data); err != nil {
+			proposeFailed.Inc()
+			s.w.Trigger(r.ID, nil) // GC wait
+			return Response{}, parseProposeErr(err)
+		}
 
 		proposePending.Inc()
 		defer proposePending.Dec()
@@ -602,6 +812,9 @@ func (s *EtcdServer) AddMember(ctx context.Context, memb Member) error {
 		NodeID:  uint64(memb.ID),
 		Context: b,
 	}
+	if memb.IsLearner {
+		cc.Type = raftpb.ConfChangeAddLearnerNode
+	}
 	return s.configure(ctx, cc)
 }
 
@@ -626,6 +839,78 @@ func (s *EtcdServer) UpdateMember(ctx context.Context, memb Member) error {
 	return s.configure(ctx, cc)
 }
 
+func (s *EtcdServer) PromoteMember(ctx context.Context, id uint64) error {
+	m := s.cluster.Member(types.ID(id))
+	if m == nil {
+		return ErrIDNotFound
+	}
+	if !m.IsLearner {
+		return ErrMemberNotLearner
+	}
+	// only the leader knows the progress of the learner, so a follower
+	// forwards the promotion to the leader.
+	if s.Leader() != s.ID() {
+		lm := s.cluster.Member(s.Leader())
+		if lm == nil {
+			return ErrNoLeader
+		}
+		return promoteMemberHTTP(ctx, lm, id, s.cfg.Transport)
+	}
+	if !s.isLearnerReady(id) {
+		return ErrLearnerNotReady
+	}
+
+	promoted := m.Clone()
+	promoted.IsLearner = false
+	b, err := json.Marshal(promoted)
+	if err != nil {
+		return err
+	}
+	cc := raftpb.ConfChange{
+		Type:    raftpb.ConfChangeAddNode,
+		NodeID:  id,
+		Context: b,
+	}
+	return s.configure(ctx, cc)
+}
+
+// ReplaceMember swaps the member of the given ID for the given member through
+// joint consensus, so that the cluster never runs with both or neither of
+// them as voters. The new member can reuse the peer URLs of the old one.
+// It returns once the cluster has left the joint consensus, when the old
+// member is removed.
+func (s *EtcdServer) ReplaceMember(ctx context.Context, id uint64, memb Member) error {
+	memb.IsLearner = false
+	b, err := json.Marshal([]Member{memb})
+	if err != nil {
+		return err
+	}
+	cc := raftpb.ConfChangeV2{
+		Changes: []raftpb.ConfChangeSingle{
+			{Type: raftpb.ConfChangeRemoveNode, NodeID: id},
+			{Type: raftpb.ConfChangeAddNode, NodeID: uint64(memb.ID)},
+		},
+		Context: b,
+	}
+	cc.ID = s.reqIDGen.Next()
+	return s.waitConfigure(ctx, cc.ID, func() error { return s.r.ProposeConfChangeV2(ctx, cc) })
+}
+
+// isLearnerReady returns true if the given learner has caught up with
+// the log of the leader.
+func (s *EtcdServer) isLearnerReady(id uint64) bool {
+	st := s.r.Status()
+	if st.RaftState != raft.StateLeader {
+		return false
+	}
+	pr, ok := st.Progress[id]
+	if !ok {
+		return false
+	}
+	leaderMatch := st.Progress[st.ID].Match
+	return float64(pr.Match) >= float64(leaderMatch)*learnerReadyPercent
+}
+
 // Implement the RaftTimer interface
 func (s *EtcdServer) Index() uint64 { return atomic.LoadUint64(&s.r.index) }
 
@@ -638,15 +923,52 @@ func (s *EtcdServer) Lead() uint64 { return atomic.LoadUint64(&s.r.lead) }
 
 func (s *EtcdServer) Leader() types.ID { return types.ID(s.Lead()) }
 
+// MoveLeader transfers the leadership of the cluster to the given member,
+// and waits until the member becomes the leader. It can be called on any
+// member, which forwards the request to the leader.
+func (s *EtcdServer) MoveLeader(ctx context.Context, transferee uint64) error {
+	m := s.cluster.Member(types.ID(transferee))
+	if m == nil {
+		return ErrIDNotFound
+	}
+	if m.IsLearner {
+		return ErrMemberIsLearner
+	}
+	if s.Lead() == transferee {
+		return nil
+	}
+
+	plog.Infof("%s starts to transfer leadership from %s to %s", s.ID(), s.Leader(), types.ID(transferee))
+	s.r.TransferLeadership(ctx, transferee)
+	interval := time.Duration(s.cfg.TickMs) * time.Millisecond
+	for s.Lead() != transferee {
+		select {
+		case <-ctx.Done():
+			return ErrTimeoutLeaderTransfer
+		case <-s.done:
+			return ErrStopped
+		case <-time.After(interval):
+		}
+	}
+	plog.Infof("%s finished transferring leadership to %s", s.ID(), types.ID(transferee))
+	return nil
+}
+
 // configure sends a configuration change through consensus and
 // then waits for it to be applied to the server. It
 // will block until the change is performed or there is an error.
 func (s *EtcdServer) configure(ctx context.Context, cc raftpb.ConfChange) error {
 	cc.ID = s.reqIDGen.Next()
-	ch := s.w.Register(cc.ID)
-	if err := s.r.ProposeConfChange(ctx, cc); err != nil {
-		s.w.Trigger(cc.ID, nil)
-		return err
+	return s.waitConfigure(ctx, cc.ID, func() error { return s.r.ProposeConfChange(ctx, cc) })
+}
+
+// waitConfigure proposes a configuration change of the given ID through the
+// given function, and waits for it to be applied to the server.
+func (s *EtcdServer) waitConfigure(ctx context.Context, id uint64, propose func() error) error {
+	ch := s.w.Register(id)
+	if err := propose(); err != nil {
+		s.w.Trigger(id, nil)
+		return parseProposeErr(err)
 	}
 	select {
 	case x := <-ch:
@@ -658,7 +980,7 @@ func (s *EtcdServer) configure(ctx context.Context, cc raftpb.ConfChange) error
 		}
 		return nil
 	case <-ctx.Done():
-		s.w.Trigger(cc.ID, nil) // GC wait
+		s.w.Trigger(id, nil) // GC wait
 		return parseCtxErr(ctx.Err())
 	case <-s.done:
 		return ErrStopped
@@ -712,6 +1034,14 @@ func (s *EtcdServer) publish(timeout time.Duration) {
 		case ErrStopped:
 			plog.Infof("aborting publish because server is stopped")
 			return
+		case ErrNoLeader:
+			// wait for the cluster to elect a leader before retrying
+			select {
+			case <-time.After(s.cfg.electionTimeout()):
+			case <-s.done:
+				plog.Infof("aborting publish because server is stopped")
+				return
+			}
 		default:
 			plog.Errorf("publish error: %v", err)
 		}
@@ -723,15 +1053,65 @@ func (s *EtcdServer) send(ms []raftpb.Message) {
 		if s.cluster.IsIDRemoved(types.ID(ms[i].To)) {
 			ms[i].To = 0
 		}
+		// the snapshot message is merged with the v3 storage snapshot
+		// and streamed to the follower by the apply routine.
+		if s.kv != nil && ms[i].Type == raftpb.MsgSnap && ms[i].To != 0 {
+			select {
+			case s.r.msgSnapC <- ms[i]:
+			default:
+				// too many snapshots in flight. raft sends the snapshot
+				// again after it learns the failure.
+				s.r.ReportSnapshot(ms[i].To, raft.SnapshotFailure)
+			}
+			ms[i].To = 0
+		}
 	}
 	s.r.transport.Send(ms)
 }
 
+// sendMergedSnapshot merges the v2 store at the applied index and the v3
+// storage into a snapshot message, and streams it to the follower. The
+// v3 storage snapshot is copied from the backend when the send starts, so
+// it might include the entries applied after the applied index, which is
+// fine since the v3 storage skips the entries applied before on recovery.
+func (s *EtcdServer) sendMergedSnapshot(m raftpb.Message, appliedt, appliedi uint64, confState raftpb.ConfState) {
+	d, err := s.store.Clone().SaveNoCopy()
+	// TODO: current store will never fail to do a snapshot
+	// what should we do if the store might fail?
+	if err != nil {
+		plog.Panicf("store save should never fail: %v", err)
+	}
+	m.Snapshot = raftpb.Snapshot{
+		Metadata: raftpb.SnapshotMetadata{
+			Index:     appliedi,
+			Term:      appliedt,
+			ConfState: confState,
+		},
+		Data: d,
+	}
+
+	// commit the applied entries, so the snapshot covers the applied index
+	s.be.ForceCommit()
+	// the snapshot is sent from a copy, so the backend tx that reads it
+	// is not held for the whole transfer, which can take long when the
+	// snapshot bandwidth is limited.
+	rc, err := s.snapshotter.NewDBCopyReader(s.be.Snapshot)
+	if err != nil {
+		// raft sends the snapshot again after it learns the failure.
+		plog.Warningf("failed to send database snapshot to %s (%v)", types.ID(m.To), err)
+		s.r.ReportSnapshot(m.To, raft.SnapshotFailure)
+		return
+	}
+	s.r.transport.SendSnapshot(*snap.NewMessage(m, rc))
+	plog.Infof("start to send database snapshot [index: %d, to %s]...", appliedi, types.ID(m.To))
+}
+
 // apply takes entries received from Raft (after it has been committed) and
 // applies them to the current state of the EtcdServer.
-// The given entries should not be empty.
-func (s *EtcdServer) apply(es []raftpb.Entry, confState *raftpb.ConfState) (uint64, bool) {
-	var applied uint64
+// The given entries should not be empty. It returns the term and the index
+// of the last applied entry.
+func (s *EtcdServer) apply(es []raftpb.Entry, confState *raftpb.ConfState) (uint64, uint64, bool) {
+	var appliedt, applied uint64
 	var shouldstop bool
 	var err error
 	for i := range es {
@@ -747,22 +1127,114 @@ func (s *EtcdServer) apply(es []raftpb.Entry, confState *raftpb.ConfState) (uint
 				}
 				break
 			}
-			var r pb.Request
-			pbutil.MustUnmarshal(&r, e.Data)
-			s.w.Trigger(r.ID, s.applyRequest(r))
+			s.applyEntryNormal(&e)
 		case raftpb.EntryConfChange:
 			var cc raftpb.ConfChange
 			pbutil.MustUnmarshal(&cc, e.Data)
 			shouldstop, err = s.applyConfChange(cc, confState)
 			s.w.Trigger(cc.ID, err)
+		case raftpb.EntryConfChangeV2:
+			var cc raftpb.ConfChangeV2
+			pbutil.MustUnmarshal(&cc, e.Data)
+			shouldstop, err = s.applyConfChangeV2(cc, confState)
+			switch {
+			case len(cc.Changes) == 0:
+				// the change that made the cluster enter the joint
+				// consensus is done once the cluster leaves it.
+				if s.jointID != 0 {
+					s.w.Trigger(s.jointID, nil)
+					s.jointID = 0
+				}
+			case err != nil:
+				s.w.Trigger(cc.ID, err)
+			default:
+				s.jointID = cc.ID
+			}
 		default:
-			plog.Panicf("entry type should be either EntryNormal or EntryConfChange")
+			plog.Panicf("entry type should be either EntryNormal, EntryConfChange or EntryConfChangeV2")
 		}
 		atomic.StoreUint64(&s.r.index, e.Index)
 		atomic.StoreUint64(&s.r.term, e.Term)
-		applied = e.Index
+		appliedt, applied = e.Term, e.Index
+	}
+	return appliedt, applied, shouldstop
+}
+
+// recoverV3 replaces the v3 storage with the database snapshot received
+// with the raft snapshot at the given index.
+func (s *EtcdServer) recoverV3(snapi uint64) {
+	fn, err := s.snapshotter.DBFilePath(snapi)
+	if err != nil {
+		plog.Panicf("failed to find the database snapshot at index %d: %v", snapi, err)
+	}
+	if err := s.be.Recover(fn); err != nil {
+		plog.Panicf("failed to recover the backend from %s: %v", fn, err)
+	}
+	// the keys are attached to the recovered leases when the kv is
+	// restored.
+	s.lessor.Recover()
+	if err := s.kv.Restore(); err != nil {
+		plog.Panicf("v3 storage restore error: %v", err)
+	}
+	s.alarmStore.Recover()
+	s.consistIndex.setConsistentIndex(s.kv.ConsistentIndex())
+	plog.Infof("recovered v3 storage from the database snapshot at index %d", snapi)
+}
+
+// recoverPendingV3 recovers the v3 storage from the database snapshot
+// that came with the raft snapshot at the given index, if the v3 storage
+// is behind the raft snapshot. It happens if the member stopped after
+// saving an incoming snapshot but before recovering the v3 storage from
+// it, in which case the entries up to the snapshot are not replayed from
+// the WAL.
+func (s *EtcdServer) recoverPendingV3(snapi uint64) {
+	if snapi <= s.kv.ConsistentIndex() {
+		return
+	}
+	if _, err := s.snapshotter.DBFilePath(snapi); err != nil {
+		plog.Warningf("v3 storage at index %d is behind the snapshot at index %d, which has no database snapshot (%v)", s.kv.ConsistentIndex(), snapi, err)
+		return
+	}
+	s.recoverV3(snapi)
+}
+
+// purgeDBSnapshots removes the received database snapshots that are not
+// newer than the snapshot at the given index, which are never applied.
+func (s *EtcdServer) purgeDBSnapshots(snapi uint64) {
+	if s.snapshotter == nil {
+		return
+	}
+	if err := s.snapshotter.PurgeDBs(snapi); err != nil {
+		plog.Warningf("failed to purge database snapshots (%v)", err)
+	}
+}
+
+// applyEntryNormal applies an EntryNormal type raftpb entry to the server.
+// The data of the entry is either a v2 Request or an InternalRaftRequest.
+func (s *EtcdServer) applyEntryNormal(e *raftpb.Entry) {
+	var raftReq pb.InternalRaftRequest
+	if !pbutil.MaybeUnmarshal(&raftReq, e.Data) { // backward compatible
+		var r pb.Request
+		pbutil.MustUnmarshal(&r, e.Data)
+		s.w.Trigger(r.ID, s.applyRequest(r))
+		return
+	}
+	if raftReq.V2 != nil {
+		s.w.Trigger(raftReq.V2.ID, s.applyRequest(*raftReq.V2))
+		return
+	}
+
+	if s.kv == nil {
+		s.w.Trigger(raftReq.ID, &v3Result{err: ErrV3NotEnabled})
+		return
 	}
-	return applied, shouldstop
+	// the entry might have been applied to the v3 storage before
+	// the server restarted.
+	if e.Index <= s.consistIndex.ConsistentIndex() {
+		return
+	}
+	s.consistIndex.setConsistentIndex(e.Index)
+	s.w.Trigger(raftReq.ID, s.applyV3Request(&raftReq))
 }
 
 // applyRequest interprets r as a call to store.X and returns a Response interpreted
@@ -833,7 +1305,7 @@ func (s *EtcdServer) applyConfChange(cc raftpb.ConfChange, confState *raftpb.Con
 	}
 	*confState = *s.r.ApplyConfChange(cc)
 	switch cc.Type {
-	case raftpb.ConfChangeAddNode:
+	case raftpb.ConfChangeAddNode, raftpb.ConfChangeAddLearnerNode:
 		m := new(Member)
 		if err := json.Unmarshal(cc.Context, m); err != nil {
 			plog.Panicf("unmarshal member should never fail: %v", err)
@@ -841,6 +1313,11 @@ func (s *EtcdServer) applyConfChange(cc raftpb.ConfChange, confState *raftpb.Con
 		if cc.NodeID != uint64(m.ID) {
 			plog.Panicf("nodeID should always be equal to member ID")
 		}
+		if em := s.cluster.Member(m.ID); em != nil && em.IsLearner && !m.IsLearner {
+			s.cluster.PromoteMember(m.ID)
+			plog.Noticef("promoted learner member %s in cluster %s", m.ID, s.cluster.ID())
+			return false, nil
+		}
 		s.cluster.AddMember(m)
 		if m.ID == s.id {
 			plog.Noticef("added local member %s %v to cluster %s", m.ID, m.PeerURLs, s.cluster.ID())
@@ -876,6 +1353,70 @@ func (s *EtcdServer) applyConfChange(cc raftpb.ConfChange, confState *raftpb.Con
 	return false, nil
 }
 
+// applyConfChangeV2 applies a joint ConfChangeV2 to the server. The added
+// members join the cluster when it enters the joint consensus, and the
+// removed members leave the cluster when it leaves the joint consensus.
+func (s *EtcdServer) applyConfChangeV2(cc raftpb.ConfChangeV2, confState *raftpb.ConfState) (bool, error) {
+	joint := len(confState.NodesOutgoing) > 0
+	if len(cc.Changes) == 0 {
+		*confState = *s.r.ApplyConfChangeV2(cc)
+		if !joint {
+			return false, nil
+		}
+		return s.removeNonVoters(*confState), nil
+	}
+
+	err := s.cluster.ValidateConfigurationChangeV2(cc)
+	if err == nil && joint {
+		err = ErrMemberChangeInProgress
+	}
+	if err != nil {
+		s.r.ApplyConfChange(raftpb.ConfChange{NodeID: raft.None})
+		return false, err
+	}
+	*confState = *s.r.ApplyConfChangeV2(cc)
+	var ms []*Member
+	if err := json.Unmarshal(cc.Context, &ms); err != nil {
+		plog.Panicf("unmarshal members should never fail: %v", err)
+	}
+	for _, m := range ms {
+		s.cluster.AddMember(m)
+		if m.ID == s.id {
+			plog.Noticef("added local member %s %v to cluster %s", m.ID, m.PeerURLs, s.cluster.ID())
+		} else {
+			s.r.transport.AddPeer(m.ID, m.PeerURLs)
+			plog.Noticef("added member %s %v to cluster %s", m.ID, m.PeerURLs, s.cluster.ID())
+		}
+	}
+	return false, nil
+}
+
+// removeNonVoters removes the members that are not in the given ConfState
+// from the cluster. It returns true if the local member is removed.
+func (s *EtcdServer) removeNonVoters(confState raftpb.ConfState) bool {
+	ids := make(map[types.ID]bool)
+	for _, id := range confState.Nodes {
+		ids[types.ID(id)] = true
+	}
+	for _, id := range confState.Learners {
+		ids[types.ID(id)] = true
+	}
+	var removed bool
+	for _, m := range s.cluster.Members() {
+		if ids[m.ID] {
+			continue
+		}
+		s.cluster.RemoveMember(m.ID)
+		if m.ID == s.id {
+			removed = true
+			continue
+		}
+		s.r.transport.RemovePeer(m.ID)
+		plog.Noticef("removed member %s from cluster %s", m.ID, s.cluster.ID())
+	}
+	return removed
+}
+
 // TODO: non-blocking snapshot
 func (s *EtcdServer) snapshot(snapi uint64, confState raftpb.ConfState) {
 	clone := s.store.Clone()
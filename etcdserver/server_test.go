@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"path"
 	"reflect"
 	"strconv"
@@ -32,6 +33,7 @@ import (
 	"github.com/coreos/etcd/pkg/types"
 	"github.com/coreos/etcd/raft"
 	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/coreos/etcd/snap"
 	"github.com/coreos/etcd/store"
 )
 
@@ -469,6 +471,75 @@ func TestApplyConfChangeError(t *testing.T) {
 	}
 }
 
+// TestApplyConfChangeV2 tests that the added members join the cluster when
+// it enters the joint consensus, and the removed members leave it when it
+// leaves the joint consensus.
+func TestApplyConfChangeV2(t *testing.T) {
+	cl := newCluster("")
+	cl.SetStore(store.New())
+	for i := 1; i <= 3; i++ {
+		cl.AddMember(&Member{ID: types.ID(i)})
+	}
+	n := &nodeConfStateRecorder{cs: raftpb.ConfState{Nodes: []uint64{1, 2, 4}, NodesOutgoing: []uint64{1, 2, 3}}}
+	srv := &EtcdServer{
+		id: 1,
+		r: raftNode{
+			Node:      n,
+			transport: &nopTransporter{},
+		},
+		cluster: cl,
+	}
+	b, err := json.Marshal([]Member{{ID: 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := raftpb.ConfChangeV2{
+		Changes: []raftpb.ConfChangeSingle{
+			{Type: raftpb.ConfChangeRemoveNode, NodeID: 3},
+			{Type: raftpb.ConfChangeAddNode, NodeID: 4},
+		},
+		Context: b,
+	}
+	cs := raftpb.ConfState{Nodes: []uint64{1, 2, 3}}
+	if _, err := srv.applyConfChangeV2(cc, &cs); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(cs, n.cs) {
+		t.Errorf("confState = %+v, want %+v", cs, n.cs)
+	}
+	if cl.Member(4) == nil || cl.Member(3) == nil {
+		t.Fatalf("members = %v, want 1, 2, 3 and 4", cl.Members())
+	}
+
+	// another change is rejected until the cluster leaves the joint consensus.
+	if _, err := srv.applyConfChangeV2(cc, &cs); err != ErrIDExists {
+		t.Errorf("error = %v, want %v", err, ErrIDExists)
+	}
+	b, err = json.Marshal([]Member{{ID: 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc5 := raftpb.ConfChangeV2{Changes: []raftpb.ConfChangeSingle{{Type: raftpb.ConfChangeAddNode, NodeID: 5}}, Context: b}
+	if _, err := srv.applyConfChangeV2(cc5, &cs); err != ErrMemberChangeInProgress {
+		t.Errorf("error = %v, want %v", err, ErrMemberChangeInProgress)
+	}
+
+	n.cs = raftpb.ConfState{Nodes: []uint64{1, 2, 4}}
+	shouldStop, err := srv.applyConfChangeV2(raftpb.ConfChangeV2{}, &cs)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if shouldStop {
+		t.Errorf("shouldStop = %t, want %t", shouldStop, false)
+	}
+	if cl.Member(3) != nil {
+		t.Errorf("member with id 3 is not removed")
+	}
+	if !cl.IsIDRemoved(3) {
+		t.Errorf("IsIDRemoved(3) = false, want true")
+	}
+}
+
 func TestApplyConfChangeShouldStop(t *testing.T) {
 	cl := newCluster("")
 	cl.SetStore(store.New())
@@ -512,7 +583,6 @@ func TestDoProposal(t *testing.T) {
 		pb.Request{Method: "POST", ID: 1},
 		pb.Request{Method: "PUT", ID: 1},
 		pb.Request{Method: "DELETE", ID: 1},
-		pb.Request{Method: "GET", ID: 1, Quorum: true},
 	}
 	for i, tt := range tests {
 		st := &storeRecorder{}
@@ -544,6 +614,41 @@ func TestDoProposal(t *testing.T) {
 	}
 }
 
+// TestDoQuorumGet tests that a quorum GET is served by the local store
+// after a read index request, instead of being proposed.
+func TestDoQuorumGet(t *testing.T) {
+	st := &storeRecorder{}
+	n := newNodeCommitter()
+	srv := &EtcdServer{
+		cfg: &ServerConfig{TickMs: 1, ElectionTicks: 10},
+		r: raftNode{
+			Node:        n,
+			storage:     &storageRecorder{},
+			raftStorage: raft.NewMemoryStorage(),
+			transport:   &nopTransporter{},
+		},
+		store:    st,
+		reqIDGen: idutil.NewGenerator(0, time.Time{}),
+	}
+	srv.start()
+	resp, err := srv.Do(context.Background(), pb.Request{Method: "GET", ID: 1, Quorum: true})
+	srv.Stop()
+
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	wresp := Response{Event: &store.Event{}}
+	if !reflect.DeepEqual(resp, wresp) {
+		t.Errorf("resp = %v, want %v", resp, wresp)
+	}
+	if action := st.Action(); len(action) != 1 || action[0].Name != "Get" {
+		t.Errorf("store action = %v, want [Get]", action)
+	}
+	if action := n.Action(); len(action) == 0 || action[0].Name != "ReadIndex" {
+		t.Errorf("node action = %v, want ReadIndex first", action)
+	}
+}
+
 func TestDoProposalCancelled(t *testing.T) {
 	wait := &waitRecorder{}
 	srv := &EtcdServer{
@@ -591,6 +696,25 @@ func TestDoProposalStopped(t *testing.T) {
 	}
 }
 
+// TestDoProposalDropped tests that Do fails fast with ErrNoLeader when raft
+// drops the proposal.
+func TestDoProposalDropped(t *testing.T) {
+	wait := &waitRecorder{}
+	srv := &EtcdServer{
+		r:        raftNode{Node: &nodeProposalDropperRecorder{}},
+		w:        wait,
+		reqIDGen: idutil.NewGenerator(0, time.Time{}),
+	}
+	_, err := srv.Do(context.Background(), pb.Request{Method: "PUT"})
+	if err != ErrNoLeader {
+		t.Fatalf("err = %v, want %v", err, ErrNoLeader)
+	}
+	w := []testutil.Action{{Name: "Register"}, {Name: "Trigger"}}
+	if !reflect.DeepEqual(wait.action, w) {
+		t.Errorf("wait.action = %+v, want %+v", wait.action, w)
+	}
+}
+
 // TestSync tests sync 1. is nonblocking 2. proposes SYNC request.
 func TestSync(t *testing.T) {
 	n := &nodeRecorder{}
@@ -917,7 +1041,227 @@ func TestRemoveMember(t *testing.T) {
 	}
 }
 
+// TestReplaceMember tests that ReplaceMember proposes a joint configuration
+// change, and adds the new member once the change is applied. The replaced
+// member is kept until the cluster leaves the joint consensus.
+func TestReplaceMember(t *testing.T) {
+	n := newNodeConfChangeCommitterRecorder()
+	n.readyc <- raft.Ready{
+		SoftState: &raft.SoftState{RaftState: raft.StateLeader},
+	}
+	cl := newTestCluster(nil)
+	st := store.New()
+	cl.SetStore(store.New())
+	cl.AddMember(&Member{ID: 1234, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:2380"}}})
+	s := &EtcdServer{
+		r: raftNode{
+			Node:        n,
+			raftStorage: raft.NewMemoryStorage(),
+			storage:     &storageRecorder{},
+			transport:   &nopTransporter{},
+		},
+		store:    st,
+		cluster:  cl,
+		reqIDGen: idutil.NewGenerator(0, time.Time{}),
+	}
+	s.start()
+	// the new member reuses the peer URL of the replaced member.
+	m := Member{ID: 5678, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:2380"}}}
+	err := s.ReplaceMember(context.TODO(), 1234, m)
+	gaction := n.Action()
+	s.Stop()
+
+	if err != nil {
+		t.Fatalf("ReplaceMember error: %v", err)
+	}
+	wactions := []testutil.Action{{Name: "ProposeConfChangeV2"}, {Name: "ApplyConfChangeV2"}, {Name: "ApplyConfChangeV2"}}
+	if !reflect.DeepEqual(gaction, wactions) {
+		t.Errorf("action = %v, want %v", gaction, wactions)
+	}
+	if cl.Member(5678) == nil {
+		t.Errorf("member with id 5678 is not added")
+	}
+}
+
+// TestReplaceMemberInProgress tests that ReplaceMember fails fast with
+// ErrMemberChangeInProgress when raft drops the change because another
+// one is in progress.
+func TestReplaceMemberInProgress(t *testing.T) {
+	wait := &waitRecorder{}
+	s := &EtcdServer{
+		r:        raftNode{Node: &nodeConfChangeDropperRecorder{}},
+		w:        wait,
+		reqIDGen: idutil.NewGenerator(0, time.Time{}),
+	}
+	m := Member{ID: 5678, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:2380"}}}
+	if err := s.ReplaceMember(context.TODO(), 1234, m); err != ErrMemberChangeInProgress {
+		t.Fatalf("err = %v, want %v", err, ErrMemberChangeInProgress)
+	}
+	w := []testutil.Action{{Name: "Register"}, {Name: "Trigger"}}
+	if !reflect.DeepEqual(wait.action, w) {
+		t.Errorf("wait.action = %+v, want %+v", wait.action, w)
+	}
+}
+
+// TestReplaceMemberWaitLeaveJoint tests that ReplaceMember does not return
+// before the cluster leaves the joint consensus.
+func TestReplaceMemberWaitLeaveJoint(t *testing.T) {
+	n := newNodeConfChangeCommitterRecorder()
+	n.stayJoint = true
+	n.readyc <- raft.Ready{
+		SoftState: &raft.SoftState{RaftState: raft.StateLeader},
+	}
+	cl := newTestCluster(nil)
+	st := store.New()
+	cl.SetStore(store.New())
+	cl.AddMember(&Member{ID: 1234, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:2380"}}})
+	s := &EtcdServer{
+		r: raftNode{
+			Node:        n,
+			raftStorage: raft.NewMemoryStorage(),
+			storage:     &storageRecorder{},
+			transport:   &nopTransporter{},
+		},
+		store:    st,
+		cluster:  cl,
+		reqIDGen: idutil.NewGenerator(0, time.Time{}),
+	}
+	s.start()
+	defer s.Stop()
+	m := Member{ID: 5678, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:2381"}}}
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+	if err := s.ReplaceMember(ctx, 1234, m); err != ErrTimeout {
+		t.Errorf("err = %v, want %v", err, ErrTimeout)
+	}
+	// the joint change is applied, so the member is added.
+	if cl.Member(5678) == nil {
+		t.Errorf("member with id 5678 is not added")
+	}
+	if cl.Member(1234) == nil {
+		t.Errorf("member with id 1234 is removed before leaving the joint consensus")
+	}
+}
+
 // TestUpdateMember tests RemoveMember can propose and perform node update.
+// TestAddLearnerMember tests that AddMember proposes a learner member as
+// ConfChangeAddLearnerNode.
+func TestAddLearnerMember(t *testing.T) {
+	n := newNodeConfChangeCommitterRecorder()
+	n.readyc <- raft.Ready{
+		SoftState: &raft.SoftState{RaftState: raft.StateLeader},
+	}
+	cl := newTestCluster(nil)
+	st := store.New()
+	cl.SetStore(st)
+	s := &EtcdServer{
+		r: raftNode{
+			Node:        n,
+			raftStorage: raft.NewMemoryStorage(),
+			storage:     &storageRecorder{},
+			transport:   &nopTransporter{},
+		},
+		store:    st,
+		cluster:  cl,
+		reqIDGen: idutil.NewGenerator(0, time.Time{}),
+	}
+	s.start()
+	m := Member{ID: 1234, RaftAttributes: RaftAttributes{PeerURLs: []string{"foo"}, IsLearner: true}}
+	err := s.AddMember(context.TODO(), m)
+	gaction := n.Action()
+	s.Stop()
+
+	if err != nil {
+		t.Fatalf("AddMember error: %v", err)
+	}
+	wactions := []testutil.Action{{Name: "ProposeConfChange:ConfChangeAddLearnerNode"}, {Name: "ApplyConfChange:ConfChangeAddLearnerNode"}}
+	if !reflect.DeepEqual(gaction, wactions) {
+		t.Errorf("action = %v, want %v", gaction, wactions)
+	}
+	if m := cl.Member(1234); m == nil || !m.IsLearner {
+		t.Errorf("member = %+v, want learner member 1234", m)
+	}
+}
+
+// TestPromoteMemberFail tests that PromoteMember refuses to promote
+// members that are not learners, and learners that are not in sync with
+// the leader.
+func TestPromoteMemberFail(t *testing.T) {
+	cl := newTestCluster(nil)
+	cl.SetStore(store.New())
+	cl.AddMember(&Member{ID: 1, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:1"}}})
+	cl.AddMember(&Member{ID: 2, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:2"}, IsLearner: true}})
+
+	tests := []struct {
+		id   uint64
+		lead uint64
+		werr error
+	}{
+		{3, 1, ErrIDNotFound},
+		{1, 1, ErrMemberNotLearner},
+		// the leader is not a known member
+		{2, 3, ErrNoLeader},
+		// the leader does not know the progress of the learner
+		{2, 1, ErrLearnerNotReady},
+	}
+	for i, tt := range tests {
+		s := &EtcdServer{
+			id:      1,
+			r:       raftNode{Node: &nodeRecorder{}, lead: tt.lead},
+			cluster: cl,
+		}
+		if err := s.PromoteMember(context.TODO(), tt.id); err != tt.werr {
+			t.Errorf("#%d: err = %v, want %v", i, err, tt.werr)
+		}
+	}
+}
+
+// TestPromoteMemberForward tests that a follower forwards the promotion
+// to the leader, and returns the error that the leader reports.
+func TestPromoteMemberForward(t *testing.T) {
+	tests := []struct {
+		code int
+		body string
+
+		werr error
+	}{
+		{http.StatusNoContent, "", nil},
+		{http.StatusPreconditionFailed, ErrLearnerNotReady.Error(), ErrLearnerNotReady},
+		{http.StatusServiceUnavailable, ErrNotLeader.Error(), ErrNotLeader},
+	}
+	for i, tt := range tests {
+		var gpath, gmethod string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gpath, gmethod = r.URL.Path, r.Method
+			if tt.code == http.StatusNoContent {
+				w.WriteHeader(tt.code)
+				return
+			}
+			http.Error(w, tt.body, tt.code)
+		}))
+
+		cl := newTestCluster(nil)
+		cl.SetStore(store.New())
+		cl.AddMember(&Member{ID: 1, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:1"}}})
+		cl.AddMember(&Member{ID: 2, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:2"}, IsLearner: true}})
+		cl.AddMember(&Member{ID: 3, RaftAttributes: RaftAttributes{PeerURLs: []string{srv.URL}}})
+		s := &EtcdServer{
+			id:      1,
+			cfg:     &ServerConfig{Transport: &http.Transport{}},
+			r:       raftNode{Node: &nodeRecorder{}, lead: 3},
+			cluster: cl,
+		}
+		err := s.PromoteMember(context.TODO(), 2)
+		srv.Close()
+		if err != tt.werr {
+			t.Errorf("#%d: err = %v, want %v", i, err, tt.werr)
+		}
+		if gmethod != "POST" || gpath != "/members/promote/2" {
+			t.Errorf("#%d: request = %s %s, want POST /members/promote/2", i, gmethod, gpath)
+		}
+	}
+}
+
 func TestUpdateMember(t *testing.T) {
 	n := newNodeConfChangeCommitterRecorder()
 	n.readyc <- raft.Ready{
@@ -1101,6 +1445,16 @@ func TestStopNotify(t *testing.T) {
 	}
 }
 
+// TestNewServerPeerGRPCCertIdentities ensures that the server cannot be
+// created with peer certificate identities and the gRPC peer transport,
+// which cannot verify the peer certificates.
+func TestNewServerPeerGRPCCertIdentities(t *testing.T) {
+	cfg := &ServerConfig{PeerGRPC: true, PeerCertIdentities: map[string]string{"*": "*"}}
+	if _, err := NewServer(cfg); err != ErrPeerGRPCCertIdentities {
+		t.Errorf("err = %v, want %v", err, ErrPeerGRPCCertIdentities)
+	}
+}
+
 func TestGetOtherPeerURLs(t *testing.T) {
 	tests := []struct {
 		membs []*Member
@@ -1301,6 +1655,10 @@ func (n *nodeRecorder) ProposeConfChange(ctx context.Context, conf raftpb.ConfCh
 	n.Record(testutil.Action{Name: "ProposeConfChange"})
 	return nil
 }
+func (n *nodeRecorder) ProposeConfChangeV2(ctx context.Context, conf raftpb.ConfChangeV2) error {
+	n.Record(testutil.Action{Name: "ProposeConfChangeV2"})
+	return nil
+}
 func (n *nodeRecorder) Step(ctx context.Context, msg raftpb.Message) error {
 	n.Record(testutil.Action{Name: "Step"})
 	return nil
@@ -1312,6 +1670,10 @@ func (n *nodeRecorder) ApplyConfChange(conf raftpb.ConfChange) *raftpb.ConfState
 	n.Record(testutil.Action{Name: "ApplyConfChange", Params: []interface{}{conf}})
 	return &raftpb.ConfState{}
 }
+func (n *nodeRecorder) ApplyConfChangeV2(conf raftpb.ConfChangeV2) *raftpb.ConfState {
+	n.Record(testutil.Action{Name: "ApplyConfChangeV2", Params: []interface{}{conf}})
+	return &raftpb.ConfState{}
+}
 
 func (n *nodeRecorder) Stop() {
 	n.Record(testutil.Action{Name: "Stop"})
@@ -1321,6 +1683,15 @@ func (n *nodeRecorder) ReportUnreachable(id uint64) {}
 
 func (n *nodeRecorder) ReportSnapshot(id uint64, status raft.SnapshotStatus) {}
 
+func (n *nodeRecorder) TransferLeadership(ctx context.Context, transferee uint64) {
+	n.Record(testutil.Action{Name: "TransferLeadership", Params: []interface{}{transferee}})
+}
+
+func (n *nodeRecorder) ReadIndex(ctx context.Context, rctx []byte) error {
+	n.Record(testutil.Action{Name: "ReadIndex"})
+	return nil
+}
+
 func (n *nodeRecorder) Compact(index uint64, nodes []uint64, d []byte) {
 	n.Record(testutil.Action{Name: "Compact"})
 }
@@ -1335,10 +1706,31 @@ func (n *nodeProposalBlockerRecorder) Propose(ctx context.Context, data []byte)
 	return nil
 }
 
+type nodeProposalDropperRecorder struct {
+	nodeRecorder
+}
+
+func (n *nodeProposalDropperRecorder) Propose(ctx context.Context, data []byte) error {
+	n.Record(testutil.Action{Name: "Propose dropped"})
+	return raft.ErrProposalDropped
+}
+
+type nodeConfChangeDropperRecorder struct {
+	nodeRecorder
+}
+
+func (n *nodeConfChangeDropperRecorder) ProposeConfChangeV2(ctx context.Context, conf raftpb.ConfChangeV2) error {
+	n.Record(testutil.Action{Name: "ProposeConfChangeV2 dropped"})
+	return raft.ErrConfChangeInProgress
+}
+
 type nodeConfChangeCommitterRecorder struct {
 	nodeRecorder
 	readyc chan raft.Ready
 	index  uint64
+	// stayJoint is true if the joint configuration changes are committed
+	// without the following change that leaves the joint consensus.
+	stayJoint bool
 }
 
 func newNodeConfChangeCommitterRecorder() *nodeConfChangeCommitterRecorder {
@@ -1355,6 +1747,26 @@ func (n *nodeConfChangeCommitterRecorder) ProposeConfChange(ctx context.Context,
 	n.readyc <- raft.Ready{CommittedEntries: []raftpb.Entry{{Index: n.index, Type: raftpb.EntryConfChange, Data: data}}}
 	return nil
 }
+func (n *nodeConfChangeCommitterRecorder) ProposeConfChangeV2(ctx context.Context, conf raftpb.ConfChangeV2) error {
+	data, err := conf.Marshal()
+	if err != nil {
+		return err
+	}
+	n.index++
+	n.Record(testutil.Action{Name: "ProposeConfChangeV2"})
+	ents := []raftpb.Entry{{Index: n.index, Type: raftpb.EntryConfChangeV2, Data: data}}
+	if len(conf.Changes) > 0 && !n.stayJoint {
+		// the leader proposes to leave the joint consensus right away.
+		leave, err := (&raftpb.ConfChangeV2{}).Marshal()
+		if err != nil {
+			return err
+		}
+		n.index++
+		ents = append(ents, raftpb.Entry{Index: n.index, Type: raftpb.EntryConfChangeV2, Data: leave})
+	}
+	n.readyc <- raft.Ready{CommittedEntries: ents}
+	return nil
+}
 func (n *nodeConfChangeCommitterRecorder) Ready() <-chan raft.Ready {
 	return n.readyc
 }
@@ -1362,6 +1774,23 @@ func (n *nodeConfChangeCommitterRecorder) ApplyConfChange(conf raftpb.ConfChange
 	n.Record(testutil.Action{Name: "ApplyConfChange:" + conf.Type.String()})
 	return &raftpb.ConfState{}
 }
+func (n *nodeConfChangeCommitterRecorder) ApplyConfChangeV2(conf raftpb.ConfChangeV2) *raftpb.ConfState {
+	n.Record(testutil.Action{Name: "ApplyConfChangeV2"})
+	return &raftpb.ConfState{}
+}
+
+// nodeConfStateRecorder returns the given ConfState after applying a
+// ConfChangeV2.
+type nodeConfStateRecorder struct {
+	nodeRecorder
+	cs raftpb.ConfState
+}
+
+func (n *nodeConfStateRecorder) ApplyConfChangeV2(conf raftpb.ConfChangeV2) *raftpb.ConfState {
+	n.Record(testutil.Action{Name: "ApplyConfChangeV2", Params: []interface{}{conf}})
+	cs := n.cs
+	return &cs
+}
 
 // nodeCommitter commits proposed data immediately.
 type nodeCommitter struct {
@@ -1383,6 +1812,13 @@ func (n *nodeCommitter) Propose(ctx context.Context, data []byte) error {
 	}
 	return nil
 }
+func (n *nodeCommitter) ReadIndex(ctx context.Context, rctx []byte) error {
+	n.Record(testutil.Action{Name: "ReadIndex"})
+	n.readyc <- raft.Ready{
+		ReadStates: []raft.ReadState{{Index: n.index, RequestCtx: rctx}},
+	}
+	return nil
+}
 func (n *nodeCommitter) Ready() <-chan raft.Ready {
 	return n.readyc
 }
@@ -1402,6 +1838,7 @@ type nopTransporter struct{}
 
 func (s *nopTransporter) Handler() http.Handler               { return nil }
 func (s *nopTransporter) Send(m []raftpb.Message)             {}
+func (s *nopTransporter) SendSnapshot(m snap.Message)         {}
 func (s *nopTransporter) AddRemote(id types.ID, us []string)  {}
 func (s *nopTransporter) AddPeer(id types.ID, us []string)    {}
 func (s *nopTransporter) RemovePeer(id types.ID)              {}
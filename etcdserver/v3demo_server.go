@@ -0,0 +1,696 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"sync/atomic"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/gogo/protobuf/proto"
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/lease"
+	"github.com/coreos/etcd/pkg/types"
+	dstorage "github.com/coreos/etcd/storage"
+	"github.com/coreos/etcd/storage/storagepb"
+)
+
+type V3DemoServer interface {
+	// V3DemoDo sends the given v3 request through consensus, waits for it
+	// to be applied to the storage and returns the response of the request.
+	V3DemoDo(ctx context.Context, r pb.InternalRaftRequest) (proto.Message, error)
+	// V3DemoRange serves the given range request from the local v3
+	// storage after the server has applied all the entries committed
+	// before the request. It does not write to the raft log.
+	V3DemoRange(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, error)
+	// V3DemoWatch watches the given key range of the local v3 storage from
+	// startRev. It does not go through consensus.
+	V3DemoWatch(key, end []byte, startRev int64) (dstorage.Watcher, error)
+	// V3DemoHeader returns a response header of the server at the current
+	// revision of the local v3 storage.
+	V3DemoHeader() *pb.ResponseHeader
+	// V3DemoLeaseRenew renews the lease with the given ID. It returns the
+	// TTL of the lease. Only the leader can renew leases.
+	V3DemoLeaseRenew(id lease.LeaseID) (int64, error)
+	// V3DemoDefragment defragments the backend of the local v3 storage.
+	// It does not go through consensus.
+	V3DemoDefragment() error
+	// V3DemoMoveLeader transfers the leadership of the cluster to the
+	// given member, and waits until the member becomes the leader.
+	V3DemoMoveLeader(ctx context.Context, transferee uint64) error
+}
+
+// v3Result is the result of applying a v3 request.
+type v3Result struct {
+	resp proto.Message
+	err  error
+}
+
+// consistentIndex is the index of the last raft entry applied to the
+// v3 storage. It implements storage.ConsistentIndexGetter.
+type consistentIndex uint64
+
+func (i *consistentIndex) setConsistentIndex(v uint64) {
+	atomic.StoreUint64((*uint64)(i), v)
+}
+
+func (i *consistentIndex) ConsistentIndex() uint64 {
+	return atomic.LoadUint64((*uint64)(i))
+}
+
+func (s *EtcdServer) V3DemoDo(ctx context.Context, r pb.InternalRaftRequest) (proto.Message, error) {
+	if s.kv == nil {
+		return nil, ErrV3NotEnabled
+	}
+	if err := s.checkQuota(&r); err != nil {
+		return nil, err
+	}
+	r.ID = s.reqIDGen.Next()
+	if r.LeaseCreate != nil && r.LeaseCreate.ID == int64(lease.NoLease) {
+		// the lease ID is decided before proposing, so that all the
+		// members grant the lease with the same ID.
+		r.LeaseCreate.ID = int64(s.reqIDGen.Next() & ((1 << 63) - 1))
+	}
+
+	data, err := r.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	ch := s.w.Register(r.ID)
+
+	if err := s.r.Propose(ctx, data); err != nil {
+		s.w.Trigger(r.ID, nil) // GC wait
+		return nil, parseProposeErr(err)
+	}
+
+	select {
+	case x := <-ch:
+		result := x.(*v3Result)
+		return result.resp, result.err
+	case <-ctx.Done():
+		s.w.Trigger(r.ID, nil) // GC wait
+		return nil, parseCtxErr(ctx.Err())
+	case <-s.done:
+		return nil, ErrStopped
+	}
+}
+
+func (s *EtcdServer) V3DemoRange(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, error) {
+	if s.kv == nil {
+		return nil, ErrV3NotEnabled
+	}
+	if err := s.linearizableReadNotify(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := applyRange(s.kv, r)
+	if err != nil {
+		return nil, err
+	}
+	s.fillHeader(resp.Header)
+	return resp, nil
+}
+
+func (s *EtcdServer) V3DemoWatch(key, end []byte, startRev int64) (dstorage.Watcher, error) {
+	if s.kv == nil {
+		return nil, ErrV3NotEnabled
+	}
+	return s.kv.Watch(key, end, startRev)
+}
+
+func (s *EtcdServer) V3DemoHeader() *pb.ResponseHeader {
+	h := &pb.ResponseHeader{}
+	if s.kv != nil {
+		h.Index = s.kv.Rev()
+	}
+	s.fillHeader(h)
+	return h
+}
+
+func (s *EtcdServer) V3DemoLeaseRenew(id lease.LeaseID) (int64, error) {
+	if s.lessor == nil {
+		return -1, ErrV3NotEnabled
+	}
+	ttl, err := s.lessor.Renew(id)
+	if err == lease.ErrNotPrimary {
+		return -1, ErrNotLeader
+	}
+	return ttl, err
+}
+
+func (s *EtcdServer) V3DemoDefragment() error {
+	if s.be == nil {
+		return ErrV3NotEnabled
+	}
+	plog.Infof("defragmenting the v3 storage backend")
+	if err := s.be.Defrag(); err != nil {
+		plog.Errorf("failed to defragment the v3 storage backend (%v)", err)
+		return err
+	}
+	plog.Infof("finished defragmenting the v3 storage backend")
+	return nil
+}
+
+func (s *EtcdServer) V3DemoMoveLeader(ctx context.Context, transferee uint64) error {
+	return s.MoveLeader(ctx, transferee)
+}
+
+// revokeExpiredLeases revokes the given expired leases through consensus.
+func (s *EtcdServer) revokeExpiredLeases(leases []*lease.Lease) {
+	for _, l := range leases {
+		ctx, cancel := context.WithTimeout(context.Background(), leaseRevokeTimeout)
+		_, err := s.V3DemoDo(ctx, pb.InternalRaftRequest{LeaseRevoke: &pb.LeaseRevokeRequest{ID: int64(l.ID)}})
+		cancel()
+		if err != nil && err != lease.ErrLeaseNotFound {
+			plog.Warningf("failed to revoke expired lease %x (%v)", l.ID, err)
+		}
+	}
+}
+
+// v3Compactable compacts the v3 storage of the server through consensus
+// for the auto compactor.
+type v3Compactable struct {
+	s *EtcdServer
+}
+
+func (c *v3Compactable) Compact(ctx context.Context, r *pb.CompactionRequest) (*pb.CompactionResponse, error) {
+	cctx, cancel := context.WithTimeout(ctx, autoCompactionTimeout)
+	defer cancel()
+	result, err := c.s.V3DemoDo(cctx, pb.InternalRaftRequest{Compaction: r})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*pb.CompactionResponse), nil
+}
+
+// applyV3Request applies the given v3 request to the storage. The request
+// has been committed by raft.
+func (s *EtcdServer) applyV3Request(r *pb.InternalRaftRequest) *v3Result {
+	result := &v3Result{}
+	// the requests that grow the storage are rejected by all the members
+	// while the NOSPACE alarm is active.
+	if s.alarmStore != nil && quotaCost(r) != 0 && s.noSpace() {
+		result.err = ErrNoSpace
+		return result
+	}
+	switch {
+	case r.Range != nil:
+		result.resp, result.err = applyRange(s.kv, r.Range)
+	case r.Put != nil:
+		result.resp, result.err = applyPut(s.kv, s.lessor, r.Put)
+	case r.DeleteRange != nil:
+		result.resp = applyDeleteRange(s.kv, r.DeleteRange)
+	case r.Txn != nil:
+		result.resp, result.err = applyTxn(s.kv, s.lessor, r.Txn)
+	case r.Compaction != nil:
+		result.resp, result.err = applyCompaction(s.kv, r.Compaction)
+	case r.LeaseCreate != nil:
+		result.resp, result.err = applyLeaseCreate(s.kv, s.lessor, r.LeaseCreate)
+	case r.LeaseRevoke != nil:
+		result.resp, result.err = applyLeaseRevoke(s.kv, s.lessor, r.LeaseRevoke)
+	case r.Alarm != nil:
+		result.resp = s.applyAlarm(r.Alarm)
+	default:
+		plog.Panicf("unexpected v3 request type")
+	}
+	if result.err == nil {
+		s.fillHeader(responseHeader(result.resp))
+	}
+	return result
+}
+
+// responseHeader returns the response header of the given v3 response.
+func responseHeader(resp proto.Message) *pb.ResponseHeader {
+	var h *pb.ResponseHeader
+	switch r := resp.(type) {
+	case *pb.RangeResponse:
+		h = r.Header
+	case *pb.PutResponse:
+		h = r.Header
+	case *pb.DeleteRangeResponse:
+		h = r.Header
+	case *pb.TxnResponse:
+		h = r.Header
+	case *pb.CompactionResponse:
+		h = r.Header
+	case *pb.LeaseCreateResponse:
+		h = r.Header
+	case *pb.LeaseRevokeResponse:
+		h = r.Header
+	case *pb.AlarmResponse:
+		h = r.Header
+	}
+	return h
+}
+
+// fillHeader fills the cluster, member and raft related fields of the
+// given response header. The index field of the header is filled when
+// applying the request.
+func (s *EtcdServer) fillHeader(h *pb.ResponseHeader) {
+	if h == nil {
+		return
+	}
+	h.ClusterId = uint64(s.cluster.ID())
+	h.MemberId = uint64(s.id)
+	h.RaftTerm = s.Term()
+}
+
+func applyRange(kv dstorage.KV, r *pb.RangeRequest) (*pb.RangeResponse, error) {
+	return doRange(kv.Range, r)
+}
+
+// rangeFunc gets the keys in the given range at rangeRev.
+type rangeFunc func(key, end []byte, limit, rangeRev int64) ([]storagepb.KeyValue, int64, error)
+
+// doRange serves the given range request through the given rangeFunc.
+func doRange(rangef rangeFunc, r *pb.RangeRequest) (*pb.RangeResponse, error) {
+	rangeRev, err := rangeRevision(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// the keys are returned in ascending key order by the storage. All
+	// the keys are needed to sort them by any other order, or to count
+	// them.
+	limit := r.Limit
+	sorted := r.SortOrder != pb.RangeRequest_NONE &&
+		!(r.SortTarget == pb.RangeRequest_KEY && r.SortOrder == pb.RangeRequest_ASCEND)
+	if sorted || r.CountOnly {
+		limit = 0
+	} else if limit > 0 {
+		// get one more key to learn if there are more keys in the range
+		limit++
+	}
+
+	kvs, rev, err := rangef(r.Key, r.RangeEnd, limit, rangeRev)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.RangeResponse{
+		Header:          &pb.ResponseHeader{Index: rev},
+		ConsistentToken: consistentToken(rev),
+	}
+	if r.CountOnly {
+		resp.Count = int64(len(kvs))
+		return resp, nil
+	}
+	if sorted {
+		sortKeyValues(kvs, r.SortOrder, r.SortTarget)
+	}
+	if r.Limit > 0 && len(kvs) > int(r.Limit) {
+		kvs = kvs[:r.Limit]
+		resp.More = true
+	}
+	for i := range kvs {
+		if r.KeysOnly {
+			kvs[i].Value = nil
+		}
+		resp.Kvs = append(resp.Kvs, &kvs[i])
+	}
+	return resp, nil
+}
+
+// rangeRevision returns the revision to serve the given range request at.
+// A request with a consistent token is served at the revision of the token
+// if it does not give a revision, and is never served at an older revision.
+func rangeRevision(r *pb.RangeRequest) (int64, error) {
+	if len(r.ConsistentToken) == 0 {
+		return r.Revision, nil
+	}
+	trev, err := tokenRevision(r.ConsistentToken)
+	if err != nil {
+		return 0, err
+	}
+	if r.Revision <= 0 {
+		return trev, nil
+	}
+	if r.Revision < trev {
+		return 0, ErrRevisionBeforeToken
+	}
+	return r.Revision, nil
+}
+
+// consistentToken returns the consistent token of the given revision.
+func consistentToken(rev int64) []byte {
+	token := make([]byte, 8)
+	binary.BigEndian.PutUint64(token, uint64(rev))
+	return token
+}
+
+// tokenRevision returns the revision of the given consistent token.
+func tokenRevision(token []byte) (int64, error) {
+	if len(token) != 8 {
+		return 0, ErrInvalidConsistentToken
+	}
+	rev := int64(binary.BigEndian.Uint64(token))
+	if rev <= 0 {
+		return 0, ErrInvalidConsistentToken
+	}
+	return rev, nil
+}
+
+type kvSort struct {
+	kvs  []storagepb.KeyValue
+	less func(a, b *storagepb.KeyValue) bool
+}
+
+func (s *kvSort) Len() int           { return len(s.kvs) }
+func (s *kvSort) Swap(i, j int)      { s.kvs[i], s.kvs[j] = s.kvs[j], s.kvs[i] }
+func (s *kvSort) Less(i, j int) bool { return s.less(&s.kvs[i], &s.kvs[j]) }
+
+// sortKeyValues sorts the given key-values, which are in ascending key
+// order, by the given target in the given order. The key-values with the
+// same target value stay in ascending key order.
+func sortKeyValues(kvs []storagepb.KeyValue, order pb.RangeRequest_SortOrder, target pb.RangeRequest_SortTarget) {
+	var cmp func(a, b *storagepb.KeyValue) int
+	switch target {
+	case pb.RangeRequest_KEY:
+		cmp = func(a, b *storagepb.KeyValue) int { return bytes.Compare(a.Key, b.Key) }
+	case pb.RangeRequest_VERSION:
+		cmp = func(a, b *storagepb.KeyValue) int { return compareInt64(a.Version, b.Version) }
+	case pb.RangeRequest_CREATE:
+		cmp = func(a, b *storagepb.KeyValue) int { return compareInt64(a.CreateIndex, b.CreateIndex) }
+	case pb.RangeRequest_MOD:
+		cmp = func(a, b *storagepb.KeyValue) int { return compareInt64(a.ModIndex, b.ModIndex) }
+	case pb.RangeRequest_VALUE:
+		cmp = func(a, b *storagepb.KeyValue) int { return bytes.Compare(a.Value, b.Value) }
+	default:
+		return
+	}
+	less := func(a, b *storagepb.KeyValue) bool { return cmp(a, b) < 0 }
+	if order == pb.RangeRequest_DESCEND {
+		less = func(a, b *storagepb.KeyValue) bool { return cmp(a, b) > 0 }
+	}
+	sort.Stable(&kvSort{kvs: kvs, less: less})
+}
+
+func applyPut(kv dstorage.KV, le lease.Lessor, r *pb.PutRequest) (*pb.PutResponse, error) {
+	if !leaseExists(le, r.Lease) {
+		return nil, lease.ErrLeaseNotFound
+	}
+	// the previous key-value is read in the same txn as the put, so that
+	// no other change can happen between them.
+	txnID := kv.TxnBegin()
+	resp := txnPut(txnID, kv, r)
+	if err := kv.TxnEnd(txnID); err != nil {
+		plog.Panicf("unexpected end txn error: %v", err)
+	}
+	resp.Header.Index = kv.Rev()
+	return resp, nil
+}
+
+func applyDeleteRange(kv dstorage.KV, r *pb.DeleteRangeRequest) *pb.DeleteRangeResponse {
+	txnID := kv.TxnBegin()
+	resp := txnDeleteRange(txnID, kv, r)
+	if err := kv.TxnEnd(txnID); err != nil {
+		plog.Panicf("unexpected end txn error: %v", err)
+	}
+	resp.Header.Index = kv.Rev()
+	return resp
+}
+
+// txnPut applies the given put request inside the on-going txn. The index
+// of the response header is left to be filled after the txn ends.
+func txnPut(txnID int64, kv dstorage.KV, r *pb.PutRequest) *pb.PutResponse {
+	resp := &pb.PutResponse{Header: &pb.ResponseHeader{}}
+	if r.PrevKv {
+		kvs, _, err := kv.TxnRange(txnID, r.Key, nil, 1, 0)
+		if err != nil {
+			plog.Panicf("unexpected error during txn: %v", err)
+		}
+		if len(kvs) != 0 {
+			resp.PrevKv = &kvs[0]
+		}
+	}
+	if _, err := kv.TxnPut(txnID, r.Key, r.Value, lease.LeaseID(r.Lease)); err != nil {
+		plog.Panicf("unexpected error during txn: %v", err)
+	}
+	return resp
+}
+
+// txnDeleteRange applies the given delete range request inside the
+// on-going txn. The index of the response header is left to be filled
+// after the txn ends.
+func txnDeleteRange(txnID int64, kv dstorage.KV, r *pb.DeleteRangeRequest) *pb.DeleteRangeResponse {
+	resp := &pb.DeleteRangeResponse{Header: &pb.ResponseHeader{}}
+	if r.PrevKv {
+		kvs, _, err := kv.TxnRange(txnID, r.Key, r.RangeEnd, 0, 0)
+		if err != nil {
+			plog.Panicf("unexpected error during txn: %v", err)
+		}
+		for i := range kvs {
+			resp.PrevKvs = append(resp.PrevKvs, &kvs[i])
+		}
+	}
+	n, _, err := kv.TxnDeleteRange(txnID, r.Key, r.RangeEnd)
+	if err != nil {
+		plog.Panicf("unexpected error during txn: %v", err)
+	}
+	resp.Deleted = n
+	return resp
+}
+
+func applyCompaction(kv dstorage.KV, r *pb.CompactionRequest) (*pb.CompactionResponse, error) {
+	if err := kv.Compact(r.Index); err != nil {
+		return nil, err
+	}
+	return &pb.CompactionResponse{Header: &pb.ResponseHeader{Index: kv.Rev()}}, nil
+}
+
+func applyTxn(kv dstorage.KV, le lease.Lessor, r *pb.TxnRequest) (*pb.TxnResponse, error) {
+	// the leases and the revisions of the ranges are checked before the
+	// txn begins, so that a txn with a bad request changes nothing.
+	for _, reqs := range [][]*pb.RequestUnion{r.Success, r.Failure} {
+		for _, req := range reqs {
+			if req.RequestPut != nil && !leaseExists(le, req.RequestPut.Lease) {
+				return nil, lease.ErrLeaseNotFound
+			}
+			if req.RequestRange != nil {
+				if err := checkRange(kv, req.RequestRange); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	txnID := kv.TxnBegin()
+
+	ok := true
+	for _, c := range r.Compare {
+		if !applyCompare(txnID, kv, c) {
+			ok = false
+			break
+		}
+	}
+
+	reqs := r.Success
+	if !ok {
+		reqs = r.Failure
+	}
+
+	resps := make([]*pb.ResponseUnion, len(reqs))
+	for i := range reqs {
+		resps[i] = applyUnion(txnID, kv, reqs[i])
+	}
+
+	if err := kv.TxnEnd(txnID); err != nil {
+		plog.Panicf("unexpected end txn error: %v", err)
+	}
+
+	// all the operations in a txn share the revision of the store
+	// after the txn ends.
+	rev := kv.Rev()
+	for _, resp := range resps {
+		setUnionHeaderIndex(resp, rev)
+	}
+
+	return &pb.TxnResponse{
+		Header:    &pb.ResponseHeader{Index: rev},
+		Succeeded: ok,
+		Responses: resps,
+	}, nil
+}
+
+// applyUnion applies the request in the given union inside the on-going txn.
+func applyUnion(txnID int64, kv dstorage.KV, union *pb.RequestUnion) *pb.ResponseUnion {
+	switch {
+	case union.RequestRange != nil:
+		rangef := func(key, end []byte, limit, rangeRev int64) ([]storagepb.KeyValue, int64, error) {
+			return kv.TxnRange(txnID, key, end, limit, rangeRev)
+		}
+		// the revision of the range has been checked before the txn
+		// begins, so it never hits a compacted or future revision.
+		resp, err := doRange(rangef, union.RequestRange)
+		if err != nil {
+			plog.Panicf("unexpected error during txn: %v", err)
+		}
+		return &pb.ResponseUnion{ReponseRange: resp}
+	case union.RequestPut != nil:
+		return &pb.ResponseUnion{ResponsePut: txnPut(txnID, kv, union.RequestPut)}
+	case union.RequestDeleteRange != nil:
+		return &pb.ResponseUnion{ResponseDeleteRange: txnDeleteRange(txnID, kv, union.RequestDeleteRange)}
+	default:
+		// empty union
+		return &pb.ResponseUnion{}
+	}
+}
+
+func applyLeaseCreate(kv dstorage.KV, le lease.Lessor, r *pb.LeaseCreateRequest) (*pb.LeaseCreateResponse, error) {
+	l, err := le.Grant(lease.LeaseID(r.ID), r.TTL)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.LeaseCreateResponse{
+		Header: &pb.ResponseHeader{Index: kv.Rev()},
+		ID:     int64(l.ID),
+		TTL:    l.TTL,
+	}, nil
+}
+
+func applyLeaseRevoke(kv dstorage.KV, le lease.Lessor, r *pb.LeaseRevokeRequest) (*pb.LeaseRevokeResponse, error) {
+	if err := le.Revoke(lease.LeaseID(r.ID)); err != nil {
+		return nil, err
+	}
+	return &pb.LeaseRevokeResponse{Header: &pb.ResponseHeader{Index: kv.Rev()}}, nil
+}
+
+// checkRange checks if the given range request can be served at its
+// revision by the given kv.
+func checkRange(kv dstorage.KV, r *pb.RangeRequest) error {
+	rangeRev, err := rangeRevision(r)
+	if err != nil {
+		return err
+	}
+	if rangeRev <= 0 {
+		return nil
+	}
+	_, _, err = kv.Range(r.Key, r.RangeEnd, 1, rangeRev)
+	return err
+}
+
+func (s *EtcdServer) applyAlarm(r *pb.AlarmRequest) *pb.AlarmResponse {
+	resp := &pb.AlarmResponse{Header: &pb.ResponseHeader{Index: s.kv.Rev()}}
+	switch r.Action {
+	case pb.AlarmRequest_GET:
+		for _, m := range s.alarmStore.Get(r.Alarm) {
+			if r.MemberId == 0 || m.MemberId == r.MemberId {
+				resp.Alarms = append(resp.Alarms, m)
+			}
+		}
+		sort.Sort(alarmsByMember(resp.Alarms))
+	case pb.AlarmRequest_ACTIVATE:
+		if r.Alarm == pb.AlarmType_NONE {
+			break
+		}
+		if m := s.alarmStore.Activate(types.ID(r.MemberId), r.Alarm); m != nil {
+			plog.Warningf("alarm %v raised by member %s", m.Alarm, types.ID(m.MemberId))
+			resp.Alarms = append(resp.Alarms, m)
+		}
+	case pb.AlarmRequest_DEACTIVATE:
+		if m := s.alarmStore.Deactivate(types.ID(r.MemberId), r.Alarm); m != nil {
+			plog.Infof("alarm %v of member %s is disarmed", m.Alarm, types.ID(m.MemberId))
+			resp.Alarms = append(resp.Alarms, m)
+		}
+	}
+	return resp
+}
+
+type alarmsByMember []*pb.AlarmMember
+
+func (a alarmsByMember) Len() int      { return len(a) }
+func (a alarmsByMember) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a alarmsByMember) Less(i, j int) bool {
+	if a[i].MemberId != a[j].MemberId {
+		return a[i].MemberId < a[j].MemberId
+	}
+	return a[i].Alarm < a[j].Alarm
+}
+
+// leaseExists returns true if the lease with the given ID can be attached
+// to a key. No lease always exists.
+func leaseExists(le lease.Lessor, id int64) bool {
+	if lease.LeaseID(id) == lease.NoLease {
+		return true
+	}
+	return le != nil && le.Lookup(lease.LeaseID(id)) != nil
+}
+
+func setUnionHeaderIndex(resp *pb.ResponseUnion, rev int64) {
+	switch {
+	case resp.ReponseRange != nil:
+		resp.ReponseRange.Header.Index = rev
+	case resp.ResponsePut != nil:
+		resp.ResponsePut.Header.Index = rev
+	case resp.ResponseDeleteRange != nil:
+		resp.ResponseDeleteRange.Header.Index = rev
+	}
+}
+
+// applyCompare applies the compare request inside the on-going txn.
+// It returns true when the compare succeeds.
+func applyCompare(txnID int64, kv dstorage.KV, c *pb.Compare) bool {
+	ckvs, _, err := kv.TxnRange(txnID, c.Key, nil, 1, 0)
+	if err != nil {
+		if err == dstorage.ErrTxnIDMismatch {
+			plog.Panicf("unexpected txn ID mismatch error")
+		}
+		return false
+	}
+
+	// a key that does not exist has zero version, create index and mod index.
+	var ckv storagepb.KeyValue
+	if len(ckvs) != 0 {
+		ckv = ckvs[0]
+	} else if c.Target == pb.Compare_VALUE {
+		// a key that does not exist has no value to compare with.
+		return false
+	}
+
+	// -1 is less, 0 is equal, 1 is greater
+	var result int
+	switch c.Target {
+	case pb.Compare_VALUE:
+		result = bytes.Compare(ckv.Value, c.Value)
+	case pb.Compare_CREATE:
+		result = compareInt64(ckv.CreateIndex, c.CreateIndex)
+	case pb.Compare_MOD:
+		result = compareInt64(ckv.ModIndex, c.ModIndex)
+	case pb.Compare_VERSION:
+		result = compareInt64(ckv.Version, c.Version)
+	}
+
+	switch c.Type {
+	case pb.Compare_EQUAL:
+		return result == 0
+	case pb.Compare_GREATER:
+		return result > 0
+	case pb.Compare_LESS:
+		return result < 0
+	}
+	return false
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,581 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	"github.com/coreos/etcd/alarm"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/lease"
+	"github.com/coreos/etcd/pkg/pbutil"
+	"github.com/coreos/etcd/pkg/wait"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/coreos/etcd/snap"
+	dstorage "github.com/coreos/etcd/storage"
+	"github.com/coreos/etcd/storage/backend"
+)
+
+// TestV2RequestIsNotInternalRaftRequest ensures that the v2 requests that
+// go through raft are never mistaken for an InternalRaftRequest.
+func TestV2RequestIsNotInternalRaftRequest(t *testing.T) {
+	methods := []string{"POST", "PUT", "DELETE", "QGET", "SYNC"}
+	for i, m := range methods {
+		r := pb.Request{
+			ID:     uint64(i + 1),
+			Method: m,
+			Path:   "/foo",
+			Val:    "bar",
+			Time:   100,
+		}
+		var raftReq pb.InternalRaftRequest
+		if pbutil.MaybeUnmarshal(&raftReq, pbutil.MustMarshal(&r)) {
+			t.Errorf("#%d: %s request is unmarshaled as InternalRaftRequest %+v", i, m, raftReq)
+		}
+	}
+}
+
+func TestApplyV3Txn(t *testing.T) {
+	kv, cleanup := newTestKV(t)
+	defer cleanup()
+
+	kv.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+
+	tests := []struct {
+		cmp *pb.Compare
+
+		wsucc bool
+	}{
+		{&pb.Compare{Type: pb.Compare_EQUAL, Target: pb.Compare_VALUE, Key: []byte("foo"), Value: []byte("bar")}, true},
+		{&pb.Compare{Type: pb.Compare_EQUAL, Target: pb.Compare_VALUE, Key: []byte("foo"), Value: []byte("baz")}, false},
+		{&pb.Compare{Type: pb.Compare_LESS, Target: pb.Compare_VALUE, Key: []byte("foo"), Value: []byte("baz")}, true},
+		{&pb.Compare{Type: pb.Compare_EQUAL, Target: pb.Compare_VALUE, Key: []byte("none"), Value: nil}, false},
+		{&pb.Compare{Type: pb.Compare_EQUAL, Target: pb.Compare_VERSION, Key: []byte("none"), Version: 0}, true},
+		{&pb.Compare{Type: pb.Compare_EQUAL, Target: pb.Compare_CREATE, Key: []byte("foo"), CreateIndex: 1}, true},
+		{&pb.Compare{Type: pb.Compare_GREATER, Target: pb.Compare_MOD, Key: []byte("foo"), ModIndex: 1}, false},
+	}
+	for i, tt := range tests {
+		rev := kv.Rev()
+		r := &pb.TxnRequest{
+			Compare: []*pb.Compare{tt.cmp},
+			Success: []*pb.RequestUnion{{RequestPut: &pb.PutRequest{Key: []byte("succ"), Value: []byte("v")}}},
+			Failure: []*pb.RequestUnion{{RequestRange: &pb.RangeRequest{Key: []byte("foo")}}},
+		}
+		resp, err := applyTxn(kv, nil, r)
+		if err != nil {
+			t.Fatalf("#%d: unexpected txn error: %v", i, err)
+		}
+		if resp.Succeeded != tt.wsucc {
+			t.Errorf("#%d: succeeded = %v, want %v", i, resp.Succeeded, tt.wsucc)
+		}
+		wrev := rev
+		if tt.wsucc {
+			wrev = rev + 1
+		}
+		if resp.Header.Index != wrev {
+			t.Errorf("#%d: index = %d, want %d", i, resp.Header.Index, wrev)
+		}
+		if len(resp.Responses) != 1 {
+			t.Fatalf("#%d: len(responses) = %d, want 1", i, len(resp.Responses))
+		}
+		if !tt.wsucc && len(resp.Responses[0].ReponseRange.Kvs) != 1 {
+			t.Errorf("#%d: len(range kvs) = %d, want 1", i, len(resp.Responses[0].ReponseRange.Kvs))
+		}
+	}
+}
+
+func TestApplyV3Range(t *testing.T) {
+	kv, cleanup := newTestKV(t)
+	defer cleanup()
+
+	kv.Put([]byte("foo1"), []byte("c"), lease.NoLease) // rev 1
+	kv.Put([]byte("foo2"), []byte("b"), lease.NoLease) // rev 2
+	kv.Put([]byte("foo3"), []byte("a"), lease.NoLease) // rev 3
+	kv.Put([]byte("foo1"), []byte("d"), lease.NoLease) // rev 4
+
+	tests := []struct {
+		r *pb.RangeRequest
+
+		wkeys  []string
+		wmore  bool
+		wcount int64
+		windex int64
+		werr   error
+	}{
+		{
+			&pb.RangeRequest{Key: []byte("foo1"), RangeEnd: []byte("foo4")},
+			[]string{"foo1", "foo2", "foo3"}, false, 0, 4, nil,
+		},
+		{
+			&pb.RangeRequest{Key: []byte("foo1"), RangeEnd: []byte("foo4"), Limit: 2},
+			[]string{"foo1", "foo2"}, true, 0, 4, nil,
+		},
+		{
+			&pb.RangeRequest{Key: []byte("foo1"), RangeEnd: []byte("foo4"), Limit: 3},
+			[]string{"foo1", "foo2", "foo3"}, false, 0, 4, nil,
+		},
+		{
+			&pb.RangeRequest{Key: []byte("foo1"), RangeEnd: []byte("foo4"), Revision: 2},
+			[]string{"foo1", "foo2"}, false, 0, 2, nil,
+		},
+		{
+			&pb.RangeRequest{Key: []byte("foo1"), RangeEnd: []byte("foo4"), Revision: 5},
+			nil, false, 0, 0, dstorage.ErrFutureRev,
+		},
+		{
+			&pb.RangeRequest{Key: []byte("foo1"), RangeEnd: []byte("foo4"), SortOrder: pb.RangeRequest_DESCEND},
+			[]string{"foo3", "foo2", "foo1"}, false, 0, 4, nil,
+		},
+		{
+			&pb.RangeRequest{Key: []byte("foo1"), RangeEnd: []byte("foo4"), SortOrder: pb.RangeRequest_ASCEND, SortTarget: pb.RangeRequest_VALUE},
+			[]string{"foo3", "foo2", "foo1"}, false, 0, 4, nil,
+		},
+		{
+			&pb.RangeRequest{Key: []byte("foo1"), RangeEnd: []byte("foo4"), SortOrder: pb.RangeRequest_DESCEND, SortTarget: pb.RangeRequest_MOD, Limit: 1},
+			[]string{"foo1"}, true, 0, 4, nil,
+		},
+		{
+			&pb.RangeRequest{Key: []byte("foo1"), RangeEnd: []byte("foo4"), SortOrder: pb.RangeRequest_ASCEND, SortTarget: pb.RangeRequest_CREATE},
+			[]string{"foo1", "foo2", "foo3"}, false, 0, 4, nil,
+		},
+		{
+			&pb.RangeRequest{Key: []byte("foo1"), RangeEnd: []byte("foo4"), CountOnly: true, Limit: 1},
+			nil, false, 3, 4, nil,
+		},
+		// the range is read at the revision of the token
+		{
+			&pb.RangeRequest{Key: []byte("foo1"), RangeEnd: []byte("foo4"), ConsistentToken: consistentToken(1)},
+			[]string{"foo1"}, false, 0, 1, nil,
+		},
+		{
+			&pb.RangeRequest{Key: []byte("foo1"), RangeEnd: []byte("foo4"), ConsistentToken: consistentToken(3), Revision: 4},
+			[]string{"foo1", "foo2", "foo3"}, false, 0, 4, nil,
+		},
+		{
+			&pb.RangeRequest{Key: []byte("foo1"), RangeEnd: []byte("foo4"), ConsistentToken: consistentToken(3), Revision: 2},
+			nil, false, 0, 0, ErrRevisionBeforeToken,
+		},
+		{
+			&pb.RangeRequest{Key: []byte("foo1"), RangeEnd: []byte("foo4"), ConsistentToken: []byte("bad")},
+			nil, false, 0, 0, ErrInvalidConsistentToken,
+		},
+	}
+	for i, tt := range tests {
+		resp, err := applyRange(kv, tt.r)
+		if err != tt.werr {
+			t.Errorf("#%d: err = %v, want %v", i, err, tt.werr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		var keys []string
+		for _, kv := range resp.Kvs {
+			keys = append(keys, string(kv.Key))
+		}
+		if !reflect.DeepEqual(keys, tt.wkeys) {
+			t.Errorf("#%d: keys = %v, want %v", i, keys, tt.wkeys)
+		}
+		if resp.More != tt.wmore {
+			t.Errorf("#%d: more = %v, want %v", i, resp.More, tt.wmore)
+		}
+		if resp.Count != tt.wcount {
+			t.Errorf("#%d: count = %d, want %d", i, resp.Count, tt.wcount)
+		}
+		if resp.Header.Index != tt.windex {
+			t.Errorf("#%d: index = %d, want %d", i, resp.Header.Index, tt.windex)
+		}
+		if !reflect.DeepEqual(resp.ConsistentToken, consistentToken(tt.windex)) {
+			t.Errorf("#%d: consistent token = %v, want token of %d", i, resp.ConsistentToken, tt.windex)
+		}
+	}
+
+	// keys only
+	resp, err := applyRange(kv, &pb.RangeRequest{Key: []byte("foo1"), KeysOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Kvs) != 1 || resp.Kvs[0].Value != nil {
+		t.Errorf("kvs = %+v, want one key without value", resp.Kvs)
+	}
+
+	// a txn with a range at a future revision changes nothing
+	txn := &pb.TxnRequest{
+		Success: []*pb.RequestUnion{
+			{RequestPut: &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar")}},
+			{RequestRange: &pb.RangeRequest{Key: []byte("foo"), Revision: 10}},
+		},
+	}
+	if _, err := applyTxn(kv, nil, txn); err != dstorage.ErrFutureRev {
+		t.Errorf("txn err = %v, want %v", err, dstorage.ErrFutureRev)
+	}
+	if rev := kv.Rev(); rev != 4 {
+		t.Errorf("rev = %d, want 4", rev)
+	}
+}
+
+func TestApplyV3PrevKV(t *testing.T) {
+	kv, cleanup := newTestKV(t)
+	defer cleanup()
+
+	presp, err := applyPut(kv, nil, &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar"), PrevKv: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if presp.PrevKv != nil {
+		t.Errorf("prev kv = %+v, want nil", presp.PrevKv)
+	}
+	presp, err = applyPut(kv, nil, &pb.PutRequest{Key: []byte("foo"), Value: []byte("baz"), PrevKv: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if presp.Header.Index != 2 {
+		t.Errorf("index = %d, want 2", presp.Header.Index)
+	}
+	if presp.PrevKv == nil || string(presp.PrevKv.Value) != "bar" || presp.PrevKv.ModIndex != 1 {
+		t.Errorf("prev kv = %+v, want foo=bar at index 1", presp.PrevKv)
+	}
+	applyPut(kv, nil, &pb.PutRequest{Key: []byte("foo1"), Value: []byte("bar1")})
+
+	dresp := applyDeleteRange(kv, &pb.DeleteRangeRequest{Key: []byte("foo"), RangeEnd: []byte("foo2"), PrevKv: true})
+	if dresp.Deleted != 2 {
+		t.Errorf("deleted = %d, want 2", dresp.Deleted)
+	}
+	if dresp.Header.Index != 4 {
+		t.Errorf("index = %d, want 4", dresp.Header.Index)
+	}
+	if len(dresp.PrevKvs) != 2 || string(dresp.PrevKvs[0].Value) != "baz" || string(dresp.PrevKvs[1].Value) != "bar1" {
+		t.Errorf("prev kvs = %+v, want foo=baz and foo1=bar1", dresp.PrevKvs)
+	}
+	dresp = applyDeleteRange(kv, &pb.DeleteRangeRequest{Key: []byte("foo")})
+	if dresp.Deleted != 0 || dresp.Header.Index != 4 {
+		t.Errorf("deleted = %d at index %d, want 0 at index 4", dresp.Deleted, dresp.Header.Index)
+	}
+
+	// every operation of the txn branch has its response
+	txn := &pb.TxnRequest{
+		Success: []*pb.RequestUnion{
+			{RequestPut: &pb.PutRequest{Key: []byte("foo"), Value: []byte("v1"), PrevKv: true}},
+			{RequestPut: &pb.PutRequest{Key: []byte("foo"), Value: []byte("v2"), PrevKv: true}},
+			{RequestDeleteRange: &pb.DeleteRangeRequest{Key: []byte("foo"), PrevKv: true}},
+		},
+	}
+	tresp, err := applyTxn(kv, nil, txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tresp.Responses) != 3 {
+		t.Fatalf("len(responses) = %d, want 3", len(tresp.Responses))
+	}
+	if p := tresp.Responses[0].ResponsePut.PrevKv; p != nil {
+		t.Errorf("prev kv of the first put = %+v, want nil", p)
+	}
+	if p := tresp.Responses[1].ResponsePut.PrevKv; p == nil || string(p.Value) != "v1" {
+		t.Errorf("prev kv of the second put = %+v, want foo=v1", p)
+	}
+	d := tresp.Responses[2].ResponseDeleteRange
+	if d.Deleted != 1 || len(d.PrevKvs) != 1 || string(d.PrevKvs[0].Value) != "v2" {
+		t.Errorf("delete response = %+v, want foo=v2 deleted", d)
+	}
+	for i, resp := range tresp.Responses {
+		var index int64
+		switch {
+		case resp.ResponsePut != nil:
+			index = resp.ResponsePut.Header.Index
+		case resp.ResponseDeleteRange != nil:
+			index = resp.ResponseDeleteRange.Header.Index
+		}
+		if index != 5 {
+			t.Errorf("#%d: index = %d, want 5", i, index)
+		}
+	}
+}
+
+func TestApplyEntryNormalV3(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "etcdserver_v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	srv := &EtcdServer{
+		id:      1,
+		cluster: &cluster{id: 2},
+		w:       wait.New(),
+	}
+	be := backend.NewDefaultBackend(path.Join(dir, "db"))
+	srv.kv = dstorage.NewConsistentWatchable(be, nil, &srv.consistIndex)
+	defer srv.kv.Close()
+
+	raftReq := pb.InternalRaftRequest{ID: 1, Put: &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar")}}
+	ch := srv.w.Register(1)
+	srv.applyEntryNormal(&raftpb.Entry{Index: 5, Data: pbutil.MustMarshal(&raftReq)})
+	result := (<-ch).(*v3Result)
+	if result.err != nil {
+		t.Fatalf("unexpected apply error: %v", result.err)
+	}
+	wresp := &pb.PutResponse{Header: &pb.ResponseHeader{ClusterId: 2, MemberId: 1, Index: 1}}
+	if !reflect.DeepEqual(result.resp, wresp) {
+		t.Errorf("resp = %+v, want %+v", result.resp, wresp)
+	}
+	if g := srv.kv.ConsistentIndex(); g != 5 {
+		t.Errorf("consistent index = %d, want 5", g)
+	}
+
+	// the entry has been applied, and should be skipped when it is replayed.
+	raftReq.ID = 2
+	srv.applyEntryNormal(&raftpb.Entry{Index: 5, Data: pbutil.MustMarshal(&raftReq)})
+	if g := srv.kv.Rev(); g != 1 {
+		t.Errorf("rev = %d, want 1", g)
+	}
+}
+
+func TestApplyV3Lease(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "etcdserver_v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var ci consistentIndex
+	be := backend.NewDefaultBackend(path.Join(dir, "db"))
+	le := lease.NewLessor(be)
+	defer le.Stop()
+	kv := dstorage.NewConsistentWatchable(be, le, &ci)
+	defer kv.Close()
+
+	if _, err := applyPut(kv, le, &pb.PutRequest{Key: []byte("foo"), Lease: 1}); err != lease.ErrLeaseNotFound {
+		t.Fatalf("put error = %v, want %v", err, lease.ErrLeaseNotFound)
+	}
+	txn := &pb.TxnRequest{Failure: []*pb.RequestUnion{{RequestPut: &pb.PutRequest{Key: []byte("foo"), Lease: 1}}}}
+	if _, err := applyTxn(kv, le, txn); err != lease.ErrLeaseNotFound {
+		t.Fatalf("txn error = %v, want %v", err, lease.ErrLeaseNotFound)
+	}
+
+	cresp, err := applyLeaseCreate(kv, le, &pb.LeaseCreateRequest{ID: 1, TTL: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cresp.ID != 1 || cresp.TTL != 10 {
+		t.Errorf("lease = %d/%d, want 1/10", cresp.ID, cresp.TTL)
+	}
+	if _, err := applyLeaseCreate(kv, le, &pb.LeaseCreateRequest{ID: 1, TTL: 10}); err != lease.ErrLeaseExists {
+		t.Errorf("create error = %v, want %v", err, lease.ErrLeaseExists)
+	}
+
+	applyPut(kv, le, &pb.PutRequest{Key: []byte("foo"), Lease: 1})
+	applyPut(kv, le, &pb.PutRequest{Key: []byte("foo1"), Lease: 1})
+	applyPut(kv, le, &pb.PutRequest{Key: []byte("foo2")})
+
+	// all the keys attached to the lease are deleted in one revision
+	rresp, err := applyLeaseRevoke(kv, le, &pb.LeaseRevokeRequest{ID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rresp.Header.Index != 4 {
+		t.Errorf("index = %d, want 4", rresp.Header.Index)
+	}
+	kvs, _, err := kv.Range([]byte("foo"), []byte("foo3"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || string(kvs[0].Key) != "foo2" {
+		t.Errorf("kvs = %+v, want only foo2", kvs)
+	}
+	if _, err := applyLeaseRevoke(kv, le, &pb.LeaseRevokeRequest{ID: 1}); err != lease.ErrLeaseNotFound {
+		t.Errorf("revoke error = %v, want %v", err, lease.ErrLeaseNotFound)
+	}
+}
+
+func TestApplyV3NoSpaceAlarm(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "etcdserver_v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	srv := &EtcdServer{
+		id:      1,
+		cluster: &cluster{id: 2},
+	}
+	srv.be = backend.NewDefaultBackend(path.Join(dir, "db"))
+	srv.kv = dstorage.NewConsistentWatchable(srv.be, nil, &srv.consistIndex)
+	defer srv.kv.Close()
+	srv.alarmStore = alarm.NewAlarmStore(srv.be)
+	srv.quotaBytes = DefaultQuotaBytes
+
+	put := &pb.InternalRaftRequest{Put: &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar")}}
+	if err := srv.checkQuota(put); err != nil {
+		t.Fatalf("quota error = %v, want nil", err)
+	}
+
+	activate := &pb.AlarmRequest{Action: pb.AlarmRequest_ACTIVATE, MemberId: 1, Alarm: pb.AlarmType_NOSPACE}
+	result := srv.applyV3Request(&pb.InternalRaftRequest{Alarm: activate})
+	if resp := result.resp.(*pb.AlarmResponse); len(resp.Alarms) != 1 {
+		t.Errorf("activated alarms = %+v, want one", resp.Alarms)
+	}
+
+	// the requests that grow the storage are rejected
+	if err := srv.checkQuota(put); err != ErrNoSpace {
+		t.Errorf("quota error = %v, want %v", err, ErrNoSpace)
+	}
+	tests := []*pb.InternalRaftRequest{
+		put,
+		{Txn: &pb.TxnRequest{Failure: []*pb.RequestUnion{{RequestPut: &pb.PutRequest{Key: []byte("foo")}}}}},
+		{LeaseCreate: &pb.LeaseCreateRequest{ID: 1, TTL: 10}},
+	}
+	for i, r := range tests {
+		if result := srv.applyV3Request(r); result.err != ErrNoSpace {
+			t.Errorf("#%d: err = %v, want %v", i, result.err, ErrNoSpace)
+		}
+	}
+	// reads, deletes and compaction keep working
+	tests = []*pb.InternalRaftRequest{
+		{Range: &pb.RangeRequest{Key: []byte("foo")}},
+		{DeleteRange: &pb.DeleteRangeRequest{Key: []byte("foo")}},
+		{Txn: &pb.TxnRequest{Success: []*pb.RequestUnion{{RequestDeleteRange: &pb.DeleteRangeRequest{Key: []byte("foo")}}}}},
+	}
+	for i, r := range tests {
+		if result := srv.applyV3Request(r); result.err != nil {
+			t.Errorf("#%d: err = %v, want nil", i, result.err)
+		}
+	}
+
+	get := &pb.AlarmRequest{Action: pb.AlarmRequest_GET}
+	result = srv.applyV3Request(&pb.InternalRaftRequest{Alarm: get})
+	wresp := &pb.AlarmResponse{
+		Header: &pb.ResponseHeader{ClusterId: 2, MemberId: 1},
+		Alarms: []*pb.AlarmMember{{MemberId: 1, Alarm: pb.AlarmType_NOSPACE}},
+	}
+	if !reflect.DeepEqual(result.resp, wresp) {
+		t.Errorf("resp = %+v, want %+v", result.resp, wresp)
+	}
+
+	deactivate := &pb.AlarmRequest{Action: pb.AlarmRequest_DEACTIVATE, MemberId: 1, Alarm: pb.AlarmType_NOSPACE}
+	srv.applyV3Request(&pb.InternalRaftRequest{Alarm: deactivate})
+	if result := srv.applyV3Request(put); result.err != nil {
+		t.Errorf("put error = %v, want nil", result.err)
+	}
+}
+
+func newTestKV(t *testing.T) (dstorage.KV, func()) {
+	dir, err := ioutil.TempDir(os.TempDir(), "etcdserver_v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kv := dstorage.New(path.Join(dir, "db"))
+	return kv, func() {
+		kv.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// TestRecoverPendingV3 ensures that a restarted member recovers the v3
+// storage from the database snapshot of an incoming snapshot that it
+// saved but did not apply before it stopped.
+func TestRecoverPendingV3(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "etcdserver_v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the database snapshot of the leader at index 10
+	var lci consistentIndex
+	lci.setConsistentIndex(10)
+	lbe := backend.NewDefaultBackend(path.Join(dir, "leader"))
+	lkv := dstorage.NewConsistentWatchable(lbe, nil, &lci)
+	lkv.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	var buf bytes.Buffer
+	lbe.ForceCommit()
+	if _, err := lbe.Snapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	lkv.Close()
+
+	srv := &EtcdServer{snapshotter: snap.New(dir)}
+	if _, err := srv.snapshotter.SaveDBFrom(&buf, 10); err != nil {
+		t.Fatal(err)
+	}
+	srv.be = backend.NewDefaultBackend(path.Join(dir, "db"))
+	srv.lessor = lease.NewLessor(srv.be)
+	defer srv.lessor.Stop()
+	srv.kv = dstorage.NewConsistentWatchable(srv.be, srv.lessor, &srv.consistIndex)
+	defer srv.kv.Close()
+	srv.alarmStore = alarm.NewAlarmStore(srv.be)
+
+	// the v3 storage is not behind the snapshot at index 0
+	srv.recoverPendingV3(0)
+	if g := srv.kv.ConsistentIndex(); g != 0 {
+		t.Errorf("consistent index = %d, want 0", g)
+	}
+
+	srv.recoverPendingV3(10)
+	if g := srv.kv.ConsistentIndex(); g != 10 {
+		t.Errorf("consistent index = %d, want 10", g)
+	}
+	kvs, _, err := srv.kv.Range([]byte("foo"), nil, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || string(kvs[0].Value) != "bar" {
+		t.Errorf("kvs = %+v, want foo=bar", kvs)
+	}
+}
+
+// TestProcessSnapshotWithoutDB ensures that a snapshot that comes without
+// its database snapshot is rejected rather than stepped into raft, which
+// would fail to recover the v3 storage when applying it.
+func TestProcessSnapshotWithoutDB(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "etcdserver_v3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	n := &nodeRecorder{}
+	srv := &EtcdServer{
+		cluster:     newTestCluster(nil),
+		r:           raftNode{Node: n},
+		snapshotter: snap.New(dir),
+	}
+	srv.be = backend.NewDefaultBackend(path.Join(dir, "db"))
+	srv.kv = dstorage.NewConsistentWatchable(srv.be, nil, &srv.consistIndex)
+	defer srv.kv.Close()
+
+	m := raftpb.Message{Type: raftpb.MsgSnap, From: 2, Snapshot: raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 10}}}
+	if err := srv.Process(context.TODO(), m); err == nil {
+		t.Errorf("err = nil, want not nil")
+	}
+	if len(n.Action()) != 0 {
+		t.Errorf("action = %v, want none", n.Action())
+	}
+
+	if _, err := srv.snapshotter.SaveDBFrom(bytes.NewReader([]byte("db")), 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.Process(context.TODO(), m); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+	if len(n.Action()) != 1 {
+		t.Errorf("len(action) = %d, want 1", len(n.Action()))
+	}
+}
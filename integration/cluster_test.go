@@ -235,6 +235,37 @@ func TestAddMemberAfterClusterFullRotation(t *testing.T) {
 	clusterMustProgress(t, c.Members)
 }
 
+// TestReplaceDeadMember ensures that a dead member can be replaced with a new
+// member in a single step.
+func TestReplaceDeadMember(t *testing.T) {
+	defer afterTest(t)
+	c := NewCluster(t, 3)
+	c.Launch(t)
+	defer c.Terminate(t)
+
+	dead := c.Members[2]
+	id := dead.s.ID()
+	dead.Terminate(t)
+	c.Members = c.Members[:2]
+
+	m := mustNewMember(t, c.name(rand.Int()), false)
+	cc := mustNewHTTPClient(t, []string{c.URL(0)})
+	ma := client.NewMembersAPI(cc)
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	peerURL := "http://" + m.PeerListeners[0].Addr().String()
+	if _, err := ma.Replace(ctx, id.String(), peerURL); err != nil {
+		t.Fatalf("replace member on %s error: %v", c.URL(0), err)
+	}
+	cancel()
+
+	// the dead member is removed once the cluster leaves the joint consensus.
+	members := append(c.HTTPMembers(), client.Member{PeerURLs: []string{peerURL}, ClientURLs: []string{}})
+	c.waitMembersMatch(t, members)
+	c.launchNewMember(t, m)
+	c.waitLeader(t, c.Members)
+	clusterMustProgress(t, c.Members)
+}
+
 // Ensure we can remove a member then add a new one back immediately.
 func TestIssue2681(t *testing.T) {
 	defer afterTest(t)
@@ -479,7 +510,12 @@ func (c *cluster) addMember(t *testing.T, usePeerTLS bool) {
 	// wait for the add node entry applied in the cluster
 	members := append(c.HTTPMembers(), client.Member{PeerURLs: []string{peerURL}, ClientURLs: []string{}})
 	c.waitMembersMatch(t, members)
+	c.launchNewMember(t, m)
+}
 
+// launchNewMember launches the given member, which has been added to the
+// cluster, to join the existing cluster.
+func (c *cluster) launchNewMember(t *testing.T, m *member) {
 	m.InitialPeerURLsMap = types.URLsMap{}
 	for _, mm := range c.Members {
 		m.InitialPeerURLsMap[mm.Name] = mm.PeerURLs
@@ -736,7 +772,7 @@ func (m *member) Launch() error {
 	m.s.SyncTicker = time.Tick(500 * time.Millisecond)
 	m.s.Start()
 
-	m.raftHandler = &testutil.PauseableHandler{Next: etcdhttp.NewPeerHandler(m.s.Cluster(), m.s.RaftHandler())}
+	m.raftHandler = &testutil.PauseableHandler{Next: etcdhttp.NewPeerHandler(m.s.Cluster(), m.s, m.s.RaftHandler())}
 
 	for _, ln := range m.PeerListeners {
 		hs := &httptest.Server{
@@ -63,6 +63,60 @@ func TestRestartMember(t *testing.T) {
 	clusterMustProgress(t, c.Members)
 }
 
+func TestMoveLeader(t *testing.T) {
+	defer afterTest(t)
+	c := NewCluster(t, 3)
+	c.Launch(t)
+	defer c.Terminate(t)
+
+	c.waitLeader(t, c.Members)
+	lead := c.Members[0].s.Lead()
+	var target uint64
+	for _, m := range c.Members {
+		if id := uint64(m.s.ID()); id != lead {
+			target = id
+			break
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	err := c.Members[0].s.MoveLeader(ctx, target)
+	cancel()
+	if err != nil {
+		t.Fatalf("move leader error: %v", err)
+	}
+	c.waitLeader(t, c.Members)
+	if lead := c.Members[0].s.Lead(); lead != target {
+		t.Errorf("lead = %x, want %x", lead, target)
+	}
+	clusterMustProgress(t, c.Members)
+}
+
+func TestTransferLeadershipOnStop(t *testing.T) {
+	defer afterTest(t)
+	c := NewCluster(t, 3)
+	c.Launch(t)
+	defer c.Terminate(t)
+
+	c.waitLeader(t, c.Members)
+	var li int
+	for i, m := range c.Members {
+		if uint64(m.s.ID()) == m.s.Lead() {
+			li = i
+		}
+	}
+	lm := c.Members[li]
+	lm.Stop(t)
+	if lead := lm.s.Lead(); lead == uint64(lm.s.ID()) || lead == 0 {
+		t.Errorf("lead = %x after stop, want another member", lead)
+	}
+
+	membs := append([]*member{}, c.Members[:li]...)
+	membs = append(membs, c.Members[li+1:]...)
+	c.waitLeader(t, membs)
+	clusterMustProgress(t, membs)
+}
+
 func TestLaunchDuplicateMemberShouldFail(t *testing.T) {
 	size := 3
 	c := NewCluster(t, size)
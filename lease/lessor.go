@@ -0,0 +1,424 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lease provides leases with time-to-live for the keys of the v3
+// storage.
+package lease
+
+import (
+	"encoding/binary"
+	"errors"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/lease/leasepb"
+	"github.com/coreos/etcd/storage/backend"
+)
+
+// NoLease is a special LeaseID representing the absence of a lease.
+const NoLease = LeaseID(0)
+
+var (
+	leaseBucketName = []byte("lease")
+
+	// expiredCheckInterval is the interval to check the expiry of the
+	// leases on the primary lessor.
+	expiredCheckInterval = 500 * time.Millisecond
+
+	// forever is the expiry of a lease that is not managed by the
+	// primary lessor.
+	forever = time.Unix(math.MaxInt64>>1, 0)
+
+	ErrNotPrimary    = errors.New("lease: not a primary lessor")
+	ErrLeaseNotFound = errors.New("lease: lease not found")
+	ErrLeaseExists   = errors.New("lease: lease already exists")
+)
+
+type LeaseID int64
+
+// RangeDeleter deletes the keys attached to a revoked lease. All the keys
+// of the lease are deleted in one txn, so they are deleted at the same
+// revision.
+type RangeDeleter interface {
+	TxnBegin() int64
+	TxnDeleteRange(txnID int64, key, end []byte) (n, rev int64, err error)
+	TxnEnd(txnID int64) error
+}
+
+// A Lessor is the owner of leases. It can grant, revoke, renew and
+// attach keys to leases.
+//
+// All the lessors of a cluster apply the same grant, revoke and attach
+// operations in the same order. Only the primary lessor, which is the
+// lessor of the leader, tracks the expiry of the leases and renews them.
+type Lessor interface {
+	// SetRangeDeleter sets the RangeDeleter to the Lessor. The Lessor
+	// deletes the keys attached to a revoked lease through it.
+	SetRangeDeleter(rd RangeDeleter)
+
+	// Grant grants a lease with the given ID that expires after ttl
+	// seconds without renewal.
+	Grant(id LeaseID, ttl int64) (*Lease, error)
+
+	// Revoke revokes the lease with the given ID, and deletes all the
+	// keys attached to it.
+	Revoke(id LeaseID) error
+
+	// Attach attaches the given key to the lease with the given ID.
+	Attach(id LeaseID, key []byte) error
+
+	// Detach detaches the given key from the lease with the given ID.
+	Detach(id LeaseID, key []byte) error
+
+	// Promote promotes the lessor to be the primary lessor. The primary
+	// lessor manages the expiry of the leases. All the leases are
+	// refreshed when the lessor is promoted, so that a lease never
+	// expires because of a leader change.
+	Promote()
+
+	// Demote demotes the lessor from being the primary lessor.
+	Demote()
+
+	// Renew renews the lease with the given ID. It returns the TTL of
+	// the lease. Only the primary lessor can renew leases.
+	Renew(id LeaseID) (int64, error)
+
+	// Lookup returns the lease with the given ID. It returns nil if the
+	// lease does not exist.
+	Lookup(id LeaseID) *Lease
+
+	// ExpiredLeasesC returns a channel that receives the expired leases.
+	// The expired leases are not revoked by the lessor; the receiver is
+	// expected to revoke them through consensus.
+	ExpiredLeasesC() <-chan []*Lease
+
+	// Recover recovers the leases from the backend, dropping the leases
+	// the lessor holds. It is called after the backend is replaced by a
+	// snapshot. The keys are attached again when the kv storage is
+	// restored.
+	Recover()
+
+	// Stop stops the lessor from checking the expiry of the leases.
+	Stop()
+}
+
+// lessor implements Lessor interface.
+type lessor struct {
+	mu sync.Mutex
+
+	// primary indicates if this lessor is the primary lessor.
+	primary bool
+
+	leaseMap map[LeaseID]*Lease
+
+	// rd deletes the keys attached to the revoked leases.
+	rd RangeDeleter
+
+	// b persists the leases. A lessor recovers its leases from b when
+	// it is created.
+	b backend.Backend
+
+	expiredC chan []*Lease
+	stopC    chan struct{}
+	doneC    chan struct{}
+}
+
+func NewLessor(b backend.Backend) Lessor {
+	le := newLessor(b)
+	go le.runLoop()
+	return le
+}
+
+func newLessor(b backend.Backend) *lessor {
+	le := &lessor{
+		leaseMap: make(map[LeaseID]*Lease),
+		b:        b,
+		expiredC: make(chan []*Lease, 16),
+		stopC:    make(chan struct{}),
+		doneC:    make(chan struct{}),
+	}
+	le.initAndRecover()
+	return le
+}
+
+func (le *lessor) SetRangeDeleter(rd RangeDeleter) {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+	le.rd = rd
+}
+
+func (le *lessor) Grant(id LeaseID, ttl int64) (*Lease, error) {
+	if id == NoLease {
+		return nil, ErrLeaseNotFound
+	}
+
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	if _, ok := le.leaseMap[id]; ok {
+		return nil, ErrLeaseExists
+	}
+
+	l := &Lease{ID: id, TTL: ttl, itemSet: make(map[string]struct{})}
+	if le.primary {
+		l.refresh()
+	} else {
+		l.forever()
+	}
+	le.leaseMap[id] = l
+	l.persistTo(le.b)
+	return l, nil
+}
+
+func (le *lessor) Revoke(id LeaseID) error {
+	le.mu.Lock()
+	l := le.leaseMap[id]
+	if l == nil {
+		le.mu.Unlock()
+		return ErrLeaseNotFound
+	}
+	keys := l.keys()
+	rd := le.rd
+	// the lock is released before deleting the keys, since the deletion
+	// detaches the keys from the lease.
+	le.mu.Unlock()
+
+	if rd == nil {
+		le.remove(l)
+		return nil
+	}
+
+	tid := rd.TxnBegin()
+	for _, key := range keys {
+		if _, _, err := rd.TxnDeleteRange(tid, []byte(key), nil); err != nil {
+			log.Panicf("lease: unexpected error when deleting key %q of lease %x: %v", key, id, err)
+		}
+	}
+	// the lease is removed from the backend in the same txn that deletes
+	// its keys.
+	le.remove(l)
+	return rd.TxnEnd(tid)
+}
+
+func (le *lessor) Attach(id LeaseID, key []byte) error {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	l := le.leaseMap[id]
+	if l == nil {
+		return ErrLeaseNotFound
+	}
+	l.itemSet[string(key)] = struct{}{}
+	return nil
+}
+
+func (le *lessor) Detach(id LeaseID, key []byte) error {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	l := le.leaseMap[id]
+	if l == nil {
+		return ErrLeaseNotFound
+	}
+	delete(l.itemSet, string(key))
+	return nil
+}
+
+func (le *lessor) Promote() {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	le.primary = true
+	for _, l := range le.leaseMap {
+		l.refresh()
+	}
+}
+
+func (le *lessor) Demote() {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	le.primary = false
+	for _, l := range le.leaseMap {
+		l.forever()
+	}
+}
+
+func (le *lessor) Recover() {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	le.leaseMap = make(map[LeaseID]*Lease)
+	le.initAndRecover()
+	if le.primary {
+		for _, l := range le.leaseMap {
+			l.refresh()
+		}
+	}
+}
+
+func (le *lessor) Renew(id LeaseID) (int64, error) {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	if !le.primary {
+		return -1, ErrNotPrimary
+	}
+	l := le.leaseMap[id]
+	if l == nil {
+		return -1, ErrLeaseNotFound
+	}
+	l.refresh()
+	return l.TTL, nil
+}
+
+func (le *lessor) Lookup(id LeaseID) *Lease {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+	return le.leaseMap[id]
+}
+
+func (le *lessor) ExpiredLeasesC() <-chan []*Lease {
+	return le.expiredC
+}
+
+func (le *lessor) Stop() {
+	close(le.stopC)
+	<-le.doneC
+}
+
+func (le *lessor) runLoop() {
+	defer close(le.doneC)
+
+	for {
+		if ls := le.findExpiredLeases(); len(ls) != 0 {
+			select {
+			case le.expiredC <- ls:
+			default:
+				// the receiver is busy; the expired leases are found
+				// again in the next round.
+			}
+		}
+
+		select {
+		case <-time.After(expiredCheckInterval):
+		case <-le.stopC:
+			return
+		}
+	}
+}
+
+// findExpiredLeases returns the expired leases if the lessor is the
+// primary lessor.
+func (le *lessor) findExpiredLeases() []*Lease {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	if !le.primary {
+		return nil
+	}
+	var ls []*Lease
+	now := time.Now()
+	for _, l := range le.leaseMap {
+		if l.expiry.Before(now) {
+			ls = append(ls, l)
+		}
+	}
+	return ls
+}
+
+// remove removes the given lease from the lessor and the backend.
+func (le *lessor) remove(l *Lease) {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+	delete(le.leaseMap, l.ID)
+	l.removeFrom(le.b)
+}
+
+func (le *lessor) initAndRecover() {
+	tx := le.b.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+
+	tx.UnsafeCreateBucket(leaseBucketName)
+	_, vs := tx.UnsafeRange(leaseBucketName, int64ToBytes(0), int64ToBytes(math.MaxInt64), 0)
+	for i := range vs {
+		var lpb leasepb.Lease
+		if err := lpb.Unmarshal(vs[i]); err != nil {
+			log.Panicf("lease: cannot unmarshal lease: %v", err)
+		}
+		id := LeaseID(lpb.ID)
+		le.leaseMap[id] = &Lease{
+			ID:  id,
+			TTL: lpb.TTL,
+			// the keys are attached when the kv storage is restored
+			itemSet: make(map[string]struct{}),
+			expiry:  forever,
+		}
+	}
+}
+
+type Lease struct {
+	ID  LeaseID
+	TTL int64 // time to live in seconds
+
+	itemSet map[string]struct{}
+	expiry  time.Time
+}
+
+func (l *Lease) persistTo(b backend.Backend) {
+	lpb := leasepb.Lease{ID: int64(l.ID), TTL: l.TTL}
+	val, err := lpb.Marshal()
+	if err != nil {
+		log.Panicf("lease: cannot marshal lease: %v", err)
+	}
+
+	tx := b.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+	tx.UnsafePut(leaseBucketName, int64ToBytes(int64(l.ID)), val)
+}
+
+func (l *Lease) removeFrom(b backend.Backend) {
+	tx := b.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+	tx.UnsafeDelete(leaseBucketName, int64ToBytes(int64(l.ID)))
+}
+
+// refresh refreshes the expiry of the lease.
+func (l *Lease) refresh() {
+	l.expiry = time.Now().Add(time.Duration(l.TTL) * time.Second)
+}
+
+// forever sets the expiry of the lease to be forever.
+func (l *Lease) forever() {
+	l.expiry = forever
+}
+
+func (l *Lease) keys() []string {
+	keys := make([]string, 0, len(l.itemSet))
+	for k := range l.itemSet {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func int64ToBytes(n int64) []byte {
+	bytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(bytes, uint64(n))
+	return bytes
+}
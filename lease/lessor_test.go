@@ -0,0 +1,239 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lease
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/storage/backend"
+)
+
+// TestLessorGrant ensures Lessor can grant leases, and a granted lease
+// is managed by the primary lessor only.
+func TestLessorGrant(t *testing.T) {
+	dir, be := newTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(be)
+
+	l, err := le.Grant(1, 100)
+	if err != nil {
+		t.Fatalf("could not grant lease 1 (%v)", err)
+	}
+	if !l.expiry.Equal(forever) {
+		t.Errorf("expiry = %v, want forever on a non-primary lessor", l.expiry)
+	}
+	if _, err := le.Grant(1, 100); err != ErrLeaseExists {
+		t.Errorf("err = %v, want %v", err, ErrLeaseExists)
+	}
+	if _, err := le.Grant(NoLease, 100); err != ErrLeaseNotFound {
+		t.Errorf("err = %v, want %v", err, ErrLeaseNotFound)
+	}
+
+	le.Promote()
+	nl, err := le.Grant(2, 100)
+	if err != nil {
+		t.Fatalf("could not grant lease 2 (%v)", err)
+	}
+	wexpiry := time.Now().Add(100 * time.Second)
+	if nl.expiry.Sub(wexpiry) > time.Second || wexpiry.Sub(nl.expiry) > time.Second {
+		t.Errorf("expiry = %v, want about %v", nl.expiry, wexpiry)
+	}
+	if le.Lookup(1).expiry.Equal(forever) {
+		t.Errorf("lease 1 is not refreshed after promotion")
+	}
+}
+
+// TestLessorRevoke ensures Lessor can revoke a lease, and deletes all the
+// keys attached to the lease in one txn.
+func TestLessorRevoke(t *testing.T) {
+	dir, be := newTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	fd := &fakeDeleter{}
+	le := newLessor(be)
+	le.SetRangeDeleter(fd)
+
+	if _, err := le.Grant(1, 100); err != nil {
+		t.Fatalf("could not grant lease 1 (%v)", err)
+	}
+	for _, key := range []string{"foo", "bar"} {
+		if err := le.Attach(1, []byte(key)); err != nil {
+			t.Fatalf("could not attach key %s (%v)", key, err)
+		}
+	}
+	if err := le.Attach(2, []byte("foo")); err != ErrLeaseNotFound {
+		t.Errorf("err = %v, want %v", err, ErrLeaseNotFound)
+	}
+
+	if err := le.Revoke(1); err != nil {
+		t.Fatalf("could not revoke lease 1 (%v)", err)
+	}
+	if le.Lookup(1) != nil {
+		t.Errorf("lease 1 still exists after revoke")
+	}
+	sort.Strings(fd.deleted)
+	wdeleted := []string{"bar", "foo"}
+	if !reflect.DeepEqual(fd.deleted, wdeleted) {
+		t.Errorf("deleted = %v, want %v", fd.deleted, wdeleted)
+	}
+	if fd.txns != 1 {
+		t.Errorf("txns = %d, want 1", fd.txns)
+	}
+	if err := le.Revoke(1); err != ErrLeaseNotFound {
+		t.Errorf("err = %v, want %v", err, ErrLeaseNotFound)
+	}
+}
+
+// TestLessorRenew ensures only the primary lessor can renew leases.
+func TestLessorRenew(t *testing.T) {
+	dir, be := newTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(be)
+	if _, err := le.Grant(1, 100); err != nil {
+		t.Fatalf("could not grant lease 1 (%v)", err)
+	}
+	if _, err := le.Renew(1); err != ErrNotPrimary {
+		t.Errorf("err = %v, want %v", err, ErrNotPrimary)
+	}
+
+	le.Promote()
+	ttl, err := le.Renew(1)
+	if err != nil {
+		t.Fatalf("could not renew lease 1 (%v)", err)
+	}
+	if ttl != 100 {
+		t.Errorf("ttl = %d, want 100", ttl)
+	}
+	if _, err := le.Renew(2); err != ErrLeaseNotFound {
+		t.Errorf("err = %v, want %v", err, ErrLeaseNotFound)
+	}
+
+	le.Demote()
+	if !le.Lookup(1).expiry.Equal(forever) {
+		t.Errorf("lease 1 is managed after demotion")
+	}
+}
+
+// TestLessorExpire ensures the primary lessor reports the expired leases.
+func TestLessorExpire(t *testing.T) {
+	dir, be := newTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := NewLessor(be)
+	defer le.Stop()
+
+	le.Promote()
+	if _, err := le.Grant(1, 1); err != nil {
+		t.Fatalf("could not grant lease 1 (%v)", err)
+	}
+
+	select {
+	case ls := <-le.ExpiredLeasesC():
+		if len(ls) != 1 || ls[0].ID != 1 {
+			t.Errorf("expired leases = %v, want lease 1", ls)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("failed to receive expired lease")
+	}
+}
+
+// TestLessorRecover ensures Lessor recovers the granted leases from the
+// backend.
+func TestLessorRecover(t *testing.T) {
+	dir, be := newTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(be)
+	le.Grant(1, 10)
+	le.Grant(2, 20)
+	le.Grant(3, 30)
+	le.Revoke(2)
+
+	nle := newLessor(be)
+	for i, id := range []LeaseID{1, 3} {
+		l := nle.Lookup(id)
+		if l == nil {
+			t.Fatalf("#%d: lease %d is not recovered", i, id)
+		}
+		if l.TTL != int64(id)*10 {
+			t.Errorf("#%d: ttl = %d, want %d", i, l.TTL, int64(id)*10)
+		}
+	}
+	if nle.Lookup(2) != nil {
+		t.Errorf("revoked lease 2 is recovered")
+	}
+}
+
+// TestLessorRecoverFromBackend ensures a running Lessor replaces its leases
+// with the leases in the backend on recovery.
+func TestLessorRecoverFromBackend(t *testing.T) {
+	dir, be := newTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(be)
+	le.Grant(1, 10)
+
+	// another lessor changes the leases in the backend
+	ole := newLessor(be)
+	ole.Revoke(1)
+	ole.Grant(2, 20)
+
+	le.Recover()
+	if le.Lookup(1) != nil {
+		t.Errorf("lease 1 is kept after recovery")
+	}
+	if l := le.Lookup(2); l == nil || l.TTL != 20 {
+		t.Errorf("lease 2 = %+v, want recovered with ttl 20", l)
+	}
+}
+
+type fakeDeleter struct {
+	deleted []string
+	txns    int
+}
+
+func (fd *fakeDeleter) TxnBegin() int64 {
+	fd.txns++
+	return 1
+}
+
+func (fd *fakeDeleter) TxnDeleteRange(txnID int64, key, end []byte) (n, rev int64, err error) {
+	fd.deleted = append(fd.deleted, string(key))
+	return 1, 1, nil
+}
+
+func (fd *fakeDeleter) TxnEnd(txnID int64) error { return nil }
+
+func newTestBackend(t *testing.T) (string, backend.Backend) {
+	dir, err := ioutil.TempDir(os.TempDir(), "lease")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, backend.NewDefaultBackend(path.Join(dir, "db"))
+}
@@ -33,12 +33,12 @@ func NewKeepAliveListener(addr string, scheme string, info TLSInfo) (net.Listene
 		if info.Empty() {
 			return nil, fmt.Errorf("cannot listen on TLS for %s: KeyFile and CertFile are not presented", scheme+"://"+addr)
 		}
-		cfg, err := info.ServerConfig()
+		config, err := info.serverConfigFunc()
 		if err != nil {
 			return nil, err
 		}
 
-		return newTLSKeepaliveListener(l, cfg), nil
+		return newTLSKeepaliveListener(l, config), nil
 	}
 
 	return &keepaliveListener{
@@ -65,7 +65,7 @@ func (kln *keepaliveListener) Accept() (net.Conn, error) {
 // A tlsKeepaliveListener implements a network listener (net.Listener) for TLS connections.
 type tlsKeepaliveListener struct {
 	net.Listener
-	config *tls.Config
+	config func() *tls.Config
 }
 
 // Accept waits for and returns the next incoming TLS connection.
@@ -81,15 +81,15 @@ func (l *tlsKeepaliveListener) Accept() (c net.Conn, err error) {
 	// default on osx:    30 + 8 * 75
 	tcpc.SetKeepAlive(true)
 	tcpc.SetKeepAlivePeriod(30 * time.Second)
-	c = tls.Server(c, l.config)
+	c = tls.Server(c, l.config())
 	return
 }
 
 // NewListener creates a Listener which accepts connections from an inner
 // Listener and wraps each connection with Server.
-// The configuration config must be non-nil and must have
+// The configuration returned by config must be non-nil and must have
 // at least one certificate.
-func newTLSKeepaliveListener(inner net.Listener, config *tls.Config) net.Listener {
+func newTLSKeepaliveListener(inner net.Listener, config func() *tls.Config) net.Listener {
 	l := &tlsKeepaliveListener{}
 	l.Listener = inner
 	l.config = config
@@ -35,23 +35,18 @@ func NewListener(addr string, scheme string, info TLSInfo) (net.Listener, error)
 		if info.Empty() {
 			return nil, fmt.Errorf("cannot listen on TLS for %s: KeyFile and CertFile are not presented", scheme+"://"+addr)
 		}
-		cfg, err := info.ServerConfig()
+		config, err := info.serverConfigFunc()
 		if err != nil {
 			return nil, err
 		}
 
-		l = tls.NewListener(l, cfg)
+		l = &tlsListener{Listener: l, config: config}
 	}
 
 	return l, nil
 }
 
 func NewTransport(info TLSInfo) (*http.Transport, error) {
-	cfg, err := info.ClientConfig()
-	if err != nil {
-		return nil, err
-	}
-
 	t := &http.Transport{
 		// timeouts taken from http.DefaultTransport
 		Dial: (&net.Dialer{
@@ -59,9 +54,21 @@ func NewTransport(info TLSInfo) (*http.Transport, error) {
 			KeepAlive: 30 * time.Second,
 		}).Dial,
 		TLSHandshakeTimeout: 10 * time.Second,
-		TLSClientConfig:     cfg,
 	}
 
+	if info.Reloader != nil {
+		// DialTLS does the handshake with the latest client config
+		// instead of TLSClientConfig.
+		t.DialTLS = tlsDialFunc(t.Dial, info.Reloader.ClientConfig, t.TLSHandshakeTimeout)
+		return t, nil
+	}
+
+	cfg, err := info.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	t.TLSClientConfig = cfg
+
 	return t, nil
 }
 
@@ -72,6 +79,11 @@ type TLSInfo struct {
 	TrustedCAFile  string
 	ClientCertAuth bool
 
+	// Reloader, if not nil, provides the TLS configs to the listeners
+	// and transports created from the TLS info instead of the files
+	// above, which makes them pick up the changed files.
+	Reloader *TLSReloader
+
 	// parseFunc exists to simplify testing. Typically, parseFunc
 	// should be left nil. In that case, tls.X509KeyPair will be used.
 	parseFunc func([]byte, []byte) (tls.Certificate, error)
@@ -153,6 +165,19 @@ func (info TLSInfo) ServerConfig() (*tls.Config, error) {
 	return cfg, nil
 }
 
+// serverConfigFunc returns a func that returns the server TLS config of
+// each new connection.
+func (info TLSInfo) serverConfigFunc() (func() *tls.Config, error) {
+	if info.Reloader != nil {
+		return info.Reloader.ServerConfig, nil
+	}
+	cfg, err := info.ServerConfig()
+	if err != nil {
+		return nil, err
+	}
+	return func() *tls.Config { return cfg }, nil
+}
+
 // ClientConfig generates a tls.Config object for use by an HTTP client.
 func (info TLSInfo) ClientConfig() (*tls.Config, error) {
 	var cfg *tls.Config
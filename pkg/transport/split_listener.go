@@ -0,0 +1,147 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// http2Preface is the client connection preface of HTTP/2, which is
+// sent by gRPC clients as the first bytes of a connection.
+var http2Preface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+// prefaceReadTimeout is the timeout to read the first bytes of an
+// accepted connection.
+const prefaceReadTimeout = 5 * time.Second
+
+var errListenerClosed = errors.New("transport: listener closed")
+
+// SplitHTTP2Listener splits the connections accepted by l by their
+// protocol. Connections that start with the HTTP/2 client preface are
+// returned by h2l; all the other connections are returned by hl.
+// Closing either of the returned listeners closes l.
+func SplitHTTP2Listener(l net.Listener) (h2l, hl net.Listener) {
+	sl := &splitListener{
+		Listener: l,
+		h2c:      make(chan net.Conn),
+		hc:       make(chan net.Conn),
+		donec:    make(chan struct{}),
+	}
+	go sl.serve()
+	return &splitChildListener{sl, sl.h2c}, &splitChildListener{sl, sl.hc}
+}
+
+type splitListener struct {
+	net.Listener
+
+	h2c chan net.Conn
+	hc  chan net.Conn
+
+	mu    sync.Mutex
+	err   error
+	donec chan struct{}
+}
+
+func (sl *splitListener) serve() {
+	for {
+		c, err := sl.Listener.Accept()
+		if err != nil {
+			sl.close(err)
+			return
+		}
+		go sl.dispatch(c)
+	}
+}
+
+// dispatch reads the first bytes of c until it knows whether c is
+// an HTTP/2 connection, and then hands c to the matching listener.
+func (sl *splitListener) dispatch(c net.Conn) {
+	c.SetReadDeadline(time.Now().Add(prefaceReadTimeout))
+	buf := make([]byte, len(http2Preface))
+	n := 0
+	for n < len(buf) && bytes.Equal(buf[:n], http2Preface[:n]) {
+		m, err := c.Read(buf[n:])
+		n += m
+		if err != nil {
+			if n == 0 {
+				c.Close()
+				return
+			}
+			break
+		}
+	}
+	c.SetReadDeadline(time.Time{})
+
+	ch := sl.hc
+	if bytes.Equal(buf[:n], http2Preface) {
+		ch = sl.h2c
+	}
+	select {
+	case ch <- &prefixConn{Conn: c, prefix: buf[:n]}:
+	case <-sl.donec:
+		c.Close()
+	}
+}
+
+func (sl *splitListener) close(err error) error {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	if sl.err != nil {
+		return nil
+	}
+	sl.err = err
+	close(sl.donec)
+	return sl.Listener.Close()
+}
+
+type splitChildListener struct {
+	*splitListener
+	connc chan net.Conn
+}
+
+func (l *splitChildListener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.connc:
+		return c, nil
+	case <-l.donec:
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return nil, l.err
+	}
+}
+
+func (l *splitChildListener) Close() error {
+	return l.close(errListenerClosed)
+}
+
+// prefixConn is a net.Conn that returns the given prefix before
+// reading from the underlying connection.
+type prefixConn struct {
+	net.Conn
+	prefix []byte
+}
+
+func (c *prefixConn) Read(b []byte) (int, error) {
+	if len(c.prefix) != 0 {
+		n := copy(b, c.prefix)
+		c.prefix = c.prefix[n:]
+		return n, nil
+	}
+	return c.Conn.Read(b)
+}
@@ -0,0 +1,72 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"io/ioutil"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestSplitHTTP2Listener(t *testing.T) {
+	tests := []struct {
+		data []byte
+		wh2  bool
+	}{
+		{[]byte("GET /v2/keys/foo HTTP/1.1\r\nHost: localhost\r\n\r\n"), false},
+		{[]byte("POST /v2/keys/foo HTTP/1.1\r\n\r\n"), false},
+		{[]byte("PRI * HTTP/1.1\r\n\r\n"), false},
+		{append([]byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"), "frames"...), true},
+	}
+	for i, tt := range tests {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("#%d: unexpected listen error: %v", i, err)
+		}
+		h2l, hl := SplitHTTP2Listener(ln)
+
+		go func() {
+			c, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				return
+			}
+			c.Write(tt.data)
+			c.Close()
+		}()
+
+		l := hl
+		if tt.wh2 {
+			l = h2l
+		}
+		c, err := l.Accept()
+		if err != nil {
+			t.Fatalf("#%d: unexpected accept error: %v", i, err)
+		}
+		b, err := ioutil.ReadAll(c)
+		if err != nil {
+			t.Fatalf("#%d: unexpected read error: %v", i, err)
+		}
+		if !reflect.DeepEqual(b, tt.data) {
+			t.Errorf("#%d: data = %q, want %q", i, b, tt.data)
+		}
+		c.Close()
+
+		hl.Close()
+		if _, err := h2l.Accept(); err == nil {
+			t.Errorf("#%d: accept error = nil, want closed error", i)
+		}
+	}
+}
@@ -39,5 +39,8 @@ func NewTimeoutTransport(info TLSInfo, dialtimeoutd, rdtimeoutd, wtimeoutd time.
 		rdtimeoutd: rdtimeoutd,
 		wtimeoutd:  wtimeoutd,
 	}).Dial
+	if info.Reloader != nil {
+		tr.DialTLS = tlsDialFunc(tr.Dial, info.Reloader.ClientConfig, tr.TLSHandshakeTimeout)
+	}
 	return tr, nil
 }
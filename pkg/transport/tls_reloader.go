@@ -0,0 +1,222 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/coreos/pkg/capnslog"
+)
+
+var plog = capnslog.NewPackageLogger("github.com/coreos/etcd/pkg", "transport")
+
+// TLSReloader builds the TLS configs of a TLSInfo, and rebuilds them when
+// the certificate, key or CA files change. The listeners and transports
+// created from a TLSInfo with the Reloader use the latest configs for each
+// new connection, so the certificates can be rotated without a restart.
+// The established connections keep the configs they were created with.
+type TLSReloader struct {
+	// name is the name of the TLS info in the logs and metrics,
+	// e.g. "client" or "peer".
+	name string
+	info TLSInfo
+
+	mu     sync.RWMutex
+	server *tls.Config
+	client *tls.Config
+	// modTimes are the modification times of the files that the
+	// configs are built from.
+	modTimes map[string]time.Time
+}
+
+// NewTLSReloader creates a TLSReloader of the given TLS info. It returns
+// an error if the TLS configs cannot be built from the files.
+func NewTLSReloader(name string, info TLSInfo) (*TLSReloader, error) {
+	info.Reloader = nil
+	r := &TLSReloader{name: name, info: info}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload rebuilds the TLS configs from the files. If the files are not
+// valid, e.g., the certificate has been replaced but the key has not yet,
+// it returns the error and keeps the previous configs.
+func (r *TLSReloader) Reload() error {
+	// stat the files before reading them, so a change in between is
+	// picked up by the next check.
+	modTimes := r.stat()
+
+	var server *tls.Config
+	if !r.info.Empty() {
+		var err error
+		if server, err = r.info.ServerConfig(); err != nil {
+			return err
+		}
+	}
+	client, err := r.info.ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.server, r.client, r.modTimes = server, client, modTimes
+	r.mu.Unlock()
+
+	r.reportExpiry(client.Certificates)
+	return nil
+}
+
+// Watch checks the files every interval, and reloads the TLS configs when
+// any of them changes. It returns when stopc is closed.
+func (r *TLSReloader) Watch(interval time.Duration, stopc <-chan struct{}) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				plog.Warningf("failed to reload %s TLS files (%v)", r.name, err)
+			}
+		case <-stopc:
+			return
+		}
+	}
+}
+
+// ServerConfig returns the latest server TLS config. It returns nil if
+// the TLS info has no certificate and key.
+func (r *TLSReloader) ServerConfig() *tls.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.server
+}
+
+// ClientConfig returns the latest client TLS config.
+func (r *TLSReloader) ClientConfig() *tls.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.client
+}
+
+func (r *TLSReloader) files() []string {
+	fs := r.info.cafiles()
+	if r.info.CertFile != "" {
+		fs = append(fs, r.info.CertFile)
+	}
+	if r.info.KeyFile != "" {
+		fs = append(fs, r.info.KeyFile)
+	}
+	return fs
+}
+
+// stat returns the modification times of the files. A file that cannot
+// be stat'ed, e.g., it is being replaced, has the zero time.
+func (r *TLSReloader) stat() map[string]time.Time {
+	ts := make(map[string]time.Time)
+	for _, f := range r.files() {
+		var t time.Time
+		if fi, err := os.Stat(f); err == nil {
+			t = fi.ModTime()
+		}
+		ts[f] = t
+	}
+	return ts
+}
+
+// changed returns true if any of the files changed since the configs
+// were built.
+func (r *TLSReloader) changed() bool {
+	ts := r.stat()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for f, t := range ts {
+		if !t.Equal(r.modTimes[f]) {
+			return true
+		}
+	}
+	return false
+}
+
+// reportExpiry logs and exports the expiry of the active certificate.
+func (r *TLSReloader) reportExpiry(certs []tls.Certificate) {
+	if len(certs) == 0 || len(certs[0].Certificate) == 0 {
+		return
+	}
+	leaf, err := x509.ParseCertificate(certs[0].Certificate[0])
+	if err != nil {
+		plog.Warningf("failed to parse %s TLS certificate %s (%v)", r.name, r.info.CertFile, err)
+		return
+	}
+	certExpiry.WithLabelValues(r.name).Set(float64(leaf.NotAfter.Unix()))
+	if time.Now().After(leaf.NotAfter) {
+		plog.Warningf("loaded %s TLS certificate %s, which expired at %v", r.name, r.info.CertFile, leaf.NotAfter)
+		return
+	}
+	plog.Infof("loaded %s TLS certificate %s, which expires at %v", r.name, r.info.CertFile, leaf.NotAfter)
+}
+
+// tlsListener wraps each connection accepted by the inner listener with
+// a TLS server of the latest config.
+type tlsListener struct {
+	net.Listener
+	config func() *tls.Config
+}
+
+func (l *tlsListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return tls.Server(c, l.config()), nil
+}
+
+// tlsDialFunc returns a dial func that dials through dial, and then does
+// the TLS handshake of the latest config on the connection.
+func tlsDialFunc(dial func(network, addr string) (net.Conn, error), config func() *tls.Config, handshakeTimeout time.Duration) func(network, addr string) (net.Conn, error) {
+	return func(network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := dial(network, addr)
+		if err != nil {
+			return nil, err
+		}
+		cfg := config()
+		tc := tls.Client(conn, &tls.Config{
+			Certificates: cfg.Certificates,
+			RootCAs:      cfg.RootCAs,
+			MinVersion:   cfg.MinVersion,
+			ServerName:   host,
+		})
+		conn.SetDeadline(time.Now().Add(handshakeTimeout))
+		if err := tc.Handshake(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn.SetDeadline(time.Time{})
+		return tc, nil
+	}
+}
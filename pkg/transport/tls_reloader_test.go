@@ -0,0 +1,294 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	dto "github.com/coreos/etcd/Godeps/_workspace/src/github.com/prometheus/client_model/go"
+)
+
+// TestTLSReloaderReload tests that the listener created from a TLS info
+// with a reloader serves the certificate reloaded from the files.
+func TestTLSReloaderReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-test-tls-reloader-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	info := TLSInfo{CertFile: path.Join(dir, "server.crt"), KeyFile: path.Join(dir, "server.key")}
+
+	notAfter := writeTestCert(t, info.CertFile, info.KeyFile, "server1")
+	r, err := NewTLSReloader("test", info)
+	if err != nil {
+		t.Fatalf("unexpected NewTLSReloader error: %v", err)
+	}
+	if g := certExpiryValue(t, "test"); g != float64(notAfter.Unix()) {
+		t.Errorf("certificate expiry = %v, want %v", g, notAfter.Unix())
+	}
+	info.Reloader = r
+	ln, err := NewListener("127.0.0.1:0", "https", info)
+	if err != nil {
+		t.Fatalf("unexpected NewListener error: %v", err)
+	}
+	defer ln.Close()
+	go serveTLSHandshakes(ln)
+
+	if cn := dialServerCN(t, ln.Addr().String()); cn != "server1" {
+		t.Errorf("served CN = %s, want server1", cn)
+	}
+
+	notAfter = writeTestCert(t, info.CertFile, info.KeyFile, "server2")
+	if err := r.Reload(); err != nil {
+		t.Fatalf("unexpected Reload error: %v", err)
+	}
+	if cn := dialServerCN(t, ln.Addr().String()); cn != "server2" {
+		t.Errorf("served CN = %s, want server2", cn)
+	}
+	if g := certExpiryValue(t, "test"); g != float64(notAfter.Unix()) {
+		t.Errorf("certificate expiry = %v, want %v", g, notAfter.Unix())
+	}
+}
+
+// TestTLSReloaderReloadBadFiles tests that the reloader keeps the previous
+// configs if the files are not valid.
+func TestTLSReloaderReloadBadFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-test-tls-reloader-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	info := TLSInfo{CertFile: path.Join(dir, "server.crt"), KeyFile: path.Join(dir, "server.key")}
+	writeTestCert(t, info.CertFile, info.KeyFile, "server1")
+	r, err := NewTLSReloader("test", info)
+	if err != nil {
+		t.Fatalf("unexpected NewTLSReloader error: %v", err)
+	}
+	server, client := r.ServerConfig(), r.ClientConfig()
+
+	// the certificate is replaced but the key is not yet
+	writeTestCert(t, info.CertFile, path.Join(dir, "other.key"), "server2")
+	if err := r.Reload(); err == nil {
+		t.Errorf("err = nil, want not nil")
+	}
+	if r.ServerConfig() != server || r.ClientConfig() != client {
+		t.Errorf("configs are changed after failed reload")
+	}
+}
+
+// TestTLSReloaderWatch tests that the reloader reloads the configs when
+// the files change.
+func TestTLSReloaderWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-test-tls-reloader-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	info := TLSInfo{CertFile: path.Join(dir, "server.crt"), KeyFile: path.Join(dir, "server.key")}
+	writeTestCert(t, info.CertFile, info.KeyFile, "server1")
+	r, err := NewTLSReloader("test", info)
+	if err != nil {
+		t.Fatalf("unexpected NewTLSReloader error: %v", err)
+	}
+	stopc := make(chan struct{})
+	defer close(stopc)
+	go r.Watch(10*time.Millisecond, stopc)
+
+	time.Sleep(50 * time.Millisecond)
+	if cfg := r.ServerConfig(); certCN(t, cfg) != "server1" {
+		t.Fatalf("CN = %s, want server1", certCN(t, cfg))
+	}
+	writeTestCert(t, info.CertFile, info.KeyFile, "server2")
+	// make sure the modification time changes on file systems with
+	// coarse timestamps
+	future := time.Now().Add(time.Hour)
+	for _, f := range []string{info.CertFile, info.KeyFile} {
+		if err := os.Chtimes(f, future, future); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		if certCN(t, r.ServerConfig()) == "server2" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("CN = %s, want server2", certCN(t, r.ServerConfig()))
+}
+
+// TestNewTransportTLSReloader tests that the transport created from a TLS
+// info with a reloader trusts the CA reloaded from the files.
+func TestNewTransportTLSReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcd-test-tls-reloader-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sinfo := TLSInfo{CertFile: path.Join(dir, "server.crt"), KeyFile: path.Join(dir, "server.key")}
+	writeTestCert(t, sinfo.CertFile, sinfo.KeyFile, "server1")
+	sr, err := NewTLSReloader("server", sinfo)
+	if err != nil {
+		t.Fatalf("unexpected NewTLSReloader error: %v", err)
+	}
+	sinfo.Reloader = sr
+	ln, err := NewListener("127.0.0.1:0", "https", sinfo)
+	if err != nil {
+		t.Fatalf("unexpected NewListener error: %v", err)
+	}
+	defer ln.Close()
+	go http.Serve(ln, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// the self-signed server certificate is its own CA
+	cinfo := TLSInfo{TrustedCAFile: path.Join(dir, "ca.crt")}
+	copyFile(t, sinfo.CertFile, cinfo.TrustedCAFile)
+	cr, err := NewTLSReloader("client", cinfo)
+	if err != nil {
+		t.Fatalf("unexpected NewTLSReloader error: %v", err)
+	}
+	cinfo.Reloader = cr
+	tr, err := NewTimeoutTransport(cinfo, time.Second, time.Second, time.Second)
+	if err != nil {
+		t.Fatalf("unexpected NewTimeoutTransport error: %v", err)
+	}
+	tr.DisableKeepAlives = true
+	u := "https://" + ln.Addr().String()
+
+	if _, err := tr.RoundTrip(mustNewRequest(t, u)); err != nil {
+		t.Fatalf("unexpected RoundTrip error: %v", err)
+	}
+
+	writeTestCert(t, sinfo.CertFile, sinfo.KeyFile, "server2")
+	if err := sr.Reload(); err != nil {
+		t.Fatalf("unexpected Reload error: %v", err)
+	}
+	if _, err := tr.RoundTrip(mustNewRequest(t, u)); err == nil {
+		t.Errorf("err = nil, want error of untrusted certificate")
+	}
+
+	copyFile(t, sinfo.CertFile, cinfo.TrustedCAFile)
+	if err := cr.Reload(); err != nil {
+		t.Fatalf("unexpected Reload error: %v", err)
+	}
+	if _, err := tr.RoundTrip(mustNewRequest(t, u)); err != nil {
+		t.Errorf("unexpected RoundTrip error: %v", err)
+	}
+}
+
+// writeTestCert writes a self-signed certificate of 127.0.0.1 with the
+// given CN and its key, and returns the expiry of the certificate.
+func writeTestCert(t *testing.T, certFile, keyFile, cn string) time.Time {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notAfter := time.Now().Add(time.Hour).Truncate(time.Second)
+	tmpl := x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return notAfter
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	b, err := ioutil.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(dst, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustNewRequest(t *testing.T, u string) *http.Request {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func certCN(t *testing.T, cfg *tls.Config) string {
+	leaf, err := x509.ParseCertificate(cfg.Certificates[0].Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+// serveTLSHandshakes does the TLS handshake of each accepted connection,
+// and then closes it.
+func serveTLSHandshakes(ln net.Listener) {
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		c.(*tls.Conn).Handshake()
+		c.Close()
+	}
+}
+
+// dialServerCN returns the CN of the certificate served at addr.
+func dialServerCN(t *testing.T, addr string) string {
+	c, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("unexpected Dial error: %v", err)
+	}
+	defer c.Close()
+	return c.ConnectionState().PeerCertificates[0].Subject.CommonName
+}
+
+func certExpiryValue(t *testing.T, name string) float64 {
+	var m dto.Metric
+	if err := certExpiry.WithLabelValues(name).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetGauge().GetValue()
+}
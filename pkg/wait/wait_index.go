@@ -0,0 +1,70 @@
+/*
+   Copyright 2015 CoreOS, Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package wait
+
+import "sync"
+
+type WaitIndex interface {
+	// Wait returns a chan that waits on the given index.
+	// The chan will be triggered when Trigger is called with an
+	// index that is equal to or greater than the one it is waiting for.
+	// The returned chan is closed immediately if the given index has
+	// already been triggered.
+	Wait(index uint64) <-chan struct{}
+	// Trigger triggers all the waiting chans with an equal or smaller index.
+	Trigger(index uint64)
+}
+
+var closec chan struct{}
+
+func init() { closec = make(chan struct{}); close(closec) }
+
+type indexList struct {
+	l                  sync.Mutex
+	lastTriggeredIndex uint64
+	m                  map[uint64]chan struct{}
+}
+
+func NewIndexList() *indexList {
+	return &indexList{m: make(map[uint64]chan struct{})}
+}
+
+func (il *indexList) Wait(index uint64) <-chan struct{} {
+	il.l.Lock()
+	defer il.l.Unlock()
+	if il.lastTriggeredIndex >= index {
+		return closec
+	}
+	ch := il.m[index]
+	if ch == nil {
+		ch = make(chan struct{})
+		il.m[index] = ch
+	}
+	return ch
+}
+
+func (il *indexList) Trigger(index uint64) {
+	il.l.Lock()
+	defer il.l.Unlock()
+	il.lastTriggeredIndex = index
+	for i, ch := range il.m {
+		if i <= index {
+			delete(il.m, i)
+			close(ch)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+/*
+   Copyright 2015 CoreOS, Inc.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+package wait
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaitIndex(t *testing.T) {
+	wi := NewIndexList()
+	ch1 := wi.Wait(1)
+	ch2 := wi.Wait(2)
+	wi.Trigger(1)
+	select {
+	case <-ch1:
+	case <-time.After(10 * time.Millisecond):
+		t.Fatalf("cannot receive from ch1 as expected")
+	}
+	select {
+	case <-ch2:
+		t.Fatalf("unexpected to receive from ch2")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	wi.Trigger(3)
+	select {
+	case <-ch2:
+	case <-time.After(10 * time.Millisecond):
+		t.Fatalf("cannot receive from ch2 as expected")
+	}
+
+	// the index has already been triggered.
+	select {
+	case <-wi.Wait(3):
+	case <-time.After(10 * time.Millisecond):
+		t.Fatalf("cannot receive from ch as expected")
+	}
+}
@@ -106,7 +106,11 @@ data, serialize it into a byte slice and call:
 
 If the proposal is committed, data will appear in committed entries with type
 raftpb.EntryNormal. There is no guarantee that a proposed command will be
-committed; you may have to re-propose after a timeout.
+committed; you may have to re-propose after a timeout. If raft drops the
+proposal, for example because there is no leader, Propose returns
+ErrProposalDropped right away. A follower forwards the proposals to the
+leader unless Config.DisableProposalForwarding is set. The proposals that
+a node receives from the others through Step wait until there is a leader.
 
 To add or remove node in a cluster, build ConfChange struct 'cc' and call:
 
@@ -119,6 +123,21 @@ raftpb.EntryConfChange will be returned. You must apply it to node through:
 	cc.Unmarshal(data)
 	n.ApplyConfChange(cc)
 
+To add and remove several nodes atomically, for example to replace a
+failed node, build ConfChangeV2 struct 'cc' and call:
+
+	n.ProposeConfChangeV2(ctx, cc)
+
+The cluster goes through joint consensus: once the change is committed and
+applied, the cluster needs a majority of both the old and the new voters to
+elect a leader or commit an entry. The leader then proposes an empty
+ConfChangeV2 to leave the joint consensus. Both entries have type
+raftpb.EntryConfChangeV2 and must be applied through:
+
+	var cc raftpb.ConfChangeV2
+	cc.Unmarshal(data)
+	n.ApplyConfChangeV2(cc)
+
 Note: An ID represents a unique node in a cluster for all time. A
 given ID MUST be used only once even if the old node has been removed.
 This means that for example IP addresses make poor node IDs since they
@@ -241,7 +241,7 @@ func (mn *multiNode) run() {
 			if mcc.msg.NodeID == None {
 				group.raft.resetPendingConf()
 				select {
-				case mcc.ch <- pb.ConfState{Nodes: group.raft.nodes()}:
+				case mcc.ch <- group.raft.confState():
 				case <-mn.done:
 				}
 				break
@@ -249,6 +249,8 @@ func (mn *multiNode) run() {
 			switch mcc.msg.Type {
 			case pb.ConfChangeAddNode:
 				group.raft.addNode(mcc.msg.NodeID)
+			case pb.ConfChangeAddLearnerNode:
+				group.raft.addLearner(mcc.msg.NodeID)
 			case pb.ConfChangeRemoveNode:
 				group.raft.removeNode(mcc.msg.NodeID)
 			case pb.ConfChangeUpdateNode:
@@ -257,7 +259,7 @@ func (mn *multiNode) run() {
 				panic("unexpected conf type")
 			}
 			select {
-			case mcc.ch <- pb.ConfState{Nodes: group.raft.nodes()}:
+			case mcc.ch <- group.raft.confState():
 			case <-mn.done:
 			}
 
@@ -277,6 +279,7 @@ func (mn *multiNode) run() {
 			// Clear outgoing messages as soon as we've passed them to the application.
 			for g := range rds {
 				groups[g].raft.msgs = nil
+				groups[g].raft.readStates = nil
 			}
 			rds = map[uint64]Ready{}
 			advancec = mn.advancec
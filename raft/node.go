@@ -60,6 +60,12 @@ type Ready struct {
 	// HardState will be equal to empty state if there is no update.
 	pb.HardState
 
+	// ReadStates can be used for node to serve linearizable read requests locally
+	// when its applied index is greater than the index in ReadState.
+	// Note that the readState will be returned when raft receives msgReadIndex.
+	// The returned is only valid for the request that requested to read.
+	ReadStates []ReadState
+
 	// Entries specifies entries to be saved to stable storage BEFORE
 	// Messages are sent.
 	Entries []pb.Entry
@@ -96,7 +102,7 @@ func IsEmptySnap(sp pb.Snapshot) bool {
 func (rd Ready) containsUpdates() bool {
 	return rd.SoftState != nil || !IsEmptyHardState(rd.HardState) ||
 		!IsEmptySnap(rd.Snapshot) || len(rd.Entries) > 0 ||
-		len(rd.CommittedEntries) > 0 || len(rd.Messages) > 0
+		len(rd.CommittedEntries) > 0 || len(rd.Messages) > 0 || len(rd.ReadStates) != 0
 }
 
 // Node represents a node in a raft cluster.
@@ -106,13 +112,27 @@ type Node interface {
 	Tick()
 	// Campaign causes the Node to transition to candidate state and start campaigning to become leader.
 	Campaign(ctx context.Context) error
-	// Propose proposes that data be appended to the log.
+	// Propose proposes that data be appended to the log. It returns
+	// ErrProposalDropped immediately if raft drops the proposal, for
+	// example when there is no leader. A proposal that is not dropped
+	// can still be lost, so the caller must wait for it to be committed.
 	Propose(ctx context.Context, data []byte) error
 	// ProposeConfChange proposes config change.
 	// At most one ConfChange can be in the process of going through consensus.
+	// The leader drops the change with ErrConfChangeInProgress otherwise.
 	// Application needs to call ApplyConfChange when applying EntryConfChange type entry.
 	ProposeConfChange(ctx context.Context, cc pb.ConfChange) error
+	// ProposeConfChangeV2 proposes a joint config change, which changes
+	// several voters atomically. The cluster first enters the joint
+	// consensus of the old and the new voters, and the leader then
+	// proposes an empty ConfChangeV2 to leave it. No other ConfChange can
+	// be proposed until the cluster leaves the joint consensus; the leader
+	// drops it with ErrConfChangeInProgress.
+	// Application needs to call ApplyConfChangeV2 when applying
+	// EntryConfChangeV2 type entry.
+	ProposeConfChangeV2(ctx context.Context, cc pb.ConfChangeV2) error
 	// Step advances the state machine using the given message. ctx.Err() will be returned, if any.
+	// A proposal received from another node waits until there is a leader.
 	Step(ctx context.Context, msg pb.Message) error
 	// Ready returns a channel that returns the current point-in-time state
 	// Users of the Node must call Advance after applying the state returned by Ready
@@ -125,12 +145,30 @@ type Node interface {
 	// in snapshots. Will never return nil; it returns a pointer only
 	// to match MemoryStorage.Compact.
 	ApplyConfChange(cc pb.ConfChange) *pb.ConfState
+	// ApplyConfChangeV2 applies joint config change to the local node.
+	// Like ApplyConfChange, it returns the ConfState that must be recorded
+	// in snapshots. A ConfChangeV2 is cancelled by calling ApplyConfChange
+	// with a ConfChange whose NodeID is zero instead.
+	ApplyConfChangeV2(cc pb.ConfChangeV2) *pb.ConfState
 	// Status returns the current status of the raft state machine.
 	Status() Status
 	// Report reports the given node is not reachable for the last send.
 	ReportUnreachable(id uint64)
 	// ReportSnapshot reports the stutus of the sent snapshot.
 	ReportSnapshot(id uint64, status SnapshotStatus)
+	// TransferLeadership asks the leader to transfer its leadership to the
+	// transferee. The leader catches up the log of the transferee and asks
+	// it to start an election immediately, and it does not accept proposals
+	// until the transfer finishes or is aborted after an election timeout.
+	// It can be called on any node, which forwards the request to the
+	// leader.
+	TransferLeadership(ctx context.Context, transferee uint64)
+	// ReadIndex requests a read state. The read state will be set in the
+	// ready. The read state has a read index. Once the application advances
+	// further than the read index, any linearizable read requests issued
+	// before the read request can be processed safely. The read state will
+	// have the same rctx attached.
+	ReadIndex(ctx context.Context, rctx []byte) error
 	// Stop performs any necessary termination of the Node
 	Stop()
 }
@@ -191,11 +229,20 @@ func RestartNode(c *Config) Node {
 	return &n
 }
 
+// msgWithResult is a proposal passed to the node goroutine together with
+// the channel to return the result of stepping it.
+type msgWithResult struct {
+	m      pb.Message
+	result chan error
+}
+
 // node is the canonical implementation of the Node interface
 type node struct {
-	propc      chan pb.Message
+	propc      chan msgWithResult
+	recvpropc  chan pb.Message
 	recvc      chan pb.Message
 	confc      chan pb.ConfChange
+	confc2     chan pb.ConfChangeV2
 	confstatec chan pb.ConfState
 	readyc     chan Ready
 	advancec   chan struct{}
@@ -207,9 +254,11 @@ type node struct {
 
 func newNode() node {
 	return node{
-		propc:      make(chan pb.Message),
+		propc:      make(chan msgWithResult),
+		recvpropc:  make(chan pb.Message),
 		recvc:      make(chan pb.Message),
 		confc:      make(chan pb.ConfChange),
+		confc2:     make(chan pb.ConfChangeV2),
 		confstatec: make(chan pb.ConfState),
 		readyc:     make(chan Ready),
 		advancec:   make(chan struct{}),
@@ -233,7 +282,13 @@ func (n *node) Stop() {
 }
 
 func (n *node) run(r *raft) {
-	var propc chan pb.Message
+	// the local proposals are accepted even if there is no leader, so
+	// that they fail fast with ErrProposalDropped instead of blocking.
+	// The proposals received from the other nodes wait for a leader
+	// instead, since their senders do not learn that they are dropped.
+	propc := n.propc
+	var recvpropc chan pb.Message
+	removed := false
 	var readyc chan Ready
 	var advancec chan struct{}
 	var prevLastUnstablei, prevLastUnstablet uint64
@@ -264,10 +319,12 @@ func (n *node) run(r *raft) {
 				} else {
 					raftLogger.Infof("raft.node: %x changed leader from %x to %x at term %d", r.id, lead, r.lead, r.Term)
 				}
-				propc = n.propc
+				if !removed {
+					recvpropc = n.recvpropc
+				}
 			} else {
 				raftLogger.Infof("raft.node: %x lost leader %x at term %d", r.id, lead, r.Term)
-				propc = nil
+				recvpropc = nil
 			}
 			lead = r.lead
 		}
@@ -276,7 +333,11 @@ func (n *node) run(r *raft) {
 		// TODO: maybe buffer the config propose if there exists one (the way
 		// described in raft dissertation)
 		// Currently it is dropped in Step silently.
-		case m := <-propc:
+		case pm := <-propc:
+			m := pm.m
+			m.From = r.id
+			pm.result <- r.Step(m)
+		case m := <-recvpropc:
 			m.From = r.id
 			r.Step(m)
 		case m := <-n.recvc:
@@ -288,7 +349,7 @@ func (n *node) run(r *raft) {
 			if cc.NodeID == None {
 				r.resetPendingConf()
 				select {
-				case n.confstatec <- pb.ConfState{Nodes: r.nodes()}:
+				case n.confstatec <- r.confState():
 				case <-n.done:
 				}
 				break
@@ -296,11 +357,13 @@ func (n *node) run(r *raft) {
 			switch cc.Type {
 			case pb.ConfChangeAddNode:
 				r.addNode(cc.NodeID)
+			case pb.ConfChangeAddLearnerNode:
+				r.addLearner(cc.NodeID)
 			case pb.ConfChangeRemoveNode:
 				// block incoming proposal when local node is
 				// removed
 				if cc.NodeID == r.id {
-					n.propc = nil
+					propc, recvpropc, removed = nil, nil, true
 				}
 				r.removeNode(cc.NodeID)
 			case pb.ConfChangeUpdateNode:
@@ -309,7 +372,17 @@ func (n *node) run(r *raft) {
 				panic("unexpected conf type")
 			}
 			select {
-			case n.confstatec <- pb.ConfState{Nodes: r.nodes()}:
+			case n.confstatec <- r.confState():
+			case <-n.done:
+			}
+		case cc := <-n.confc2:
+			r.applyConfChangeV2(cc)
+			// block incoming proposal when local node is removed
+			if _, ok := r.prs[r.id]; !ok {
+				propc, recvpropc, removed = nil, nil, true
+			}
+			select {
+			case n.confstatec <- r.confState():
 			case <-n.done:
 			}
 		case <-n.tickc:
@@ -330,6 +403,7 @@ func (n *node) run(r *raft) {
 				prevSnapi = rd.Snapshot.Metadata.Index
 			}
 			r.msgs = nil
+			r.readStates = nil
 			advancec = n.advancec
 		case <-advancec:
 			if prevHardSt.Commit != 0 {
@@ -371,6 +445,10 @@ func (n *node) Step(ctx context.Context, m pb.Message) error {
 		// TODO: return an error?
 		return nil
 	}
+	if m.Type == pb.MsgProp {
+		// a proposal received from another node waits for a leader.
+		return n.send(ctx, n.recvpropc, m)
+	}
 	return n.step(ctx, m)
 }
 
@@ -379,17 +457,45 @@ func (n *node) ProposeConfChange(ctx context.Context, cc pb.ConfChange) error {
 	if err != nil {
 		return err
 	}
-	return n.Step(ctx, pb.Message{Type: pb.MsgProp, Entries: []pb.Entry{{Type: pb.EntryConfChange, Data: data}}})
+	return n.step(ctx, pb.Message{Type: pb.MsgProp, Entries: []pb.Entry{{Type: pb.EntryConfChange, Data: data}}})
+}
+
+func (n *node) ProposeConfChangeV2(ctx context.Context, cc pb.ConfChangeV2) error {
+	data, err := cc.Marshal()
+	if err != nil {
+		return err
+	}
+	return n.step(ctx, pb.Message{Type: pb.MsgProp, Entries: []pb.Entry{{Type: pb.EntryConfChangeV2, Data: data}}})
 }
 
 // Step advances the state machine using msgs. The ctx.Err() will be returned,
-// if any.
+// if any. A proposal waits until raft steps it, and returns
+// ErrProposalDropped if raft drops it.
 func (n *node) step(ctx context.Context, m pb.Message) error {
-	ch := n.recvc
-	if m.Type == pb.MsgProp {
-		ch = n.propc
+	if m.Type != pb.MsgProp {
+		return n.send(ctx, n.recvc, m)
 	}
 
+	pm := msgWithResult{m: m, result: make(chan error, 1)}
+	select {
+	case n.propc <- pm:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-n.done:
+		return ErrStopped
+	}
+	select {
+	case err := <-pm.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-n.done:
+		return ErrStopped
+	}
+}
+
+// send passes the message to the node goroutine through the given channel.
+func (n *node) send(ctx context.Context, ch chan pb.Message, m pb.Message) error {
 	select {
 	case ch <- m:
 		return nil
@@ -422,10 +528,27 @@ func (n *node) ApplyConfChange(cc pb.ConfChange) *pb.ConfState {
 	return &cs
 }
 
+func (n *node) ApplyConfChangeV2(cc pb.ConfChangeV2) *pb.ConfState {
+	var cs pb.ConfState
+	select {
+	case n.confc2 <- cc:
+	case <-n.done:
+	}
+	select {
+	case cs = <-n.confstatec:
+	case <-n.done:
+	}
+	return &cs
+}
+
 func (n *node) Status() Status {
 	c := make(chan Status)
-	n.status <- c
-	return <-c
+	select {
+	case n.status <- c:
+		return <-c
+	case <-n.done:
+		return Status{}
+	}
 }
 
 func (n *node) ReportUnreachable(id uint64) {
@@ -444,11 +567,26 @@ func (n *node) ReportSnapshot(id uint64, status SnapshotStatus) {
 	}
 }
 
+func (n *node) TransferLeadership(ctx context.Context, transferee uint64) {
+	select {
+	// the transferee is carried in m.From, so that the request can be
+	// forwarded to the leader.
+	case n.recvc <- pb.Message{Type: pb.MsgTransferLeader, From: transferee}:
+	case <-n.done:
+	case <-ctx.Done():
+	}
+}
+
+func (n *node) ReadIndex(ctx context.Context, rctx []byte) error {
+	return n.step(ctx, pb.Message{Type: pb.MsgReadIndex, Entries: []pb.Entry{{Data: rctx}}})
+}
+
 func newReady(r *raft, prevSoftSt *SoftState, prevHardSt pb.HardState) Ready {
 	rd := Ready{
 		Entries:          r.raftLog.unstableEntries(),
 		CommittedEntries: r.raftLog.nextEnts(),
 		Messages:         r.msgs,
+		ReadStates:       r.readStates,
 	}
 	if softSt := r.softState(); !softSt.equal(prevSoftSt) {
 		rd.SoftState = softSt
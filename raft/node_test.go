@@ -24,25 +24,25 @@ import (
 	"github.com/coreos/etcd/raft/raftpb"
 )
 
-// TestNodeStep ensures that node.Step sends msgProp to propc chan
+// TestNodeStep ensures that node.Step sends msgProp to recvpropc chan
 // and other kinds of messages to recvc chan.
 func TestNodeStep(t *testing.T) {
 	for i, msgn := range raftpb.MessageType_name {
 		n := &node{
-			propc: make(chan raftpb.Message, 1),
-			recvc: make(chan raftpb.Message, 1),
+			recvpropc: make(chan raftpb.Message, 1),
+			recvc:     make(chan raftpb.Message, 1),
 		}
 		msgt := raftpb.MessageType(i)
 		n.Step(context.TODO(), raftpb.Message{Type: msgt})
-		// Proposal goes to proc chan. Others go to recvc chan.
+		// Proposal goes to recvpropc chan. Others go to recvc chan.
 		if msgt == raftpb.MsgProp {
 			select {
-			case <-n.propc:
+			case <-n.recvpropc:
 			default:
-				t.Errorf("%d: cannot receive %s on propc chan", msgt, msgn)
+				t.Errorf("%d: cannot receive %s on recvpropc chan", msgt, msgn)
 			}
 		} else {
-			if msgt == raftpb.MsgBeat || msgt == raftpb.MsgHup || msgt == raftpb.MsgUnreachable || msgt == raftpb.MsgSnapStatus {
+			if msgt == raftpb.MsgBeat || msgt == raftpb.MsgHup || msgt == raftpb.MsgUnreachable || msgt == raftpb.MsgSnapStatus || msgt == raftpb.MsgCheckQuorum {
 				select {
 				case <-n.recvc:
 					t.Errorf("%d: step should ignore %s", msgt, msgn)
@@ -59,12 +59,72 @@ func TestNodeStep(t *testing.T) {
 	}
 }
 
+// TestNodeTransferLeadership ensures that TransferLeadership sends
+// MsgTransferLeader with the transferee as its sender to the raft state
+// machine.
+func TestNodeTransferLeadership(t *testing.T) {
+	n := &node{
+		recvc: make(chan raftpb.Message, 1),
+		done:  make(chan struct{}),
+	}
+	n.TransferLeadership(context.TODO(), 2)
+	select {
+	case m := <-n.recvc:
+		wm := raftpb.Message{Type: raftpb.MsgTransferLeader, From: 2}
+		if !reflect.DeepEqual(m, wm) {
+			t.Errorf("m = %+v, want %+v", m, wm)
+		}
+	default:
+		t.Errorf("cannot receive MsgTransferLeader on recvc chan")
+	}
+}
+
+// TestNodeReadIndex ensures that ReadIndex sends MsgReadIndex with the
+// request context as its entry to the raft state machine.
+func TestNodeReadIndex(t *testing.T) {
+	n := &node{
+		recvc: make(chan raftpb.Message, 1),
+		done:  make(chan struct{}),
+	}
+	if err := n.ReadIndex(context.TODO(), []byte("ctx")); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	select {
+	case m := <-n.recvc:
+		wm := raftpb.Message{Type: raftpb.MsgReadIndex, Entries: []raftpb.Entry{{Data: []byte("ctx")}}}
+		if !reflect.DeepEqual(m, wm) {
+			t.Errorf("m = %+v, want %+v", m, wm)
+		}
+	default:
+		t.Errorf("cannot receive MsgReadIndex on recvc chan")
+	}
+}
+
+// TestNodeProposeResult ensures that node.Propose sends the proposal to
+// propc chan, and waits for the result of stepping it.
+func TestNodeProposeResult(t *testing.T) {
+	n := &node{propc: make(chan msgWithResult, 1)}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- n.Propose(context.TODO(), []byte("somedata"))
+	}()
+	select {
+	case pm := <-n.propc:
+		pm.result <- ErrProposalDropped
+		if err := <-errc; err != ErrProposalDropped {
+			t.Errorf("err = %v, want %v", err, ErrProposalDropped)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("cannot receive proposal on propc chan")
+	}
+}
+
 // Cancel and Stop should unblock Step()
 func TestNodeStepUnblock(t *testing.T) {
 	// a node without buffer to block step
 	n := &node{
-		propc: make(chan raftpb.Message),
-		done:  make(chan struct{}),
+		recvpropc: make(chan raftpb.Message),
+		done:      make(chan struct{}),
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -108,8 +168,9 @@ func TestNodeStepUnblock(t *testing.T) {
 // TestNodePropose ensures that node.Propose sends the given proposal to the underlying raft.
 func TestNodePropose(t *testing.T) {
 	msgs := []raftpb.Message{}
-	appendStep := func(r *raft, m raftpb.Message) {
+	appendStep := func(r *raft, m raftpb.Message) error {
 		msgs = append(msgs, m)
+		return nil
 	}
 
 	n := newNode()
@@ -146,8 +207,9 @@ func TestNodePropose(t *testing.T) {
 // to the underlying raft.
 func TestNodeProposeConfig(t *testing.T) {
 	msgs := []raftpb.Message{}
-	appendStep := func(r *raft, m raftpb.Message) {
+	appendStep := func(r *raft, m raftpb.Message) error {
 		msgs = append(msgs, m)
+		return nil
 	}
 
 	n := newNode()
@@ -185,36 +247,50 @@ func TestNodeProposeConfig(t *testing.T) {
 	}
 }
 
-// TestBlockProposal ensures that node will block proposal when it does not
-// know who is the current leader; node will accept proposal when it knows
-// who is the current leader.
-func TestBlockProposal(t *testing.T) {
+// TestProposalDroppedWithoutLeader ensures that node drops the proposal
+// with ErrProposalDropped when it does not know who is the current leader;
+// node will accept proposal when it knows who is the current leader.
+func TestProposalDroppedWithoutLeader(t *testing.T) {
 	n := newNode()
 	r := newTestRaft(1, []uint64{1}, 10, 1, NewMemoryStorage())
 	go n.run(r)
 	defer n.Stop()
 
-	errc := make(chan error, 1)
-	go func() {
-		errc <- n.Propose(context.TODO(), []byte("somedata"))
-	}()
+	if err := n.Propose(context.TODO(), []byte("somedata")); err != ErrProposalDropped {
+		t.Errorf("err = %v, want %v", err, ErrProposalDropped)
+	}
 
+	n.Campaign(context.TODO())
 	testutil.WaitSchedule()
-	select {
-	case err := <-errc:
-		t.Errorf("err = %v, want blocking", err)
-	default:
+	if err := n.Propose(context.TODO(), []byte("somedata")); err != nil {
+		t.Errorf("err = %v, want %v", err, nil)
 	}
+}
+
+// TestRecvProposalWaitsForLeader ensures that the proposal received from
+// another node waits until there is a leader rather than being dropped,
+// since the sender does not learn that it is dropped.
+func TestRecvProposalWaitsForLeader(t *testing.T) {
+	n := newNode()
+	s := NewMemoryStorage()
+	r := newTestRaft(1, []uint64{1}, 10, 1, s)
+	go n.run(r)
+
+	m := raftpb.Message{From: 2, To: 1, Type: raftpb.MsgProp, Entries: []raftpb.Entry{{Data: []byte("somedata")}}}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	if err := n.Step(ctx, m); err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	cancel()
 
 	n.Campaign(context.TODO())
 	testutil.WaitSchedule()
-	select {
-	case err := <-errc:
-		if err != nil {
-			t.Errorf("err = %v, want %v", err, nil)
-		}
-	default:
-		t.Errorf("blocking proposal, want unblocking")
+	if err := n.Step(context.TODO(), m); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+	n.Stop()
+	if li := r.raftLog.lastIndex(); li != 2 {
+		t.Errorf("last index = %d, want 2", li)
 	}
 }
 
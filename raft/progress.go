@@ -56,6 +56,17 @@ type Progress struct {
 	// is reported to be failed.
 	PendingSnapshot uint64
 
+	// RecentActive is true if the progress is recently active. Receiving any messages
+	// from the corresponding follower indicates the progress is active.
+	// RecentActive is reset to false by the leader after an election timeout
+	// when Config.CheckQuorum is true.
+	RecentActive bool
+
+	// IsLearner is true if the follower is a learner. A learner receives
+	// the replicated log from the leader, but it does not vote and does
+	// not count toward the quorum.
+	IsLearner bool
+
 	// inflights is a sliding window for the inflight messages.
 	// When inflights is full, no more message should be sent.
 	// When sends out a message, the index of the last entry should
@@ -157,6 +168,16 @@ func (pr *Progress) isPaused() bool {
 	}
 }
 
+// InflightCount returns the number of in-flight append messages to the
+// follower, which the leader has sent but the follower has not yet
+// acknowledged. It is only non-zero in ProgressStateReplicate.
+func (pr *Progress) InflightCount() int {
+	if pr.ins == nil {
+		return 0
+	}
+	return pr.ins.count
+}
+
 func (pr *Progress) snapshotFailure() { pr.PendingSnapshot = 0 }
 
 // maybeSnapshotAbort unsets pendingSnapshot if Match is equal or higher than
@@ -187,6 +208,13 @@ func newInflights(size int) *inflights {
 	}
 }
 
+// clone returns a copy of the inflights that shares no state with it.
+func (in *inflights) clone() *inflights {
+	c := *in
+	c.buffer = append([]uint64(nil), in.buffer...)
+	return &c
+}
+
 // add adds an inflight into inflights
 func (in *inflights) add(inflight uint64) {
 	if in.full() {
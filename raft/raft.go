@@ -15,6 +15,7 @@
 package raft
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math"
@@ -31,11 +32,37 @@ const noLimit = math.MaxUint64
 
 var errNoLeader = errors.New("no leader")
 
+// ErrProposalDropped is returned when the proposal is dropped by raft,
+// because there is no leader, the leader is transferring its leadership,
+// or the follower is configured not to forward the proposals.
+var ErrProposalDropped = errors.New("raft proposal dropped")
+
+// ErrConfChangeInProgress is returned when a configuration change is
+// proposed before the previous one is applied, or while the cluster is in
+// the joint consensus.
+var ErrConfChangeInProgress = errors.New("raft configuration change in progress")
+
+// Possible values for campaignType.
+const (
+	// campaignPreElection represents the pre-vote phase of an election
+	// when Config.PreVote is true.
+	campaignPreElection campaignType = "CampaignPreElection"
+	// campaignElection represents a normal election.
+	campaignElection campaignType = "CampaignElection"
+	// campaignTransfer represents an election started by a leader
+	// transfer. It overrides the leader lease of the voters.
+	campaignTransfer campaignType = "CampaignTransfer"
+)
+
+// campaignType represents the type of an election.
+type campaignType string
+
 // Possible values for StateType.
 const (
 	StateFollower StateType = iota
 	StateCandidate
 	StateLeader
+	StatePreCandidate
 )
 
 // StateType represents the role of a node in a cluster.
@@ -45,6 +72,7 @@ var stmap = [...]string{
 	"StateFollower",
 	"StateCandidate",
 	"StateLeader",
+	"StatePreCandidate",
 }
 
 func (st StateType) String() string {
@@ -64,6 +92,12 @@ type Config struct {
 	// peer is private and only used for testing right now.
 	peers []uint64
 
+	// learners contains the IDs of all learner nodes (including self if the
+	// local node is a learner) in the raft cluster. A learner only receives
+	// entries from the leader node. It does not vote or promote itself.
+	// learners is private and only used for testing right now.
+	learners []uint64
+
 	// ElectionTick is the election timeout. If a follower does not
 	// receive any message from the leader of current term during
 	// ElectionTick, it will become candidate and start an election.
@@ -96,6 +130,27 @@ type Config struct {
 	// buffer over TCP/UDP. Setting MaxInflightMsgs to avoid overflowing that sending buffer.
 	// TODO (xiangli): feedback to application to limit the proposal rate?
 	MaxInflightMsgs int
+
+	// PreVote enables the pre-vote phase of the election described in
+	// section 9.6 of the raft thesis. Before starting an election, a
+	// node asks the cluster whether it would be elected, and increments
+	// its term only if a quorum would vote for it. This prevents a node
+	// that rejoins the cluster after a partition from disrupting the
+	// leader with its higher term.
+	PreVote bool
+
+	// CheckQuorum specifies if the leader should check quorum activity.
+	// The leader steps down when it has not heard from a quorum of the
+	// cluster within an election timeout. A follower that has heard from
+	// the leader within an election timeout ignores vote requests with a
+	// higher term, unless they are sent for a leader transfer.
+	CheckQuorum bool
+
+	// DisableProposalForwarding makes a follower drop the proposals instead
+	// of forwarding them to the leader, so that the proposals are only
+	// accepted by the leader. A dropped proposal fails with
+	// ErrProposalDropped.
+	DisableProposalForwarding bool
 }
 
 func (c *Config) validate() error {
@@ -146,6 +201,33 @@ type raft struct {
 	// New configuration is ignored if there exists unapplied configuration.
 	pendingConf bool
 
+	// incoming and outgoing are the voters of the new and the old
+	// configuration while the cluster is in joint consensus, in which
+	// decisions need a majority of both. They are nil otherwise.
+	incoming map[uint64]struct{}
+	outgoing map[uint64]struct{}
+
+	preVote     bool
+	checkQuorum bool
+
+	disableProposalForwarding bool
+
+	// leadTransferee is the id of the leader transfer target when it is
+	// not None. The leader does not accept proposals during the transfer.
+	leadTransferee uint64
+	// transferElapsed is the number of ticks since the leader transfer
+	// started. The transfer is aborted after an election timeout.
+	transferElapsed int
+	// readOnly tracks the read index requests of the leader.
+	readOnly *readOnly
+	// readStates are the confirmed read index requests to be returned
+	// in the next Ready.
+	readStates []ReadState
+
+	// quorumElapsed is the number of ticks since the leader last checked
+	// the activity of the quorum. It is only used when checkQuorum is true.
+	quorumElapsed int
+
 	elapsed          int // number of ticks since the last msg
 	heartbeatTimeout int
 	electionTimeout  int
@@ -164,14 +246,17 @@ func newRaft(c *Config) *raft {
 		panic(err) // TODO(bdarnell)
 	}
 	peers := c.peers
-	if len(cs.Nodes) > 0 {
-		if len(peers) > 0 {
+	learners := c.learners
+	outgoing := cs.NodesOutgoing
+	if len(cs.Nodes) > 0 || len(cs.Learners) > 0 || len(outgoing) > 0 {
+		if len(peers) > 0 || len(learners) > 0 {
 			// TODO(bdarnell): the peers argument is always nil except in
 			// tests; the argument should be removed and these tests should be
 			// updated to specify their nodes through a snapshot.
-			panic("cannot specify both newRaft(peers) and ConfState.Nodes)")
+			panic("cannot specify both newRaft(peers, learners) and ConfState.(Nodes, Learners))")
 		}
 		peers = cs.Nodes
+		learners = cs.Learners
 	}
 	r := &raft{
 		id:      c.ID,
@@ -185,11 +270,28 @@ func newRaft(c *Config) *raft {
 		prs:              make(map[uint64]*Progress),
 		electionTimeout:  c.ElectionTick,
 		heartbeatTimeout: c.HeartbeatTick,
+		preVote:          c.PreVote,
+		checkQuorum:      c.CheckQuorum,
+		readOnly:         newReadOnly(),
+
+		disableProposalForwarding: c.DisableProposalForwarding,
 	}
 	r.rand = rand.New(rand.NewSource(int64(c.ID)))
 	for _, p := range peers {
 		r.prs[p] = &Progress{Next: 1, ins: newInflights(r.maxInflight)}
 	}
+	r.setJoint(peers, outgoing)
+	for _, p := range outgoing {
+		if _, ok := r.prs[p]; !ok {
+			r.prs[p] = &Progress{Next: 1, ins: newInflights(r.maxInflight)}
+		}
+	}
+	for _, p := range learners {
+		if _, ok := r.prs[p]; ok {
+			panic(fmt.Sprintf("node %x is in both learner and peer list", p))
+		}
+		r.prs[p] = &Progress{Next: 1, ins: newInflights(r.maxInflight), IsLearner: true}
+	}
 	if !isHardStateEqual(hs, emptyState) {
 		r.loadState(hs)
 	}
@@ -202,9 +304,13 @@ func newRaft(c *Config) *raft {
 	for _, n := range r.nodes() {
 		nodesStrs = append(nodesStrs, fmt.Sprintf("%x", n))
 	}
+	learnersStrs := make([]string, 0)
+	for _, n := range r.learnerNodes() {
+		learnersStrs = append(learnersStrs, fmt.Sprintf("%x", n))
+	}
 
-	raftLogger.Infof("newRaft %x [peers: [%s], term: %d, commit: %d, applied: %d, lastindex: %d, lastterm: %d]",
-		r.id, strings.Join(nodesStrs, ","), r.Term, r.raftLog.committed, r.raftLog.applied, r.raftLog.lastIndex(), r.raftLog.lastTerm())
+	raftLogger.Infof("newRaft %x [peers: [%s], learners: [%s], term: %d, commit: %d, applied: %d, lastindex: %d, lastterm: %d]",
+		r.id, strings.Join(nodesStrs, ","), strings.Join(learnersStrs, ","), r.Term, r.raftLog.committed, r.raftLog.applied, r.raftLog.lastIndex(), r.raftLog.lastTerm())
 	return r
 }
 
@@ -212,24 +318,144 @@ func (r *raft) hasLeader() bool { return r.lead != None }
 
 func (r *raft) softState() *SoftState { return &SoftState{Lead: r.lead, RaftState: r.state} }
 
-func (r *raft) q() int { return len(r.prs)/2 + 1 }
+// q returns the quorum size of the cluster. Learners do not count
+// toward the quorum. During joint consensus it is the quorum size of the
+// incoming configuration; use hasQuorum to check a joint quorum.
+func (r *raft) q() int { return len(r.nodes())/2 + 1 }
 
+// nodes returns the sorted IDs of the voting members of the cluster. During
+// joint consensus, it returns the voters of the incoming configuration.
 func (r *raft) nodes() []uint64 {
 	nodes := make([]uint64, 0, len(r.prs))
-	for k := range r.prs {
+	if r.isJoint() {
+		for k := range r.incoming {
+			nodes = append(nodes, k)
+		}
+		sort.Sort(uint64Slice(nodes))
+		return nodes
+	}
+	for k, pr := range r.prs {
+		if pr.IsLearner {
+			continue
+		}
 		nodes = append(nodes, k)
 	}
 	sort.Sort(uint64Slice(nodes))
 	return nodes
 }
 
+// outgoingNodes returns the sorted IDs of the voters of the outgoing
+// configuration, or nil if the cluster is not in joint consensus.
+func (r *raft) outgoingNodes() []uint64 {
+	var nodes []uint64
+	for k := range r.outgoing {
+		nodes = append(nodes, k)
+	}
+	sort.Sort(uint64Slice(nodes))
+	return nodes
+}
+
+func (r *raft) isJoint() bool { return r.outgoing != nil }
+
+// setJoint enters joint consensus with the given incoming and outgoing
+// voters. It leaves joint consensus if outgoing is empty.
+func (r *raft) setJoint(incoming, outgoing []uint64) {
+	if len(outgoing) == 0 {
+		r.incoming, r.outgoing = nil, nil
+		return
+	}
+	r.incoming = make(map[uint64]struct{})
+	for _, id := range incoming {
+		r.incoming[id] = struct{}{}
+	}
+	r.outgoing = make(map[uint64]struct{})
+	for _, id := range outgoing {
+		r.outgoing[id] = struct{}{}
+	}
+}
+
+// voterSets returns the sets of voters that each need to reach a majority
+// for the cluster to make a decision: the voters of the cluster, or the
+// incoming and the outgoing voters during joint consensus.
+// Empty voter sets, e.g. the incoming voters after all of them are removed,
+// are skipped.
+func (r *raft) voterSets() []map[uint64]struct{} {
+	if r.isJoint() {
+		var sets []map[uint64]struct{}
+		for _, voters := range []map[uint64]struct{}{r.incoming, r.outgoing} {
+			if len(voters) != 0 {
+				sets = append(sets, voters)
+			}
+		}
+		return sets
+	}
+	voters := make(map[uint64]struct{})
+	for id, pr := range r.prs {
+		if !pr.IsLearner {
+			voters[id] = struct{}{}
+		}
+	}
+	if len(voters) == 0 {
+		return nil
+	}
+	return []map[uint64]struct{}{voters}
+}
+
+// hasQuorum returns true if the given nodes contain a majority of every
+// voter set of the cluster. It returns false if the cluster has no voters.
+func (r *raft) hasQuorum(ids map[uint64]bool) bool {
+	sets := r.voterSets()
+	if len(sets) == 0 {
+		return false
+	}
+	for _, voters := range sets {
+		var n int
+		for id := range voters {
+			if ids[id] {
+				n++
+			}
+		}
+		if n < len(voters)/2+1 {
+			return false
+		}
+	}
+	return true
+}
+
+// learnerNodes returns the sorted IDs of the learners of the cluster.
+func (r *raft) learnerNodes() []uint64 {
+	var nodes []uint64
+	for k, pr := range r.prs {
+		if pr.IsLearner {
+			nodes = append(nodes, k)
+		}
+	}
+	sort.Sort(uint64Slice(nodes))
+	return nodes
+}
+
+// confState returns the current configuration of the cluster.
+func (r *raft) confState() pb.ConfState {
+	return pb.ConfState{Nodes: r.nodes(), Learners: r.learnerNodes(), NodesOutgoing: r.outgoingNodes()}
+}
+
 // send persists state to stable storage and then sends to its mailbox.
 func (r *raft) send(m pb.Message) {
-	m.From = r.id
-	// do not attach term to MsgProp
-	// proposals are a way to forward to the leader and
-	// should be treated as local message.
-	if m.Type != pb.MsgProp {
+	// MsgTransferLeader carries the transferee in m.From, which is kept
+	// when the message is forwarded to the leader.
+	if m.Type != pb.MsgTransferLeader || m.From == None {
+		m.From = r.id
+	}
+	switch m.Type {
+	case pb.MsgProp, pb.MsgReadIndex:
+		// do not attach term to MsgProp, MsgReadIndex
+		// proposals are a way to forward to the leader and
+		// should be treated as local message.
+		// MsgReadIndex is also forwarded to leader.
+	case pb.MsgPreVote, pb.MsgPreVoteResp:
+		// pre-vote messages carry the term of the proposed election
+		// instead of the current term, which is set by the caller.
+	default:
 		m.Term = r.Term
 	}
 	r.msgs = append(r.msgs, m)
@@ -285,8 +511,9 @@ func (r *raft) sendAppend(to uint64) {
 	r.send(m)
 }
 
-// sendHeartbeat sends an empty MsgApp
-func (r *raft) sendHeartbeat(to uint64) {
+// sendHeartbeat sends an empty MsgApp. The given ctx is echoed back by
+// the follower to acknowledge the pending read index requests.
+func (r *raft) sendHeartbeat(to uint64, ctx []byte) {
 	// Attach the commit as min(to.matched, r.committed).
 	// When the leader sends out heartbeat message,
 	// the receiver(follower) might not be matched with the leader
@@ -295,9 +522,10 @@ func (r *raft) sendHeartbeat(to uint64) {
 	// an unmatched index.
 	commit := min(r.prs[to].Match, r.raftLog.committed)
 	m := pb.Message{
-		To:     to,
-		Type:   pb.MsgHeartbeat,
-		Commit: commit,
+		To:      to,
+		Type:    pb.MsgHeartbeat,
+		Commit:  commit,
+		Context: ctx,
 	}
 	r.send(m)
 }
@@ -315,23 +543,49 @@ func (r *raft) bcastAppend() {
 
 // bcastHeartbeat sends RRPC, without entries to all the peers.
 func (r *raft) bcastHeartbeat() {
+	lastCtx := r.readOnly.lastPendingRequestCtx()
+	if len(lastCtx) == 0 {
+		r.bcastHeartbeatWithCtx(nil)
+	} else {
+		r.bcastHeartbeatWithCtx([]byte(lastCtx))
+	}
+}
+
+func (r *raft) bcastHeartbeatWithCtx(ctx []byte) {
 	for i := range r.prs {
 		if i == r.id {
 			continue
 		}
-		r.sendHeartbeat(i)
+		r.sendHeartbeat(i, ctx)
 		r.prs[i].resume()
 	}
 }
 
+// maybeCommit attempts to advance the commit index. During joint consensus,
+// an entry is committed once it is replicated to a majority of both the
+// incoming and the outgoing voters.
 func (r *raft) maybeCommit() bool {
 	// TODO(bmizerany): optimize.. Currently naive
-	mis := make(uint64Slice, 0, len(r.prs))
-	for i := range r.prs {
-		mis = append(mis, r.prs[i].Match)
+	sets := r.voterSets()
+	if len(sets) == 0 {
+		return false
+	}
+	var mci uint64
+	for i, voters := range sets {
+		mis := make(uint64Slice, 0, len(voters))
+		for id := range voters {
+			// a voter without progress has not replicated anything.
+			var match uint64
+			if pr, ok := r.prs[id]; ok {
+				match = pr.Match
+			}
+			mis = append(mis, match)
+		}
+		sort.Sort(sort.Reverse(mis))
+		if m := mis[len(mis)/2]; i == 0 || m < mci {
+			mci = m
+		}
 	}
-	sort.Sort(sort.Reverse(mis))
-	mci := mis[r.q()-1]
 	return r.raftLog.maybeCommit(mci, r.Term)
 }
 
@@ -342,14 +596,17 @@ func (r *raft) reset(term uint64) {
 	}
 	r.lead = None
 	r.elapsed = 0
+	r.quorumElapsed = 0
 	r.votes = make(map[uint64]bool)
-	for i := range r.prs {
-		r.prs[i] = &Progress{Next: r.raftLog.lastIndex() + 1, ins: newInflights(r.maxInflight)}
+	for i, pr := range r.prs {
+		r.prs[i] = &Progress{Next: r.raftLog.lastIndex() + 1, ins: newInflights(r.maxInflight), IsLearner: pr.IsLearner}
 		if i == r.id {
 			r.prs[i].Match = r.raftLog.lastIndex()
 		}
 	}
 	r.pendingConf = false
+	r.abortLeaderTransfer()
+	r.readOnly = newReadOnly()
 }
 
 func (r *raft) appendEntry(es ...pb.Entry) {
@@ -378,6 +635,24 @@ func (r *raft) tickElection() {
 
 // tickHeartbeat is run by leaders to send a MsgBeat after r.heartbeatTimeout.
 func (r *raft) tickHeartbeat() {
+	if r.leadTransferee != None {
+		r.transferElapsed++
+		if r.transferElapsed >= r.electionTimeout {
+			raftLogger.Infof("%x aborted leader transfer to %x after election timeout", r.id, r.leadTransferee)
+			r.abortLeaderTransfer()
+		}
+	}
+	if r.checkQuorum {
+		r.quorumElapsed++
+		if r.quorumElapsed >= r.electionTimeout {
+			r.quorumElapsed = 0
+			r.Step(pb.Message{From: r.id, Type: pb.MsgCheckQuorum})
+			// the leader steps down if the quorum is not active.
+			if r.state != StateLeader {
+				return
+			}
+		}
+	}
 	r.elapsed++
 	if r.elapsed >= r.heartbeatTimeout {
 		r.elapsed = 0
@@ -407,6 +682,21 @@ func (r *raft) becomeCandidate() {
 	raftLogger.Infof("%x became candidate at term %d", r.id, r.Term)
 }
 
+// becomePreCandidate transitions the node to the pre-candidate state.
+// Unlike becomeCandidate, it changes neither the term nor the vote.
+func (r *raft) becomePreCandidate() {
+	// TODO(xiangli) remove the panic when the raft implementation is stable
+	if r.state == StateLeader {
+		panic("invalid transition [leader -> pre-candidate]")
+	}
+	r.step = stepCandidate
+	r.votes = make(map[uint64]bool)
+	r.tick = r.tickElection
+	r.lead = None
+	r.state = StatePreCandidate
+	raftLogger.Infof("%x became pre-candidate at term %d", r.id, r.Term)
+}
+
 func (r *raft) becomeLeader() {
 	// TODO(xiangli) remove the panic when the raft implementation is stable
 	if r.state == StateFollower {
@@ -423,7 +713,7 @@ func (r *raft) becomeLeader() {
 	}
 
 	for _, e := range ents {
-		if e.Type != pb.EntryConfChange {
+		if e.Type != pb.EntryConfChange && e.Type != pb.EntryConfChangeV2 {
 			continue
 		}
 		if r.pendingConf {
@@ -432,30 +722,92 @@ func (r *raft) becomeLeader() {
 		r.pendingConf = true
 	}
 	r.appendEntry(pb.Entry{Data: nil})
+	// the previous leader might not have proposed to leave the joint
+	// consensus before it lost its leadership.
+	if r.isJoint() && !r.pendingConf {
+		r.appendLeaveJoint()
+	}
 	raftLogger.Infof("%x became leader at term %d", r.id, r.Term)
 }
 
-func (r *raft) campaign() {
-	r.becomeCandidate()
-	if r.q() == r.poll(r.id, true) {
-		r.becomeLeader()
+// campaign starts an election of the given type. campaignPreElection
+// starts the pre-vote phase of the election for the next term instead.
+func (r *raft) campaign(t campaignType) {
+	var term uint64
+	var voteMsg pb.MessageType
+	if t == campaignPreElection {
+		r.becomePreCandidate()
+		voteMsg = pb.MsgPreVote
+		// pre-vote requests are sent for the next term before the term
+		// is incremented.
+		term = r.Term + 1
+	} else {
+		r.becomeCandidate()
+		voteMsg = pb.MsgVote
+		term = r.Term
+	}
+	if r.poll(r.id, voteMsg, true); r.voteResult() == voteWon {
+		// a single node cluster wins the pre-vote phase immediately.
+		if t == campaignPreElection {
+			r.campaign(campaignElection)
+		} else {
+			r.becomeLeader()
+		}
 		return
 	}
-	for i := range r.prs {
-		if i == r.id {
+	var ctx []byte
+	if t == campaignTransfer {
+		ctx = []byte(t)
+	}
+	for i, pr := range r.prs {
+		if i == r.id || pr.IsLearner {
 			continue
 		}
-		raftLogger.Infof("%x [logterm: %d, index: %d] sent vote request to %x at term %d",
-			r.id, r.raftLog.lastTerm(), r.raftLog.lastIndex(), i, r.Term)
-		r.send(pb.Message{To: i, Type: pb.MsgVote, Index: r.raftLog.lastIndex(), LogTerm: r.raftLog.lastTerm()})
+		raftLogger.Infof("%x [logterm: %d, index: %d] sent %s request to %x at term %d",
+			r.id, r.raftLog.lastTerm(), r.raftLog.lastIndex(), voteMsg, i, term)
+		r.send(pb.Message{Term: term, To: i, Type: voteMsg, Index: r.raftLog.lastIndex(), LogTerm: r.raftLog.lastTerm(), Context: ctx})
 	}
 }
 
-func (r *raft) poll(id uint64, v bool) (granted int) {
+// voteResult indicates whether a candidate won or lost the election, or
+// whether it is still waiting for votes.
+type voteResult uint8
+
+const (
+	votePending voteResult = iota
+	voteLost
+	voteWon
+)
+
+// voteResult returns the result of the election from the votes received so
+// far. The election is lost once a quorum of any voter set rejects it.
+func (r *raft) voteResult() voteResult {
+	granted := make(map[uint64]bool)
+	for id, v := range r.votes {
+		granted[id] = v
+	}
+	if r.hasQuorum(granted) {
+		return voteWon
+	}
+	for _, voters := range r.voterSets() {
+		var rejected int
+		for id := range voters {
+			if v, ok := r.votes[id]; ok && !v {
+				rejected++
+			}
+		}
+		if rejected >= len(voters)/2+1 {
+			return voteLost
+		}
+	}
+	return votePending
+}
+
+func (r *raft) poll(id uint64, t pb.MessageType, v bool) (granted int) {
 	if v {
-		raftLogger.Infof("%x received vote from %x at term %d", r.id, id, r.Term)
+		raftLogger.Infof("%x received %s from %x at term %d", r.id, t, id, r.Term)
 	} else {
-		raftLogger.Infof("%x received vote rejection from %x at term %d", r.id, id, r.Term)
+		raftLogger.Infof("%x received %s rejection from %x at term %d", r.id, t, id, r.Term)
 	}
 	if _, ok := r.votes[id]; !ok {
 		r.votes[id] = v
@@ -470,8 +822,16 @@ func (r *raft) poll(id uint64, v bool) (granted int) {
 
 func (r *raft) Step(m pb.Message) error {
 	if m.Type == pb.MsgHup {
+		if pr, ok := r.prs[r.id]; ok && pr.IsLearner {
+			raftLogger.Warningf("%x is a learner and cannot start an election at term %d", r.id, r.Term)
+			return nil
+		}
 		raftLogger.Infof("%x is starting a new election at term %d", r.id, r.Term)
-		r.campaign()
+		if r.preVote {
+			r.campaign(campaignPreElection)
+		} else {
+			r.campaign(campaignElection)
+		}
 		r.Commit = r.raftLog.committed
 		return nil
 	}
@@ -480,47 +840,111 @@ func (r *raft) Step(m pb.Message) error {
 	case m.Term == 0:
 		// local message
 	case m.Term > r.Term:
-		lead := m.From
 		if m.Type == pb.MsgVote {
-			lead = None
+			force := bytes.Equal(m.Context, []byte(campaignTransfer))
+			inLease := r.checkQuorum && r.hasRecentLeader()
+			if !force && inLease {
+				// the node has heard from the current leader within the
+				// election timeout, so the leader lease is not expired.
+				raftLogger.Infof("%x [logterm: %d, index: %d, vote: %x] ignored vote from %x [logterm: %d, index: %d] at term %d: lease is not expired (remaining ticks: %d)",
+					r.id, r.raftLog.lastTerm(), r.raftLog.lastIndex(), r.Vote, m.From, m.LogTerm, m.Index, r.Term, r.electionTimeout-r.elapsed)
+				return nil
+			}
+		}
+		switch {
+		case m.Type == pb.MsgPreVote:
+			// never change the term in response to a pre-vote request
+		case m.Type == pb.MsgPreVoteResp && !m.Reject:
+			// pre-vote requests are sent with the next term. If the
+			// pre-vote is granted, the term is incremented when a quorum
+			// grants it. Otherwise, the term comes from the node that
+			// rejected the pre-vote, and the node becomes its follower.
+		default:
+			// only the leader sends appends, heartbeats and snapshots.
+			// The other messages, e.g. votes, rejected pre-votes or the
+			// responses to a stale leader, are not sent by the leader.
+			lead := None
+			if m.Type == pb.MsgApp || m.Type == pb.MsgHeartbeat || m.Type == pb.MsgSnap {
+				lead = m.From
+			}
+			raftLogger.Infof("%x [term: %d] received a %s message with higher term from %x [term: %d]",
+				r.id, r.Term, m.Type, m.From, m.Term)
+			r.becomeFollower(m.Term, lead)
 		}
-		raftLogger.Infof("%x [term: %d] received a %s message with higher term from %x [term: %d]",
-			r.id, r.Term, m.Type, m.From, m.Term)
-		r.becomeFollower(m.Term, lead)
 	case m.Term < r.Term:
+		if m.Type == pb.MsgPreVote {
+			// reject the pre-vote request with the current term, so that
+			// the stale pre-candidate can catch up with the term.
+			raftLogger.Infof("%x [logterm: %d, index: %d, vote: %x] rejected %s from %x [logterm: %d, index: %d] at term %d",
+				r.id, r.raftLog.lastTerm(), r.raftLog.lastIndex(), r.Vote, m.Type, m.From, m.LogTerm, m.Index, r.Term)
+			r.send(pb.Message{To: m.From, Term: r.Term, Type: pb.MsgPreVoteResp, Reject: true})
+			return nil
+		}
+		if (r.checkQuorum || r.preVote) && (m.Type == pb.MsgHeartbeat || m.Type == pb.MsgApp) {
+			// a node whose term was increased by an election it could not
+			// win cannot advance the term of the others: with CheckQuorum
+			// they ignore its votes while the leader is in lease, and with
+			// PreVote they reject its pre-votes. Respond to the stale
+			// leader with the higher term, so that it steps down and the
+			// cluster elects a new leader.
+			r.send(pb.Message{To: m.From, Type: pb.MsgAppResp})
+			return nil
+		}
 		// ignore
 		raftLogger.Infof("%x [term: %d] ignored a %s message with lower term from %x [term: %d]",
 			r.id, r.Term, m.Type, m.From, m.Term)
 		return nil
 	}
-	r.step(r, m)
+	err := r.step(r, m)
 	r.Commit = r.raftLog.committed
-	return nil
+	return err
 }
 
-type stepFunc func(r *raft, m pb.Message)
+type stepFunc func(r *raft, m pb.Message) error
 
-func stepLeader(r *raft, m pb.Message) {
+func stepLeader(r *raft, m pb.Message) error {
 	pr := r.prs[m.From]
 
 	switch m.Type {
 	case pb.MsgBeat:
 		r.bcastHeartbeat()
+	case pb.MsgCheckQuorum:
+		if !r.checkQuorumActive() {
+			raftLogger.Warningf("%x stepped down to follower since quorum is not active", r.id)
+			r.becomeFollower(r.Term, None)
+		}
 	case pb.MsgProp:
 		if len(m.Entries) == 0 {
 			raftLogger.Panicf("%x stepped empty MsgProp", r.id)
 		}
-		for i, e := range m.Entries {
-			if e.Type == pb.EntryConfChange {
-				if r.pendingConf {
-					m.Entries[i] = pb.Entry{Type: pb.EntryNormal}
+		if _, ok := r.prs[r.id]; !ok {
+			// the leader was removed from the cluster by a committed
+			// configuration change, so it cannot commit the proposal.
+			return ErrProposalDropped
+		}
+		if r.leadTransferee != None {
+			raftLogger.Debugf("%x [term %d] transfer leadership to %x is in progress; dropping proposal", r.id, r.Term, r.leadTransferee)
+			return ErrProposalDropped
+		}
+		conf := false
+		for _, e := range m.Entries {
+			if e.Type == pb.EntryConfChange || e.Type == pb.EntryConfChangeV2 {
+				// a new configuration change has to wait until the previous
+				// one is applied and the cluster leaves the joint consensus.
+				if conf || r.pendingConf || r.isJoint() {
+					raftLogger.Infof("%x [term %d] configuration change is in progress; dropping proposal", r.id, r.Term)
+					return ErrConfChangeInProgress
 				}
-				r.pendingConf = true
+				conf = true
 			}
 		}
+		if conf {
+			r.pendingConf = true
+		}
 		r.appendEntry(m.Entries...)
 		r.bcastAppend()
 	case pb.MsgAppResp:
+		pr.RecentActive = true
 		if m.Reject {
 			raftLogger.Debugf("%x received msgApp rejection(lastindex: %d) from %x for index %d",
 				r.id, m.RejectHint, m.From, m.Index)
@@ -551,9 +975,15 @@ func stepLeader(r *raft, m pb.Message) {
 					// an update before, send it now.
 					r.sendAppend(m.From)
 				}
+				// transfer leadership once the transferee catches up.
+				if m.From == r.leadTransferee && pr.Match == r.raftLog.lastIndex() {
+					raftLogger.Infof("%x sent MsgTimeoutNow to %x after it caught up with the log", r.id, m.From)
+					r.sendTimeoutNow(m.From)
+				}
 			}
 		}
 	case pb.MsgHeartbeatResp:
+		pr.RecentActive = true
 		// free one slot for the full inflights window to allow progress.
 		if pr.State == ProgressStateReplicate && pr.ins.full() {
 			pr.ins.freeFirstOne()
@@ -561,13 +991,82 @@ func stepLeader(r *raft, m pb.Message) {
 		if pr.Match < r.raftLog.lastIndex() {
 			r.sendAppend(m.From)
 		}
+
+		// learners do not count toward the quorum that confirms the
+		// leadership.
+		if len(m.Context) == 0 || pr.IsLearner {
+			return nil
+		}
+		if !r.hasQuorum(r.readOnly.recvAck(m)) {
+			return nil
+		}
+		// the quorum acknowledged the leadership, so the read index
+		// requests received before the heartbeat can be served.
+		for _, rs := range r.readOnly.advance(m) {
+			req := rs.req
+			if req.From == None || req.From == r.id {
+				r.readStates = append(r.readStates, ReadState{Index: rs.index, RequestCtx: req.Entries[0].Data})
+			} else {
+				r.send(pb.Message{To: req.From, Type: pb.MsgReadIndexResp, Index: rs.index, Entries: req.Entries})
+			}
+		}
+	case pb.MsgReadIndex:
+		if !r.isJoint() && r.q() == 1 {
+			// a single node cluster does not need to confirm its leadership.
+			r.readStates = append(r.readStates, ReadState{Index: r.raftLog.committed, RequestCtx: m.Entries[0].Data})
+			return nil
+		}
+		// the leader does not know the commit index of the cluster until
+		// it commits an entry of its own term.
+		if zeroTermOnErrCompacted(r.raftLog.term(r.raftLog.committed)) != r.Term {
+			raftLogger.Debugf("%x [term %d] has not committed an entry in its term; dropping read index request", r.id, r.Term)
+			return nil
+		}
+		r.readOnly.addRequest(r.raftLog.committed, m)
+		// the leader acknowledges its own leadership.
+		r.readOnly.recvAck(pb.Message{From: r.id, Context: m.Entries[0].Data})
+		r.bcastHeartbeatWithCtx(m.Entries[0].Data)
 	case pb.MsgVote:
 		raftLogger.Infof("%x [logterm: %d, index: %d, vote: %x] rejected vote from %x [logterm: %d, index: %d] at term %d",
 			r.id, r.raftLog.lastTerm(), r.raftLog.lastIndex(), r.Vote, m.From, m.LogTerm, m.Index, r.Term)
 		r.send(pb.Message{To: m.From, Type: pb.MsgVoteResp, Reject: true})
+	case pb.MsgPreVote:
+		r.handlePreVote(m)
+	case pb.MsgTransferLeader:
+		transferee := m.From
+		if pr == nil {
+			raftLogger.Debugf("%x ignored leader transfer to unknown node %x", r.id, transferee)
+			return nil
+		}
+		if pr.IsLearner {
+			raftLogger.Debugf("%x ignored leader transfer to learner %x", r.id, transferee)
+			return nil
+		}
+		if r.leadTransferee != None {
+			if r.leadTransferee == transferee {
+				raftLogger.Infof("%x [term %d] transfer leadership to %x is in progress, ignored request to the same node",
+					r.id, r.Term, transferee)
+				return nil
+			}
+			raftLogger.Infof("%x [term %d] abort previous transfer leadership to %x", r.id, r.Term, r.leadTransferee)
+			r.abortLeaderTransfer()
+		}
+		if transferee == r.id {
+			raftLogger.Debugf("%x is already leader; ignored transfer leadership to itself", r.id)
+			return nil
+		}
+		raftLogger.Infof("%x [term %d] starts to transfer leadership to %x", r.id, r.Term, transferee)
+		r.leadTransferee = transferee
+		r.transferElapsed = 0
+		if pr.Match == r.raftLog.lastIndex() {
+			raftLogger.Infof("%x sent MsgTimeoutNow to %x immediately as %x already has up-to-date log", r.id, transferee, transferee)
+			r.sendTimeoutNow(transferee)
+		} else {
+			r.sendAppend(transferee)
+		}
 	case pb.MsgSnapStatus:
 		if pr.State != ProgressStateSnapshot {
-			return
+			return nil
 		}
 		if !m.Reject {
 			pr.becomeProbe()
@@ -589,13 +1088,17 @@ func stepLeader(r *raft, m pb.Message) {
 		}
 		raftLogger.Debugf("%x failed to send message to %x because it is unreachable [%s]", r.id, m.From, pr)
 	}
+	return nil
 }
 
-func stepCandidate(r *raft, m pb.Message) {
+func stepCandidate(r *raft, m pb.Message) error {
 	switch m.Type {
 	case pb.MsgProp:
 		raftLogger.Infof("%x no leader at term %d; dropping proposal", r.id, r.Term)
-		return
+		return ErrProposalDropped
+	case pb.MsgReadIndex:
+		raftLogger.Infof("%x no leader at term %d; dropping read index request", r.id, r.Term)
+		return nil
 	case pb.MsgApp:
 		r.becomeFollower(r.Term, m.From)
 		r.handleAppendEntries(m)
@@ -609,25 +1112,40 @@ func stepCandidate(r *raft, m pb.Message) {
 		raftLogger.Infof("%x [logterm: %d, index: %d, vote: %x] rejected vote from %x [logterm: %d, index: %d] at term %x",
 			r.id, r.raftLog.lastTerm(), r.raftLog.lastIndex(), r.Vote, m.From, m.LogTerm, m.Index, r.Term)
 		r.send(pb.Message{To: m.From, Type: pb.MsgVoteResp, Reject: true})
-	case pb.MsgVoteResp:
-		gr := r.poll(m.From, !m.Reject)
-		raftLogger.Infof("%x [q:%d] has received %d votes and %d vote rejections", r.id, r.q(), gr, len(r.votes)-gr)
-		switch r.q() {
-		case gr:
-			r.becomeLeader()
-			r.bcastAppend()
-		case len(r.votes) - gr:
+	case pb.MsgPreVote:
+		r.handlePreVote(m)
+	case pb.MsgVoteResp, pb.MsgPreVoteResp:
+		// only count the responses to the requests of the current phase
+		if (m.Type == pb.MsgPreVoteResp) != (r.state == StatePreCandidate) {
+			return nil
+		}
+		gr := r.poll(m.From, m.Type, !m.Reject)
+		raftLogger.Infof("%x [q:%d] has received %d %s votes and %d vote rejections", r.id, r.q(), gr, m.Type, len(r.votes)-gr)
+		switch r.voteResult() {
+		case voteWon:
+			if r.state == StatePreCandidate {
+				r.campaign(campaignElection)
+			} else {
+				r.becomeLeader()
+				r.bcastAppend()
+			}
+		case voteLost:
 			r.becomeFollower(r.Term, None)
 		}
 	}
+	return nil
 }
 
-func stepFollower(r *raft, m pb.Message) {
+func stepFollower(r *raft, m pb.Message) error {
 	switch m.Type {
 	case pb.MsgProp:
 		if r.lead == None {
 			raftLogger.Infof("%x no leader at term %d; dropping proposal", r.id, r.Term)
-			return
+			return ErrProposalDropped
+		}
+		if r.disableProposalForwarding {
+			raftLogger.Infof("%x not forwarding to leader %x at term %d; dropping proposal", r.id, r.lead, r.Term)
+			return ErrProposalDropped
 		}
 		m.To = r.lead
 		r.send(m)
@@ -654,7 +1172,62 @@ func stepFollower(r *raft, m pb.Message) {
 				r.id, r.raftLog.lastTerm(), r.raftLog.lastIndex(), r.Vote, m.From, m.LogTerm, m.Index, r.Term)
 			r.send(pb.Message{To: m.From, Type: pb.MsgVoteResp, Reject: true})
 		}
+	case pb.MsgPreVote:
+		r.handlePreVote(m)
+	case pb.MsgTransferLeader:
+		if r.lead == None {
+			raftLogger.Infof("%x no leader at term %d; dropping leader transfer msg", r.id, r.Term)
+			return nil
+		}
+		m.To = r.lead
+		r.send(m)
+	case pb.MsgReadIndex:
+		if r.lead == None {
+			raftLogger.Infof("%x no leader at term %d; dropping read index request", r.id, r.Term)
+			return nil
+		}
+		m.To = r.lead
+		r.send(m)
+	case pb.MsgReadIndexResp:
+		if len(m.Entries) != 1 {
+			raftLogger.Errorf("%x invalid format of MsgReadIndexResp from %x, entries count: %d", r.id, m.From, len(m.Entries))
+			return nil
+		}
+		r.readStates = append(r.readStates, ReadState{Index: m.Index, RequestCtx: m.Entries[0].Data})
+	case pb.MsgTimeoutNow:
+		if !r.promotable() {
+			raftLogger.Infof("%x [term %d] ignored MsgTimeoutNow from %x as it is not promotable", r.id, r.Term, m.From)
+			return nil
+		}
+		// the leader asked the node to campaign, so the pre-vote phase is
+		// skipped to start the election immediately.
+		raftLogger.Infof("%x [term %d] received MsgTimeoutNow from %x and starts an election to get leadership", r.id, r.Term, m.From)
+		r.campaign(campaignTransfer)
 	}
+	return nil
+}
+
+// handlePreVote responds to a pre-vote request. The pre-vote is granted if
+// it is for a higher term, the log of the pre-candidate is at least as
+// up-to-date as the local log, and the node has not heard from a leader
+// within the election timeout. Granting a pre-vote changes neither the
+// term nor the vote of the node.
+func (r *raft) handlePreVote(m pb.Message) {
+	if m.Term > r.Term && !r.hasRecentLeader() && r.raftLog.isUpToDate(m.Index, m.LogTerm) {
+		raftLogger.Infof("%x [logterm: %d, index: %d, vote: %x] granted %s for %x [logterm: %d, index: %d] at term %d",
+			r.id, r.raftLog.lastTerm(), r.raftLog.lastIndex(), r.Vote, m.Type, m.From, m.LogTerm, m.Index, r.Term)
+		r.send(pb.Message{To: m.From, Term: m.Term, Type: pb.MsgPreVoteResp})
+		return
+	}
+	raftLogger.Infof("%x [logterm: %d, index: %d, vote: %x] rejected %s from %x [logterm: %d, index: %d] at term %d",
+		r.id, r.raftLog.lastTerm(), r.raftLog.lastIndex(), r.Vote, m.Type, m.From, m.LogTerm, m.Index, r.Term)
+	r.send(pb.Message{To: m.From, Term: r.Term, Type: pb.MsgPreVoteResp, Reject: true})
+}
+
+// hasRecentLeader returns true if the node is the leader, or it has heard
+// from the leader within the election timeout.
+func (r *raft) hasRecentLeader() bool {
+	return r.state == StateLeader || (r.lead != None && r.elapsed < r.electionTimeout)
 }
 
 func (r *raft) handleAppendEntries(m pb.Message) {
@@ -674,7 +1247,7 @@ func (r *raft) handleAppendEntries(m pb.Message) {
 
 func (r *raft) handleHeartbeat(m pb.Message) {
 	r.raftLog.commitTo(m.Commit)
-	r.send(pb.Message{To: m.From, Type: pb.MsgHeartbeatResp})
+	r.send(pb.Message{To: m.From, Type: pb.MsgHeartbeatResp, Context: m.Context})
 }
 
 func (r *raft) handleSnapshot(m pb.Message) {
@@ -707,47 +1280,193 @@ func (r *raft) restore(s pb.Snapshot) bool {
 		r.id, r.Commit, r.raftLog.lastIndex(), r.raftLog.lastTerm(), s.Metadata.Index, s.Metadata.Term)
 
 	r.raftLog.restore(s)
+	cs := s.Metadata.ConfState
 	r.prs = make(map[uint64]*Progress)
-	for _, n := range s.Metadata.ConfState.Nodes {
+	r.restoreNode(cs.Nodes, false)
+	for _, n := range cs.NodesOutgoing {
+		if _, ok := r.prs[n]; !ok {
+			r.restoreNode([]uint64{n}, false)
+		}
+	}
+	r.restoreNode(cs.Learners, true)
+	r.setJoint(cs.Nodes, cs.NodesOutgoing)
+	return true
+}
+
+func (r *raft) restoreNode(nodes []uint64, isLearner bool) {
+	for _, n := range nodes {
 		match, next := uint64(0), uint64(r.raftLog.lastIndex())+1
 		if n == r.id {
 			match = next - 1
-		} else {
-			match = 0
 		}
-		r.setProgress(n, match, next)
+		r.setProgress(n, match, next, isLearner)
 		raftLogger.Infof("%x restored progress of %x [%s]", r.id, n, r.prs[n])
 	}
-	return true
 }
 
 // promotable indicates whether state machine can be promoted to leader,
-// which is true when its own id is in progress list.
+// which is true when its own id is in progress list and it is not a
+// learner.
 func (r *raft) promotable() bool {
-	_, ok := r.prs[r.id]
-	return ok
+	pr, ok := r.prs[r.id]
+	return ok && !pr.IsLearner
 }
 
 func (r *raft) addNode(id uint64) {
-	if _, ok := r.prs[id]; ok {
+	r.addNodeOrLearnerNode(id, false)
+}
+
+func (r *raft) addLearner(id uint64) {
+	r.addNodeOrLearnerNode(id, true)
+}
+
+// addNodeOrLearnerNode adds the given node as a voter or a learner. Adding
+// an existing learner as a voter promotes it. A voter is never demoted to
+// a learner.
+func (r *raft) addNodeOrLearnerNode(id uint64, isLearner bool) {
+	r.pendingConf = false
+	pr, ok := r.prs[id]
+	if !ok {
+		r.setProgress(id, 0, r.raftLog.lastIndex()+1, isLearner)
+		return
+	}
+	if isLearner && !pr.IsLearner {
+		raftLogger.Infof("%x ignored addLearner request because %x is already a voter", r.id, id)
+		return
+	}
+	if isLearner == pr.IsLearner {
 		// Ignore any redundant addNode calls (which can happen because the
 		// initial bootstrapping entries are applied twice).
 		return
 	}
-
-	r.setProgress(id, 0, r.raftLog.lastIndex()+1)
-	r.pendingConf = false
+	// promote the learner to a voter. Its progress is kept, so that the
+	// leader does not need to probe it again.
+	pr.IsLearner = false
+	raftLogger.Infof("%x promoted learner %x to voter", r.id, id)
 }
 
 func (r *raft) removeNode(id uint64) {
 	r.delProgress(id)
+	delete(r.incoming, id)
+	delete(r.outgoing, id)
+	if r.isJoint() && len(r.outgoing) == 0 {
+		// the outgoing configuration has no voter left, so only the
+		// incoming one decides.
+		r.incoming, r.outgoing = nil, nil
+		raftLogger.Infof("%x left joint consensus after removing the last outgoing voter %x [voters: %v]", r.id, id, r.nodes())
+	}
 	r.pendingConf = false
+	if r.leadTransferee == id {
+		r.abortLeaderTransfer()
+	}
+}
+
+// applyConfChangeV2 applies a joint configuration change. A change with
+// Changes enters the joint consensus of the current and the new voters; an
+// empty change leaves it for the new voters. The leader proposes the empty
+// change as soon as the cluster enters the joint consensus.
+func (r *raft) applyConfChangeV2(cc pb.ConfChangeV2) {
+	r.pendingConf = false
+	if len(cc.Changes) == 0 {
+		r.leaveJoint()
+		return
+	}
+	r.enterJoint(cc.Changes)
+}
+
+func (r *raft) enterJoint(changes []pb.ConfChangeSingle) {
+	if r.isJoint() {
+		raftLogger.Warningf("%x ignored joint configuration change because it is already in joint consensus", r.id)
+		return
+	}
+	incoming, outgoing := make(map[uint64]struct{}), make(map[uint64]struct{})
+	for _, id := range r.nodes() {
+		incoming[id] = struct{}{}
+		outgoing[id] = struct{}{}
+	}
+	for _, c := range changes {
+		switch c.Type {
+		case pb.ConfChangeAddNode:
+			incoming[c.NodeID] = struct{}{}
+		case pb.ConfChangeRemoveNode:
+			delete(incoming, c.NodeID)
+		default:
+			raftLogger.Warningf("%x ignored unsupported %s of %x in joint configuration change", r.id, c.Type, c.NodeID)
+		}
+	}
+	if len(incoming) == 0 {
+		raftLogger.Warningf("%x ignored joint configuration change that removes all voters", r.id)
+		return
+	}
+
+	for id := range incoming {
+		pr, ok := r.prs[id]
+		if !ok {
+			r.setProgress(id, 0, r.raftLog.lastIndex()+1, false)
+			continue
+		}
+		if pr.IsLearner {
+			pr.IsLearner = false
+			raftLogger.Infof("%x promoted learner %x to voter", r.id, id)
+		}
+	}
+	// learners do not vote in either configuration, so removed learners
+	// are dropped immediately.
+	for _, c := range changes {
+		if pr, ok := r.prs[c.NodeID]; ok && pr.IsLearner && c.Type == pb.ConfChangeRemoveNode {
+			r.delProgress(c.NodeID)
+		}
+	}
+	r.incoming, r.outgoing = incoming, outgoing
+	raftLogger.Infof("%x entered joint consensus [incoming: %v, outgoing: %v]", r.id, r.nodes(), r.outgoingNodes())
+
+	if r.state == StateLeader {
+		r.appendLeaveJoint()
+		r.bcastAppend()
+	}
+}
+
+// appendLeaveJoint appends the empty configuration change that makes the
+// cluster leave the joint consensus once it is applied.
+func (r *raft) appendLeaveJoint() {
+	data, err := (&pb.ConfChangeV2{}).Marshal()
+	if err != nil {
+		raftLogger.Panicf("%x unexpected marshal error (%v)", r.id, err)
+	}
+	r.appendEntry(pb.Entry{Type: pb.EntryConfChangeV2, Data: data})
+	r.pendingConf = true
+}
+
+func (r *raft) leaveJoint() {
+	if !r.isJoint() {
+		raftLogger.Warningf("%x ignored request to leave joint consensus because it is not in joint consensus", r.id)
+		return
+	}
+	for id := range r.outgoing {
+		if _, ok := r.incoming[id]; ok {
+			continue
+		}
+		r.delProgress(id)
+		if r.leadTransferee == id {
+			r.abortLeaderTransfer()
+		}
+	}
+	r.incoming, r.outgoing = nil, nil
+	raftLogger.Infof("%x left joint consensus [voters: %v]", r.id, r.nodes())
+
+	// the quorum of the new configuration might have replicated entries
+	// that the joint quorum has not.
+	if _, ok := r.prs[r.id]; ok && r.state == StateLeader {
+		if r.maybeCommit() {
+			r.bcastAppend()
+		}
+	}
 }
 
 func (r *raft) resetPendingConf() { r.pendingConf = false }
 
-func (r *raft) setProgress(id, match, next uint64) {
-	r.prs[id] = &Progress{Next: next, Match: match, ins: newInflights(r.maxInflight)}
+func (r *raft) setProgress(id, match, next uint64, isLearner bool) {
+	r.prs[id] = &Progress{Next: next, Match: match, ins: newInflights(r.maxInflight), IsLearner: isLearner}
 }
 
 func (r *raft) delProgress(id uint64) {
@@ -774,3 +1493,31 @@ func (r *raft) isElectionTimeout() bool {
 	}
 	return d > r.rand.Int()%r.electionTimeout
 }
+
+// sendTimeoutNow asks the given node to start an election immediately.
+func (r *raft) sendTimeoutNow(to uint64) {
+	r.send(pb.Message{To: to, Type: pb.MsgTimeoutNow})
+}
+
+func (r *raft) abortLeaderTransfer() {
+	r.leadTransferee = None
+	r.transferElapsed = 0
+}
+
+// checkQuorumActive returns true if a quorum of the cluster, including the
+// leader itself, has been active since the last check. It resets the
+// recent activity of the followers for the next check.
+func (r *raft) checkQuorumActive() bool {
+	act := make(map[uint64]bool)
+	for id, pr := range r.prs {
+		if id == r.id {
+			act[id] = true
+			continue
+		}
+		if pr.RecentActive && !pr.IsLearner {
+			act[id] = true
+		}
+		pr.RecentActive = false
+	}
+	return r.hasQuorum(act)
+}
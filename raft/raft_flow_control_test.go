@@ -153,3 +153,35 @@ func TestMsgAppFlowControlRecvHeartbeat(t *testing.T) {
 		r.readMessages()
 	}
 }
+
+// TestStatusInflightCount ensures the status reports the number of inflight
+// msgApps of each follower, and that the reported progress is not changed
+// by the following msgAppResps.
+func TestStatusInflightCount(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2}, 5, 1, NewMemoryStorage())
+	r.becomeCandidate()
+	r.becomeLeader()
+
+	pr2 := r.prs[2]
+	// force the progress to be in replicate state
+	pr2.becomeReplicate()
+	for i := 0; i < r.maxInflight; i++ {
+		r.Step(pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{Data: []byte("somedata")}}})
+		r.readMessages()
+	}
+
+	st := getStatus(r)
+	spr2 := st.Progress[2]
+	if g := spr2.InflightCount(); g != r.maxInflight {
+		t.Fatalf("inflight count = %d, want %d", g, r.maxInflight)
+	}
+
+	// free all inflights
+	r.Step(pb.Message{From: 2, To: 1, Type: pb.MsgAppResp, Index: r.raftLog.lastIndex()})
+	if g := pr2.InflightCount(); g != 0 {
+		t.Errorf("inflight count = %d, want 0", g)
+	}
+	if g := spr2.InflightCount(); g != r.maxInflight {
+		t.Errorf("status inflight count = %d, want %d", g, r.maxInflight)
+	}
+}
@@ -79,8 +79,9 @@ func testUpdateTermFromMessage(t *testing.T, state StateType) {
 // Reference: section 5.1
 func TestRejectStaleTermMessage(t *testing.T) {
 	called := false
-	fakeStep := func(r *raft, m pb.Message) {
+	fakeStep := func(r *raft, m pb.Message) error {
 		called = true
+		return nil
 	}
 	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
 	r.step = fakeStep
@@ -183,6 +184,58 @@ func testNonleaderStartElection(t *testing.T, state StateType) {
 	}
 }
 
+func TestFollowerStartPreElection(t *testing.T) {
+	testNonleaderStartPreElection(t, StateFollower)
+}
+func TestPreCandidateStartNewPreElection(t *testing.T) {
+	testNonleaderStartPreElection(t, StatePreCandidate)
+}
+
+// testNonleaderStartPreElection tests that if a server with PreVote enabled
+// receives no communication over election timeout, it begins the pre-vote
+// phase of an election instead. It transitions to pre-candidate state
+// without incrementing its current term, and issues PreVote RPCs for the
+// next term to each of the other servers in the cluster.
+// Reference: section 9.6 of the raft thesis
+func testNonleaderStartPreElection(t *testing.T, state StateType) {
+	// election timeout
+	et := 10
+	r := newPreVoteTestRaft(1, []uint64{1, 2, 3}, et, 1, NewMemoryStorage())
+	switch state {
+	case StateFollower:
+		r.becomeFollower(1, 2)
+	case StatePreCandidate:
+		r.becomeFollower(1, None)
+		r.becomePreCandidate()
+	}
+
+	for i := 0; i < 2*et; i++ {
+		r.tick()
+	}
+
+	if r.Term != 1 {
+		t.Errorf("term = %d, want 1", r.Term)
+	}
+	if r.state != StatePreCandidate {
+		t.Errorf("state = %s, want %s", r.state, StatePreCandidate)
+	}
+	if r.votes[r.id] != true {
+		t.Errorf("vote for self = false, want true")
+	}
+	if r.Vote != None {
+		t.Errorf("vote = %x, want none", r.Vote)
+	}
+	msgs := r.readMessages()
+	sort.Sort(messageSlice(msgs))
+	wmsgs := []pb.Message{
+		{From: 1, To: 2, Term: 2, Type: pb.MsgPreVote},
+		{From: 1, To: 3, Term: 2, Type: pb.MsgPreVote},
+	}
+	if !reflect.DeepEqual(msgs, wmsgs) {
+		t.Errorf("msgs = %v, want %v", msgs, wmsgs)
+	}
+}
+
 // TestLeaderElectionInOneRoundRPC tests all cases that may happen in
 // leader election during one round of RequestVote RPC:
 // a) it wins the election
@@ -231,6 +284,60 @@ func TestLeaderElectionInOneRoundRPC(t *testing.T) {
 	}
 }
 
+// TestPreVoteInOneRoundRPC tests all cases that may happen in the pre-vote
+// phase of an election during one round of PreVote RPC:
+// a) it wins the pre-vote phase, and starts the election of the next term
+// b) it loses the pre-vote phase, and returns to follower state
+// c) it is unclear about the result
+// Reference: section 9.6 of the raft thesis
+func TestPreVoteInOneRoundRPC(t *testing.T) {
+	tests := []struct {
+		size  int
+		votes map[uint64]bool
+		state StateType
+		term  uint64
+	}{
+		// start the election when receiving pre-votes from a majority of
+		// the servers
+		{1, map[uint64]bool{}, StateLeader, 1},
+		{3, map[uint64]bool{2: true, 3: true}, StateCandidate, 1},
+		{3, map[uint64]bool{2: true}, StateCandidate, 1},
+		{5, map[uint64]bool{2: true, 3: true, 4: true, 5: true}, StateCandidate, 1},
+		{5, map[uint64]bool{2: true, 3: true}, StateCandidate, 1},
+
+		// return to follower state if it receives pre-vote denial from a
+		// majority
+		{3, map[uint64]bool{2: false, 3: false}, StateFollower, 0},
+		{5, map[uint64]bool{2: true, 3: false, 4: false, 5: false}, StateFollower, 0},
+
+		// stay in pre-candidate if it does not obtain the majority
+		{3, map[uint64]bool{}, StatePreCandidate, 0},
+		{5, map[uint64]bool{2: true}, StatePreCandidate, 0},
+		{5, map[uint64]bool{2: false, 3: false}, StatePreCandidate, 0},
+	}
+	for i, tt := range tests {
+		r := newPreVoteTestRaft(1, idsBySize(tt.size), 10, 1, NewMemoryStorage())
+
+		r.Step(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+		for id, vote := range tt.votes {
+			// a granted pre-vote carries the term of the requested election,
+			// and a rejection carries the current term of the voter.
+			m := pb.Message{From: id, To: 1, Term: 1, Type: pb.MsgPreVoteResp}
+			if !vote {
+				m.Term, m.Reject = 0, true
+			}
+			r.Step(m)
+		}
+
+		if r.state != tt.state {
+			t.Errorf("#%d: state = %s, want %s", i, r.state, tt.state)
+		}
+		if g := r.Term; g != tt.term {
+			t.Errorf("#%d: term = %d, want %d", i, g, tt.term)
+		}
+	}
+}
+
 // TestFollowerVote tests that each follower will vote for at most one
 // candidate in a given term, on a first-come-first-served basis.
 // Reference: section 5.2
@@ -263,6 +370,45 @@ func TestFollowerVote(t *testing.T) {
 	}
 }
 
+// TestFollowerPreVote tests that a follower grants a pre-vote regardless of
+// the vote of its current term, unless it has heard from the leader within
+// the election timeout. Granting a pre-vote changes neither its term nor
+// its vote.
+// Reference: section 9.6 of the raft thesis
+func TestFollowerPreVote(t *testing.T) {
+	tests := []struct {
+		vote    uint64
+		lead    uint64
+		wreject bool
+	}{
+		{None, None, false},
+		{3, None, false},
+		{None, 3, true},
+		{3, 3, true},
+	}
+	for i, tt := range tests {
+		r := newPreVoteTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+		r.loadState(pb.HardState{Term: 1, Vote: tt.vote})
+		r.lead = tt.lead
+
+		r.Step(pb.Message{From: 2, To: 1, Term: 2, Type: pb.MsgPreVote})
+
+		msgs := r.readMessages()
+		wmsgs := []pb.Message{
+			{From: 1, To: 2, Term: 2, Type: pb.MsgPreVoteResp},
+		}
+		if tt.wreject {
+			wmsgs[0].Term, wmsgs[0].Reject = 1, true
+		}
+		if !reflect.DeepEqual(msgs, wmsgs) {
+			t.Errorf("#%d: msgs = %v, want %v", i, msgs, wmsgs)
+		}
+		if r.Term != 1 || r.Vote != tt.vote {
+			t.Errorf("#%d: term, vote = %d, %x, want %d, %x", i, r.Term, r.Vote, 1, tt.vote)
+		}
+	}
+}
+
 // TestCandidateFallback tests that while waiting for votes,
 // if a candidate receives an AppendEntries RPC from another server claiming
 // to be leader whose term is at least as large as the candidate's current term,
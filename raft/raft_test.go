@@ -317,6 +317,265 @@ func TestLeaderElection(t *testing.T) {
 	}
 }
 
+func TestLeaderElectionPreVote(t *testing.T) {
+	tests := []struct {
+		*network
+		state StateType
+		term  uint64
+	}{
+		{newPreVoteNetwork(nil, nil, nil), StateLeader, 1},
+		{newPreVoteNetwork(nil, nil, nopStepper), StateLeader, 1},
+		{newPreVoteNetwork(nil, nopStepper, nopStepper), StatePreCandidate, 0},
+		{newPreVoteNetwork(nil, nopStepper, nopStepper, nil), StatePreCandidate, 0},
+		{newPreVoteNetwork(nil, nopStepper, nopStepper, nil, nil), StateLeader, 1},
+
+		// three logs further along than 0, but in the pre-vote phase
+		{newPreVoteNetwork(nil, ents(1), ents(2), ents(1, 3), nil), StateFollower, 0},
+
+		// logs converge
+		{newPreVoteNetwork(ents(1), nil, ents(2), ents(1), nil), StateLeader, 1},
+	}
+
+	for i, tt := range tests {
+		tt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+		sm := tt.network.peers[1].(*raft)
+		if sm.state != tt.state {
+			t.Errorf("#%d: state = %s, want %s", i, sm.state, tt.state)
+		}
+		if g := sm.Term; g != tt.term {
+			t.Errorf("#%d: term = %d, want %d", i, g, tt.term)
+		}
+	}
+}
+
+// TestPreVoteFromPartitionedNode tests that a node rejoining the cluster
+// after a partition does not disrupt the leader with a higher term when
+// PreVote is enabled.
+func TestPreVoteFromPartitionedNode(t *testing.T) {
+	nt := newPreVoteNetwork(nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+
+	nt.isolate(3)
+	for i := 0; i < 5; i++ {
+		nt.send(pb.Message{From: 3, To: 3, Type: pb.MsgHup})
+	}
+	c := nt.peers[3].(*raft)
+	if c.state != StatePreCandidate || c.Term != 1 {
+		t.Fatalf("node 3: state, term = %s, %d, want %s, %d", c.state, c.Term, StatePreCandidate, 1)
+	}
+
+	nt.recover()
+	nt.send(pb.Message{From: 3, To: 3, Type: pb.MsgHup})
+
+	tests := []struct {
+		id    uint64
+		state StateType
+	}{
+		{1, StateLeader},
+		{2, StateFollower},
+		{3, StateFollower},
+	}
+	for i, tt := range tests {
+		sm := nt.peers[tt.id].(*raft)
+		if sm.state != tt.state {
+			t.Errorf("#%d: state = %s, want %s", i, sm.state, tt.state)
+		}
+		if sm.Term != 1 {
+			t.Errorf("#%d: term = %d, want 1", i, sm.Term)
+		}
+	}
+}
+
+// TestRecvMsgPreVoteStaleTerm tests that a pre-vote request with a stale
+// term is rejected with the current term, so that the pre-candidate can
+// catch up.
+func TestRecvMsgPreVoteStaleTerm(t *testing.T) {
+	r := newPreVoteTestRaft(1, []uint64{1, 2}, 10, 1, NewMemoryStorage())
+	r.loadState(pb.HardState{Term: 3})
+
+	r.Step(pb.Message{From: 2, To: 1, Term: 2, Type: pb.MsgPreVote})
+
+	msgs := r.readMessages()
+	wmsgs := []pb.Message{{From: 1, To: 2, Term: 3, Type: pb.MsgPreVoteResp, Reject: true}}
+	if !reflect.DeepEqual(msgs, wmsgs) {
+		t.Fatalf("msgs = %v, want %v", msgs, wmsgs)
+	}
+
+	p := newPreVoteTestRaft(2, []uint64{1, 2}, 10, 1, NewMemoryStorage())
+	p.loadState(pb.HardState{Term: 1})
+	p.becomePreCandidate()
+	p.Step(msgs[0])
+	if p.state != StateFollower || p.Term != 3 {
+		t.Errorf("state, term = %s, %d, want %s, %d", p.state, p.Term, StateFollower, 3)
+	}
+	// the node that rejected the pre-vote is not the leader.
+	if p.lead != None {
+		t.Errorf("lead = %x, want %x", p.lead, None)
+	}
+}
+
+// TestRecvMsgAppStaleTerm tests that a message from a stale leader is
+// answered with the higher term when CheckQuorum or PreVote is enabled,
+// which could otherwise keep the node from advancing the term of the
+// others, and ignored otherwise.
+func TestRecvMsgAppStaleTerm(t *testing.T) {
+	tests := []struct {
+		checkQuorum, preVote bool
+		mt                   pb.MessageType
+
+		wmsgs []pb.Message
+	}{
+		{false, false, pb.MsgApp, nil},
+		{false, false, pb.MsgHeartbeat, nil},
+		{true, false, pb.MsgApp, []pb.Message{{From: 1, To: 2, Term: 3, Type: pb.MsgAppResp}}},
+		{true, false, pb.MsgHeartbeat, []pb.Message{{From: 1, To: 2, Term: 3, Type: pb.MsgAppResp}}},
+		{false, true, pb.MsgApp, []pb.Message{{From: 1, To: 2, Term: 3, Type: pb.MsgAppResp}}},
+		{false, true, pb.MsgHeartbeat, []pb.Message{{From: 1, To: 2, Term: 3, Type: pb.MsgAppResp}}},
+	}
+	for i, tt := range tests {
+		r := newTestRaft(1, []uint64{1, 2}, 10, 1, NewMemoryStorage())
+		r.checkQuorum, r.preVote = tt.checkQuorum, tt.preVote
+		r.loadState(pb.HardState{Term: 3})
+
+		r.Step(pb.Message{From: 2, To: 1, Term: 2, Type: tt.mt})
+
+		if msgs := r.readMessages(); !reflect.DeepEqual(msgs, tt.wmsgs) {
+			t.Errorf("#%d: msgs = %v, want %v", i, msgs, tt.wmsgs)
+		}
+		if r.Term != 3 {
+			t.Errorf("#%d: term = %d, want 3", i, r.Term)
+		}
+	}
+}
+
+// TestLeaderStepdownWhenQuorumActive tests that the leader with CheckQuorum
+// stays the leader while a quorum keeps responding to it.
+func TestLeaderStepdownWhenQuorumActive(t *testing.T) {
+	sm := newTestRaft(1, []uint64{1, 2, 3}, 5, 1, NewMemoryStorage())
+	sm.checkQuorum = true
+
+	sm.becomeCandidate()
+	sm.becomeLeader()
+
+	for i := 0; i < sm.electionTimeout+1; i++ {
+		sm.Step(pb.Message{From: 2, Type: pb.MsgHeartbeatResp, Term: sm.Term})
+		sm.tick()
+	}
+
+	if sm.state != StateLeader {
+		t.Errorf("state = %v, want %v", sm.state, StateLeader)
+	}
+}
+
+// TestLeaderStepdownWhenQuorumLost tests that the leader with CheckQuorum
+// steps down when it has not heard from a quorum within an election
+// timeout.
+func TestLeaderStepdownWhenQuorumLost(t *testing.T) {
+	sm := newTestRaft(1, []uint64{1, 2, 3}, 5, 1, NewMemoryStorage())
+	sm.checkQuorum = true
+
+	sm.becomeCandidate()
+	sm.becomeLeader()
+
+	for i := 0; i < sm.electionTimeout+1; i++ {
+		sm.tick()
+	}
+
+	if sm.state != StateFollower {
+		t.Errorf("state = %v, want %v", sm.state, StateFollower)
+	}
+}
+
+// TestLeaderSupersedingWithCheckQuorum tests that a follower with
+// CheckQuorum ignores the vote requests while the leader lease is not
+// expired, and votes again after an election timeout without hearing
+// from the leader.
+func TestLeaderSupersedingWithCheckQuorum(t *testing.T) {
+	nt := newCheckQuorumNetwork(nil, nil, nil)
+	a := nt.peers[1].(*raft)
+	b := nt.peers[2].(*raft)
+	c := nt.peers[3].(*raft)
+
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+	if a.state != StateLeader {
+		t.Fatalf("state = %s, want %s", a.state, StateLeader)
+	}
+	if c.state != StateFollower {
+		t.Fatalf("state = %s, want %s", c.state, StateFollower)
+	}
+
+	nt.send(pb.Message{From: 3, To: 3, Type: pb.MsgHup})
+	// the peers ignore the vote request of c as their lease is not expired.
+	if c.state != StateCandidate {
+		t.Errorf("state = %s, want %s", c.state, StateCandidate)
+	}
+
+	for i := 0; i < b.electionTimeout; i++ {
+		b.tick()
+	}
+	nt.send(pb.Message{From: 3, To: 3, Type: pb.MsgHup})
+	if c.state != StateLeader {
+		t.Errorf("state = %s, want %s", c.state, StateLeader)
+	}
+}
+
+// TestFreeStuckCandidateWithCheckQuorum tests that a candidate whose term
+// was increased while it was isolated makes the stale leader step down
+// after the partition heals, so that the cluster can elect a new leader.
+func TestFreeStuckCandidateWithCheckQuorum(t *testing.T) {
+	nt := newCheckQuorumNetwork(nil, nil, nil)
+	a := nt.peers[1].(*raft)
+	b := nt.peers[2].(*raft)
+	c := nt.peers[3].(*raft)
+
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+
+	nt.isolate(1)
+	nt.send(pb.Message{From: 3, To: 3, Type: pb.MsgHup})
+	if b.state != StateFollower {
+		t.Errorf("state = %s, want %s", b.state, StateFollower)
+	}
+	if c.state != StateCandidate {
+		t.Errorf("state = %s, want %s", c.state, StateCandidate)
+	}
+	if c.Term != b.Term+1 {
+		t.Errorf("term = %d, want %d", c.Term, b.Term+1)
+	}
+
+	nt.recover()
+	// the heartbeat of the stale leader is answered with the higher term.
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgBeat})
+	if a.state != StateFollower {
+		t.Errorf("state = %s, want %s", a.state, StateFollower)
+	}
+	if c.Term != a.Term {
+		t.Errorf("term = %d, want %d", c.Term, a.Term)
+	}
+	// the responders are not the leader of the higher term.
+	if a.lead != None {
+		t.Errorf("lead = %x, want %x", a.lead, None)
+	}
+
+	for i := 0; i < b.electionTimeout; i++ {
+		b.tick()
+	}
+	nt.send(pb.Message{From: 3, To: 3, Type: pb.MsgHup})
+	if c.state != StateLeader {
+		t.Errorf("state = %s, want %s", c.state, StateLeader)
+	}
+}
+
+// TestLeaderTransferWithCheckQuorum tests that the vote requests of a
+// leader transfer override the leader lease of the voters.
+func TestLeaderTransferWithCheckQuorum(t *testing.T) {
+	nt := newCheckQuorumNetwork(nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+
+	lead := nt.peers[1].(*raft)
+	nt.send(pb.Message{From: 2, To: 1, Type: pb.MsgTransferLeader})
+	checkLeaderTransferState(t, lead, StateFollower, 2)
+}
+
 func TestLogReplication(t *testing.T) {
 	tests := []struct {
 		*network
@@ -703,6 +962,55 @@ func TestProposalByProxy(t *testing.T) {
 	}
 }
 
+// TestProposalDropped ensures that a proposal is dropped with
+// ErrProposalDropped when there is no leader, when the leader is
+// transferring its leadership, and when the follower does not forward
+// proposals; otherwise a follower forwards the proposal to the leader.
+func TestProposalDropped(t *testing.T) {
+	prop := pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{Data: []byte("somedata")}}}
+
+	// a follower without leader
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	if err := r.Step(prop); err != ErrProposalDropped {
+		t.Errorf("follower without leader: err = %v, want %v", err, ErrProposalDropped)
+	}
+
+	// a candidate
+	r.becomeCandidate()
+	if err := r.Step(prop); err != ErrProposalDropped {
+		t.Errorf("candidate: err = %v, want %v", err, ErrProposalDropped)
+	}
+
+	// a leader transferring its leadership
+	r.becomeLeader()
+	r.leadTransferee = 2
+	if err := r.Step(prop); err != ErrProposalDropped {
+		t.Errorf("leader in transfer: err = %v, want %v", err, ErrProposalDropped)
+	}
+
+	tests := []struct {
+		disableForwarding bool
+
+		werr  error
+		wmsgs int
+	}{
+		{false, nil, 1},
+		{true, ErrProposalDropped, 0},
+	}
+	for i, tt := range tests {
+		c := newTestConfig(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+		c.DisableProposalForwarding = tt.disableForwarding
+		r := newRaft(c)
+		r.becomeFollower(1, 2)
+		if err := r.Step(prop); err != tt.werr {
+			t.Errorf("#%d: err = %v, want %v", i, err, tt.werr)
+		}
+		if msgs := r.readMessages(); len(msgs) != tt.wmsgs {
+			t.Errorf("#%d: len(msgs) = %d, want %d", i, len(msgs), tt.wmsgs)
+		}
+	}
+}
+
 func TestCommit(t *testing.T) {
 	tests := []struct {
 		matches []uint64
@@ -738,7 +1046,7 @@ func TestCommit(t *testing.T) {
 
 		sm := newTestRaft(1, []uint64{1}, 5, 1, storage)
 		for j := 0; j < len(tt.matches); j++ {
-			sm.setProgress(uint64(j)+1, tt.matches[j], tt.matches[j]+1)
+			sm.setProgress(uint64(j)+1, tt.matches[j], tt.matches[j]+1, false)
 		}
 		sm.maybeCommit()
 		if g := sm.raftLog.committed; g != tt.w {
@@ -783,8 +1091,9 @@ func TestIsElectionTimeout(t *testing.T) {
 // acutal stepX function.
 func TestStepIgnoreOldTermMsg(t *testing.T) {
 	called := false
-	fakeStep := func(r *raft, m pb.Message) {
+	fakeStep := func(r *raft, m pb.Message) error {
 		called = true
+		return nil
 	}
 	sm := newTestRaft(1, []uint64{1}, 10, 1, NewMemoryStorage())
 	sm.step = fakeStep
@@ -1493,6 +1802,29 @@ func TestRestore(t *testing.T) {
 	}
 }
 
+// TestRestoreWithLearner tests that restore recovers the learners from
+// the ConfState of the snapshot.
+func TestRestoreWithLearner(t *testing.T) {
+	s := pb.Snapshot{
+		Metadata: pb.SnapshotMetadata{
+			Index:     11, // magic number
+			Term:      11, // magic number
+			ConfState: pb.ConfState{Nodes: []uint64{1, 2}, Learners: []uint64{3}},
+		},
+	}
+
+	sm := newTestLearnerRaft(3, []uint64{1, 2}, []uint64{3}, 10, 1, NewMemoryStorage())
+	if ok := sm.restore(s); !ok {
+		t.Fatal("restore fail, want succeed")
+	}
+	if g := sm.confState(); !reflect.DeepEqual(g, s.Metadata.ConfState) {
+		t.Errorf("confState = %+v, want %+v", g, s.Metadata.ConfState)
+	}
+	if sm.promotable() {
+		t.Errorf("promotable = true, want false")
+	}
+}
+
 func TestRestoreIgnoreSnapshot(t *testing.T) {
 	previousEnts := []pb.Entry{{Term: 1, Index: 1}, {Term: 1, Index: 2}, {Term: 1, Index: 3}}
 	commit := uint64(1)
@@ -1618,8 +1950,8 @@ func TestStepConfig(t *testing.T) {
 }
 
 // TestStepIgnoreConfig tests that if raft step the second msgProp in
-// EntryConfChange type when the first one is uncommitted, the node will set
-// the proposal to noop and keep its original state.
+// EntryConfChange type when the first one is uncommitted, the node will drop
+// the proposal with ErrConfChangeInProgress and keep its original state.
 func TestStepIgnoreConfig(t *testing.T) {
 	// a raft that cannot make progress
 	r := newTestRaft(1, []uint64{1, 2}, 10, 1, NewMemoryStorage())
@@ -1628,20 +1960,37 @@ func TestStepIgnoreConfig(t *testing.T) {
 	r.Step(pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{Type: pb.EntryConfChange}}})
 	index := r.raftLog.lastIndex()
 	pendingConf := r.pendingConf
-	r.Step(pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{Type: pb.EntryConfChange}}})
-	wents := []pb.Entry{{Type: pb.EntryNormal, Term: 1, Index: 3, Data: nil}}
-	ents, err := r.raftLog.entries(index+1, noLimit)
-	if err != nil {
-		t.Fatalf("unexpected error %v", err)
+	err := r.Step(pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{Type: pb.EntryConfChange}}})
+	if err != ErrConfChangeInProgress {
+		t.Errorf("err = %v, want %v", err, ErrConfChangeInProgress)
 	}
-	if !reflect.DeepEqual(ents, wents) {
-		t.Errorf("ents = %+v, want %+v", ents, wents)
+	if g := r.raftLog.lastIndex(); g != index {
+		t.Errorf("index = %d, want %d", g, index)
 	}
 	if r.pendingConf != pendingConf {
 		t.Errorf("pendingConf = %v, want %v", r.pendingConf, pendingConf)
 	}
 }
 
+// TestStepIgnoreConfigInSameProposal tests that a proposal of two
+// configuration changes is dropped as a whole.
+func TestStepIgnoreConfigInSameProposal(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2}, 10, 1, NewMemoryStorage())
+	r.becomeCandidate()
+	r.becomeLeader()
+	index := r.raftLog.lastIndex()
+	err := r.Step(pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{Type: pb.EntryConfChange}, {Type: pb.EntryConfChangeV2}}})
+	if err != ErrConfChangeInProgress {
+		t.Errorf("err = %v, want %v", err, ErrConfChangeInProgress)
+	}
+	if g := r.raftLog.lastIndex(); g != index {
+		t.Errorf("index = %d, want %d", g, index)
+	}
+	if r.pendingConf {
+		t.Errorf("pendingConf = true, want false")
+	}
+}
+
 // TestRecoverPendingConfig tests that new leader recovers its pendingConf flag
 // based on uncommitted entries.
 func TestRecoverPendingConfig(t *testing.T) {
@@ -1710,6 +2059,341 @@ func TestRemoveNode(t *testing.T) {
 	}
 }
 
+// TestAddLearner tests that addLearner adds a learner that does not count
+// toward the quorum, and that addNode promotes the learner to a voter.
+func TestAddLearner(t *testing.T) {
+	r := newTestRaft(1, []uint64{1}, 10, 1, NewMemoryStorage())
+	r.pendingConf = true
+	r.addLearner(2)
+	if r.pendingConf != false {
+		t.Errorf("pendingConf = %v, want false", r.pendingConf)
+	}
+	if g, w := r.nodes(), []uint64{1}; !reflect.DeepEqual(g, w) {
+		t.Errorf("nodes = %v, want %v", g, w)
+	}
+	if g, w := r.learnerNodes(), []uint64{2}; !reflect.DeepEqual(g, w) {
+		t.Errorf("learnerNodes = %v, want %v", g, w)
+	}
+	if g := r.q(); g != 1 {
+		t.Errorf("q = %d, want 1", g)
+	}
+
+	r.addNode(2)
+	if g, w := r.nodes(), []uint64{1, 2}; !reflect.DeepEqual(g, w) {
+		t.Errorf("nodes = %v, want %v", g, w)
+	}
+	if g := r.learnerNodes(); len(g) != 0 {
+		t.Errorf("learnerNodes = %v, want []", g)
+	}
+
+	// a voter is never demoted to a learner.
+	r.addLearner(2)
+	if g, w := r.nodes(), []uint64{1, 2}; !reflect.DeepEqual(g, w) {
+		t.Errorf("nodes = %v, want %v", g, w)
+	}
+}
+
+// TestLearnerElectionTimeout tests that a learner does not start an
+// election after the election timeout.
+func TestLearnerElectionTimeout(t *testing.T) {
+	n := newTestLearnerRaft(2, []uint64{1}, []uint64{2}, 10, 1, NewMemoryStorage())
+	for i := 0; i < 2*n.electionTimeout; i++ {
+		n.tick()
+	}
+	if n.state != StateFollower {
+		t.Errorf("state = %s, want %s", n.state, StateFollower)
+	}
+
+	n.Step(pb.Message{From: 2, To: 2, Type: pb.MsgHup})
+	if n.state != StateFollower {
+		t.Errorf("state = %s, want %s", n.state, StateFollower)
+	}
+}
+
+// TestLearnerLogReplication tests that a learner receives the replicated
+// log, but the leader commits entries without its acknowledgment.
+func TestLearnerLogReplication(t *testing.T) {
+	n1 := newTestLearnerRaft(1, []uint64{1}, []uint64{2}, 10, 1, NewMemoryStorage())
+	n2 := newTestLearnerRaft(2, []uint64{1}, []uint64{2}, 10, 1, NewMemoryStorage())
+	nt := newNetwork(n1, n2)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+	if n1.state != StateLeader {
+		t.Fatalf("state = %s, want %s", n1.state, StateLeader)
+	}
+
+	nt.isolate(2)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{Data: []byte("somedata")}}})
+	if g, w := n1.raftLog.committed, n1.raftLog.lastIndex(); g != w {
+		t.Errorf("leader committed = %d, want %d", g, w)
+	}
+
+	nt.recover()
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgBeat})
+	if g, w := n2.raftLog.committed, n1.raftLog.committed; g != w {
+		t.Errorf("learner committed = %d, want %d", g, w)
+	}
+	if g, w := n1.prs[2].Match, n2.raftLog.lastIndex(); g != w {
+		t.Errorf("learner match = %d, want %d", g, w)
+	}
+}
+
+// TestLearnerNotQuorum tests that the acknowledgments of a learner do not
+// count toward the quorum of a cluster with several voters.
+func TestLearnerNotQuorum(t *testing.T) {
+	n1 := newTestLearnerRaft(1, []uint64{1, 2}, []uint64{3}, 10, 1, NewMemoryStorage())
+	n2 := newTestLearnerRaft(2, []uint64{1, 2}, []uint64{3}, 10, 1, NewMemoryStorage())
+	n3 := newTestLearnerRaft(3, []uint64{1, 2}, []uint64{3}, 10, 1, NewMemoryStorage())
+	nt := newNetwork(n1, n2, n3)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+	if n1.state != StateLeader {
+		t.Fatalf("state = %s, want %s", n1.state, StateLeader)
+	}
+
+	nt.isolate(2)
+	committed := n1.raftLog.committed
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{Data: []byte("somedata")}}})
+	if n1.raftLog.committed != committed {
+		t.Errorf("committed = %d, want %d", n1.raftLog.committed, committed)
+	}
+	if g, w := n1.prs[3].Match, n1.raftLog.lastIndex(); g != w {
+		t.Errorf("learner match = %d, want %d", g, w)
+	}
+}
+
+// TestLearnerPromotion tests that a promoted learner can be elected.
+func TestLearnerPromotion(t *testing.T) {
+	n1 := newTestLearnerRaft(1, []uint64{1}, []uint64{2}, 10, 1, NewMemoryStorage())
+	n2 := newTestLearnerRaft(2, []uint64{1}, []uint64{2}, 10, 1, NewMemoryStorage())
+	nt := newNetwork(n1, n2)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+	// replicate the log to the learner
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgBeat})
+
+	n1.addNode(2)
+	n2.addNode(2)
+	if n2.prs[2].IsLearner {
+		t.Fatalf("node 2 is still a learner")
+	}
+
+	nt.send(pb.Message{From: 2, To: 2, Type: pb.MsgHup})
+	if n2.state != StateLeader {
+		t.Errorf("state = %s, want %s", n2.state, StateLeader)
+	}
+	if n1.state != StateFollower {
+		t.Errorf("state = %s, want %s", n1.state, StateFollower)
+	}
+}
+
+// TestLeaderTransferToLearner tests that the leadership is not
+// transferred to a learner.
+func TestLeaderTransferToLearner(t *testing.T) {
+	n1 := newTestLearnerRaft(1, []uint64{1}, []uint64{2}, 10, 1, NewMemoryStorage())
+	n2 := newTestLearnerRaft(2, []uint64{1}, []uint64{2}, 10, 1, NewMemoryStorage())
+	nt := newNetwork(n1, n2)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+
+	nt.send(pb.Message{From: 2, To: 1, Type: pb.MsgTransferLeader})
+	if n1.leadTransferee != None {
+		t.Errorf("leadTransferee = %x, want %x", n1.leadTransferee, None)
+	}
+	if n1.state != StateLeader {
+		t.Errorf("state = %s, want %s", n1.state, StateLeader)
+	}
+}
+
+// TestJointConsensusCommit tests that an entry is committed during joint
+// consensus only once it is replicated to a majority of both the incoming
+// and the outgoing voters.
+func TestJointConsensusCommit(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	r.becomeCandidate()
+	r.becomeLeader()
+	r.Step(pb.Message{From: 2, To: 1, Term: r.Term, Type: pb.MsgAppResp, Index: r.raftLog.lastIndex()})
+
+	r.applyConfChangeV2(pb.ConfChangeV2{Changes: []pb.ConfChangeSingle{
+		{Type: pb.ConfChangeAddNode, NodeID: 4},
+		{Type: pb.ConfChangeRemoveNode, NodeID: 3},
+	}})
+	if !r.isJoint() {
+		t.Fatalf("isJoint = false, want true")
+	}
+	wcs := pb.ConfState{Nodes: []uint64{1, 2, 4}, NodesOutgoing: []uint64{1, 2, 3}}
+	if cs := r.confState(); !reflect.DeepEqual(cs, wcs) {
+		t.Fatalf("confState = %+v, want %+v", cs, wcs)
+	}
+	// the leader proposes to leave the joint consensus right away.
+	li := r.raftLog.lastIndex()
+	if ents := r.raftLog.allEntries(); ents[len(ents)-1].Type != pb.EntryConfChangeV2 {
+		t.Fatalf("last entry type = %s, want %s", ents[len(ents)-1].Type, pb.EntryConfChangeV2)
+	}
+	if !r.pendingConf {
+		t.Errorf("pendingConf = false, want true")
+	}
+
+	// a majority of the incoming voters is not enough.
+	r.Step(pb.Message{From: 4, To: 1, Term: r.Term, Type: pb.MsgAppResp, Index: li})
+	if r.raftLog.committed == li {
+		t.Fatalf("committed = %d, want less than %d", r.raftLog.committed, li)
+	}
+	// the removed node still counts toward the outgoing majority.
+	r.Step(pb.Message{From: 3, To: 1, Term: r.Term, Type: pb.MsgAppResp, Index: li})
+	if r.raftLog.committed != li {
+		t.Errorf("committed = %d, want %d", r.raftLog.committed, li)
+	}
+}
+
+// TestJointConsensusLeave tests that leaving the joint consensus removes the
+// voters of the outgoing configuration that are not in the incoming one.
+func TestJointConsensusLeave(t *testing.T) {
+	r := newTestLearnerRaft(1, []uint64{1, 2, 3}, []uint64{4}, 10, 1, NewMemoryStorage())
+	r.applyConfChangeV2(pb.ConfChangeV2{Changes: []pb.ConfChangeSingle{
+		{Type: pb.ConfChangeAddNode, NodeID: 4},
+		{Type: pb.ConfChangeRemoveNode, NodeID: 3},
+	}})
+	if r.prs[4].IsLearner {
+		t.Errorf("node 4 is still a learner")
+	}
+	if _, ok := r.prs[3]; !ok {
+		t.Fatalf("node 3 is removed before leaving the joint consensus")
+	}
+
+	r.applyConfChangeV2(pb.ConfChangeV2{})
+	if r.isJoint() {
+		t.Fatalf("isJoint = true, want false")
+	}
+	if _, ok := r.prs[3]; ok {
+		t.Errorf("node 3 is not removed")
+	}
+	wcs := pb.ConfState{Nodes: []uint64{1, 2, 4}}
+	if cs := r.confState(); !reflect.DeepEqual(cs, wcs) {
+		t.Errorf("confState = %+v, want %+v", cs, wcs)
+	}
+}
+
+// TestJointConsensusElection tests that a candidate needs the votes of a
+// majority of both the incoming and the outgoing voters during joint
+// consensus, and that the new leader proposes to leave it.
+func TestJointConsensusElection(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	r.applyConfChangeV2(pb.ConfChangeV2{Changes: []pb.ConfChangeSingle{
+		{Type: pb.ConfChangeAddNode, NodeID: 4},
+		{Type: pb.ConfChangeAddNode, NodeID: 5},
+		{Type: pb.ConfChangeRemoveNode, NodeID: 2},
+		{Type: pb.ConfChangeRemoveNode, NodeID: 3},
+	}})
+	r.Step(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+	r.Step(pb.Message{From: 4, To: 1, Term: r.Term, Type: pb.MsgVoteResp})
+	r.Step(pb.Message{From: 5, To: 1, Term: r.Term, Type: pb.MsgVoteResp})
+	if r.state != StateCandidate {
+		t.Fatalf("state = %s, want %s", r.state, StateCandidate)
+	}
+	r.Step(pb.Message{From: 2, To: 1, Term: r.Term, Type: pb.MsgVoteResp})
+	if r.state != StateLeader {
+		t.Fatalf("state = %s, want %s", r.state, StateLeader)
+	}
+	if ents := r.raftLog.allEntries(); ents[len(ents)-1].Type != pb.EntryConfChangeV2 {
+		t.Errorf("last entry type = %s, want %s", ents[len(ents)-1].Type, pb.EntryConfChangeV2)
+	}
+}
+
+// TestJointConsensusLost tests that a candidate loses the election once a
+// majority of the outgoing voters rejects it.
+func TestJointConsensusLost(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	r.applyConfChangeV2(pb.ConfChangeV2{Changes: []pb.ConfChangeSingle{
+		{Type: pb.ConfChangeAddNode, NodeID: 4},
+	}})
+	r.Step(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+	r.Step(pb.Message{From: 2, To: 1, Term: r.Term, Type: pb.MsgVoteResp, Reject: true})
+	r.Step(pb.Message{From: 3, To: 1, Term: r.Term, Type: pb.MsgVoteResp, Reject: true})
+	if r.state != StateFollower {
+		t.Errorf("state = %s, want %s", r.state, StateFollower)
+	}
+}
+
+// TestJointConsensusRejectConfChange tests that the leader does not accept
+// a configuration change before the cluster leaves the joint consensus.
+func TestJointConsensusRejectConfChange(t *testing.T) {
+	r := newTestRaft(1, []uint64{1, 2}, 10, 1, NewMemoryStorage())
+	r.becomeCandidate()
+	r.becomeLeader()
+	r.applyConfChangeV2(pb.ConfChangeV2{Changes: []pb.ConfChangeSingle{
+		{Type: pb.ConfChangeAddNode, NodeID: 3},
+	}})
+	// a repeated joint change is ignored.
+	r.applyConfChangeV2(pb.ConfChangeV2{Changes: []pb.ConfChangeSingle{
+		{Type: pb.ConfChangeRemoveNode, NodeID: 2},
+	}})
+	if _, ok := r.incoming[2]; !ok {
+		t.Errorf("node 2 is removed from the incoming voters")
+	}
+
+	// the changes proposed before leaving it are dropped.
+	li := r.raftLog.lastIndex()
+	for _, typ := range []pb.EntryType{pb.EntryConfChange, pb.EntryConfChangeV2} {
+		err := r.Step(pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{Type: typ}}})
+		if err != ErrConfChangeInProgress {
+			t.Errorf("%s: err = %v, want %v", typ, err, ErrConfChangeInProgress)
+		}
+		if g := r.raftLog.lastIndex(); g != li {
+			t.Errorf("%s: last index = %d, want %d", typ, g, li)
+		}
+	}
+}
+
+// TestJointConsensusRemoveLastOutgoing tests that removing the last voter
+// of the outgoing configuration leaves the joint consensus, and the leader
+// commits with the incoming voters alone.
+func TestJointConsensusRemoveLastOutgoing(t *testing.T) {
+	r := newTestLearnerRaft(3, []uint64{1, 2}, []uint64{3}, 10, 1, NewMemoryStorage())
+	r.applyConfChangeV2(pb.ConfChangeV2{Changes: []pb.ConfChangeSingle{
+		{Type: pb.ConfChangeAddNode, NodeID: 3},
+	}})
+	r.removeNode(1)
+	if !r.isJoint() {
+		t.Fatalf("isJoint = false, want true")
+	}
+	r.removeNode(2)
+	if r.isJoint() {
+		t.Fatalf("isJoint = true, want false")
+	}
+	wcs := pb.ConfState{Nodes: []uint64{3}}
+	if cs := r.confState(); !reflect.DeepEqual(cs, wcs) {
+		t.Errorf("confState = %+v, want %+v", cs, wcs)
+	}
+
+	r.becomeCandidate()
+	r.becomeLeader()
+	if r.raftLog.committed != r.raftLog.lastIndex() {
+		t.Errorf("committed = %d, want %d", r.raftLog.committed, r.raftLog.lastIndex())
+	}
+}
+
+// TestRestoreJoint tests that a snapshot taken during joint consensus
+// restores both the incoming and the outgoing voters.
+func TestRestoreJoint(t *testing.T) {
+	s := pb.Snapshot{
+		Metadata: pb.SnapshotMetadata{
+			Index:     11,
+			Term:      11,
+			ConfState: pb.ConfState{Nodes: []uint64{1, 2, 4}, NodesOutgoing: []uint64{1, 2, 3}},
+		},
+	}
+	r := newTestRaft(1, []uint64{1, 2}, 10, 1, NewMemoryStorage())
+	if ok := r.restore(s); !ok {
+		t.Fatal("restore fail, want succeed")
+	}
+	if !r.isJoint() {
+		t.Fatalf("isJoint = false, want true")
+	}
+	if len(r.prs) != 4 {
+		t.Errorf("len(prs) = %d, want 4", len(r.prs))
+	}
+	if cs := r.confState(); !reflect.DeepEqual(cs, s.Metadata.ConfState) {
+		t.Errorf("confState = %+v, want %+v", cs, s.Metadata.ConfState)
+	}
+}
+
 func TestPromotable(t *testing.T) {
 	id := uint64(1)
 	tests := []struct {
@@ -1751,6 +2435,234 @@ func TestRaftNodes(t *testing.T) {
 	}
 }
 
+// TestReadIndex tests that a read index request sent to any node returns
+// a read state with the commit index of the leader once the leader
+// confirms its leadership with a quorum of heartbeat acknowledgments.
+func TestReadIndex(t *testing.T) {
+	a := newTestRaft(1, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	b := newTestRaft(2, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	c := newTestRaft(3, []uint64{1, 2, 3}, 10, 1, NewMemoryStorage())
+	nt := newNetwork(a, b, c)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+	if a.state != StateLeader {
+		t.Fatalf("state = %s, want %s", a.state, StateLeader)
+	}
+
+	tests := []struct {
+		sm        *raft
+		proposals int
+		wri       uint64
+		wctx      []byte
+	}{
+		{a, 10, 11, []byte("ctx1")},
+		{b, 10, 21, []byte("ctx2")},
+		{c, 10, 31, []byte("ctx3")},
+		{a, 10, 41, []byte("ctx4")},
+		{b, 10, 51, []byte("ctx5")},
+		{c, 10, 61, []byte("ctx6")},
+	}
+
+	for i, tt := range tests {
+		for j := 0; j < tt.proposals; j++ {
+			nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{}}})
+		}
+
+		id := tt.sm.id
+		nt.send(pb.Message{From: id, To: id, Type: pb.MsgReadIndex, Entries: []pb.Entry{{Data: tt.wctx}}})
+
+		if len(tt.sm.readStates) != 1 {
+			t.Fatalf("#%d: len(readStates) = %d, want 1", i, len(tt.sm.readStates))
+		}
+		rs := tt.sm.readStates[0]
+		if rs.Index != tt.wri {
+			t.Errorf("#%d: readIndex = %d, want %d", i, rs.Index, tt.wri)
+		}
+		if !bytes.Equal(rs.RequestCtx, tt.wctx) {
+			t.Errorf("#%d: requestCtx = %v, want %v", i, rs.RequestCtx, tt.wctx)
+		}
+		tt.sm.readStates = nil
+	}
+}
+
+// TestReadIndexWithoutQuorum tests that the leader does not return a read
+// state until a quorum acknowledges its leadership.
+func TestReadIndexWithoutQuorum(t *testing.T) {
+	nt := newNetwork(nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+	lead := nt.peers[1].(*raft)
+
+	nt.isolate(1)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgReadIndex, Entries: []pb.Entry{{Data: []byte("ctx")}}})
+	if len(lead.readStates) != 0 {
+		t.Errorf("len(readStates) = %d, want 0", len(lead.readStates))
+	}
+
+	// the pending request is confirmed by the next heartbeat round.
+	nt.recover()
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgBeat})
+	if len(lead.readStates) != 1 {
+		t.Fatalf("len(readStates) = %d, want 1", len(lead.readStates))
+	}
+	if rs := lead.readStates[0]; rs.Index != lead.raftLog.committed || !bytes.Equal(rs.RequestCtx, []byte("ctx")) {
+		t.Errorf("readState = %+v, want index %d and ctx %q", rs, lead.raftLog.committed, "ctx")
+	}
+}
+
+// TestReadIndexSingleNode tests that the leader of a single node cluster
+// returns a read state immediately.
+func TestReadIndexSingleNode(t *testing.T) {
+	r := newTestRaft(1, []uint64{1}, 10, 1, NewMemoryStorage())
+	r.becomeCandidate()
+	r.becomeLeader()
+
+	r.Step(pb.Message{From: 1, To: 1, Type: pb.MsgReadIndex, Entries: []pb.Entry{{Data: []byte("ctx")}}})
+	wrs := []ReadState{{Index: r.raftLog.committed, RequestCtx: []byte("ctx")}}
+	if !reflect.DeepEqual(r.readStates, wrs) {
+		t.Errorf("readStates = %+v, want %+v", r.readStates, wrs)
+	}
+}
+
+// TestLeaderTransferToUpToDateNode tests that the leadership is transferred
+// to an up-to-date follower immediately, both when the transfer is
+// requested on the leader and on a follower.
+func TestLeaderTransferToUpToDateNode(t *testing.T) {
+	nt := newNetwork(nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+
+	lead := nt.peers[1].(*raft)
+	if lead.lead != 1 {
+		t.Fatalf("after election leader is %x, want 1", lead.lead)
+	}
+
+	// transfer leadership to 2.
+	nt.send(pb.Message{From: 2, To: 1, Type: pb.MsgTransferLeader})
+	checkLeaderTransferState(t, lead, StateFollower, 2)
+
+	// the follower 1 forwards the request to the leader 2.
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgTransferLeader})
+	checkLeaderTransferState(t, lead, StateLeader, 1)
+}
+
+// TestLeaderTransferToSlowFollower tests that the leader catches up the log
+// of the transferee before transferring the leadership.
+func TestLeaderTransferToSlowFollower(t *testing.T) {
+	nt := newNetwork(nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+
+	nt.isolate(3)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{}}})
+	nt.recover()
+
+	lead := nt.peers[1].(*raft)
+	if lead.prs[3].Match != 1 {
+		t.Fatalf("node 3 log match = %d, want %d", lead.prs[3].Match, 1)
+	}
+
+	nt.send(pb.Message{From: 3, To: 1, Type: pb.MsgTransferLeader})
+	checkLeaderTransferState(t, lead, StateFollower, 3)
+}
+
+// TestLeaderTransferWithPreVote tests that the transferee skips the pre-vote
+// phase of the election.
+func TestLeaderTransferWithPreVote(t *testing.T) {
+	nt := newPreVoteNetwork(nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+
+	nt.send(pb.Message{From: 2, To: 1, Type: pb.MsgTransferLeader})
+	checkLeaderTransferState(t, nt.peers[1].(*raft), StateFollower, 2)
+}
+
+// TestLeaderTransferTimeout tests that the leader transfer is aborted after an
+// election timeout, and the leader does not accept proposals during the
+// transfer.
+func TestLeaderTransferTimeout(t *testing.T) {
+	nt := newNetwork(nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+
+	nt.isolate(3)
+
+	lead := nt.peers[1].(*raft)
+	nt.send(pb.Message{From: 3, To: 1, Type: pb.MsgTransferLeader})
+	if lead.leadTransferee != 3 {
+		t.Fatalf("wait transferring, leadTransferee = %v, want %v", lead.leadTransferee, 3)
+	}
+
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{}}})
+	if lead.prs[1].Match != 1 {
+		t.Fatalf("node 1 log match = %d, want %d", lead.prs[1].Match, 1)
+	}
+
+	for i := 0; i < lead.electionTimeout-1; i++ {
+		lead.tick()
+	}
+	if lead.leadTransferee != 3 {
+		t.Fatalf("wait transferring, leadTransferee = %v, want %v", lead.leadTransferee, 3)
+	}
+	lead.tick()
+	checkLeaderTransferState(t, lead, StateLeader, 1)
+
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgProp, Entries: []pb.Entry{{}}})
+	if lead.prs[1].Match != 2 {
+		t.Errorf("node 1 log match = %d, want %d", lead.prs[1].Match, 2)
+	}
+}
+
+// TestLeaderTransferToSelf tests that the leader ignores the transfer to
+// itself.
+func TestLeaderTransferToSelf(t *testing.T) {
+	nt := newNetwork(nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+
+	lead := nt.peers[1].(*raft)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgTransferLeader})
+	checkLeaderTransferState(t, lead, StateLeader, 1)
+}
+
+// TestLeaderTransferToAnotherNode tests that a new transfer request to
+// another node aborts the ongoing transfer.
+func TestLeaderTransferToAnotherNode(t *testing.T) {
+	nt := newNetwork(nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+
+	nt.isolate(3)
+
+	lead := nt.peers[1].(*raft)
+	nt.send(pb.Message{From: 3, To: 1, Type: pb.MsgTransferLeader})
+	if lead.leadTransferee != 3 {
+		t.Fatalf("wait transferring, leadTransferee = %v, want %v", lead.leadTransferee, 3)
+	}
+
+	nt.send(pb.Message{From: 2, To: 1, Type: pb.MsgTransferLeader})
+	checkLeaderTransferState(t, lead, StateFollower, 2)
+}
+
+// TestLeaderTransferRemoveNode tests that removing the transferee aborts the
+// leader transfer.
+func TestLeaderTransferRemoveNode(t *testing.T) {
+	nt := newNetwork(nil, nil, nil)
+	nt.send(pb.Message{From: 1, To: 1, Type: pb.MsgHup})
+
+	nt.ignore(pb.MsgTimeoutNow)
+
+	lead := nt.peers[1].(*raft)
+	nt.send(pb.Message{From: 3, To: 1, Type: pb.MsgTransferLeader})
+	if lead.leadTransferee != 3 {
+		t.Fatalf("wait transferring, leadTransferee = %v, want %v", lead.leadTransferee, 3)
+	}
+
+	lead.removeNode(3)
+	checkLeaderTransferState(t, lead, StateLeader, 1)
+}
+
+func checkLeaderTransferState(t *testing.T, r *raft, state StateType, lead uint64) {
+	if r.state != state || r.lead != lead {
+		t.Fatalf("after transferring, node has state %v lead %v, want state %v lead %v", r.state, r.lead, state, lead)
+	}
+	if r.leadTransferee != None {
+		t.Fatalf("after transferring, node has leadTransferee %v, want leadTransferee %v", r.leadTransferee, None)
+	}
+}
+
 func ents(terms ...uint64) *raft {
 	storage := NewMemoryStorage()
 	for i, term := range terms {
@@ -1787,10 +2699,17 @@ func newNetwork(peers ...Interface) *network {
 			sm := newTestRaft(id, peerAddrs, 10, 1, nstorage[id])
 			npeers[id] = sm
 		case *raft:
+			// keep the learners of the given raft
+			learners := make(map[uint64]bool)
+			for i, pr := range v.prs {
+				if pr.IsLearner {
+					learners[i] = true
+				}
+			}
 			v.id = id
 			v.prs = make(map[uint64]*Progress)
 			for i := 0; i < size; i++ {
-				v.prs[peerAddrs[i]] = &Progress{}
+				v.prs[peerAddrs[i]] = &Progress{IsLearner: learners[peerAddrs[i]]}
 			}
 			v.reset(0)
 			npeers[id] = v
@@ -1808,6 +2727,30 @@ func newNetwork(peers ...Interface) *network {
 	}
 }
 
+// newPreVoteNetwork initializes a network like newNetwork, with PreVote
+// enabled on all the raft peers.
+func newPreVoteNetwork(peers ...Interface) *network {
+	nw := newNetwork(peers...)
+	for _, p := range nw.peers {
+		if sm, ok := p.(*raft); ok {
+			sm.preVote = true
+		}
+	}
+	return nw
+}
+
+// newCheckQuorumNetwork initializes a network like newNetwork, with
+// CheckQuorum enabled on all the raft peers.
+func newCheckQuorumNetwork(peers ...Interface) *network {
+	nw := newNetwork(peers...)
+	for _, p := range nw.peers {
+		if sm, ok := p.(*raft); ok {
+			sm.checkQuorum = true
+		}
+	}
+	return nw
+}
+
 func (nw *network) send(msgs ...pb.Message) {
 	for len(msgs) > 0 {
 		m := msgs[0]
@@ -1900,3 +2843,15 @@ func newTestConfig(id uint64, peers []uint64, election, heartbeat int, storage S
 func newTestRaft(id uint64, peers []uint64, election, heartbeat int, storage Storage) *raft {
 	return newRaft(newTestConfig(id, peers, election, heartbeat, storage))
 }
+
+func newTestLearnerRaft(id uint64, peers, learners []uint64, election, heartbeat int, storage Storage) *raft {
+	c := newTestConfig(id, peers, election, heartbeat, storage)
+	c.learners = learners
+	return newRaft(c)
+}
+
+func newPreVoteTestRaft(id uint64, peers []uint64, election, heartbeat int, storage Storage) *raft {
+	c := newTestConfig(id, peers, election, heartbeat, storage)
+	c.PreVote = true
+	return newRaft(c)
+}
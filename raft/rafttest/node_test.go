@@ -22,7 +22,7 @@ func TestBasicProgress(t *testing.T) {
 	time.Sleep(10 * time.Millisecond)
 
 	for i := 0; i < 10000; i++ {
-		nodes[0].Propose(context.TODO(), []byte("somedata"))
+		propose(nodes[0], []byte("somedata"))
 	}
 
 	time.Sleep(500 * time.Millisecond)
@@ -47,19 +47,19 @@ func TestRestart(t *testing.T) {
 
 	time.Sleep(50 * time.Millisecond)
 	for i := 0; i < 300; i++ {
-		nodes[0].Propose(context.TODO(), []byte("somedata"))
+		propose(nodes[0], []byte("somedata"))
 	}
 	nodes[1].stop()
 	for i := 0; i < 300; i++ {
-		nodes[0].Propose(context.TODO(), []byte("somedata"))
+		propose(nodes[0], []byte("somedata"))
 	}
 	nodes[2].stop()
 	for i := 0; i < 300; i++ {
-		nodes[0].Propose(context.TODO(), []byte("somedata"))
+		propose(nodes[0], []byte("somedata"))
 	}
 	nodes[2].restart()
 	for i := 0; i < 300; i++ {
-		nodes[0].Propose(context.TODO(), []byte("somedata"))
+		propose(nodes[0], []byte("somedata"))
 	}
 	nodes[1].restart()
 
@@ -86,19 +86,19 @@ func TestPause(t *testing.T) {
 
 	time.Sleep(50 * time.Millisecond)
 	for i := 0; i < 300; i++ {
-		nodes[0].Propose(context.TODO(), []byte("somedata"))
+		propose(nodes[0], []byte("somedata"))
 	}
 	nodes[1].pause()
 	for i := 0; i < 300; i++ {
-		nodes[0].Propose(context.TODO(), []byte("somedata"))
+		propose(nodes[0], []byte("somedata"))
 	}
 	nodes[2].pause()
 	for i := 0; i < 300; i++ {
-		nodes[0].Propose(context.TODO(), []byte("somedata"))
+		propose(nodes[0], []byte("somedata"))
 	}
 	nodes[2].resume()
 	for i := 0; i < 300; i++ {
-		nodes[0].Propose(context.TODO(), []byte("somedata"))
+		propose(nodes[0], []byte("somedata"))
 	}
 	nodes[1].resume()
 
@@ -111,3 +111,11 @@ func TestPause(t *testing.T) {
 		}
 	}
 }
+
+// propose proposes the data through the node, and retries the proposal
+// while it is dropped because there is no leader.
+func propose(n *node, data []byte) {
+	for n.Propose(context.TODO(), data) == raft.ErrProposalDropped {
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,724 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package raft
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	pb "github.com/coreos/etcd/raft/raftpb"
+)
+
+// The simulation harness replays scripts that describe a scenario for a
+// raft cluster. The nodes are driven synchronously by a single goroutine,
+// and the only source of randomness is seeded by the script, so that a
+// scenario is replayed exactly. The safety of the cluster is checked after
+// every tick and every delivered message.
+//
+// A script is a list of commands, one per line. Blank lines and anything
+// after '#' are ignored.
+//
+//	seed <n>                     seed the randomness; defaults to 0
+//	nodes <id>... [prevote] [checkquorum]
+//	                             start a cluster of the given nodes
+//	campaign <id>                make the node start an election
+//	propose <id> <data>          propose the data through the node
+//	tick [<n>] [<id>...]         tick the given (default all) nodes n times
+//	deliver [<n>]                deliver the pending messages n rounds
+//	stabilize                    deliver messages until there is none left
+//	elapse <n>                   tick all nodes and stabilize, n times
+//	partition <id,id...>...      cut the links between the given groups
+//	isolate <id>                 cut the links between the node and others
+//	drop <from> <to> <rate>      drop the messages of a link at the rate
+//	heal                         remove all partitions and drops
+//	crash <id>                   stop the node; it keeps its storage
+//	restart <id>                 restart a crashed node from its storage
+//	expect leader <id|none>      the leader of the latest term
+//	expect state <id> <state>    leader, follower, candidate or precandidate
+//	expect term <term> [<id>...] the term of the given (default all) nodes
+//	expect commit <index> [<id>...]
+//	                             the commit index of the given nodes
+//
+// Commands that take node IDs apply only to the running nodes.
+
+var simScript = flag.String("sim.script", "", "run only the given simulation script")
+
+func TestSimulation(t *testing.T) {
+	files := []string{*simScript}
+	if *simScript == "" {
+		var err error
+		files, err = filepath.Glob(filepath.Join("testdata", "simulation", "*.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) == 0 {
+			t.Fatal("no simulation scripts found")
+		}
+	}
+	for _, fn := range files {
+		if err := runSimulationScript(fn); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// TestSimulationSafetyViolation tests that the harness detects a committed
+// entry that is overwritten.
+func TestSimulationSafetyViolation(t *testing.T) {
+	s := newSimulator(0)
+	if err := s.exec([]string{"nodes", "1", "2", "3"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, cmd := range [][]string{{"campaign", "1"}, {"stabilize"}, {"propose", "1", "foo"}, {"stabilize"}} {
+		if err := s.exec(cmd); err != nil {
+			t.Fatalf("%v: %v", cmd, err)
+		}
+	}
+	// corrupt the committed entry in the storage of node 2.
+	st := s.storages[2]
+	st.ents[len(st.ents)-1].Data = []byte("bar")
+	if err := s.checkSafety(); err == nil {
+		t.Errorf("checkSafety = nil, want error")
+	}
+}
+
+func runSimulationScript(fn string) error {
+	f, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s := newSimulator(0)
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		l := sc.Text()
+		if i := strings.Index(l, "#"); i >= 0 {
+			l = l[:i]
+		}
+		args := strings.Fields(l)
+		if len(args) == 0 {
+			continue
+		}
+		if err := s.exec(args); err != nil {
+			return fmt.Errorf("%s:%d: %s: %v\n%s", fn, line, strings.Join(args, " "), err, s.describe())
+		}
+	}
+	return sc.Err()
+}
+
+// simConn is a directed link between two nodes.
+type simConn struct {
+	from, to uint64
+}
+
+type simulator struct {
+	seed int64
+	rand *rand.Rand
+
+	ids         []uint64
+	nodes       map[uint64]*raft
+	storages    map[uint64]*MemoryStorage
+	preVote     bool
+	checkQuorum bool
+
+	msgs  []pb.Message
+	cut   map[simConn]bool
+	drops map[simConn]float64
+
+	// leaders records the leader elected in each term.
+	leaders map[uint64]uint64
+	// committed records the committed entries, and committedTerm the term
+	// in which each of them was first seen committed.
+	committed     map[uint64]pb.Entry
+	committedTerm map[uint64]uint64
+}
+
+func newSimulator(seed int64) *simulator {
+	s := &simulator{
+		nodes:         make(map[uint64]*raft),
+		storages:      make(map[uint64]*MemoryStorage),
+		cut:           make(map[simConn]bool),
+		drops:         make(map[simConn]float64),
+		leaders:       make(map[uint64]uint64),
+		committed:     make(map[uint64]pb.Entry),
+		committedTerm: make(map[uint64]uint64),
+	}
+	s.setSeed(seed)
+	return s
+}
+
+func (s *simulator) setSeed(seed int64) {
+	s.seed = seed
+	s.rand = rand.New(rand.NewSource(seed))
+}
+
+func (s *simulator) exec(args []string) error {
+	cmd, args := args[0], args[1:]
+	if cmd != "seed" && cmd != "nodes" && len(s.ids) == 0 {
+		return fmt.Errorf("no nodes")
+	}
+	switch cmd {
+	case "seed":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: seed <n>")
+		}
+		seed, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return err
+		}
+		s.setSeed(seed)
+		return nil
+	case "nodes":
+		return s.startCluster(args)
+	case "campaign":
+		n, err := s.node(args)
+		if err != nil {
+			return err
+		}
+		n.Step(pb.Message{From: n.id, To: n.id, Type: pb.MsgHup})
+		return s.ready(n)
+	case "propose":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: propose <id> <data>")
+		}
+		n, err := s.node(args[:1])
+		if err != nil {
+			return err
+		}
+		// a dropped proposal is not an error of the script.
+		n.Step(pb.Message{From: n.id, To: n.id, Type: pb.MsgProp, Entries: []pb.Entry{{Data: []byte(args[1])}}})
+		return s.ready(n)
+	case "tick":
+		times := 1
+		if len(args) > 0 {
+			if v, err := strconv.Atoi(args[0]); err == nil {
+				times, args = v, args[1:]
+			}
+		}
+		ids, err := s.parseIDs(args)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < times; i++ {
+			if err := s.tick(ids); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "deliver":
+		rounds := 1
+		if len(args) > 0 {
+			v, err := strconv.Atoi(args[0])
+			if err != nil {
+				return err
+			}
+			rounds = v
+		}
+		for i := 0; i < rounds; i++ {
+			if err := s.deliver(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "stabilize":
+		return s.stabilize()
+	case "elapse":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: elapse <n>")
+		}
+		times, err := strconv.Atoi(args[0])
+		if err != nil {
+			return err
+		}
+		for i := 0; i < times; i++ {
+			if err := s.tick(s.runningIDs()); err != nil {
+				return err
+			}
+			if err := s.stabilize(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "partition":
+		return s.partition(args)
+	case "isolate":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: isolate <id>")
+		}
+		return s.partition(args)
+	case "drop":
+		if len(args) != 3 {
+			return fmt.Errorf("usage: drop <from> <to> <rate>")
+		}
+		ids, err := s.parseIDs(args[:2])
+		if err != nil {
+			return err
+		}
+		rate, err := strconv.ParseFloat(args[2], 64)
+		if err != nil {
+			return err
+		}
+		s.drops[simConn{ids[0], ids[1]}] = rate
+		return nil
+	case "heal":
+		s.cut = make(map[simConn]bool)
+		s.drops = make(map[simConn]float64)
+		return nil
+	case "crash":
+		n, err := s.node(args)
+		if err != nil {
+			return err
+		}
+		delete(s.nodes, n.id)
+		return nil
+	case "restart":
+		ids, err := s.parseIDs(args)
+		if err != nil || len(ids) != 1 {
+			return fmt.Errorf("usage: restart <id>")
+		}
+		if _, ok := s.nodes[ids[0]]; ok {
+			return fmt.Errorf("node %x is running", ids[0])
+		}
+		if _, ok := s.storages[ids[0]]; !ok {
+			return fmt.Errorf("unknown node %x", ids[0])
+		}
+		s.startNode(ids[0])
+		return s.checkSafety()
+	case "expect":
+		if len(args) == 0 {
+			return fmt.Errorf("usage: expect <leader|state|term|commit> ...")
+		}
+		return s.expect(args[0], args[1:])
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+func (s *simulator) startCluster(args []string) error {
+	if len(s.ids) != 0 {
+		return fmt.Errorf("nodes are already started")
+	}
+	var ids []uint64
+	for _, a := range args {
+		switch a {
+		case "prevote":
+			s.preVote = true
+		case "checkquorum":
+			s.checkQuorum = true
+		default:
+			id, err := strconv.ParseUint(a, 10, 64)
+			if err != nil || id == None {
+				return fmt.Errorf("bad node id %q", a)
+			}
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("usage: nodes <id>... [prevote] [checkquorum]")
+	}
+	sort.Sort(uint64Slice(ids))
+	s.ids = ids
+	for _, id := range ids {
+		// all the nodes start from a snapshot of the initial configuration.
+		st := NewMemoryStorage()
+		st.ApplySnapshot(pb.Snapshot{Metadata: pb.SnapshotMetadata{Index: 1, Term: 1, ConfState: pb.ConfState{Nodes: ids}}})
+		st.SetHardState(pb.HardState{Term: 1, Commit: 1})
+		s.storages[id] = st
+		s.startNode(id)
+	}
+	return nil
+}
+
+// startNode starts the node from its storage.
+func (s *simulator) startNode(id uint64) {
+	st := s.storages[id]
+	hs, _, _ := st.InitialState()
+	c := &Config{
+		ID:              id,
+		ElectionTick:    10,
+		HeartbeatTick:   1,
+		Storage:         st,
+		MaxSizePerMsg:   noLimit,
+		MaxInflightMsgs: 256,
+		Applied:         hs.Commit,
+		PreVote:         s.preVote,
+		CheckQuorum:     s.checkQuorum,
+	}
+	r := newRaft(c)
+	// the randomized election timeout of each node is derived from the
+	// seed, so that a different seed replays a different schedule.
+	r.rand = rand.New(rand.NewSource(s.seed*1000003 + int64(id)))
+	s.nodes[id] = r
+}
+
+// node returns the running node of the only argument.
+func (s *simulator) node(args []string) (*raft, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected a single node id")
+	}
+	ids, err := s.parseIDs(args)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := s.nodes[ids[0]]
+	if !ok {
+		return nil, fmt.Errorf("node %x is not running", ids[0])
+	}
+	return r, nil
+}
+
+// parseIDs parses the given node IDs. It returns the running nodes if
+// no ID is given.
+func (s *simulator) parseIDs(args []string) ([]uint64, error) {
+	if len(args) == 0 {
+		return s.runningIDs(), nil
+	}
+	var ids []uint64
+	for _, a := range args {
+		id, err := strconv.ParseUint(a, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad node id %q", a)
+		}
+		if _, ok := s.storages[id]; !ok {
+			return nil, fmt.Errorf("unknown node %x", id)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (s *simulator) runningIDs() []uint64 {
+	var ids []uint64
+	for _, id := range s.ids {
+		if _, ok := s.nodes[id]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (s *simulator) partition(groups []string) error {
+	group := make(map[uint64]int)
+	for i, g := range groups {
+		ids, err := s.parseIDs(strings.Split(g, ","))
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			group[id] = i + 1
+		}
+	}
+	// the nodes that are not in any group are in a group of their own.
+	for _, id := range s.ids {
+		if _, ok := group[id]; !ok {
+			group[id] = 0
+		}
+	}
+	for _, from := range s.ids {
+		for _, to := range s.ids {
+			if from != to && group[from] != group[to] {
+				s.cut[simConn{from, to}] = true
+			}
+		}
+	}
+	return nil
+}
+
+func (s *simulator) tick(ids []uint64) error {
+	for _, id := range ids {
+		r, ok := s.nodes[id]
+		if !ok {
+			continue
+		}
+		r.tick()
+		if err := s.ready(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver delivers the messages that are pending at the time of the call.
+// The messages sent in response are left pending.
+func (s *simulator) deliver() error {
+	msgs := s.msgs
+	s.msgs = nil
+	for _, m := range msgs {
+		r, ok := s.nodes[m.To]
+		if !ok || s.cut[simConn{m.From, m.To}] {
+			continue
+		}
+		if rate := s.drops[simConn{m.From, m.To}]; rate > 0 && s.rand.Float64() < rate {
+			continue
+		}
+		// filter out response message from unknown From, as the node does.
+		if _, ok := r.prs[m.From]; !ok && IsResponseMsg(m) {
+			continue
+		}
+		r.Step(m)
+		if err := s.ready(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *simulator) stabilize() error {
+	for i := 0; len(s.msgs) > 0; i++ {
+		if i >= 1000 {
+			return fmt.Errorf("messages are still pending after %d rounds", i)
+		}
+		if err := s.deliver(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ready handles the updates of the node as the application does with a
+// Ready: it persists the snapshot, the entries and the hard state, applies
+// the committed entries, and queues the messages. It then checks the
+// safety of the cluster.
+func (s *simulator) ready(r *raft) error {
+	st := s.storages[r.id]
+	if snap := r.raftLog.unstable.snapshot; snap != nil {
+		st.ApplySnapshot(*snap)
+		r.raftLog.stableSnapTo(snap.Metadata.Index)
+	}
+	if ents := r.raftLog.unstableEntries(); len(ents) > 0 {
+		st.Append(ents)
+		last := ents[len(ents)-1]
+		r.raftLog.stableTo(last.Index, last.Term)
+	}
+	st.SetHardState(pb.HardState{Term: r.Term, Vote: r.Vote, Commit: r.raftLog.committed})
+	if r.raftLog.committed > r.raftLog.applied {
+		r.raftLog.appliedTo(r.raftLog.committed)
+	}
+	// the leader sends to its peers in the random order of the map of
+	// their progresses. The messages are ordered by their receivers, so
+	// that the drops decided by the seeded randomness do not depend on
+	// the order. The messages to a receiver are kept in the order sent.
+	msgs := r.readMessages()
+	sort.Stable(msgsByTo(msgs))
+	s.msgs = append(s.msgs, msgs...)
+	return s.checkSafety()
+}
+
+// checkSafety checks the safety invariants of raft:
+//   - election safety: at most one leader is elected in a term.
+//   - log matching: if two logs contain an entry with the same index and
+//     term, the logs are identical up through the index.
+//   - state machine safety: a committed entry is never changed, and the
+//     leaders of the later terms contain it.
+func (s *simulator) checkSafety() error {
+	for _, id := range s.runningIDs() {
+		r := s.nodes[id]
+		if r.state != StateLeader {
+			continue
+		}
+		if l, ok := s.leaders[r.Term]; ok && l != id {
+			return fmt.Errorf("election safety: both %x and %x are leaders at term %d", l, id, r.Term)
+		}
+		s.leaders[r.Term] = id
+	}
+
+	logs := make(map[uint64]map[uint64]pb.Entry)
+	for _, id := range s.ids {
+		logs[id] = s.entries(id)
+	}
+	for i, a := range s.ids {
+		for _, b := range s.ids[i+1:] {
+			for idx, ea := range logs[a] {
+				eb, ok := logs[b][idx]
+				if !ok || ea.Term != eb.Term {
+					continue
+				}
+				if !bytes.Equal(ea.Data, eb.Data) {
+					return fmt.Errorf("log matching: %x and %x have different entries at index %d term %d", a, b, idx, ea.Term)
+				}
+				prevA, okA := logs[a][idx-1]
+				prevB, okB := logs[b][idx-1]
+				if okA && okB && prevA.Term != prevB.Term {
+					return fmt.Errorf("log matching: %x and %x agree at index %d term %d, but not at index %d", a, b, idx, ea.Term, idx-1)
+				}
+			}
+		}
+	}
+
+	for _, id := range s.runningIDs() {
+		r := s.nodes[id]
+		for idx := r.raftLog.firstIndex(); idx <= r.raftLog.committed; idx++ {
+			e, ok := logs[id][idx]
+			if !ok {
+				continue
+			}
+			c, ok := s.committed[idx]
+			if !ok {
+				s.committed[idx] = e
+				s.committedTerm[idx] = r.Term
+				continue
+			}
+			if c.Term != e.Term || !bytes.Equal(c.Data, e.Data) {
+				return fmt.Errorf("state machine safety: committed entry at index %d term %d is replaced with term %d on %x", idx, c.Term, e.Term, id)
+			}
+		}
+	}
+	for _, id := range s.runningIDs() {
+		r := s.nodes[id]
+		if r.state != StateLeader {
+			continue
+		}
+		for idx, c := range s.committed {
+			if r.Term <= s.committedTerm[idx] || idx < r.raftLog.firstIndex() {
+				continue
+			}
+			if e, ok := logs[id][idx]; !ok || e.Term != c.Term {
+				return fmt.Errorf("leader completeness: leader %x at term %d misses committed entry at index %d term %d", id, r.Term, idx, c.Term)
+			}
+		}
+	}
+	return nil
+}
+
+// entries returns the log of the node by index. It is the log in memory
+// for a running node, and the persisted log for a crashed node.
+func (s *simulator) entries(id uint64) map[uint64]pb.Entry {
+	var ents []pb.Entry
+	if r, ok := s.nodes[id]; ok {
+		ents = r.raftLog.allEntries()
+	} else {
+		st := s.storages[id]
+		fi, _ := st.FirstIndex()
+		li, _ := st.LastIndex()
+		ents, _ = st.Entries(fi, li+1, noLimit)
+	}
+	m := make(map[uint64]pb.Entry, len(ents))
+	for _, e := range ents {
+		m[e.Index] = e
+	}
+	return m
+}
+
+func (s *simulator) expect(what string, args []string) error {
+	switch what {
+	case "leader":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: expect leader <id|none>")
+		}
+		var lead, term uint64
+		for _, id := range s.runningIDs() {
+			r := s.nodes[id]
+			if r.state == StateLeader && r.Term >= term {
+				lead, term = id, r.Term
+			}
+		}
+		if args[0] == "none" {
+			if lead != None {
+				return fmt.Errorf("leader = %x, want none", lead)
+			}
+			return nil
+		}
+		ids, err := s.parseIDs(args)
+		if err != nil {
+			return err
+		}
+		if lead != ids[0] {
+			return fmt.Errorf("leader = %x, want %x", lead, ids[0])
+		}
+		return nil
+	case "state":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: expect state <id> <state>")
+		}
+		r, err := s.node(args[:1])
+		if err != nil {
+			return err
+		}
+		states := map[string]StateType{
+			"leader":       StateLeader,
+			"follower":     StateFollower,
+			"candidate":    StateCandidate,
+			"precandidate": StatePreCandidate,
+		}
+		w, ok := states[args[1]]
+		if !ok {
+			return fmt.Errorf("unknown state %q", args[1])
+		}
+		if r.state != w {
+			return fmt.Errorf("state of %x = %s, want %s", r.id, r.state, w)
+		}
+		return nil
+	case "term", "commit":
+		if len(args) == 0 {
+			return fmt.Errorf("usage: expect %s <n> [<id>...]", what)
+		}
+		w, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return err
+		}
+		ids, err := s.parseIDs(args[1:])
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			r, ok := s.nodes[id]
+			if !ok {
+				return fmt.Errorf("node %x is not running", id)
+			}
+			g := r.Term
+			if what == "commit" {
+				g = r.raftLog.committed
+			}
+			if g != w {
+				return fmt.Errorf("%s of %x = %d, want %d", what, id, g, w)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown expectation %q", what)
+	}
+}
+
+// describe returns the state of the nodes for the failure report.
+func (s *simulator) describe() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "seed %d, %d pending messages\n", s.seed, len(s.msgs))
+	for _, id := range s.ids {
+		r, ok := s.nodes[id]
+		if !ok {
+			fmt.Fprintf(&b, "  %x: crashed\n", id)
+			continue
+		}
+		fmt.Fprintf(&b, "  %x: %s term=%d vote=%x lead=%x commit=%d lastindex=%d lastterm=%d\n",
+			id, r.state, r.Term, r.Vote, r.lead, r.raftLog.committed, r.raftLog.lastIndex(), r.raftLog.lastTerm())
+	}
+	return b.String()
+}
+
+type msgsByTo []pb.Message
+
+func (ms msgsByTo) Len() int           { return len(ms) }
+func (ms msgsByTo) Less(i, j int) bool { return ms[i].To < ms[j].To }
+func (ms msgsByTo) Swap(i, j int)      { ms[i], ms[j] = ms[j], ms[i] }
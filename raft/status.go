@@ -28,6 +28,10 @@ type Status struct {
 
 	Applied  uint64
 	Progress map[uint64]Progress
+
+	// LeadTransferee is the target of the ongoing leader transfer, or
+	// None if there is no leader transfer.
+	LeadTransferee uint64
 }
 
 // getStatus gets a copy of the current raft status.
@@ -37,11 +41,17 @@ func getStatus(r *raft) Status {
 	s.SoftState = *r.softState()
 
 	s.Applied = r.raftLog.applied
+	s.LeadTransferee = r.leadTransferee
 
 	if s.RaftState == StateLeader {
 		s.Progress = make(map[uint64]Progress)
 		for id, p := range r.prs {
-			s.Progress[id] = *p
+			pr := *p
+			// the inflights window keeps changing in the raft goroutine
+			if pr.ins != nil {
+				pr.ins = pr.ins.clone()
+			}
+			s.Progress[id] = pr
 		}
 	}
 
@@ -57,7 +67,7 @@ func (s Status) MarshalJSON() ([]byte, error) {
 		j += "}}"
 	} else {
 		for k, v := range s.Progress {
-			subj := fmt.Sprintf(`"%x":{"match":%d,"next":%d,"state":%q},`, k, v.Match, v.Next, v.State)
+			subj := fmt.Sprintf(`"%x":{"match":%d,"next":%d,"state":%q,"isLearner":%t},`, k, v.Match, v.Next, v.State, v.IsLearner)
 			j += subj
 		}
 		// remove the trailing ","
@@ -0,0 +1,105 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rafthttp
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// limitedReadSize is the maximum number of bytes read at once through
+	// a bandwidth limiter, which keeps the transfer smooth.
+	limitedReadSize = 16 * 1024
+)
+
+// BandwidthLimitable is a Transporter that limits the bandwidth of the
+// snapshot transfers to each peer.
+type BandwidthLimitable interface {
+	// SetSnapshotBandwidth sets the maximum bandwidth in bytes per second
+	// of the snapshots sent to each peer. 0 means unlimited.
+	// It MUST be called before any peer is added.
+	SetSnapshotBandwidth(bytesPerSec int64)
+}
+
+// bandwidthLimiter paces the data sent to a remote peer at a given rate.
+// It is shared by all the snapshot transfers to the peer.
+type bandwidthLimiter struct {
+	remote string
+	rate   int64 // bytes per second
+
+	mu sync.Mutex
+	// next is the time at which the next byte may be sent.
+	next time.Time
+}
+
+// newBandwidthLimiter returns a bandwidthLimiter of the given rate. It
+// returns nil if the rate is not positive, which means unlimited.
+func newBandwidthLimiter(remote string, bytesPerSec int64) *bandwidthLimiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return &bandwidthLimiter{remote: remote, rate: bytesPerSec}
+}
+
+// reserve reserves n bytes, and returns how long the caller must wait
+// before it sends them.
+func (l *bandwidthLimiter) reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	d := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.rate))
+	return d
+}
+
+// reader returns a reader that reads from r at the rate of the limiter.
+// A read returns errStopped once stopc is closed. If the limiter is nil,
+// r is returned.
+func (l *bandwidthLimiter) reader(r io.Reader, stopc <-chan struct{}) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &limitedReader{r: r, l: l, stopc: stopc}
+}
+
+type limitedReader struct {
+	r     io.Reader
+	l     *bandwidthLimiter
+	stopc <-chan struct{}
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > limitedReadSize {
+		p = p[:limitedReadSize]
+	}
+	n, err := r.r.Read(p)
+	if n == 0 {
+		return n, err
+	}
+	if d := r.l.reserve(n); d > 0 {
+		snapshotThrottledSeconds.WithLabelValues(r.l.remote).Add(d.Seconds())
+		select {
+		case <-time.After(d):
+		case <-r.stopc:
+			return n, errStopped
+		}
+	}
+	return n, err
+}
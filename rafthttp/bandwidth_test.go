@@ -0,0 +1,79 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rafthttp
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestBandwidthLimiterReader(t *testing.T) {
+	l := newBandwidthLimiter("1", 100*1024)
+	data := bytes.Repeat([]byte("a"), 50*1024)
+	before := counterValue(t, snapshotThrottledSeconds.WithLabelValues("1").Write)
+
+	start := time.Now()
+	b, err := ioutil.ReadAll(l.reader(bytes.NewReader(data), make(chan struct{})))
+	if err != nil {
+		t.Fatalf("unexpected ReadAll error: %v", err)
+	}
+	if !bytes.Equal(b, data) {
+		t.Errorf("read data differs from the written data")
+	}
+	// the first 16KB is sent at once, and the remaining 34KB takes ~340ms.
+	if d := time.Since(start); d < 300*time.Millisecond {
+		t.Errorf("read duration = %v, want >= 300ms", d)
+	}
+	if after := counterValue(t, snapshotThrottledSeconds.WithLabelValues("1").Write); after <= before {
+		t.Errorf("throttled seconds = %v, want > %v", after, before)
+	}
+}
+
+func TestBandwidthLimiterReaderStop(t *testing.T) {
+	l := newBandwidthLimiter("1", 1024)
+	stopc := make(chan struct{})
+	r := l.reader(bytes.NewReader(make([]byte, 64*1024)), stopc)
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(ioutil.Discard, r)
+		errc <- err
+	}()
+	close(stopc)
+	select {
+	case err := <-errc:
+		if err != errStopped {
+			t.Errorf("err = %v, want %v", err, errStopped)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("failed to stop the limited read")
+	}
+}
+
+func TestBandwidthLimiterUnlimited(t *testing.T) {
+	for i, bps := range []int64{0, -1} {
+		l := newBandwidthLimiter("1", bps)
+		if l != nil {
+			t.Errorf("#%d: limiter = %v, want nil", i, l)
+		}
+		r := bytes.NewReader(nil)
+		if g := l.reader(r, nil); g != r {
+			t.Errorf("#%d: reader = %v, want %v", i, g, r)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rafthttp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/golang/snappy"
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/prometheus/client_golang/prometheus"
+)
+
+type compression string
+
+const (
+	// compressionNone is the empty string, which is also the compression
+	// of the peers that do not negotiate any.
+	compressionNone   compression = ""
+	compressionSnappy compression = "snappy"
+	compressionGzip   compression = "gzip"
+)
+
+var (
+	// supportedCompressions are the compressions that the local member can
+	// decompress. They are advertised to the remote peers in the
+	// X-Raft-Accept-Compression header.
+	supportedCompressions = []compression{compressionSnappy, compressionGzip}
+
+	errUnsupportedCompression = errors.New("unsupported compression")
+)
+
+// Compressible is a Transporter that compresses the data sent to the
+// peers.
+type Compressible interface {
+	// SetCompression sets the compression of the data sent to the peers:
+	// "snappy", "gzip" or "none". The data is compressed only for the
+	// peers that advertised that they accept the compression. The data
+	// sent to the other peers, such as members running an older version,
+	// is not compressed.
+	// It MUST be called before any peer is added.
+	SetCompression(c string) error
+}
+
+func parseCompression(s string) (compression, error) {
+	switch c := compression(s); c {
+	case compressionNone, compressionSnappy, compressionGzip:
+		return c, nil
+	case "none":
+		return compressionNone, nil
+	default:
+		return "", errUnsupportedCompression
+	}
+}
+
+// acceptCompressionHeader returns the value of the X-Raft-Accept-Compression
+// header for the given compressions.
+func acceptCompressionHeader(cs []compression) string {
+	ss := make([]string, len(cs))
+	for i, c := range cs {
+		ss[i] = string(c)
+	}
+	return strings.Join(ss, ", ")
+}
+
+// acceptsCompression returns true if the value h of the
+// X-Raft-Accept-Compression header contains the compression c.
+func acceptsCompression(h string, c compression) bool {
+	if c == compressionNone {
+		return false
+	}
+	for _, s := range strings.Split(h, ",") {
+		if compression(strings.TrimSpace(s)) == c {
+			return true
+		}
+	}
+	return false
+}
+
+// peerCompression picks the compression of the data sent to a remote
+// peer. The remote advertises the compressions it accepts in the response
+// of the stream requests.
+type peerCompression struct {
+	local compression
+
+	mu       sync.Mutex // guard accepted
+	accepted string
+}
+
+func newPeerCompression(local compression) *peerCompression {
+	return &peerCompression{local: local}
+}
+
+// setAccepted records the value of the X-Raft-Accept-Compression header
+// returned by the remote.
+func (pc *peerCompression) setAccepted(h string) {
+	if pc == nil {
+		return
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	pc.accepted = h
+}
+
+// pick returns the compression of the data sent to the remote. It returns
+// compressionNone if the remote does not accept the local compression.
+func (pc *peerCompression) pick() compression {
+	if pc == nil {
+		return compressionNone
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if !acceptsCompression(pc.accepted, pc.local) {
+		return compressionNone
+	}
+	return pc.local
+}
+
+// compressWriter compresses the data written to it. Flush writes out the
+// pending compressed data, so the remote can decode everything that was
+// written before it.
+type compressWriter interface {
+	io.Writer
+	Flush() error
+	Close() error
+}
+
+// newCompressWriter returns a compressWriter that writes the data
+// compressed by c to w. The raw and compressed bytes are counted in the
+// metrics of the given sending type and remote.
+func newCompressWriter(w io.Writer, c compression, sendingType, remote string) compressWriter {
+	cw := &countingWriter{w: w, c: compressedBytes.WithLabelValues(sendingType, remote, string(c))}
+	var zw compressWriter
+	switch c {
+	case compressionSnappy:
+		zw = snappy.NewBufferedWriter(cw)
+	case compressionGzip:
+		zw = gzip.NewWriter(cw)
+	default:
+		plog.Panicf("unhandled compression %s", c)
+	}
+	return &rawCountingWriter{compressWriter: zw, c: rawBytes.WithLabelValues(sendingType, remote, string(c))}
+}
+
+// compress returns the data compressed by c.
+func compress(data []byte, c compression, sendingType, remote string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := newCompressWriter(&buf, c, sendingType, remote)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newDecompressReader returns a reader that decompresses the data read
+// from r by c.
+func newDecompressReader(r io.Reader, c compression) (io.Reader, error) {
+	switch c {
+	case compressionNone:
+		return r, nil
+	case compressionSnappy:
+		return snappy.NewReader(r), nil
+	case compressionGzip:
+		return &gzipReader{r: r}, nil
+	default:
+		return nil, fmt.Errorf("%v %q", errUnsupportedCompression, string(c))
+	}
+}
+
+// gzipReader reads the gzip header at the first read, so creating it
+// never blocks on a stream that has no data yet.
+type gzipReader struct {
+	r  io.Reader
+	zr *gzip.Reader
+}
+
+func (r *gzipReader) Read(p []byte) (int, error) {
+	if r.zr == nil {
+		zr, err := gzip.NewReader(r.r)
+		if err != nil {
+			return 0, err
+		}
+		r.zr = zr
+	}
+	return r.zr.Read(p)
+}
+
+type countingWriter struct {
+	w io.Writer
+	c prometheus.Counter
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.c.Add(float64(n))
+	return n, err
+}
+
+type rawCountingWriter struct {
+	compressWriter
+	c prometheus.Counter
+}
+
+func (w *rawCountingWriter) Write(p []byte) (int, error) {
+	n, err := w.compressWriter.Write(p)
+	w.c.Add(float64(n))
+	return n, err
+}
+
+// compressFlusher flushes the compressed data before it flushes the
+// underlying connection.
+type compressFlusher struct {
+	w compressWriter
+	f http.Flusher
+}
+
+func (f *compressFlusher) Flush() {
+	if err := f.w.Flush(); err != nil {
+		plog.Debugf("failed to flush compressed stream (%v)", err)
+	}
+	f.f.Flush()
+}
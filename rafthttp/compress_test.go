@@ -0,0 +1,344 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rafthttp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	dto "github.com/coreos/etcd/Godeps/_workspace/src/github.com/prometheus/client_model/go"
+	"github.com/coreos/etcd/etcdserver/stats"
+	"github.com/coreos/etcd/pkg/types"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/coreos/etcd/snap"
+)
+
+func TestCompressWriterReader(t *testing.T) {
+	msgs := []raftpb.Message{
+		linkHeartbeatMessage,
+		{Type: raftpb.MsgApp, From: 1, To: 2, Term: 1, Index: 3, Entries: []raftpb.Entry{{Index: 4, Term: 1, Data: bytes.Repeat([]byte("some data"), 100)}}},
+		{Type: raftpb.MsgHeartbeat, From: 1, To: 2, Term: 1, Commit: 3},
+	}
+	for i, c := range supportedCompressions {
+		buf := &bytes.Buffer{}
+		w := newCompressWriter(buf, c, "test", "2")
+		enc := &messageEncoder{w: w}
+		r, err := newDecompressReader(buf, c)
+		if err != nil {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+		dec := &messageDecoder{r: r}
+		for j, m := range msgs {
+			if err := enc.encode(m); err != nil {
+				t.Fatalf("#%d.%d: unexpected encode error: %v", i, j, err)
+			}
+			// the message can be decoded right after the flush
+			if err := w.Flush(); err != nil {
+				t.Fatalf("#%d.%d: unexpected flush error: %v", i, j, err)
+			}
+			g, err := dec.decode()
+			if err != nil {
+				t.Fatalf("#%d.%d: unexpected decode error: %v", i, j, err)
+			}
+			if !reflect.DeepEqual(g, m) {
+				t.Errorf("#%d.%d: message = %+v, want %+v", i, j, g, m)
+			}
+		}
+	}
+}
+
+func TestNewDecompressReaderUnsupported(t *testing.T) {
+	if _, err := newDecompressReader(&bytes.Buffer{}, compression("br")); err == nil {
+		t.Errorf("err = nil, want unsupported compression error")
+	}
+}
+
+func TestPeerCompressionPick(t *testing.T) {
+	tests := []struct {
+		local    compression
+		accepted string
+
+		w compression
+	}{
+		{compressionSnappy, "snappy, gzip", compressionSnappy},
+		{compressionGzip, "snappy, gzip", compressionGzip},
+		{compressionGzip, "snappy", compressionNone},
+		// older members do not advertise the header
+		{compressionSnappy, "", compressionNone},
+		{compressionNone, "snappy, gzip", compressionNone},
+		{compressionNone, "", compressionNone},
+	}
+	for i, tt := range tests {
+		pc := newPeerCompression(tt.local)
+		pc.setAccepted(tt.accepted)
+		if g := pc.pick(); g != tt.w {
+			t.Errorf("#%d: compression = %q, want %q", i, g, tt.w)
+		}
+	}
+	var pc *peerCompression
+	if g := pc.pick(); g != compressionNone {
+		t.Errorf("compression of nil = %q, want %q", g, compressionNone)
+	}
+}
+
+func TestServeRaftCompressed(t *testing.T) {
+	m := raftpb.Message{Type: raftpb.MsgHeartbeat, From: 1, To: 2, Term: 1, Commit: 3}
+	data, err := m.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		encoding string
+		body     func() []byte
+
+		wcode int
+	}{
+		{"", func() []byte { return data }, http.StatusNoContent},
+		{"snappy", func() []byte { b, _ := compress(data, compressionSnappy, "test", "2"); return b }, http.StatusNoContent},
+		{"gzip", func() []byte { b, _ := compress(data, compressionGzip, "test", "2"); return b }, http.StatusNoContent},
+		{"br", func() []byte { return data }, http.StatusUnsupportedMediaType},
+	}
+	for i, tt := range tests {
+		req, err := http.NewRequest("POST", "foo", bytes.NewReader(tt.body()))
+		if err != nil {
+			t.Fatalf("#%d: could not create request: %#v", i, err)
+		}
+		req.Header.Set("X-Etcd-Cluster-ID", "0")
+		req.Header.Set("X-Server-Version", "2.1.0")
+		if tt.encoding != "" {
+			req.Header.Set("Content-Encoding", tt.encoding)
+		}
+		rw := httptest.NewRecorder()
+		h := NewHandler(&fakeRaft{}, types.ID(0))
+		h.ServeHTTP(rw, req)
+		if rw.Code != tt.wcode {
+			t.Errorf("#%d: got code=%d, want %d", i, rw.Code, tt.wcode)
+		}
+	}
+}
+
+// TestSendMessageCompressed tests that the messages are sent compressed
+// between the members that both accept the compression.
+func TestSendMessageCompressed(t *testing.T) {
+	for i, c := range supportedCompressions {
+		// member 1
+		tr := NewTransporter(&http.Transport{}, types.ID(1), types.ID(1), &fakeRaft{}, nil, nil, newServerStats(), stats.NewLeaderStats("1"))
+		if err := tr.(Compressible).SetCompression(string(c)); err != nil {
+			t.Fatal(err)
+		}
+		srv := httptest.NewServer(tr.Handler())
+
+		// member 2
+		recvc := make(chan raftpb.Message, 1)
+		tr2 := NewTransporter(&http.Transport{}, types.ID(2), types.ID(1), &fakeRaft{recvc: recvc}, nil, nil, newServerStats(), stats.NewLeaderStats("2"))
+		if err := tr2.(Compressible).SetCompression(string(c)); err != nil {
+			t.Fatal(err)
+		}
+		srv2 := httptest.NewServer(tr2.Handler())
+
+		tr.AddPeer(types.ID(2), []string{srv2.URL})
+		tr2.AddPeer(types.ID(1), []string{srv.URL})
+		p := tr.(*transport).Get(types.ID(2)).(*peer)
+		if !waitStreamWorking(p) {
+			t.Fatalf("#%d: stream from 1 to 2 is not in work as expected", i)
+		}
+		if g := p.pipeline.compression.pick(); g != c {
+			t.Errorf("#%d: compression = %q, want %q", i, g, c)
+		}
+
+		data := bytes.Repeat([]byte("some data"), 100)
+		msgs := []raftpb.Message{
+			// sent by stream msgappv2
+			{Type: raftpb.MsgApp, From: 1, To: 2, Term: 1, Index: 3, LogTerm: 1, Entries: []raftpb.Entry{{Index: 4, Term: 1, Data: data}}, Commit: 3},
+			// sent by pipeline
+			{Type: raftpb.MsgSnap, From: 1, To: 2, Term: 1, Snapshot: raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 1000, Term: 1}, Data: data}},
+			{Type: raftpb.MsgHeartbeat, From: 1, To: 2, Term: 1, Commit: 3},
+		}
+		for j, m := range msgs {
+			tr.Send([]raftpb.Message{m})
+			select {
+			case msg := <-recvc:
+				if !reflect.DeepEqual(msg, m) {
+					t.Errorf("#%d.%d: msg = %+v, want %+v", i, j, msg, m)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("#%d.%d: failed to receive the message", i, j)
+			}
+		}
+		// stream message only carries small messages, which are not
+		// smaller compressed.
+		for _, typ := range []string{string(streamTypeMsgAppV2), pipelineMsg} {
+			raw := counterValue(t, rawBytes.WithLabelValues(typ, "2", string(c)).Write)
+			compressed := counterValue(t, compressedBytes.WithLabelValues(typ, "2", string(c)).Write)
+			if compressed == 0 || compressed >= raw {
+				t.Errorf("#%d: %s raw bytes = %v, compressed bytes = %v, want 0 < compressed < raw", i, typ, raw, compressed)
+			}
+		}
+
+		tr.Stop()
+		tr2.Stop()
+		srv.Close()
+		srv2.Close()
+	}
+}
+
+// TestSendMessageCompressedToOldPeer tests that the messages are sent
+// uncompressed to the member that does not advertise any compression.
+func TestSendMessageCompressedToOldPeer(t *testing.T) {
+	// member 1
+	tr := NewTransporter(&http.Transport{}, types.ID(1), types.ID(1), &fakeRaft{}, nil, nil, newServerStats(), stats.NewLeaderStats("1"))
+	if err := tr.(Compressible).SetCompression(string(compressionSnappy)); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(tr.Handler())
+	defer srv.Close()
+
+	// member 2, which does not know the compression headers
+	recvc := make(chan raftpb.Message, 1)
+	tr2 := NewTransporter(&http.Transport{}, types.ID(2), types.ID(1), &fakeRaft{recvc: recvc}, nil, nil, newServerStats(), stats.NewLeaderStats("2"))
+	srv2 := httptest.NewServer(&noCompressionHandler{h: tr2.Handler()})
+	defer srv2.Close()
+
+	tr.AddPeer(types.ID(2), []string{srv2.URL})
+	defer tr.Stop()
+	tr2.AddPeer(types.ID(1), []string{srv.URL})
+	defer tr2.Stop()
+	p := tr.(*transport).Get(types.ID(2)).(*peer)
+	if !waitStreamWorking(p) {
+		t.Fatalf("stream from 1 to 2 is not in work as expected")
+	}
+	if g := p.pipeline.compression.pick(); g != compressionNone {
+		t.Errorf("compression = %q, want %q", g, compressionNone)
+	}
+
+	msgs := []raftpb.Message{
+		{Type: raftpb.MsgApp, From: 1, To: 2, Term: 1, Index: 3, LogTerm: 0, Entries: []raftpb.Entry{{Index: 4, Term: 1, Data: []byte("some data")}}, Commit: 3},
+		{Type: raftpb.MsgSnap, From: 1, To: 2, Term: 1, Snapshot: raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 1000, Term: 1}, Data: []byte("some data")}},
+	}
+	for i, m := range msgs {
+		tr.Send([]raftpb.Message{m})
+		select {
+		case msg := <-recvc:
+			if !reflect.DeepEqual(msg, m) {
+				t.Errorf("#%d: msg = %+v, want %+v", i, msg, m)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("#%d: failed to receive the message", i)
+		}
+	}
+}
+
+// TestSendSnapshotCompressed tests that the v3 storage snapshot is sent
+// compressed to the member that accepts the compression.
+func TestSendSnapshotCompressed(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "rafthttp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// member 1
+	tr := NewTransporter(&http.Transport{}, types.ID(1), types.ID(1), &fakeRaft{}, nil, nil, newServerStats(), stats.NewLeaderStats("1"))
+	if err := tr.(Compressible).SetCompression(string(compressionGzip)); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(tr.Handler())
+	defer srv.Close()
+
+	// member 2
+	recvc := make(chan raftpb.Message, 1)
+	ss := snap.New(dir)
+	tr2 := NewTransporter(&http.Transport{}, types.ID(2), types.ID(1), &fakeRaft{recvc: recvc}, ss, nil, newServerStats(), stats.NewLeaderStats("2"))
+	srv2 := httptest.NewServer(tr2.Handler())
+	defer srv2.Close()
+
+	tr.AddPeer(types.ID(2), []string{srv2.URL})
+	defer tr.Stop()
+	tr2.AddPeer(types.ID(1), []string{srv.URL})
+	defer tr2.Stop()
+	// the compressions accepted by member 2 are learnt through the streams.
+	if !waitStreamWorking(tr.(*transport).Get(types.ID(2)).(*peer)) {
+		t.Fatalf("stream from 1 to 2 is not in work as expected")
+	}
+
+	data := bytes.Repeat([]byte("some db data"), 10000)
+	m := raftpb.Message{Type: raftpb.MsgSnap, From: 1, To: 2, Term: 1, Snapshot: raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 1000, Term: 1}, Data: []byte("v2")}}
+	sm := snap.NewMessage(m, ioutil.NopCloser(bytes.NewReader(data)))
+	tr.SendSnapshot(*sm)
+
+	select {
+	case msg := <-recvc:
+		if !reflect.DeepEqual(msg, m) {
+			t.Errorf("msg = %+v, want %+v", msg, m)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("failed to receive the snapshot message")
+	}
+	if ok := <-sm.CloseNotify(); !ok {
+		t.Errorf("snapshot is reported failed, want sent")
+	}
+	fn, err := ss.DBFilePath(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(g, data) {
+		t.Errorf("saved data length = %d, want %d", len(g), len(data))
+	}
+	raw := counterValue(t, rawBytes.WithLabelValues(sendSnap, "2", string(compressionGzip)).Write)
+	compressed := counterValue(t, compressedBytes.WithLabelValues(sendSnap, "2", string(compressionGzip)).Write)
+	if raw < float64(len(data)) || compressed >= raw {
+		t.Errorf("raw bytes = %v, compressed bytes = %v, want raw >= %d and compressed < raw", raw, compressed, len(data))
+	}
+}
+
+func counterValue(t *testing.T, write func(*dto.Metric) error) float64 {
+	var m dto.Metric
+	if err := write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+// noCompressionHandler serves like a member of an older version, which
+// neither sends nor advertises any compression header.
+type noCompressionHandler struct {
+	h http.Handler
+}
+
+func (h *noCompressionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Header.Del("X-Raft-Accept-Compression")
+	h.h.ServeHTTP(&noCompressionResponseWriter{ResponseWriter: w}, r)
+}
+
+type noCompressionResponseWriter struct {
+	http.ResponseWriter
+}
+
+func (w *noCompressionResponseWriter) WriteHeader(code int) {
+	w.Header().Del("X-Raft-Accept-Compression")
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *noCompressionResponseWriter) Flush() { w.ResponseWriter.(http.Flusher).Flush() }
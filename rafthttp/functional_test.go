@@ -15,8 +15,11 @@
 package rafthttp
 
 import (
+	"bytes"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"reflect"
 	"testing"
 	"time"
@@ -26,18 +29,19 @@ import (
 	"github.com/coreos/etcd/pkg/types"
 	"github.com/coreos/etcd/raft"
 	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/coreos/etcd/snap"
 )
 
 func TestSendMessage(t *testing.T) {
 	// member 1
-	tr := NewTransporter(&http.Transport{}, types.ID(1), types.ID(1), &fakeRaft{}, nil, newServerStats(), stats.NewLeaderStats("1"))
+	tr := NewTransporter(&http.Transport{}, types.ID(1), types.ID(1), &fakeRaft{}, nil, nil, newServerStats(), stats.NewLeaderStats("1"))
 	srv := httptest.NewServer(tr.Handler())
 	defer srv.Close()
 
 	// member 2
 	recvc := make(chan raftpb.Message, 1)
 	p := &fakeRaft{recvc: recvc}
-	tr2 := NewTransporter(&http.Transport{}, types.ID(2), types.ID(1), p, nil, newServerStats(), stats.NewLeaderStats("2"))
+	tr2 := NewTransporter(&http.Transport{}, types.ID(2), types.ID(1), p, nil, nil, newServerStats(), stats.NewLeaderStats("2"))
 	srv2 := httptest.NewServer(tr2.Handler())
 	defer srv2.Close()
 
@@ -58,6 +62,8 @@ func TestSendMessage(t *testing.T) {
 		{Type: raftpb.MsgAppResp, From: 1, To: 2, Term: 1, Index: 3},
 		{Type: raftpb.MsgVote, From: 1, To: 2, Term: 1, Index: 3, LogTerm: 0},
 		{Type: raftpb.MsgVoteResp, From: 1, To: 2, Term: 1},
+		{Type: raftpb.MsgPreVote, From: 1, To: 2, Term: 2, Index: 3, LogTerm: 0},
+		{Type: raftpb.MsgPreVoteResp, From: 1, To: 2, Term: 2},
 		{Type: raftpb.MsgSnap, From: 1, To: 2, Term: 1, Snapshot: raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 1000, Term: 1}, Data: data}},
 		{Type: raftpb.MsgHeartbeat, From: 1, To: 2, Term: 1, Commit: 3},
 		{Type: raftpb.MsgHeartbeatResp, From: 1, To: 2, Term: 1},
@@ -75,14 +81,14 @@ func TestSendMessage(t *testing.T) {
 // remote in a limited time when all underlying connections are broken.
 func TestSendMessageWhenStreamIsBroken(t *testing.T) {
 	// member 1
-	tr := NewTransporter(&http.Transport{}, types.ID(1), types.ID(1), &fakeRaft{}, nil, newServerStats(), stats.NewLeaderStats("1"))
+	tr := NewTransporter(&http.Transport{}, types.ID(1), types.ID(1), &fakeRaft{}, nil, nil, newServerStats(), stats.NewLeaderStats("1"))
 	srv := httptest.NewServer(tr.Handler())
 	defer srv.Close()
 
 	// member 2
 	recvc := make(chan raftpb.Message, 1)
 	p := &fakeRaft{recvc: recvc}
-	tr2 := NewTransporter(&http.Transport{}, types.ID(2), types.ID(1), p, nil, newServerStats(), stats.NewLeaderStats("2"))
+	tr2 := NewTransporter(&http.Transport{}, types.ID(2), types.ID(1), p, nil, nil, newServerStats(), stats.NewLeaderStats("2"))
 	srv2 := httptest.NewServer(tr2.Handler())
 	defer srv2.Close()
 
@@ -113,6 +119,61 @@ func TestSendMessageWhenStreamIsBroken(t *testing.T) {
 	}
 }
 
+// TestSendSnapshot tests that the snapshot message is processed by the
+// remote after the v3 storage snapshot streamed with it is saved.
+func TestSendSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "rafthttp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// member 1
+	tr := NewTransporter(&http.Transport{}, types.ID(1), types.ID(1), &fakeRaft{}, nil, nil, newServerStats(), stats.NewLeaderStats("1"))
+	srv := httptest.NewServer(tr.Handler())
+	defer srv.Close()
+
+	// member 2
+	recvc := make(chan raftpb.Message, 1)
+	p := &fakeRaft{recvc: recvc}
+	ss := snap.New(dir)
+	tr2 := NewTransporter(&http.Transport{}, types.ID(2), types.ID(1), p, ss, nil, newServerStats(), stats.NewLeaderStats("2"))
+	srv2 := httptest.NewServer(tr2.Handler())
+	defer srv2.Close()
+
+	tr.AddPeer(types.ID(2), []string{srv2.URL})
+	defer tr.Stop()
+
+	// larger than a chunk of the snapshot stream
+	data := bytes.Repeat([]byte("some db data"), 10000)
+	m := raftpb.Message{Type: raftpb.MsgSnap, From: 1, To: 2, Term: 1, Snapshot: raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 1000, Term: 1}, Data: []byte("v2")}}
+	sm := snap.NewMessage(m, ioutil.NopCloser(bytes.NewReader(data)))
+	tr.SendSnapshot(*sm)
+
+	select {
+	case msg := <-recvc:
+		if !reflect.DeepEqual(msg, m) {
+			t.Errorf("msg = %+v, want %+v", msg, m)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("failed to receive the snapshot message")
+	}
+	if ok := <-sm.CloseNotify(); !ok {
+		t.Errorf("snapshot is reported failed, want sent")
+	}
+	fn, err := ss.DBFilePath(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(g, data) {
+		t.Errorf("saved data length = %d, want %d", len(g), len(data))
+	}
+}
+
 func newServerStats() *stats.ServerStats {
 	ss := &stats.ServerStats{}
 	ss.Initialize()
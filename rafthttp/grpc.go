@@ -0,0 +1,231 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rafthttp
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	"github.com/coreos/etcd/Godeps/_workspace/src/google.golang.org/grpc"
+	"github.com/coreos/etcd/Godeps/_workspace/src/google.golang.org/grpc/codes"
+	"github.com/coreos/etcd/Godeps/_workspace/src/google.golang.org/grpc/metadata"
+	"github.com/coreos/etcd/etcdserver/stats"
+	"github.com/coreos/etcd/pkg/types"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/coreos/etcd/rafthttp/rafthttppb"
+	"github.com/coreos/etcd/snap"
+)
+
+// transportGRPC is the value of the X-Raft-Transport header, with which
+// a member advertises that it serves the gRPC transport.
+const transportGRPC = "grpc"
+
+// GRPCTransporter is a Transporter that sends the messages and the
+// snapshots to the peers over gRPC streams on HTTP/2.
+// A peer is sent to over gRPC only after it advertised the gRPC transport
+// in the response of a stream request. The peers that do not support it,
+// such as members running an older version during a rolling upgrade, are
+// sent to over the HTTP streams and pipeline as usual.
+type GRPCTransporter interface {
+	Transporter
+	// ServeGRPC accepts the gRPC connections from the remote peers on the
+	// listener l. The HTTP handler of the transporter MUST still be
+	// served for the peers that do not use gRPC.
+	ServeGRPC(l net.Listener) error
+}
+
+type grpcTransport struct {
+	*transport
+	srv *grpc.Server
+}
+
+// NewGRPCTransporter creates a GRPCTransporter. The arguments are the
+// same as NewTransporter.
+func NewGRPCTransporter(rt http.RoundTripper, id, cid types.ID, r Raft, snapshotter *snap.Snapshotter, errorc chan error, ss *stats.ServerStats, ls *stats.LeaderStats) GRPCTransporter {
+	t := NewTransporter(rt, id, cid, r, snapshotter, errorc, ss, ls).(*transport)
+	t.grpc = true
+	srv := grpc.NewServer()
+	rafthttppb.RegisterRaftServer(srv, &grpcHandler{
+		peerGetter:  t,
+		r:           r,
+		snapshotter: snapshotter,
+		id:          id,
+		cid:         cid,
+	})
+	return &grpcTransport{transport: t, srv: srv}
+}
+
+func (t *grpcTransport) ServeGRPC(l net.Listener) error { return t.srv.Serve(l) }
+
+// SetPeerVerifier panics, since the gRPC server does not tell the
+// certificates of the peers, which cannot be verified.
+func (t *grpcTransport) SetPeerVerifier(v PeerVerifier) {
+	plog.Panicf("cannot verify the peer certificates over the gRPC transport")
+}
+
+// grpcHandler serves the gRPC transport. It is the counterpart of the
+// stream and snapshot HTTP handlers.
+type grpcHandler struct {
+	peerGetter  peerGetter
+	r           Raft
+	snapshotter *snap.Snapshotter
+	id          types.ID
+	cid         types.ID
+}
+
+// Message receives the messages from the remote peer and hands them to
+// the local raft through the peer. It sends link heartbeats back, so the
+// remote detects a broken stream in time.
+func (h *grpcHandler) Message(stream rafthttppb.Raft_MessageServer) error {
+	from, err := h.checkSender(stream.Context())
+	if err != nil {
+		return err
+	}
+	p := h.peerGetter.Get(from)
+	if p == nil {
+		// see streamHandler for the cases in which this happens.
+		plog.Errorf("failed to find member %s in cluster %s", from, h.cid)
+		return grpc.Errorf(codes.NotFound, "error sender not found")
+	}
+
+	donec := make(chan struct{})
+	defer close(donec)
+	go func() {
+		ticker := time.NewTicker(ConnReadTimeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := stream.Send(&linkHeartbeatMessage); err != nil {
+					return
+				}
+			case <-donec:
+				return
+			}
+		}
+	}()
+
+	for {
+		m, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if isLinkHeartbeatMessage(*m) {
+			continue
+		}
+		p.receive(*m)
+	}
+}
+
+// Snapshot receives the snapshot message, saves the v3 storage snapshot
+// that follows it, and then processes the message like the snapshot HTTP
+// handler does.
+func (h *grpcHandler) Snapshot(stream rafthttppb.Raft_SnapshotServer) error {
+	if _, err := h.checkSender(stream.Context()); err != nil {
+		return err
+	}
+
+	r := &snapshotChunkReader{stream: stream}
+	dec := &messageDecoder{r: r}
+	m, err := dec.decode()
+	if err != nil {
+		plog.Errorf("failed to decode raft message (%v)", err)
+		return grpc.Errorf(codes.InvalidArgument, "error decoding raft message")
+	}
+	if m.Type != raftpb.MsgSnap {
+		plog.Errorf("unexpected raft message type %s on snapshot stream", m.Type)
+		return grpc.Errorf(codes.InvalidArgument, "wrong raft message type")
+	}
+	if h.snapshotter == nil {
+		plog.Errorf("cannot save v3 storage snapshot without snapshotter")
+		return grpc.Errorf(codes.Internal, "error saving snapshot")
+	}
+
+	index := m.Snapshot.Metadata.Index
+	if _, err := h.snapshotter.SaveDBFrom(snap.NewReader(r), index); err != nil {
+		plog.Errorf("failed to save v3 storage snapshot at index %d (%v)", index, err)
+		return grpc.Errorf(codes.Internal, "error saving snapshot")
+	}
+	if err := h.r.Process(context.TODO(), m); err != nil {
+		// the snapshot is never applied without the message
+		if rerr := h.snapshotter.RemoveDB(index); rerr != nil {
+			plog.Warningf("failed to remove v3 storage snapshot at index %d (%v)", index, rerr)
+		}
+		plog.Warningf("failed to process raft message (%v)", err)
+		return grpc.Errorf(codes.Internal, "error processing raft message")
+	}
+	return stream.SendAndClose(&rafthttppb.SnapshotResponse{})
+}
+
+// checkSender checks the metadata of the stream like the HTTP handlers
+// check the request header, and returns the ID of the remote peer.
+func (h *grpcHandler) checkSender(ctx context.Context) (types.ID, error) {
+	md, _ := metadata.FromContext(ctx)
+	header := make(http.Header)
+	for k, v := range md {
+		header.Set(k, v)
+	}
+
+	if err := checkVersionCompability(header.Get("X-Server-From"), serverVersion(header), minClusterVersion(header)); err != nil {
+		plog.Errorf("request received was ignored (%v)", err)
+		return 0, grpc.Errorf(codes.FailedPrecondition, "%s", errIncompatibleVersion)
+	}
+	if gcid := header.Get("X-Etcd-Cluster-ID"); gcid != h.cid.String() {
+		plog.Errorf("gRPC stream ignored (cluster ID mismatch got %s want %s)", gcid, h.cid)
+		return 0, grpc.Errorf(codes.FailedPrecondition, "%s", errClusterIDMismatch)
+	}
+	if gto := header.Get("X-Raft-To"); gto != h.id.String() {
+		plog.Errorf("gRPC stream ignored (ID mismatch got %s want %s)", gto, h.id)
+		return 0, grpc.Errorf(codes.FailedPrecondition, "to field mismatch")
+	}
+
+	fromStr := header.Get("X-Server-From")
+	from, err := types.IDFromString(fromStr)
+	if err != nil {
+		plog.Errorf("failed to parse from %s into ID (%v)", fromStr, err)
+		return 0, grpc.Errorf(codes.InvalidArgument, "invalid from")
+	}
+	if h.r.IsIDRemoved(uint64(from)) {
+		plog.Warningf("rejected the gRPC stream from peer %s since it was removed", from)
+		return 0, grpc.Errorf(codes.PermissionDenied, "removed member")
+	}
+	return from, nil
+}
+
+// snapshotChunkReader reads the data of the snapshot chunks received on
+// the stream.
+type snapshotChunkReader struct {
+	stream rafthttppb.Raft_SnapshotServer
+	buf    []byte
+}
+
+func (r *snapshotChunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		c, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = c.Data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
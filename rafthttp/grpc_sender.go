@@ -0,0 +1,362 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rafthttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/golang.org/x/net/context"
+	"github.com/coreos/etcd/Godeps/_workspace/src/google.golang.org/grpc"
+	"github.com/coreos/etcd/Godeps/_workspace/src/google.golang.org/grpc/codes"
+	"github.com/coreos/etcd/Godeps/_workspace/src/google.golang.org/grpc/credentials"
+	"github.com/coreos/etcd/Godeps/_workspace/src/google.golang.org/grpc/metadata"
+	"github.com/coreos/etcd/pkg/types"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/coreos/etcd/rafthttp/rafthttppb"
+	"github.com/coreos/etcd/version"
+)
+
+const (
+	// snapChunkSize is the size of the snapshot chunks sent over gRPC.
+	snapChunkSize = 32 * 1024
+)
+
+var (
+	errGRPCNotWorking = errors.New("gRPC stream is not working")
+	errLinkTimeout    = errors.New("no link heartbeat from the remote")
+)
+
+// grpcSender is a long-running go-routine that sends the messages to the
+// remote peer over a gRPC stream. It connects to the remote only after
+// the remote advertised that it serves the gRPC transport.
+type grpcSender struct {
+	tr            http.RoundTripper
+	picker        *urlPicker
+	local, remote types.ID
+	cid           types.ID
+	status        *peerStatus
+	r             Raft
+	errorc        chan<- error
+
+	mu        sync.Mutex // guard the fields below
+	supported bool
+	working   bool
+	conn      *grpc.ClientConn
+	msgc      chan raftpb.Message
+	urgentc   chan raftpb.Message
+
+	supportc chan struct{}
+	stopc    chan struct{}
+	done     chan struct{}
+}
+
+func startGRPCSender(tr http.RoundTripper, picker *urlPicker, local, remote, cid types.ID, status *peerStatus, r Raft, errorc chan<- error) *grpcSender {
+	s := &grpcSender{
+		tr:       tr,
+		picker:   picker,
+		local:    local,
+		remote:   remote,
+		cid:      cid,
+		status:   status,
+		r:        r,
+		errorc:   errorc,
+		msgc:     make(chan raftpb.Message, streamBufSize),
+		urgentc:  make(chan raftpb.Message, streamBufSize),
+		supportc: make(chan struct{}, 1),
+		stopc:    make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *grpcSender) run() {
+	for {
+		if s.isSupported() {
+			if err := s.stream(); err != nil {
+				s.status.deactivate(failureType{source: grpcStream, action: "stream"}, err.Error())
+			}
+		}
+		select {
+		// Wait 100ms to create a new stream, so it doesn't bring too much
+		// overhead when retry.
+		case <-time.After(100 * time.Millisecond):
+		case <-s.stopc:
+			close(s.done)
+			return
+		}
+	}
+}
+
+// stream dials the remote and sends the messages over a gRPC stream
+// until the stream breaks, the remote stops supporting gRPC, or the
+// sender is stopped.
+func (s *grpcSender) stream() error {
+	u := s.picker.pick()
+	cc, err := s.dial(u)
+	if err != nil {
+		s.picker.unreachable(u)
+		return err
+	}
+	ctx, cancel := context.WithCancel(s.newContext(context.Background()))
+	defer cancel()
+	stream, err := rafthttppb.NewRaftClient(cc).Message(ctx)
+	if err != nil {
+		cc.Close()
+		s.picker.unreachable(u)
+		return err
+	}
+
+	s.mu.Lock()
+	s.conn = cc
+	s.working = true
+	msgc, urgentc := s.msgc, s.urgentc
+	s.mu.Unlock()
+	s.status.activate()
+	defer s.close()
+
+	recvc := make(chan struct{}, 1)
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			// the remote only sends link heartbeats back.
+			if _, err := stream.Recv(); err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case recvc <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(ConnReadTimeout / 3)
+	defer heartbeat.Stop()
+	timeout := time.NewTimer(ConnReadTimeout)
+	defer timeout.Stop()
+	send := func(m raftpb.Message) error {
+		start := time.Now()
+		if err := stream.Send(&m); err != nil {
+			reportSentFailure(grpcStream, m)
+			s.r.ReportUnreachable(m.To)
+			return err
+		}
+		reportSentDuration(grpcStream, m, time.Since(start))
+		return nil
+	}
+	for {
+		// the urgent messages are sent ahead of the queued messages.
+		select {
+		case m := <-urgentc:
+			if len(msgc) > 0 {
+				urgentMsgSentAhead.WithLabelValues(grpcStream, s.remote.String()).Inc()
+			}
+			if err := send(m); err != nil {
+				return err
+			}
+			continue
+		default:
+		}
+
+		select {
+		case m := <-urgentc:
+			if err := send(m); err != nil {
+				return err
+			}
+		case m := <-msgc:
+			if err := send(m); err != nil {
+				return err
+			}
+		case <-heartbeat.C:
+			start := time.Now()
+			if err := stream.Send(&linkHeartbeatMessage); err != nil {
+				reportSentFailure(grpcStream, linkHeartbeatMessage)
+				return err
+			}
+			reportSentDuration(grpcStream, linkHeartbeatMessage, time.Since(start))
+		case <-recvc:
+			timeout.Reset(ConnReadTimeout)
+		case <-timeout.C:
+			return errLinkTimeout
+		case err := <-errc:
+			s.checkRemoved(err)
+			return err
+		case <-s.supportc:
+			if !s.isSupported() {
+				return nil
+			}
+		case <-s.stopc:
+			return nil
+		}
+	}
+}
+
+// sendSnap sends the snapshot body to the remote over the working gRPC
+// connection, and returns nil if the remote saved the snapshot and
+// processed the raft message.
+func (s *grpcSender) sendSnap(body io.Reader, stopc <-chan struct{}) error {
+	s.mu.Lock()
+	cc := s.conn
+	s.mu.Unlock()
+	if cc == nil {
+		return errGRPCNotWorking
+	}
+
+	ctx, cancel := context.WithCancel(s.newContext(context.Background()))
+	defer cancel()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-stopc:
+			cancel()
+		}
+	}()
+
+	stream, err := rafthttppb.NewRaftClient(cc).Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, snapChunkSize)
+	for {
+		n, rerr := body.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&rafthttppb.SnapshotChunk{Data: buf[:n]}); err != nil {
+				return err
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return rerr
+		}
+	}
+	_, err = stream.CloseAndRecv()
+	s.checkRemoved(err)
+	return err
+}
+
+func (s *grpcSender) dial(u url.URL) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{grpc.WithTimeout(DialTimeout)}
+	if u.Scheme == "https" {
+		tr, ok := s.tr.(*http.Transport)
+		switch {
+		case ok && tr.DialTLS != nil:
+			// the transport does the TLS handshake with the latest
+			// reloaded config.
+			dialTLS := tr.DialTLS
+			opts = append(opts, grpc.WithDialer(func(addr string, _ time.Duration) (net.Conn, error) {
+				return dialTLS("tcp", addr)
+			}))
+		case ok && tr.TLSClientConfig != nil:
+			opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tr.TLSClientConfig)))
+		default:
+			return nil, fmt.Errorf("no TLS config to dial %s", u.String())
+		}
+	}
+	return grpc.Dial(u.Host, opts...)
+}
+
+// newContext returns a context that carries the same information as the
+// header of the HTTP stream requests.
+func (s *grpcSender) newContext(ctx context.Context) context.Context {
+	return metadata.NewContext(ctx, metadata.New(map[string]string{
+		"x-server-from":         s.local.String(),
+		"x-server-version":      version.Version,
+		"x-min-cluster-version": version.MinClusterVersion,
+		"x-etcd-cluster-id":     s.cid.String(),
+		"x-raft-to":             s.remote.String(),
+	}))
+}
+
+func (s *grpcSender) checkRemoved(err error) {
+	if grpc.Code(err) != codes.PermissionDenied {
+		return
+	}
+	select {
+	case s.errorc <- fmt.Errorf("the member has been permanently removed from the cluster"):
+	default:
+	}
+}
+
+// setSupported sets whether the remote serves the gRPC transport.
+func (s *grpcSender) setSupported(supported bool) {
+	s.mu.Lock()
+	changed := s.supported != supported
+	s.supported = supported
+	s.mu.Unlock()
+	if changed {
+		plog.Infof("gRPC transport supported by %s: %v", s.remote, supported)
+		select {
+		case s.supportc <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *grpcSender) isSupported() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.supported
+}
+
+func (s *grpcSender) isWorking() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.working
+}
+
+func (s *grpcSender) writec() (chan<- raftpb.Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.msgc, s.working
+}
+
+// urgentWritec returns the chan of the urgent messages, which are sent
+// ahead of the messages written into the chan returned by writec.
+func (s *grpcSender) urgentWritec() (chan<- raftpb.Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.urgentc, s.working
+}
+
+func (s *grpcSender) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.working {
+		return
+	}
+	s.conn.Close()
+	if len(s.msgc) > 0 || len(s.urgentc) > 0 {
+		s.r.ReportUnreachable(uint64(s.remote))
+	}
+	s.msgc = make(chan raftpb.Message, streamBufSize)
+	s.urgentc = make(chan raftpb.Message, streamBufSize)
+	s.conn = nil
+	s.working = false
+}
+
+func (s *grpcSender) stop() {
+	close(s.stopc)
+	<-s.done
+}
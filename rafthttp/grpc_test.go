@@ -0,0 +1,214 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rafthttp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/etcdserver/stats"
+	etcdtransport "github.com/coreos/etcd/pkg/transport"
+	"github.com/coreos/etcd/pkg/types"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/coreos/etcd/snap"
+)
+
+// TestGRPCSendMessage tests that the messages are sent over gRPC between
+// the members that both use the gRPC transport.
+func TestGRPCSendMessage(t *testing.T) {
+	// member 1
+	tr := NewGRPCTransporter(&http.Transport{}, types.ID(1), types.ID(1), &fakeRaft{}, nil, nil, newServerStats(), stats.NewLeaderStats("1"))
+	url1, stop1 := serveGRPCTransport(t, tr)
+	defer stop1()
+
+	// member 2
+	recvc := make(chan raftpb.Message, 1)
+	tr2 := NewGRPCTransporter(&http.Transport{}, types.ID(2), types.ID(1), &fakeRaft{recvc: recvc}, nil, nil, newServerStats(), stats.NewLeaderStats("2"))
+	url2, stop2 := serveGRPCTransport(t, tr2)
+	defer stop2()
+
+	tr.AddPeer(types.ID(2), []string{url2})
+	defer tr.Stop()
+	tr2.AddPeer(types.ID(1), []string{url1})
+	defer tr2.Stop()
+
+	p := tr.(*grpcTransport).Get(types.ID(2)).(*peer)
+	if !waitGRPCWorking(p) {
+		t.Fatalf("gRPC stream from 1 to 2 is not in work as expected")
+	}
+
+	data := []byte("some data")
+	tests := []raftpb.Message{
+		{Type: raftpb.MsgProp, From: 1, To: 2, Entries: []raftpb.Entry{{Data: data}}},
+		{Type: raftpb.MsgApp, From: 1, To: 2, Term: 1, Index: 3, LogTerm: 0, Entries: []raftpb.Entry{{Index: 4, Term: 1, Data: data}}, Commit: 3},
+		{Type: raftpb.MsgAppResp, From: 1, To: 2, Term: 1, Index: 3},
+		{Type: raftpb.MsgVote, From: 1, To: 2, Term: 1, Index: 3, LogTerm: 0},
+		{Type: raftpb.MsgHeartbeat, From: 1, To: 2, Term: 1, Commit: 3},
+	}
+	for i, tt := range tests {
+		if _, name := p.pick(tt); name != grpcStream {
+			t.Errorf("#%d: picked = %s, want %s", i, name, grpcStream)
+		}
+		tr.Send([]raftpb.Message{tt})
+		select {
+		case msg := <-recvc:
+			if !reflect.DeepEqual(msg, tt) {
+				t.Errorf("#%d: msg = %+v, want %+v", i, msg, tt)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("#%d: failed to receive the message", i)
+		}
+	}
+}
+
+// TestGRPCFallbackToHTTP tests that a member using the gRPC transport
+// talks to a member that does not support it over the HTTP streams.
+func TestGRPCFallbackToHTTP(t *testing.T) {
+	// member 1
+	tr := NewGRPCTransporter(&http.Transport{}, types.ID(1), types.ID(1), &fakeRaft{}, nil, nil, newServerStats(), stats.NewLeaderStats("1"))
+	url1, stop1 := serveGRPCTransport(t, tr)
+	defer stop1()
+
+	// member 2
+	recvc := make(chan raftpb.Message, 1)
+	tr2 := NewTransporter(&http.Transport{}, types.ID(2), types.ID(1), &fakeRaft{recvc: recvc}, nil, nil, newServerStats(), stats.NewLeaderStats("2"))
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go http.Serve(ln, tr2.Handler())
+
+	tr.AddPeer(types.ID(2), []string{"http://" + ln.Addr().String()})
+	defer tr.Stop()
+	tr2.AddPeer(types.ID(1), []string{url1})
+	defer tr2.Stop()
+
+	p := tr.(*grpcTransport).Get(types.ID(2)).(*peer)
+	if !waitStreamWorking(p) {
+		t.Fatalf("stream from 1 to 2 is not in work as expected")
+	}
+	if p.grpcSender.isSupported() {
+		t.Errorf("gRPC supported = true, want false")
+	}
+
+	m := raftpb.Message{Type: raftpb.MsgHeartbeat, From: 1, To: 2, Term: 1, Commit: 3}
+	if _, name := p.pick(m); name != streamMsg {
+		t.Errorf("picked = %s, want %s", name, streamMsg)
+	}
+	tr.Send([]raftpb.Message{m})
+	select {
+	case msg := <-recvc:
+		if !reflect.DeepEqual(msg, m) {
+			t.Errorf("msg = %+v, want %+v", msg, m)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("failed to receive the message")
+	}
+}
+
+// TestGRPCSendSnapshot tests that the snapshot is streamed over gRPC, and
+// the snapshot message is processed by the remote after the v3 storage
+// snapshot is saved.
+func TestGRPCSendSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "rafthttp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// member 1
+	tr := NewGRPCTransporter(&http.Transport{}, types.ID(1), types.ID(1), &fakeRaft{}, nil, nil, newServerStats(), stats.NewLeaderStats("1"))
+	url1, stop1 := serveGRPCTransport(t, tr)
+	defer stop1()
+
+	// member 2
+	recvc := make(chan raftpb.Message, 1)
+	ss := snap.New(dir)
+	tr2 := NewGRPCTransporter(&http.Transport{}, types.ID(2), types.ID(1), &fakeRaft{recvc: recvc}, ss, nil, newServerStats(), stats.NewLeaderStats("2"))
+	url2, stop2 := serveGRPCTransport(t, tr2)
+	defer stop2()
+
+	tr.AddPeer(types.ID(2), []string{url2})
+	defer tr.Stop()
+	tr2.AddPeer(types.ID(1), []string{url1})
+	defer tr2.Stop()
+
+	if !waitGRPCWorking(tr.(*grpcTransport).Get(types.ID(2)).(*peer)) {
+		t.Fatalf("gRPC stream from 1 to 2 is not in work as expected")
+	}
+
+	// larger than a chunk of the gRPC snapshot stream
+	data := bytes.Repeat([]byte("some db data"), 10000)
+	m := raftpb.Message{Type: raftpb.MsgSnap, From: 1, To: 2, Term: 1, Snapshot: raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 1000, Term: 1}, Data: []byte("v2")}}
+	sm := snap.NewMessage(m, ioutil.NopCloser(bytes.NewReader(data)))
+	tr.SendSnapshot(*sm)
+
+	select {
+	case msg := <-recvc:
+		if !reflect.DeepEqual(msg, m) {
+			t.Errorf("msg = %+v, want %+v", msg, m)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("failed to receive the snapshot message")
+	}
+	if ok := <-sm.CloseNotify(); !ok {
+		t.Errorf("snapshot is reported failed, want sent")
+	}
+	fn, err := ss.DBFilePath(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(g, data) {
+		t.Errorf("saved data length = %d, want %d", len(g), len(data))
+	}
+}
+
+// serveGRPCTransport serves the HTTP handler and the gRPC transport of tr
+// on the same listener. It returns the URL of the listener and a function
+// to stop serving.
+func serveGRPCTransport(t *testing.T, tr GRPCTransporter) (string, func()) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gl, hl := etcdtransport.SplitHTTP2Listener(ln)
+	go tr.ServeGRPC(gl)
+	go http.Serve(hl, tr.Handler())
+	return "http://" + ln.Addr().String(), func() {
+		ln.Close()
+		tr.(*grpcTransport).srv.Stop()
+	}
+}
+
+func waitGRPCWorking(p *peer) bool {
+	for i := 0; i < 1000; i++ {
+		time.Sleep(time.Millisecond)
+		if p.grpcSender.isWorking() {
+			return true
+		}
+	}
+	return false
+}
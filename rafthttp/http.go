@@ -24,6 +24,7 @@ import (
 	pioutil "github.com/coreos/etcd/pkg/ioutil"
 	"github.com/coreos/etcd/pkg/types"
 	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/coreos/etcd/snap"
 	"github.com/coreos/etcd/version"
 )
 
@@ -32,8 +33,9 @@ const (
 )
 
 var (
-	RaftPrefix       = "/raft"
-	RaftStreamPrefix = path.Join(RaftPrefix, "stream")
+	RaftPrefix         = "/raft"
+	RaftStreamPrefix   = path.Join(RaftPrefix, "stream")
+	RaftSnapshotPrefix = path.Join(RaftPrefix, "snapshot")
 
 	errIncompatibleVersion = errors.New("incompatible version")
 	errClusterIDMismatch   = errors.New("cluster ID mismatch")
@@ -66,6 +68,9 @@ type writerToResponse interface {
 type handler struct {
 	r   Raft
 	cid types.ID
+	// verifier verifies the identity of the peers. It is nil if the
+	// peers are not verified.
+	verifier PeerVerifier
 }
 
 func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -91,10 +96,25 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var from types.ID
+	if h.verifier != nil {
+		var err error
+		if from, err = verifyPeerRequest(h.verifier, r, "pipeline request"); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+
 	// Limit the data size that could be read from the request body, which ensures that read from
 	// connection will not time out accidentally due to possible block in underlying implementation.
 	limitedr := pioutil.NewLimitedBufferReader(r.Body, ConnReadLimitByte)
-	b, err := ioutil.ReadAll(limitedr)
+	body, err := newDecompressReader(limitedr, compression(r.Header.Get("Content-Encoding")))
+	if err != nil {
+		plog.Errorf("request received was ignored (%v)", err)
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	b, err := ioutil.ReadAll(body)
 	if err != nil {
 		plog.Errorf("failed to read raft message (%v)", err)
 		http.Error(w, "error reading raft message", http.StatusBadRequest)
@@ -106,6 +126,10 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "error unmarshaling raft message", http.StatusBadRequest)
 		return
 	}
+	if err := verifyMessageFrom(h.verifier, from, m.From, "pipeline message"); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
 	if err := h.r.Process(context.TODO(), m); err != nil {
 		switch v := err.(type) {
 		case writerToResponse:
@@ -121,11 +145,122 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+type snapshotHandler struct {
+	r           Raft
+	snapshotter *snap.Snapshotter
+	cid         types.ID
+	verifier    PeerVerifier
+}
+
+func newSnapshotHandler(r Raft, snapshotter *snap.Snapshotter, cid types.ID) http.Handler {
+	return &snapshotHandler{
+		r:           r,
+		snapshotter: snapshotter,
+		cid:         cid,
+	}
+}
+
+// ServeHTTP serves the snapshot request. The body of the request is the
+// raft MsgSnap followed by the chunks of the v3 storage snapshot, which
+// are saved to disk as they arrive. The message is processed by raft
+// after the snapshot is saved.
+func (h *snapshotHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := checkVersionCompability(r.Header.Get("X-Server-From"), serverVersion(r.Header), minClusterVersion(r.Header)); err != nil {
+		plog.Errorf("request received was ignored (%v)", err)
+		http.Error(w, errIncompatibleVersion.Error(), http.StatusPreconditionFailed)
+		return
+	}
+
+	wcid := h.cid.String()
+	w.Header().Set("X-Etcd-Cluster-ID", wcid)
+
+	if gcid := r.Header.Get("X-Etcd-Cluster-ID"); gcid != wcid {
+		plog.Errorf("snapshot request ignored (cluster ID mismatch got %s want %s)", gcid, wcid)
+		http.Error(w, errClusterIDMismatch.Error(), http.StatusPreconditionFailed)
+		return
+	}
+
+	var from types.ID
+	if h.verifier != nil {
+		var err error
+		if from, err = verifyPeerRequest(h.verifier, r, "snapshot request"); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+	}
+
+	body, err := newDecompressReader(r.Body, compression(r.Header.Get("Content-Encoding")))
+	if err != nil {
+		plog.Errorf("snapshot request ignored (%v)", err)
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	dec := &messageDecoder{r: body}
+	m, err := dec.decode()
+	if err != nil {
+		plog.Errorf("failed to decode raft message (%v)", err)
+		http.Error(w, "error decoding raft message", http.StatusBadRequest)
+		return
+	}
+	if m.Type != raftpb.MsgSnap {
+		plog.Errorf("unexpected raft message type %s on snapshot path", m.Type)
+		http.Error(w, "wrong raft message type", http.StatusBadRequest)
+		return
+	}
+	if err := verifyMessageFrom(h.verifier, from, m.From, "snapshot message"); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if h.snapshotter == nil {
+		plog.Errorf("cannot save v3 storage snapshot without snapshotter")
+		http.Error(w, "error saving snapshot", http.StatusInternalServerError)
+		return
+	}
+
+	index := m.Snapshot.Metadata.Index
+	if _, err := h.snapshotter.SaveDBFrom(snap.NewReader(body), index); err != nil {
+		plog.Errorf("failed to save v3 storage snapshot at index %d (%v)", index, err)
+		http.Error(w, "error saving snapshot", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.r.Process(context.TODO(), m); err != nil {
+		// the snapshot is never applied without the message
+		if rerr := h.snapshotter.RemoveDB(index); rerr != nil {
+			plog.Warningf("failed to remove v3 storage snapshot at index %d (%v)", index, rerr)
+		}
+		switch v := err.(type) {
+		case writerToResponse:
+			v.WriteTo(w)
+		default:
+			plog.Warningf("failed to process raft message (%v)", err)
+			http.Error(w, "error processing raft message", http.StatusInternalServerError)
+		}
+		return
+	}
+	// Write StatusNoContent header after the message has been processed by
+	// raft, which facilitates the client to report MsgSnap status.
+	w.WriteHeader(http.StatusNoContent)
+}
+
 type streamHandler struct {
 	peerGetter peerGetter
 	r          Raft
 	id         types.ID
 	cid        types.ID
+	// grpc is true if the local member serves the gRPC transport, which
+	// is advertised to the remote in the response header.
+	grpc bool
+	// compression is the compression of the stream if the remote
+	// accepts it.
+	compression compression
+	verifier    PeerVerifier
 }
 
 func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -136,6 +271,10 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("X-Server-Version", version.Version)
+	if h.grpc {
+		w.Header().Set("X-Raft-Transport", transportGRPC)
+	}
+	w.Header().Set("X-Raft-Accept-Compression", acceptCompressionHeader(supportedCompressions))
 
 	if err := checkVersionCompability(r.Header.Get("X-Server-From"), serverVersion(r.Header), minClusterVersion(r.Header)); err != nil {
 		plog.Errorf("request received was ignored (%v)", err)
@@ -179,6 +318,10 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "removed member", http.StatusGone)
 		return
 	}
+	if err := verifyPeer(h.verifier, from, r.TLS, r.RemoteAddr, "stream"); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
 	p := h.peerGetter.Get(from)
 	if p == nil {
 		// This may happen in following cases:
@@ -198,9 +341,6 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.(http.Flusher).Flush()
-
 	c := newCloseNotifier()
 	conn := &outgoingConn{
 		t:       t,
@@ -209,6 +349,18 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Flusher: w.(http.Flusher),
 		Closer:  c,
 	}
+	// the stream is compressed only if the remote accepts the compression,
+	// which older members never do.
+	if acceptsCompression(r.Header.Get("X-Raft-Accept-Compression"), h.compression) {
+		w.Header().Set("X-Raft-Compression", string(h.compression))
+		cw := newCompressWriter(w, h.compression, string(t), from.String())
+		conn.Writer = cw
+		conn.Flusher = &compressFlusher{w: cw, f: w.(http.Flusher)}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+
 	p.attachOutgoingConn(conn)
 	<-c.closeNotify()
 }
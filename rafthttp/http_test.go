@@ -19,8 +19,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -28,6 +30,7 @@ import (
 	"github.com/coreos/etcd/pkg/pbutil"
 	"github.com/coreos/etcd/pkg/types"
 	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/coreos/etcd/snap"
 	"github.com/coreos/etcd/version"
 )
 
@@ -156,6 +159,34 @@ func TestServeRaftPrefix(t *testing.T) {
 	}
 }
 
+// TestServeSnapshotProcessError tests that the saved v3 storage snapshot
+// is removed if the snapshot message fails to be processed.
+func TestServeSnapshotProcessError(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "snapdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ss := snap.New(dir)
+
+	m := raftpb.Message{Type: raftpb.MsgSnap, From: 1, To: 2, Term: 1, Snapshot: raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 1000, Term: 1}}}
+	sm := snap.NewMessage(m, ioutil.NopCloser(strings.NewReader("some db data")))
+	req, err := http.NewRequest("POST", "foo", createSnapBody(*sm, compressionNone, types.ID(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Etcd-Cluster-ID", "0")
+	rw := httptest.NewRecorder()
+	h := newSnapshotHandler(&fakeRaft{err: errors.New("blah")}, ss, types.ID(0))
+	h.ServeHTTP(rw, req)
+	if rw.Code != http.StatusInternalServerError {
+		t.Errorf("code = %d, want %d", rw.Code, http.StatusInternalServerError)
+	}
+	if _, err := ss.DBFilePath(1000); err != snap.ErrNoDBSnapshot {
+		t.Errorf("err = %v, want %v", err, snap.ErrNoDBSnapshot)
+	}
+}
+
 func TestServeRaftStreamPrefix(t *testing.T) {
 	tests := []struct {
 		path  string
@@ -360,7 +391,9 @@ func newFakePeer() *fakePeer {
 }
 
 func (pr *fakePeer) Send(m raftpb.Message)                 { pr.msgs = append(pr.msgs, m) }
+func (pr *fakePeer) sendSnap(m snap.Message)               {}
 func (pr *fakePeer) Update(urls types.URLs)                { pr.urls = urls }
 func (pr *fakePeer) setTerm(term uint64)                   { pr.term = term }
 func (pr *fakePeer) attachOutgoingConn(conn *outgoingConn) { pr.connc <- conn }
+func (pr *fakePeer) receive(m raftpb.Message)              { pr.msgs = append(pr.msgs, m) }
 func (pr *fakePeer) Stop()                                 {}
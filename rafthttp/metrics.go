@@ -41,11 +41,51 @@ var (
 	},
 		[]string{"sendingType", "remoteID", "msgType"},
 	)
+
+	rawBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "rafthttp",
+		Name:      "compression_raw_bytes_total",
+		Help:      "The total number of bytes sent compressed before compression.",
+	},
+		[]string{"sendingType", "remoteID", "compression"},
+	)
+
+	compressedBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "rafthttp",
+		Name:      "compression_compressed_bytes_total",
+		Help:      "The total number of bytes sent compressed after compression.",
+	},
+		[]string{"sendingType", "remoteID", "compression"},
+	)
+
+	urgentMsgSentAhead = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "rafthttp",
+		Name:      "urgent_message_sent_ahead_total",
+		Help:      "The total number of heartbeat and vote messages sent ahead of the queued messages of other types.",
+	},
+		[]string{"sendingType", "remoteID"},
+	)
+
+	snapshotThrottledSeconds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "rafthttp",
+		Name:      "snapshot_throttled_seconds_total",
+		Help:      "The total time in seconds that snapshot transfers waited for the bandwidth limit.",
+	},
+		[]string{"remoteID"},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(msgSentDuration)
 	prometheus.MustRegister(msgSentFailed)
+	prometheus.MustRegister(rawBytes)
+	prometheus.MustRegister(compressedBytes)
+	prometheus.MustRegister(urgentMsgSentAhead)
+	prometheus.MustRegister(snapshotThrottledSeconds)
 }
 
 func reportSentDuration(sendingType string, m raftpb.Message, duration time.Duration) {
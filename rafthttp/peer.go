@@ -23,6 +23,7 @@ import (
 	"github.com/coreos/etcd/pkg/types"
 	"github.com/coreos/etcd/raft"
 	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/coreos/etcd/snap"
 )
 
 const (
@@ -49,7 +50,9 @@ const (
 	streamApp   = "streamMsgApp"
 	streamAppV2 = "streamMsgAppV2"
 	streamMsg   = "streamMsg"
+	grpcStream  = "grpcStream"
 	pipelineMsg = "pipeline"
+	sendSnap    = "sendMsgSnap"
 )
 
 type Peer interface {
@@ -60,6 +63,9 @@ type Peer interface {
 	Send(m raftpb.Message)
 	// Update updates the urls of remote peer.
 	Update(urls types.URLs)
+	// sendSnap sends the snapshot message to the remote peer in the
+	// background. The result is notified through the message.
+	sendSnap(m snap.Message)
 	// setTerm sets the term of ongoing communication.
 	setTerm(term uint64)
 	// attachOutgoingConn attachs the outgoing connection to the peer for
@@ -67,6 +73,10 @@ type Peer interface {
 	// connection hands over to the peer. The peer will close the connection
 	// when it is no longer used.
 	attachOutgoingConn(conn *outgoingConn)
+	// receive hands the message received from the remote peer over gRPC
+	// to the local raft. The message is dropped if the receiving buffer
+	// is full.
+	receive(m raftpb.Message)
 	// Stop performs any necessary finalization and terminates the peer
 	// elegantly.
 	Stop()
@@ -83,15 +93,22 @@ type Peer interface {
 // to the remote follower node.
 // A pipeline is a series of http clients that send http requests to the remote.
 // It is only used when the stream has not been established.
+// If the gRPC transport is enabled and the remote supports it, the messages
+// are sent over a gRPC stream instead, and the HTTP streams and pipeline are
+// only used while the gRPC stream is not working.
 type peer struct {
 	// id of the remote raft peer node
-	id types.ID
-	r  Raft
+	id     types.ID
+	r      Raft
+	status *peerStatus
 
 	msgAppWriter *streamWriter
 	writer       *streamWriter
 	pipeline     *pipeline
+	snapSender   *snapshotSender
 	msgAppReader *streamReader
+	// grpcSender is nil if the gRPC transport is disabled.
+	grpcSender *grpcSender
 
 	sendc    chan raftpb.Message
 	recvc    chan raftpb.Message
@@ -107,15 +124,24 @@ type peer struct {
 	done  chan struct{}
 }
 
-func startPeer(tr http.RoundTripper, urls types.URLs, local, to, cid types.ID, r Raft, fs *stats.FollowerStats, errorc chan error, term uint64) *peer {
+func startPeer(tr http.RoundTripper, urls types.URLs, local, to, cid types.ID, r Raft, fs *stats.FollowerStats, errorc chan error, term uint64, useGRPC bool, c compression, snapBandwidth int64, v PeerVerifier) *peer {
 	picker := newURLPicker(urls)
 	status := newPeerStatus(to)
+	pc := newPeerCompression(c)
+	limiter := newBandwidthLimiter(to.String(), snapBandwidth)
+	var gs *grpcSender
+	if useGRPC {
+		gs = startGRPCSender(tr, picker, local, to, cid, status, r, errorc)
+	}
 	p := &peer{
 		id:           to,
 		r:            r,
+		status:       status,
 		msgAppWriter: startStreamWriter(to, status, fs, r),
 		writer:       startStreamWriter(to, status, fs, r),
-		pipeline:     newPipeline(tr, picker, local, to, cid, status, fs, r, errorc),
+		pipeline:     newPipeline(tr, picker, local, to, cid, status, fs, r, errorc, pc, limiter),
+		snapSender:   newSnapshotSender(tr, picker, local, to, cid, status, r, errorc, gs, pc, limiter),
+		grpcSender:   gs,
 		sendc:        make(chan raftpb.Message),
 		recvc:        make(chan raftpb.Message, recvBufSize),
 		propc:        make(chan raftpb.Message, maxPendingProposals),
@@ -143,8 +169,8 @@ func startPeer(tr http.RoundTripper, urls types.URLs, local, to, cid types.ID, r
 		}
 	}()
 
-	p.msgAppReader = startStreamReader(tr, picker, streamTypeMsgAppV2, local, to, cid, status, p.recvc, p.propc, errorc, term)
-	reader := startStreamReader(tr, picker, streamTypeMessage, local, to, cid, status, p.recvc, p.propc, errorc, term)
+	p.msgAppReader = startStreamReader(tr, picker, streamTypeMsgAppV2, local, to, cid, status, p.recvc, p.propc, errorc, term, gs, pc, v)
+	reader := startStreamReader(tr, picker, streamTypeMessage, local, to, cid, status, p.recvc, p.propc, errorc, term, gs, pc, v)
 	go func() {
 		var paused bool
 		for {
@@ -182,8 +208,12 @@ func startPeer(tr http.RoundTripper, urls types.URLs, local, to, cid types.ID, r
 				p.msgAppWriter.stop()
 				p.writer.stop()
 				p.pipeline.stop()
+				p.snapSender.stop()
 				p.msgAppReader.stop()
 				reader.stop()
+				if p.grpcSender != nil {
+					p.grpcSender.stop()
+				}
 				close(p.done)
 				return
 			}
@@ -200,6 +230,10 @@ func (p *peer) Send(m raftpb.Message) {
 	}
 }
 
+func (p *peer) sendSnap(m snap.Message) {
+	go p.snapSender.send(m)
+}
+
 func (p *peer) Update(urls types.URLs) {
 	select {
 	case p.newURLsC <- urls:
@@ -224,6 +258,22 @@ func (p *peer) attachOutgoingConn(conn *outgoingConn) {
 	}
 }
 
+func (p *peer) receive(m raftpb.Message) {
+	recvc := p.recvc
+	if m.Type == raftpb.MsgProp {
+		recvc = p.propc
+	}
+	select {
+	case recvc <- m:
+	default:
+		if p.status.isActive() {
+			plog.Warningf("dropped %s from %s since receiving buffer is full", m.Type, types.ID(m.From))
+		} else {
+			plog.Debugf("dropped %s from %s since receiving buffer is full", m.Type, types.ID(m.From))
+		}
+	}
+}
+
 // Pause pauses the peer. The peer will simply drops all incoming
 // messages without retruning an error.
 func (p *peer) Pause() {
@@ -248,18 +298,58 @@ func (p *peer) Stop() {
 
 // pick picks a chan for sending the given message. The picked chan and the picked chan
 // string name are returned.
+// The urgent messages are put into the urgent chan of the sender, so they are
+// sent ahead of the append and snapshot messages queued to the same peer.
 func (p *peer) pick(m raftpb.Message) (writec chan<- raftpb.Message, picked string) {
 	var ok bool
+	urgent := isUrgentMsg(m)
 	// Considering MsgSnap may have a big size, e.g., 1G, and will block
 	// stream for a long time, only use one of the N pipelines to send MsgSnap.
 	if isMsgSnap(m) {
 		return p.pipeline.msgc, pipelineMsg
+	} else if writec, ok = p.grpcWriterc(urgent); ok {
+		return writec, grpcStream
 	} else if writec, ok = p.msgAppWriter.writec(); ok && canUseMsgAppStream(m) {
 		return writec, streamApp
-	} else if writec, ok = p.writer.writec(); ok {
+	} else if writec, ok = p.streamWriterc(urgent); ok {
 		return writec, streamMsg
 	}
+	if urgent {
+		return p.pipeline.urgentc, pipelineMsg
+	}
 	return p.pipeline.msgc, pipelineMsg
 }
 
+func (p *peer) grpcWriterc(urgent bool) (chan<- raftpb.Message, bool) {
+	if p.grpcSender == nil {
+		return nil, false
+	}
+	if urgent {
+		return p.grpcSender.urgentWritec()
+	}
+	return p.grpcSender.writec()
+}
+
+func (p *peer) streamWriterc(urgent bool) (chan<- raftpb.Message, bool) {
+	if urgent {
+		return p.writer.urgentWritec()
+	}
+	return p.writer.writec()
+}
+
 func isMsgSnap(m raftpb.Message) bool { return m.Type == raftpb.MsgSnap }
+
+// isUrgentMsg returns true if m is a heartbeat or vote message, which is
+// sent ahead of the other messages, so that a busy link to the peer does
+// not delay it enough to trigger an election.
+func isUrgentMsg(m raftpb.Message) bool {
+	switch m.Type {
+	case raftpb.MsgHeartbeat, raftpb.MsgHeartbeatResp,
+		raftpb.MsgVote, raftpb.MsgVoteResp,
+		raftpb.MsgPreVote, raftpb.MsgPreVoteResp,
+		raftpb.MsgTimeoutNow:
+		return true
+	default:
+		return false
+	}
+}
@@ -85,3 +85,38 @@ func TestPeerPick(t *testing.T) {
 		}
 	}
 }
+
+// TestPeerPickUrgent tests that the heartbeat and vote messages are put
+// into the urgent chan of the picked sender.
+func TestPeerPickUrgent(t *testing.T) {
+	tests := []struct {
+		messageWorking bool
+		m              raftpb.Message
+		wurgent        bool
+	}{
+		{true, raftpb.Message{Type: raftpb.MsgHeartbeat}, true},
+		{true, raftpb.Message{Type: raftpb.MsgHeartbeatResp}, true},
+		{true, raftpb.Message{Type: raftpb.MsgVote}, true},
+		{true, raftpb.Message{Type: raftpb.MsgPreVoteResp}, true},
+		{true, raftpb.Message{Type: raftpb.MsgTimeoutNow}, true},
+		{true, raftpb.Message{Type: raftpb.MsgProp}, false},
+		{true, raftpb.Message{Type: raftpb.MsgApp}, false},
+		{false, raftpb.Message{Type: raftpb.MsgVote}, true},
+		{false, raftpb.Message{Type: raftpb.MsgApp}, false},
+		{false, raftpb.Message{Type: raftpb.MsgSnap}, false},
+	}
+	for i, tt := range tests {
+		writer := &streamWriter{working: tt.messageWorking, msgc: make(chan raftpb.Message), urgentc: make(chan raftpb.Message)}
+		pl := &pipeline{msgc: make(chan raftpb.Message), urgentc: make(chan raftpb.Message)}
+		peer := &peer{
+			msgAppWriter: &streamWriter{},
+			writer:       writer,
+			pipeline:     pl,
+		}
+		writec, _ := peer.pick(tt.m)
+		urgent := writec == chan<- raftpb.Message(writer.urgentc) || writec == chan<- raftpb.Message(pl.urgentc)
+		if urgent != tt.wurgent {
+			t.Errorf("#%d: urgent = %v, want %v", i, urgent, tt.wurgent)
+		}
+	}
+}
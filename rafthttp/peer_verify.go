@@ -0,0 +1,101 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rafthttp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/coreos/pkg/capnslog"
+	"github.com/coreos/etcd/pkg/types"
+)
+
+// alog logs the rejections of the peers whose identity is not verified.
+var alog = capnslog.NewPackageLogger("github.com/coreos/etcd/rafthttp", "audit")
+
+var errPeerNotVerified = errors.New("peer certificate not verified")
+
+// PeerVerifier verifies the identity of the remote peers by the TLS
+// certificates they present.
+type PeerVerifier interface {
+	// VerifyPeer returns an error if the certificate chain presented by a
+	// peer does not belong to the member of the given id. certs is empty
+	// if the peer presented no certificate.
+	VerifyPeer(id types.ID, certs []*x509.Certificate) error
+}
+
+// PeerVerifiable is a Transporter that verifies the identity of the peers.
+type PeerVerifiable interface {
+	// SetPeerVerifier sets the verifier of the peers. The requests and
+	// streams from a peer, and the messages on them, are rejected unless
+	// the certificate of the peer is verified to belong to the member the
+	// peer claims to be. The streams dialed to a peer are rejected unless
+	// the certificate the peer serves is verified too.
+	// The gRPC transporter cannot verify the peers, and panics.
+	// It MUST be called before the handler is created and any peer is added.
+	SetPeerVerifier(v PeerVerifier)
+}
+
+// verifyPeer verifies that the peer at addr with the TLS connection state
+// is the member id. The rejection of the given action is audit-logged.
+func verifyPeer(v PeerVerifier, id types.ID, state *tls.ConnectionState, addr, action string) error {
+	if v == nil {
+		return nil
+	}
+	var certs []*x509.Certificate
+	if state != nil {
+		certs = state.PeerCertificates
+	}
+	if err := v.VerifyPeer(id, certs); err != nil {
+		alog.Warningf("rejected %s from peer %s at %s with certificate %s (%v)", action, id, addr, certIdentity(certs), err)
+		return errPeerNotVerified
+	}
+	return nil
+}
+
+// verifyPeerRequest verifies the peer that sent the request, which claims
+// to be the member in the X-Server-From header, and returns the ID of the
+// member.
+func verifyPeerRequest(v PeerVerifier, r *http.Request, action string) (types.ID, error) {
+	fromStr := r.Header.Get("X-Server-From")
+	from, err := types.IDFromString(fromStr)
+	if err != nil {
+		alog.Warningf("rejected %s from peer at %s (invalid from %q)", action, r.RemoteAddr, fromStr)
+		return 0, errPeerNotVerified
+	}
+	return from, verifyPeer(v, from, r.TLS, r.RemoteAddr, action)
+}
+
+// verifyMessageFrom verifies that a message received from the verified
+// peer id is sent from it.
+func verifyMessageFrom(v PeerVerifier, id types.ID, from uint64, action string) error {
+	if v == nil || types.ID(from) == id {
+		return nil
+	}
+	alog.Warningf("rejected %s from peer %s (message from %s)", action, id, types.ID(from))
+	return errPeerNotVerified
+}
+
+// certIdentity returns the CN and DNS SANs of the leaf certificate in the
+// audit logs.
+func certIdentity(certs []*x509.Certificate) string {
+	if len(certs) == 0 {
+		return "<none>"
+	}
+	return fmt.Sprintf("CN=%q DNS=%q", certs[0].Subject.CommonName, certs[0].DNSNames)
+}
@@ -0,0 +1,141 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rafthttp
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coreos/etcd/pkg/pbutil"
+	"github.com/coreos/etcd/pkg/types"
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+func TestServeRaftPrefixVerifyPeer(t *testing.T) {
+	tests := []struct {
+		from    string
+		cn      string
+		msgFrom uint64
+
+		wcode int
+	}{
+		// no certificate
+		{"1", "", 1, http.StatusUnauthorized},
+		// certificate of another member
+		{"1", "2", 1, http.StatusUnauthorized},
+		// invalid from
+		{"bad", "1", 1, http.StatusUnauthorized},
+		// message from another member
+		{"1", "1", 2, http.StatusUnauthorized},
+		// verified
+		{"1", "1", 1, http.StatusNoContent},
+	}
+	for i, tt := range tests {
+		req, err := http.NewRequest("POST", "foo", bytes.NewReader(pbutil.MustMarshal(&raftpb.Message{From: tt.msgFrom})))
+		if err != nil {
+			t.Fatalf("#%d: could not create request: %#v", i, err)
+		}
+		req.Header.Set("X-Etcd-Cluster-ID", "0")
+		req.Header.Set("X-Server-From", tt.from)
+		req.TLS = newTestConnectionState(tt.cn)
+		rw := httptest.NewRecorder()
+		h := &handler{r: &fakeRaft{}, cid: types.ID(0), verifier: &fakePeerVerifier{}}
+		h.ServeHTTP(rw, req)
+		if rw.Code != tt.wcode {
+			t.Errorf("#%d: got code=%d, want %d", i, rw.Code, tt.wcode)
+		}
+	}
+}
+
+func TestServeRaftStreamPrefixVerifyPeer(t *testing.T) {
+	tests := []struct {
+		cn string
+
+		wcode int
+	}{
+		// no certificate
+		{"", http.StatusUnauthorized},
+		// certificate of another member
+		{"2", http.StatusUnauthorized},
+	}
+	for i, tt := range tests {
+		req, err := http.NewRequest("GET", "http://localhost:2380"+RaftStreamPrefix+"/message/1", nil)
+		if err != nil {
+			t.Fatalf("#%d: could not create request: %#v", i, err)
+		}
+		req.Header.Set("X-Etcd-Cluster-ID", "1")
+		req.TLS = newTestConnectionState(tt.cn)
+		peerGetter := &fakePeerGetter{peers: map[types.ID]Peer{types.ID(1): newFakePeer()}}
+		h := &streamHandler{
+			peerGetter: peerGetter,
+			r:          &fakeRaft{},
+			id:         types.ID(2),
+			cid:        types.ID(1),
+			verifier:   &fakePeerVerifier{},
+		}
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+		if rw.Code != tt.wcode {
+			t.Errorf("#%d: code = %d, want %d", i, rw.Code, tt.wcode)
+		}
+	}
+}
+
+func TestVerifyMessageFrom(t *testing.T) {
+	tests := []struct {
+		v    PeerVerifier
+		from uint64
+
+		werr error
+	}{
+		{nil, 2, nil},
+		{&fakePeerVerifier{}, 1, nil},
+		{&fakePeerVerifier{}, 2, errPeerNotVerified},
+	}
+	for i, tt := range tests {
+		if err := verifyMessageFrom(tt.v, types.ID(1), tt.from, "message"); err != tt.werr {
+			t.Errorf("#%d: err = %v, want %v", i, err, tt.werr)
+		}
+	}
+}
+
+// fakePeerVerifier verifies the peers whose certificate has the CN of
+// their member ID.
+type fakePeerVerifier struct{}
+
+func (v *fakePeerVerifier) VerifyPeer(id types.ID, certs []*x509.Certificate) error {
+	if len(certs) == 0 || certs[0].Subject.CommonName != id.String() {
+		return errors.New("not verified")
+	}
+	return nil
+}
+
+// newTestConnectionState returns the TLS connection state of a peer that
+// presents a certificate with the given CN, or no certificate if cn is
+// empty.
+func newTestConnectionState(cn string) *tls.ConnectionState {
+	if cn == "" {
+		return &tls.ConnectionState{}
+	}
+	return &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: cn}}},
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -57,26 +58,37 @@ type pipeline struct {
 	fs     *stats.FollowerStats
 	r      Raft
 	errorc chan error
+	// compression picks the compression of the request body.
+	compression *peerCompression
+	// limiter limits the bandwidth of the snapshot messages. It is nil
+	// if the bandwidth is unlimited.
+	limiter *bandwidthLimiter
 
 	msgc chan raftpb.Message
+	// urgentc carries the heartbeat and vote messages, which are posted
+	// ahead of the messages in msgc.
+	urgentc chan raftpb.Message
 	// wait for the handling routines
 	wg    sync.WaitGroup
 	stopc chan struct{}
 }
 
-func newPipeline(tr http.RoundTripper, picker *urlPicker, from, to, cid types.ID, status *peerStatus, fs *stats.FollowerStats, r Raft, errorc chan error) *pipeline {
+func newPipeline(tr http.RoundTripper, picker *urlPicker, from, to, cid types.ID, status *peerStatus, fs *stats.FollowerStats, r Raft, errorc chan error, pc *peerCompression, l *bandwidthLimiter) *pipeline {
 	p := &pipeline{
-		from:   from,
-		to:     to,
-		cid:    cid,
-		tr:     tr,
-		picker: picker,
-		status: status,
-		fs:     fs,
-		r:      r,
-		errorc: errorc,
-		stopc:  make(chan struct{}),
-		msgc:   make(chan raftpb.Message, pipelineBufSize),
+		from:        from,
+		to:          to,
+		cid:         cid,
+		tr:          tr,
+		picker:      picker,
+		status:      status,
+		fs:          fs,
+		r:           r,
+		errorc:      errorc,
+		compression: pc,
+		limiter:     l,
+		stopc:       make(chan struct{}),
+		msgc:        make(chan raftpb.Message, pipelineBufSize),
+		urgentc:     make(chan raftpb.Message, pipelineBufSize),
 	}
 	p.wg.Add(connPerPipeline)
 	for i := 0; i < connPerPipeline; i++ {
@@ -87,15 +99,34 @@ func newPipeline(tr http.RoundTripper, picker *urlPicker, from, to, cid types.ID
 
 func (p *pipeline) stop() {
 	close(p.msgc)
+	close(p.urgentc)
 	close(p.stopc)
 	p.wg.Wait()
 }
 
 func (p *pipeline) handle() {
 	defer p.wg.Done()
-	for m := range p.msgc {
+	for {
+		var m raftpb.Message
+		var ok bool
+		// the urgent messages are posted ahead of the queued messages.
+		select {
+		case m, ok = <-p.urgentc:
+			if ok && len(p.msgc) > 0 {
+				urgentMsgSentAhead.WithLabelValues(pipelineMsg, p.to.String()).Inc()
+			}
+		default:
+			select {
+			case m, ok = <-p.urgentc:
+			case m, ok = <-p.msgc:
+			}
+		}
+		if !ok {
+			return
+		}
+
 		start := time.Now()
-		err := p.post(pbutil.MustMarshal(&m))
+		err := p.post(pbutil.MustMarshal(&m), isMsgSnap(m))
 		if err == errStopped {
 			return
 		}
@@ -125,17 +156,32 @@ func (p *pipeline) handle() {
 }
 
 // post POSTs a data payload to a url. Returns nil if the POST succeeds,
-// error on any failure.
-func (p *pipeline) post(data []byte) (err error) {
+// error on any failure. The bandwidth of the snapshot payload is limited.
+func (p *pipeline) post(data []byte, snapshot bool) (err error) {
+	c := p.compression.pick()
+	if c != compressionNone {
+		if data, err = compress(data, c, pipelineMsg, p.to.String()); err != nil {
+			return err
+		}
+	}
+
 	u := p.picker.pick()
 	uu := u
 	uu.Path = RaftPrefix
-	req, err := http.NewRequest("POST", uu.String(), bytes.NewBuffer(data))
+	var body io.Reader = bytes.NewBuffer(data)
+	if snapshot {
+		body = p.limiter.reader(body, p.stopc)
+	}
+	req, err := http.NewRequest("POST", uu.String(), body)
 	if err != nil {
 		p.picker.unreachable(u)
 		return err
 	}
+	req.ContentLength = int64(len(data))
 	req.Header.Set("Content-Type", "application/protobuf")
+	if c != compressionNone {
+		req.Header.Set("Content-Encoding", string(c))
+	}
 	req.Header.Set("X-Server-From", p.from.String())
 	req.Header.Set("X-Server-Version", version.Version)
 	req.Header.Set("X-Min-Cluster-Version", version.MinClusterVersion)
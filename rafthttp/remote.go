@@ -33,7 +33,7 @@ func startRemote(tr http.RoundTripper, urls types.URLs, local, to, cid types.ID,
 	return &remote{
 		id:       to,
 		status:   status,
-		pipeline: newPipeline(tr, picker, local, to, cid, status, nil, r, errorc),
+		pipeline: newPipeline(tr, picker, local, to, cid, status, nil, r, errorc, nil, nil),
 	}
 }
 
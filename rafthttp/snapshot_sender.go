@@ -0,0 +1,220 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rafthttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coreos/etcd/pkg/types"
+	"github.com/coreos/etcd/raft"
+	"github.com/coreos/etcd/snap"
+	"github.com/coreos/etcd/version"
+)
+
+var errMemberNotFound = errors.New("member not found")
+
+// snapshotSender sends the snapshot messages to the remote peer. The
+// snapshot of the v3 storage is streamed in the body of the request right
+// after the raft message, so it is never held in memory as a whole.
+// The body is streamed over gRPC instead if the gRPC stream to the remote
+// is working.
+type snapshotSender struct {
+	from, to types.ID
+	cid      types.ID
+
+	tr     http.RoundTripper
+	picker *urlPicker
+	status *peerStatus
+	r      Raft
+	errorc chan error
+	grpc   *grpcSender
+	// compression picks the compression of the snapshot sent over HTTP.
+	compression *peerCompression
+	// limiter limits the bandwidth of the snapshots. It is nil if the
+	// bandwidth is unlimited.
+	limiter *bandwidthLimiter
+
+	stopc chan struct{}
+}
+
+func newSnapshotSender(tr http.RoundTripper, picker *urlPicker, from, to, cid types.ID, status *peerStatus, r Raft, errorc chan error, gs *grpcSender, pc *peerCompression, l *bandwidthLimiter) *snapshotSender {
+	return &snapshotSender{
+		from:        from,
+		to:          to,
+		cid:         cid,
+		tr:          tr,
+		picker:      picker,
+		status:      status,
+		r:           r,
+		errorc:      errorc,
+		grpc:        gs,
+		compression: pc,
+		limiter:     l,
+		stopc:       make(chan struct{}),
+	}
+}
+
+func (s *snapshotSender) stop() { close(s.stopc) }
+
+func (s *snapshotSender) send(merged snap.Message) {
+	start := time.Now()
+
+	m := merged.Message
+	var err error
+	if s.grpc != nil && s.grpc.isWorking() {
+		body := createSnapBody(merged, compressionNone, s.to)
+		err = s.grpc.sendSnap(s.limiter.reader(body, s.stopc), s.stopc)
+		body.Close()
+	} else {
+		c := s.compression.pick()
+		body := createSnapBody(merged, c, s.to)
+		err = s.sendHTTP(s.limiter.reader(body, s.stopc), c)
+		body.Close()
+	}
+	if err != nil {
+		plog.Warningf("snapshot [index: %d] failed to be sent to %s (%v)", m.Snapshot.Metadata.Index, s.to, err)
+		reportSentFailure(sendSnap, m)
+		s.status.deactivate(failureType{source: sendSnap, action: "post"}, err.Error())
+		s.r.ReportUnreachable(m.To)
+		// the remote raft waits for the snapshot until it reports
+		// the failure.
+		s.r.ReportSnapshot(m.To, raft.SnapshotFailure)
+		merged.CloseWithError(err)
+		return
+	}
+	s.status.activate()
+	s.r.ReportSnapshot(m.To, raft.SnapshotFinish)
+	plog.Infof("snapshot [index: %d] sent to %s successfully", m.Snapshot.Metadata.Index, s.to)
+	reportSentDuration(sendSnap, m, time.Since(start))
+	merged.CloseWithError(nil)
+}
+
+// sendHTTP POSTs the snapshot body compressed by c to the remote.
+func (s *snapshotSender) sendHTTP(body io.Reader, c compression) error {
+	u := s.picker.pick()
+	uu := u
+	uu.Path = RaftSnapshotPrefix
+	req, err := http.NewRequest("POST", uu.String(), body)
+	if err == nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.Header.Set("X-Server-From", s.from.String())
+		req.Header.Set("X-Server-Version", version.Version)
+		req.Header.Set("X-Min-Cluster-Version", version.MinClusterVersion)
+		req.Header.Set("X-Etcd-Cluster-ID", s.cid.String())
+		if c != compressionNone {
+			req.Header.Set("Content-Encoding", string(c))
+		}
+		err = s.post(req)
+	}
+	if err != nil {
+		s.picker.unreachable(u)
+	}
+	return err
+}
+
+// post POSTs the snapshot request, and returns nil if the remote saved
+// the snapshot and processed the raft message.
+func (s *snapshotSender) post(req *http.Request) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-done:
+		case <-s.stopc:
+			if cancel, ok := s.tr.(canceler); ok {
+				cancel.CancelRequest(req)
+			}
+		}
+	}()
+
+	resp, err := s.tr.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPreconditionFailed:
+		switch strings.TrimSuffix(string(b), "\n") {
+		case errIncompatibleVersion.Error():
+			plog.Errorf("snapshot sent was ignored by peer %s (server version incompatible)", s.to)
+			return errIncompatibleVersion
+		case errClusterIDMismatch.Error():
+			plog.Errorf("snapshot sent was ignored (cluster ID mismatch: remote[%s]=%s, local=%s)",
+				s.to, resp.Header.Get("X-Etcd-Cluster-ID"), s.cid)
+			return errClusterIDMismatch
+		default:
+			return fmt.Errorf("unhandled error %q when precondition failed", string(b))
+		}
+	case http.StatusForbidden:
+		err := fmt.Errorf("the member has been permanently removed from the cluster")
+		select {
+		case s.errorc <- err:
+		default:
+		}
+		return err
+	case http.StatusNoContent:
+		return nil
+	default:
+		return fmt.Errorf("unexpected http status %s while posting to %q", http.StatusText(resp.StatusCode), req.URL.String())
+	}
+}
+
+// createSnapBody returns the body of the snapshot request, which is the
+// encoded raft message followed by the chunks of the v3 storage snapshot,
+// compressed by c. The body is produced as it is read.
+func createSnapBody(merged snap.Message, c compression, to types.ID) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		var bw io.Writer = pw
+		var cw compressWriter
+		if c != compressionNone {
+			cw = newCompressWriter(pw, c, sendSnap, to.String())
+			bw = cw
+		}
+		enc := &messageEncoder{w: bw}
+		if err := enc.encode(merged.Message); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		w := snap.NewWriter(bw)
+		if _, err := io.Copy(w, merged.ReadCloser); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if err := w.Close(); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if cw != nil {
+			if err := cw.Close(); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.Close()
+	}()
+	return pr
+}
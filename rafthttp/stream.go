@@ -111,29 +111,33 @@ type streamWriter struct {
 	closer  io.Closer
 	working bool
 
-	msgc  chan raftpb.Message
-	connc chan *outgoingConn
-	stopc chan struct{}
-	done  chan struct{}
+	msgc chan raftpb.Message
+	// urgentc carries the heartbeat and vote messages, which are sent
+	// ahead of the messages in msgc.
+	urgentc chan raftpb.Message
+	connc   chan *outgoingConn
+	stopc   chan struct{}
+	done    chan struct{}
 }
 
 func startStreamWriter(id types.ID, status *peerStatus, fs *stats.FollowerStats, r Raft) *streamWriter {
 	w := &streamWriter{
-		id:     id,
-		status: status,
-		fs:     fs,
-		r:      r,
-		msgc:   make(chan raftpb.Message, streamBufSize),
-		connc:  make(chan *outgoingConn),
-		stopc:  make(chan struct{}),
-		done:   make(chan struct{}),
+		id:      id,
+		status:  status,
+		fs:      fs,
+		r:       r,
+		msgc:    make(chan raftpb.Message, streamBufSize),
+		urgentc: make(chan raftpb.Message, streamBufSize),
+		connc:   make(chan *outgoingConn),
+		stopc:   make(chan struct{}),
+		done:    make(chan struct{}),
 	}
 	go w.run()
 	return w
 }
 
 func (cw *streamWriter) run() {
-	var msgc chan raftpb.Message
+	var msgc, urgentc chan raftpb.Message
 	var heartbeatc <-chan time.Time
 	var t streamType
 	var msgAppTerm uint64
@@ -141,7 +145,47 @@ func (cw *streamWriter) run() {
 	var flusher http.Flusher
 	tickc := time.Tick(ConnReadTimeout / 3)
 
+	// send writes m into the stream, and returns false if the stream is
+	// closed.
+	send := func(m raftpb.Message) bool {
+		if t == streamTypeMsgApp && m.Term != msgAppTerm {
+			// TODO: reasonable retry logic
+			if m.Term > msgAppTerm {
+				cw.close()
+				// TODO: report to raft at peer level
+				cw.r.ReportUnreachable(m.To)
+				return false
+			}
+			return true
+		}
+		start := time.Now()
+		if err := enc.encode(m); err != nil {
+			reportSentFailure(string(t), m)
+
+			cw.status.deactivate(failureType{source: t.String(), action: "write"}, err.Error())
+			cw.close()
+			cw.r.ReportUnreachable(m.To)
+			return false
+		}
+		flusher.Flush()
+		reportSentDuration(string(t), m, time.Since(start))
+		return true
+	}
+
 	for {
+		// the urgent messages are sent ahead of the queued messages.
+		select {
+		case m := <-urgentc:
+			if len(msgc) > 0 {
+				urgentMsgSentAhead.WithLabelValues(string(t), cw.id.String()).Inc()
+			}
+			if !send(m) {
+				heartbeatc, msgc, urgentc = nil, nil, nil
+			}
+			continue
+		default:
+		}
+
 		select {
 		case <-heartbeatc:
 			start := time.Now()
@@ -150,34 +194,19 @@ func (cw *streamWriter) run() {
 
 				cw.status.deactivate(failureType{source: t.String(), action: "heartbeat"}, err.Error())
 				cw.close()
-				heartbeatc, msgc = nil, nil
+				heartbeatc, msgc, urgentc = nil, nil, nil
 				continue
 			}
 			flusher.Flush()
 			reportSentDuration(string(t), linkHeartbeatMessage, time.Since(start))
-		case m := <-msgc:
-			if t == streamTypeMsgApp && m.Term != msgAppTerm {
-				// TODO: reasonable retry logic
-				if m.Term > msgAppTerm {
-					cw.close()
-					heartbeatc, msgc = nil, nil
-					// TODO: report to raft at peer level
-					cw.r.ReportUnreachable(m.To)
-				}
-				continue
+		case m := <-urgentc:
+			if !send(m) {
+				heartbeatc, msgc, urgentc = nil, nil, nil
 			}
-			start := time.Now()
-			if err := enc.encode(m); err != nil {
-				reportSentFailure(string(t), m)
-
-				cw.status.deactivate(failureType{source: t.String(), action: "write"}, err.Error())
-				cw.close()
-				heartbeatc, msgc = nil, nil
-				cw.r.ReportUnreachable(m.To)
-				continue
+		case m := <-msgc:
+			if !send(m) {
+				heartbeatc, msgc, urgentc = nil, nil, nil
 			}
-			flusher.Flush()
-			reportSentDuration(string(t), m, time.Since(start))
 		case conn := <-cw.connc:
 			cw.close()
 			t = conn.t
@@ -201,8 +230,8 @@ func (cw *streamWriter) run() {
 			cw.status.activate()
 			cw.closer = conn.Closer
 			cw.working = true
+			heartbeatc, msgc, urgentc = tickc, cw.msgc, cw.urgentc
 			cw.mu.Unlock()
-			heartbeatc, msgc = tickc, cw.msgc
 		case <-cw.stopc:
 			cw.close()
 			close(cw.done)
@@ -217,6 +246,14 @@ func (cw *streamWriter) writec() (chan<- raftpb.Message, bool) {
 	return cw.msgc, cw.working
 }
 
+// urgentWritec returns the chan of the urgent messages, which are sent
+// ahead of the messages written into the chan returned by writec.
+func (cw *streamWriter) urgentWritec() (chan<- raftpb.Message, bool) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	return cw.urgentc, cw.working
+}
+
 func (cw *streamWriter) close() {
 	cw.mu.Lock()
 	defer cw.mu.Unlock()
@@ -224,10 +261,11 @@ func (cw *streamWriter) close() {
 		return
 	}
 	cw.closer.Close()
-	if len(cw.msgc) > 0 {
+	if len(cw.msgc) > 0 || len(cw.urgentc) > 0 {
 		cw.r.ReportUnreachable(uint64(cw.id))
 	}
 	cw.msgc = make(chan raftpb.Message, streamBufSize)
+	cw.urgentc = make(chan raftpb.Message, streamBufSize)
 	cw.working = false
 }
 
@@ -257,6 +295,14 @@ type streamReader struct {
 	recvc         chan<- raftpb.Message
 	propc         chan<- raftpb.Message
 	errorc        chan<- error
+	// grpc is told whether the remote serves the gRPC transport. It is
+	// nil if the gRPC transport is disabled.
+	grpc *grpcSender
+	// compression is told the compressions that the remote accepts.
+	compression *peerCompression
+	// verifier verifies the identity of the remote. It is nil if the
+	// remote is not verified.
+	verifier PeerVerifier
 
 	mu         sync.Mutex
 	msgAppTerm uint64
@@ -266,21 +312,24 @@ type streamReader struct {
 	done       chan struct{}
 }
 
-func startStreamReader(tr http.RoundTripper, picker *urlPicker, t streamType, local, remote, cid types.ID, status *peerStatus, recvc chan<- raftpb.Message, propc chan<- raftpb.Message, errorc chan<- error, term uint64) *streamReader {
+func startStreamReader(tr http.RoundTripper, picker *urlPicker, t streamType, local, remote, cid types.ID, status *peerStatus, recvc chan<- raftpb.Message, propc chan<- raftpb.Message, errorc chan<- error, term uint64, gs *grpcSender, pc *peerCompression, v PeerVerifier) *streamReader {
 	r := &streamReader{
-		tr:         tr,
-		picker:     picker,
-		t:          t,
-		local:      local,
-		remote:     remote,
-		cid:        cid,
-		status:     status,
-		recvc:      recvc,
-		propc:      propc,
-		errorc:     errorc,
-		msgAppTerm: term,
-		stopc:      make(chan struct{}),
-		done:       make(chan struct{}),
+		tr:          tr,
+		picker:      picker,
+		t:           t,
+		local:       local,
+		remote:      remote,
+		cid:         cid,
+		status:      status,
+		recvc:       recvc,
+		propc:       propc,
+		errorc:      errorc,
+		grpc:        gs,
+		compression: pc,
+		verifier:    v,
+		msgAppTerm:  term,
+		stopc:       make(chan struct{}),
+		done:        make(chan struct{}),
 	}
 	go r.run()
 	return r
@@ -352,6 +401,11 @@ func (cr *streamReader) decodeLoop(rc io.ReadCloser, t streamType) error {
 			return err
 		case isLinkHeartbeatMessage(m):
 			// do nothing for linkHeartbeatMessage
+		case verifyMessageFrom(cr.verifier, cr.remote, m.From, "stream message") != nil:
+			cr.mu.Lock()
+			cr.close()
+			cr.mu.Unlock()
+			return errPeerNotVerified
 		default:
 			recvc := cr.recvc
 			if m.Type == raftpb.MsgProp {
@@ -419,6 +473,7 @@ func (cr *streamReader) dial(t streamType) (io.ReadCloser, error) {
 	req.Header.Set("X-Min-Cluster-Version", version.MinClusterVersion)
 	req.Header.Set("X-Etcd-Cluster-ID", cr.cid.String())
 	req.Header.Set("X-Raft-To", cr.remote.String())
+	req.Header.Set("X-Raft-Accept-Compression", acceptCompressionHeader(supportedCompressions))
 	if t == streamTypeMsgApp {
 		req.Header.Set("X-Raft-Term", strconv.FormatUint(term, 10))
 	}
@@ -450,10 +505,26 @@ func (cr *streamReader) dial(t streamType) (io.ReadCloser, error) {
 		}
 		return nil, err
 	case http.StatusOK:
-		return resp.Body, nil
+		if err := verifyPeer(cr.verifier, cr.remote, resp.TLS, u.Host, "stream"); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if cr.grpc != nil {
+			cr.grpc.setSupported(resp.Header.Get("X-Raft-Transport") == transportGRPC)
+		}
+		cr.compression.setAccepted(resp.Header.Get("X-Raft-Accept-Compression"))
+		r, err := newDecompressReader(resp.Body, compression(resp.Header.Get("X-Raft-Compression")))
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		return &readCloser{Reader: r, Closer: resp.Body}, nil
 	case http.StatusNotFound:
 		resp.Body.Close()
 		return nil, fmt.Errorf("remote member %s could not recognize local member", cr.remote)
+	case http.StatusUnauthorized:
+		resp.Body.Close()
+		return nil, fmt.Errorf("remote member %s could not verify the certificate of local member", cr.remote)
 	case http.StatusPreconditionFailed:
 		b, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
@@ -492,6 +563,11 @@ func (cr *streamReader) close() {
 	cr.closer = nil
 }
 
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
 func canUseMsgAppStream(m raftpb.Message) bool {
 	return m.Type == raftpb.MsgApp && m.Term == m.LogTerm
 }
@@ -1,11 +1,13 @@
 package rafthttp
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -83,6 +85,47 @@ func TestStreamWriterAttachBadOutgoingConn(t *testing.T) {
 	}
 }
 
+// TestStreamWriterSendUrgentAhead tests that streamWriter sends the urgent
+// messages ahead of the messages queued before them.
+func TestStreamWriterSendUrgentAhead(t *testing.T) {
+	sw := startStreamWriter(types.ID(1), newPeerStatus(types.ID(1)), &stats.FollowerStats{}, &fakeRaft{})
+	defer sw.stop()
+	bw := &blockingWriteFlushCloser{blockedc: make(chan struct{}), releasec: make(chan struct{})}
+	sw.attach(&outgoingConn{t: streamTypeMessage, Writer: bw, Flusher: bw, Closer: bw})
+	testutil.WaitSchedule()
+	before := counterValue(t, urgentMsgSentAhead.WithLabelValues(string(streamTypeMessage), "1").Write)
+
+	sw.msgc <- raftpb.Message{Type: raftpb.MsgApp, Index: 1}
+	// the first write blocks until all the other messages are queued
+	<-bw.blockedc
+	sw.msgc <- raftpb.Message{Type: raftpb.MsgApp, Index: 2}
+	sw.msgc <- raftpb.Message{Type: raftpb.MsgApp, Index: 3}
+	sw.urgentc <- raftpb.Message{Type: raftpb.MsgHeartbeat}
+	close(bw.releasec)
+	testutil.WaitSchedule()
+
+	dec := &messageDecoder{r: bytes.NewReader(bw.Bytes())}
+	wmsgs := []raftpb.Message{
+		{Type: raftpb.MsgApp, Index: 1},
+		{Type: raftpb.MsgHeartbeat},
+		{Type: raftpb.MsgApp, Index: 2},
+		{Type: raftpb.MsgApp, Index: 3},
+	}
+	for i, wm := range wmsgs {
+		m, err := dec.decode()
+		if err != nil {
+			t.Fatalf("#%d: unexpected decode error: %v", i, err)
+		}
+		if !reflect.DeepEqual(m, wm) {
+			t.Errorf("#%d: message = %+v, want %+v", i, m, wm)
+		}
+	}
+	after := counterValue(t, urgentMsgSentAhead.WithLabelValues(string(streamTypeMessage), "1").Write)
+	if after != before+1 {
+		t.Errorf("urgent messages sent ahead = %v, want %v", after, before+1)
+	}
+}
+
 func TestStreamReaderDialRequest(t *testing.T) {
 	for i, tt := range []streamType{streamTypeMsgApp, streamTypeMessage, streamTypeMsgAppV2} {
 		tr := &roundTripperRecorder{}
@@ -274,7 +317,7 @@ func TestStream(t *testing.T) {
 		h.sw = sw
 
 		picker := mustNewURLPicker(t, []string{srv.URL})
-		sr := startStreamReader(&http.Transport{}, picker, tt.t, types.ID(1), types.ID(2), types.ID(1), newPeerStatus(types.ID(1)), recvc, propc, nil, tt.term)
+		sr := startStreamReader(&http.Transport{}, picker, tt.t, types.ID(1), types.ID(2), types.ID(1), newPeerStatus(types.ID(1)), recvc, propc, nil, tt.term, nil, nil, nil)
 		defer sr.stop()
 		// wait for stream to work
 		var writec chan<- raftpb.Message
@@ -353,6 +396,38 @@ func (wfc *fakeWriteFlushCloser) Close() error {
 	return wfc.err
 }
 
+// blockingWriteFlushCloser blocks the first write until releasec is closed,
+// and notifies blockedc when it starts blocking.
+type blockingWriteFlushCloser struct {
+	blockedc chan struct{}
+	releasec chan struct{}
+
+	mu      sync.Mutex
+	blocked bool
+	buf     bytes.Buffer
+}
+
+func (bw *blockingWriteFlushCloser) Write(p []byte) (int, error) {
+	bw.mu.Lock()
+	blocked := bw.blocked
+	bw.blocked = true
+	bw.mu.Unlock()
+	if !blocked {
+		bw.blockedc <- struct{}{}
+		<-bw.releasec
+	}
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return bw.buf.Write(p)
+}
+func (bw *blockingWriteFlushCloser) Flush()       {}
+func (bw *blockingWriteFlushCloser) Close() error { return nil }
+func (bw *blockingWriteFlushCloser) Bytes() []byte {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return append([]byte(nil), bw.buf.Bytes()...)
+}
+
 type fakeStreamHandler struct {
 	t  streamType
 	sw *streamWriter
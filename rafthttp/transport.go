@@ -24,6 +24,7 @@ import (
 	"github.com/coreos/etcd/pkg/types"
 	"github.com/coreos/etcd/raft"
 	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/coreos/etcd/snap"
 )
 
 var plog = capnslog.NewPackageLogger("github.com/coreos/etcd", "rafthttp")
@@ -48,6 +49,11 @@ type Transporter interface {
 	// If the id cannot be found in the transport, the message
 	// will be ignored.
 	Send(m []raftpb.Message)
+	// SendSnapshot sends out the given snapshot message to a remote peer.
+	// The behavior of SendSnapshot is similar to Send, but the v3 storage
+	// snapshot of the message is streamed to the peer chunk by chunk.
+	// The result is notified through the message.
+	SendSnapshot(m snap.Message)
 	// AddRemote adds a remote with given peer urls into the transport.
 	// A remote helps newly joined member to catch up the progress of cluster,
 	// and will not be used after that.
@@ -76,9 +82,23 @@ type transport struct {
 	id           types.ID
 	clusterID    types.ID
 	raft         Raft
+	snapshotter  *snap.Snapshotter
 	serverStats  *stats.ServerStats
 	leaderStats  *stats.LeaderStats
 
+	// grpc is true if the messages are sent over gRPC to the peers that
+	// support it.
+	grpc bool
+	// compression is the compression of the data sent to the peers that
+	// accept it.
+	compression compression
+	// snapshotBandwidth is the maximum bandwidth in bytes per second of
+	// the snapshots sent to each peer. 0 means unlimited.
+	snapshotBandwidth int64
+	// verifier verifies the identity of the peers. It is nil if the
+	// peers are not verified.
+	verifier PeerVerifier
+
 	mu      sync.RWMutex         // protect the term, remote and peer map
 	term    uint64               // the latest term that has been observed
 	remotes map[types.ID]*remote // remotes map that helps newly joined member to catch up
@@ -86,12 +106,15 @@ type transport struct {
 	errorc  chan error
 }
 
-func NewTransporter(rt http.RoundTripper, id, cid types.ID, r Raft, errorc chan error, ss *stats.ServerStats, ls *stats.LeaderStats) Transporter {
+// NewTransporter creates a Transporter. The v3 storage snapshots received
+// from the peers are saved by the given snapshotter.
+func NewTransporter(rt http.RoundTripper, id, cid types.ID, r Raft, snapshotter *snap.Snapshotter, errorc chan error, ss *stats.ServerStats, ls *stats.LeaderStats) Transporter {
 	return &transport{
 		roundTripper: rt,
 		id:           id,
 		clusterID:    cid,
 		raft:         r,
+		snapshotter:  snapshotter,
 		serverStats:  ss,
 		leaderStats:  ls,
 		remotes:      make(map[types.ID]*remote),
@@ -100,12 +123,44 @@ func NewTransporter(rt http.RoundTripper, id, cid types.ID, r Raft, errorc chan
 	}
 }
 
+func (t *transport) SetCompression(s string) error {
+	c, err := parseCompression(s)
+	if err != nil {
+		return err
+	}
+	t.compression = c
+	return nil
+}
+
+func (t *transport) SetSnapshotBandwidth(bytesPerSec int64) { t.snapshotBandwidth = bytesPerSec }
+
+func (t *transport) SetPeerVerifier(v PeerVerifier) { t.verifier = v }
+
 func (t *transport) Handler() http.Handler {
-	pipelineHandler := NewHandler(t.raft, t.clusterID)
-	streamHandler := newStreamHandler(t, t.raft, t.id, t.clusterID)
+	pipelineHandler := &handler{
+		r:        t.raft,
+		cid:      t.clusterID,
+		verifier: t.verifier,
+	}
+	streamHandler := &streamHandler{
+		peerGetter:  t,
+		r:           t.raft,
+		id:          t.id,
+		cid:         t.clusterID,
+		grpc:        t.grpc,
+		compression: t.compression,
+		verifier:    t.verifier,
+	}
+	snapHandler := &snapshotHandler{
+		r:           t.raft,
+		snapshotter: t.snapshotter,
+		cid:         t.clusterID,
+		verifier:    t.verifier,
+	}
 	mux := http.NewServeMux()
 	mux.Handle(RaftPrefix, pipelineHandler)
 	mux.Handle(RaftStreamPrefix+"/", streamHandler)
+	mux.Handle(RaftSnapshotPrefix, snapHandler)
 	return mux
 }
 
@@ -135,7 +190,7 @@ func (t *transport) Send(msgs []raftpb.Message) {
 		}
 		to := types.ID(m.To)
 
-		if m.Type != raftpb.MsgProp { // proposal message does not have a valid term
+		if hasCurrentTerm(m) {
 			t.maybeUpdatePeersTerm(m.Term)
 		}
 
@@ -158,6 +213,17 @@ func (t *transport) Send(msgs []raftpb.Message) {
 	}
 }
 
+func (t *transport) SendSnapshot(m snap.Message) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	p := t.peers[types.ID(m.To)]
+	if p == nil {
+		m.CloseWithError(errMemberNotFound)
+		return
+	}
+	p.sendSnap(m)
+}
+
 func (t *transport) Stop() {
 	for _, r := range t.remotes {
 		r.Stop()
@@ -194,7 +260,7 @@ func (t *transport) AddPeer(id types.ID, us []string) {
 		plog.Panicf("newURLs %+v should never fail: %+v", us, err)
 	}
 	fs := t.leaderStats.Follower(id.String())
-	t.peers[id] = startPeer(t.roundTripper, urls, t.id, id, t.clusterID, t.raft, fs, t.errorc, t.term)
+	t.peers[id] = startPeer(t.roundTripper, urls, t.id, id, t.clusterID, t.raft, fs, t.errorc, t.term, t.grpc, t.compression, t.snapshotBandwidth, t.verifier)
 }
 
 func (t *transport) RemovePeer(id types.ID) {
@@ -253,3 +319,16 @@ func (t *transport) Resume() {
 		p.(Pausable).Resume()
 	}
 }
+
+// hasCurrentTerm returns true if the message carries the current term of
+// the local raft. Proposal and read index messages do not have a valid
+// term, and pre-vote messages carry the term of an election that may
+// never happen.
+func hasCurrentTerm(m raftpb.Message) bool {
+	switch m.Type {
+	case raftpb.MsgProp, raftpb.MsgReadIndex, raftpb.MsgPreVote, raftpb.MsgPreVoteResp:
+		return false
+	default:
+		return true
+	}
+}
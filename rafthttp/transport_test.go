@@ -65,6 +65,36 @@ func TestTransportSend(t *testing.T) {
 	}
 }
 
+// TestTransportSendTerm tests that transport updates the term of the peers
+// from the sent messages, except the ones without the current term.
+func TestTransportSendTerm(t *testing.T) {
+	ss := &stats.ServerStats{}
+	ss.Initialize()
+	peer := newFakePeer()
+	tr := &transport{
+		serverStats: ss,
+		peers:       map[types.ID]Peer{types.ID(1): peer},
+	}
+	tests := []struct {
+		m     raftpb.Message
+		wterm uint64
+	}{
+		{raftpb.Message{Type: raftpb.MsgApp, To: 1, Term: 2}, 2},
+		// proposal message does not have a valid term
+		{raftpb.Message{Type: raftpb.MsgProp, To: 1}, 2},
+		// pre-vote messages carry the term of a future election
+		{raftpb.Message{Type: raftpb.MsgPreVote, To: 1, Term: 3}, 2},
+		{raftpb.Message{Type: raftpb.MsgPreVoteResp, To: 1, Term: 3}, 2},
+		{raftpb.Message{Type: raftpb.MsgVote, To: 1, Term: 3}, 3},
+	}
+	for i, tt := range tests {
+		tr.Send([]raftpb.Message{tt.m})
+		if peer.term != tt.wterm {
+			t.Errorf("#%d: term = %d, want %d", i, peer.term, tt.wterm)
+		}
+	}
+}
+
 func TestTransportAdd(t *testing.T) {
 	ls := stats.NewLeaderStats("")
 	term := uint64(10)
@@ -0,0 +1,195 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	dbSuffix   = ".snap.db"
+	tmpSuffix  = ".tmp"
+	copySuffix = ".copy"
+
+	// maxDBCopies is the maximum number of the copies of the v3 storage
+	// snapshot being sent at a time. Each copy takes as much disk space
+	// as the database.
+	maxDBCopies = 2
+)
+
+var (
+	ErrNoDBSnapshot    = errors.New("snap: v3 storage snapshot file doesn't exist")
+	ErrTooManyDBCopies = errors.New("snap: too many v3 storage snapshot copies being sent")
+)
+
+// SaveDBFrom saves the v3 storage snapshot read from r into a file named
+// after the given raft index. The data is copied to the file as it is
+// read, so the snapshot is never held in memory as a whole. It returns
+// the number of bytes saved.
+func (s *Snapshotter) SaveDBFrom(r io.Reader, id uint64) (int64, error) {
+	fn := s.dbFilePath(id)
+	tmpfn := fn + tmpSuffix
+	f, err := os.OpenFile(tmpfn, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, r)
+	if err == nil {
+		err = f.Sync()
+	}
+	f.Close()
+	if err == nil {
+		err = os.Rename(tmpfn, fn)
+	}
+	if err != nil {
+		os.Remove(tmpfn)
+		return n, err
+	}
+	plog.Infof("saved v3 storage snapshot at index %d [total bytes: %d]", id, n)
+	return n, nil
+}
+
+// DBFilePath returns the path of the v3 storage snapshot saved for the
+// given raft index. It returns ErrNoDBSnapshot if there is none.
+func (s *Snapshotter) DBFilePath(id uint64) (string, error) {
+	fn := s.dbFilePath(id)
+	if _, err := os.Stat(fn); err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrNoDBSnapshot
+		}
+		return "", err
+	}
+	return fn, nil
+}
+
+// NewDBCopyReader returns a reader of the v3 storage snapshot written by
+// save. The snapshot is saved into a copy in the snapshot directory before
+// it is read, so save, which usually holds a database tx, returns as soon
+// as the copy is written to disk rather than after the reader reads all of
+// it, which can take long when the transfer is limited in bandwidth. The
+// copy is removed once it is read or the reader is closed. It returns
+// ErrTooManyDBCopies if maxDBCopies copies are being sent already.
+func (s *Snapshotter) NewDBCopyReader(save func(w io.Writer) (int64, error)) (io.ReadCloser, error) {
+	s.mu.Lock()
+	if s.sending >= maxDBCopies {
+		s.mu.Unlock()
+		return nil, ErrTooManyDBCopies
+	}
+	s.sending++
+	s.mu.Unlock()
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(s.sendDBCopy(save, pw))
+		s.mu.Lock()
+		s.sending--
+		s.mu.Unlock()
+	}()
+	return pr, nil
+}
+
+func (s *Snapshotter) sendDBCopy(save func(w io.Writer) (int64, error), w io.Writer) error {
+	f, err := ioutil.TempFile(s.dir, "")
+	if err != nil {
+		return err
+	}
+	// name the copy after the v3 storage snapshots, so it is not taken
+	// as an unexpected file.
+	fn := f.Name() + dbSuffix + copySuffix
+	s.mu.Lock()
+	s.copies[path.Base(fn)] = true
+	s.mu.Unlock()
+	defer func() {
+		f.Close()
+		os.Remove(fn)
+		s.mu.Lock()
+		delete(s.copies, path.Base(fn))
+		s.mu.Unlock()
+	}()
+	if err = os.Rename(f.Name(), fn); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	if _, err = save(f); err != nil {
+		return err
+	}
+	if _, err = f.Seek(0, 0); err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// RemoveDB removes the v3 storage snapshot saved for the given raft index,
+// e.g., after the raft snapshot message it comes with failed to be
+// processed.
+func (s *Snapshotter) RemoveDB(id uint64) error {
+	err := os.Remove(s.dbFilePath(id))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// PurgeDBs removes the v3 storage snapshots saved for the raft indexes up
+// to the given index, which is usually the index of the latest applied
+// snapshot. Such snapshots are never applied, e.g., they came with a raft
+// snapshot message that raft ignored as stale.
+func (s *Snapshotter) PurgeDBs(index uint64) error {
+	dir, err := os.Open(s.dir)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if strings.HasSuffix(name, dbSuffix+copySuffix) {
+			// the copies left behind by a crash
+			s.mu.Lock()
+			sending := s.copies[name]
+			s.mu.Unlock()
+			if !sending {
+				os.Remove(path.Join(s.dir, name))
+			}
+			continue
+		}
+		if !strings.HasSuffix(name, dbSuffix) {
+			continue
+		}
+		var id uint64
+		if _, err := fmt.Sscanf(name, "%016x"+dbSuffix, &id); err != nil || id > index {
+			continue
+		}
+		if err := os.Remove(path.Join(s.dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		plog.Infof("purged unused v3 storage snapshot %s", name)
+	}
+	return nil
+}
+
+func (s *Snapshotter) dbFilePath(id uint64) string {
+	return path.Join(s.dir, fmt.Sprintf("%016x%s", id, dbSuffix))
+}
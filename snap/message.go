@@ -0,0 +1,56 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snap
+
+import (
+	"io"
+
+	"github.com/coreos/etcd/raft/raftpb"
+)
+
+// Message is a raft MsgSnap together with the snapshot of the v3 storage
+// that is streamed to the receiver after it. The raft snapshot of the
+// message carries the v2 store only. The v3 storage snapshot is read from
+// ReadCloser chunk by chunk while it is sent, so it is never held in
+// memory as a whole.
+//
+// The user of the message can learn whether it is sent through
+// CloseNotify, after the sender calls CloseWithError.
+type Message struct {
+	raftpb.Message
+	ReadCloser io.ReadCloser
+	closeC     chan bool
+}
+
+func NewMessage(rs raftpb.Message, rc io.ReadCloser) *Message {
+	return &Message{
+		Message:    rs,
+		ReadCloser: rc,
+		closeC:     make(chan bool, 1),
+	}
+}
+
+// CloseNotify returns a channel that receives true when the message is
+// sent, or false when it fails to be sent.
+func (m Message) CloseNotify() <-chan bool {
+	return m.closeC
+}
+
+// CloseWithError closes the ReadCloser of the message, and notifies the
+// result of sending it.
+func (m Message) CloseWithError(err error) {
+	m.ReadCloser.Close()
+	m.closeC <- err == nil
+}
@@ -23,6 +23,7 @@ import (
 	"path"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/coreos/etcd/pkg/pbutil"
@@ -48,11 +49,19 @@ var (
 
 type Snapshotter struct {
 	dir string
+
+	// mu protects copies, the names of the copies of the v3 storage
+	// snapshot that are being sent, and sending, the number of the
+	// copies that are being made or sent.
+	mu      sync.Mutex
+	copies  map[string]bool
+	sending int
 }
 
 func New(dir string) *Snapshotter {
 	return &Snapshotter{
-		dir: dir,
+		dir:    dir,
+		copies: make(map[string]bool),
 	}
 }
 
@@ -168,9 +177,12 @@ func (s *Snapshotter) snapNames() ([]string, error) {
 func checkSuffix(names []string) []string {
 	snaps := []string{}
 	for i := range names {
-		if strings.HasSuffix(names[i], snapSuffix) {
+		switch {
+		case strings.HasSuffix(names[i], snapSuffix):
 			snaps = append(snaps, names[i])
-		} else {
+		case strings.HasSuffix(names[i], dbSuffix), strings.HasSuffix(names[i], dbSuffix+tmpSuffix), strings.HasSuffix(names[i], dbSuffix+copySuffix):
+			// the v3 storage snapshots are not raft snapshots
+		default:
 			plog.Warningf("skipped unexpected non snapshot file %v", names[i])
 		}
 	}
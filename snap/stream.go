@@ -0,0 +1,132 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snap
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// maxChunkSize is the maximum size of the data carried by a chunk of a
+// snapshot stream.
+const maxChunkSize = 64 * 1024
+
+var ErrInvalidChunk = errors.New("snap: invalid snapshot chunk")
+
+// Writer writes the data of a snapshot as a stream of chunks. Each chunk
+// is made of the length of its data, the data and the CRC-32C checksum of
+// the data. An empty chunk ends the stream, so that the receiver can tell
+// a complete stream from a broken connection.
+// It MUST be used with a paired Reader.
+type Writer struct {
+	w io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write writes p into the stream in one or more chunks.
+func (w *Writer) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		c := p
+		if len(c) > maxChunkSize {
+			c = c[:maxChunkSize]
+		}
+		if err := w.writeChunk(c); err != nil {
+			return n, err
+		}
+		n += len(c)
+		p = p[len(c):]
+	}
+	return n, nil
+}
+
+// Close ends the stream. It does not close the underlying writer.
+func (w *Writer) Close() error { return w.writeChunk(nil) }
+
+func (w *Writer) writeChunk(c []byte) error {
+	if err := binary.Write(w.w, binary.BigEndian, uint32(len(c))); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(c); err != nil {
+		return err
+	}
+	return binary.Write(w.w, binary.BigEndian, crc32.Checksum(c, crcTable))
+}
+
+// Reader reads the data of a snapshot from a stream written by Writer.
+// It verifies the checksum of each chunk before returning its data.
+type Reader struct {
+	r     io.Reader
+	chunk []byte
+	// buf is the data of the current chunk that has not been read.
+	buf []byte
+	eof bool
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r, chunk: make([]byte, maxChunkSize)}
+}
+
+// Read reads the data of the stream into p. It returns ErrCRCMismatch if
+// a chunk is corrupted, and io.ErrUnexpectedEOF if the stream ends
+// before the empty chunk.
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		if err := r.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *Reader) readChunk() error {
+	var l, crc uint32
+	if err := binary.Read(r.r, binary.BigEndian, &l); err != nil {
+		return unexpectedEOF(err)
+	}
+	if l > maxChunkSize {
+		return ErrInvalidChunk
+	}
+	c := r.chunk[:l]
+	if _, err := io.ReadFull(r.r, c); err != nil {
+		return unexpectedEOF(err)
+	}
+	if err := binary.Read(r.r, binary.BigEndian, &crc); err != nil {
+		return unexpectedEOF(err)
+	}
+	if crc32.Checksum(c, crcTable) != crc {
+		return ErrCRCMismatch
+	}
+	r.buf = c
+	r.eof = l == 0
+	return nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,260 @@
+// Copyright 2015 CoreOS, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snap
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStreamReadWrite(t *testing.T) {
+	tests := []int{0, 1, maxChunkSize - 1, maxChunkSize, 3*maxChunkSize + 7}
+	for i, size := range tests {
+		data := make([]byte, size)
+		for j := range data {
+			data[j] = byte(j)
+		}
+
+		buf := &bytes.Buffer{}
+		w := NewWriter(buf)
+		if _, err := w.Write(data); err != nil {
+			t.Fatalf("#%d: write error: %v", i, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("#%d: close error: %v", i, err)
+		}
+
+		g, err := ioutil.ReadAll(NewReader(buf))
+		if err != nil {
+			t.Fatalf("#%d: read error: %v", i, err)
+		}
+		if !bytes.Equal(g, data) {
+			t.Errorf("#%d: data mismatch (len = %d, want %d)", i, len(g), len(data))
+		}
+	}
+}
+
+func TestStreamReadBroken(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	w.Write([]byte("some snapshot data"))
+	w.Close()
+	b := buf.Bytes()
+
+	corrupted := make([]byte, len(b))
+	copy(corrupted, b)
+	// flip a bit of the data of the first chunk
+	corrupted[4] ^= 1
+
+	tests := []struct {
+		b    []byte
+		werr error
+	}{
+		{corrupted, ErrCRCMismatch},
+		// the stream ends before the empty chunk
+		{b[:len(b)-8], io.ErrUnexpectedEOF},
+		// the stream ends in the middle of a chunk
+		{b[:10], io.ErrUnexpectedEOF},
+		// the length of the chunk is too large
+		{[]byte{0xff, 0xff, 0xff, 0xff}, ErrInvalidChunk},
+	}
+	for i, tt := range tests {
+		_, err := ioutil.ReadAll(NewReader(bytes.NewReader(tt.b)))
+		if err != tt.werr {
+			t.Errorf("#%d: err = %v, want %v", i, err, tt.werr)
+		}
+	}
+}
+
+func TestSaveDBFrom(t *testing.T) {
+	dir := path.Join(os.TempDir(), "snapshot")
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ss := New(dir)
+
+	if _, err := ss.DBFilePath(1); err != ErrNoDBSnapshot {
+		t.Errorf("err = %v, want %v", err, ErrNoDBSnapshot)
+	}
+
+	data := []byte("some db snapshot")
+	n, err := ss.SaveDBFrom(bytes.NewReader(data), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(data)) {
+		t.Errorf("n = %d, want %d", n, len(data))
+	}
+	fn, err := ss.DBFilePath(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := ioutil.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(g, data) {
+		t.Errorf("data = %q, want %q", g, data)
+	}
+
+	// a broken stream leaves no file behind
+	buf := &bytes.Buffer{}
+	NewWriter(buf).Write(data)
+	if _, err = ss.SaveDBFrom(NewReader(buf), 2); err != io.ErrUnexpectedEOF {
+		t.Errorf("err = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	names, err := ss.snapNames()
+	if err != ErrNoSnapshot {
+		t.Errorf("names = %v, err = %v, want db snapshots ignored", names, err)
+	}
+	fs, _ := ioutil.ReadDir(dir)
+	var files []string
+	for _, f := range fs {
+		files = append(files, f.Name())
+	}
+	if w := []string{"0000000000000001.snap.db"}; !reflect.DeepEqual(files, w) {
+		t.Errorf("files = %v, want %v", files, w)
+	}
+}
+
+func TestPurgeDBs(t *testing.T) {
+	dir := path.Join(os.TempDir(), "snapshot")
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ss := New(dir)
+
+	for _, id := range []uint64{1, 2, 3} {
+		if _, err := ss.SaveDBFrom(bytes.NewReader([]byte("some db snapshot")), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the raft snapshots are not purged
+	if err := ioutil.WriteFile(path.Join(dir, "0000000000000001-0000000000000001.snap"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ss.PurgeDBs(2); err != nil {
+		t.Fatal(err)
+	}
+	fs, _ := ioutil.ReadDir(dir)
+	var files []string
+	for _, f := range fs {
+		files = append(files, f.Name())
+	}
+	if w := []string{"0000000000000001-0000000000000001.snap", "0000000000000003.snap.db"}; !reflect.DeepEqual(files, w) {
+		t.Errorf("files = %v, want %v", files, w)
+	}
+
+	if err := ss.RemoveDB(3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ss.DBFilePath(3); err != ErrNoDBSnapshot {
+		t.Errorf("err = %v, want %v", err, ErrNoDBSnapshot)
+	}
+	// removing a missing snapshot is not an error
+	if err := ss.RemoveDB(3); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+}
+
+func TestNewDBCopyReader(t *testing.T) {
+	dir := path.Join(os.TempDir(), "snapshot")
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ss := New(dir)
+
+	data := []byte("some db snapshot")
+	savedc := make(chan struct{})
+	rc, err := ss.NewDBCopyReader(func(w io.Writer) (int64, error) {
+		defer close(savedc)
+		n, err := w.Write(data)
+		return int64(n), err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// save returns before the snapshot is read
+	select {
+	case <-savedc:
+	case <-time.After(time.Second):
+		t.Fatalf("save is blocked by the reader")
+	}
+	g, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc.Close()
+	if !bytes.Equal(g, data) {
+		t.Errorf("data = %q, want %q", g, data)
+	}
+
+	// the copy is removed after it is read
+	for i := 0; i < 100; i++ {
+		fs, _ := ioutil.ReadDir(dir)
+		if len(fs) == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("copy is not removed")
+}
+
+func TestNewDBCopyReaderLimit(t *testing.T) {
+	dir := path.Join(os.TempDir(), "snapshot")
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ss := New(dir)
+
+	save := func(w io.Writer) (int64, error) {
+		n, err := w.Write([]byte("some db snapshot"))
+		return int64(n), err
+	}
+	var rcs []io.ReadCloser
+	for i := 0; i < maxDBCopies; i++ {
+		rc, err := ss.NewDBCopyReader(save)
+		if err != nil {
+			t.Fatalf("#%d: err = %v, want nil", i, err)
+		}
+		rcs = append(rcs, rc)
+	}
+	if _, err := ss.NewDBCopyReader(save); err != ErrTooManyDBCopies {
+		t.Fatalf("err = %v, want %v", err, ErrTooManyDBCopies)
+	}
+
+	// a copy can be made again after one of the copies is closed
+	rcs[0].Close()
+	for i := 0; i < 100; i++ {
+		rc, err := ss.NewDBCopyReader(save)
+		if err == nil {
+			rc.Close()
+			rcs[1].Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("copy cannot be made after one is closed")
+}
@@ -1,32 +1,73 @@
 package backend
 
 import (
+	"fmt"
 	"io"
 	"log"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/boltdb/bolt"
 )
 
+var (
+	defaultBatchLimit    = 10000
+	defaultBatchInterval = 100 * time.Millisecond
+
+	// defragLimit is the number of keys copied in one tx during
+	// defragmentation.
+	defragLimit = 10000
+)
+
 type Backend interface {
 	BatchTx() BatchTx
+	ReadTx() ReadTx
 	Snapshot(w io.Writer) (n int64, err error)
 	ForceCommit()
+	// Size returns the size of the database in bytes as of the last
+	// commit of the batch tx.
+	Size() int64
+	// Defrag rewrites the database into a new file to release the free
+	// space of the database file.
+	Defrag() error
+	// Recover replaces the database with the database file at the given
+	// path, which is usually a snapshot received from the leader.
+	Recover(path string) error
 	Close() error
 }
 
 type backend struct {
+	// mu protects db. The read-only txs hold the read lock until they
+	// end, so db is not replaced under them by defragmentation.
+	mu sync.RWMutex
 	db *bolt.DB
 
+	// size is the size of db in bytes, updated by each commit. It is
+	// accessed atomically.
+	size int64
+
 	batchInterval time.Duration
 	batchLimit    int
 	batchTx       *batchTx
 
+	// bufMu protects buf, which records the writes of the batch tx that
+	// are not committed yet. buf is replaced after each commit.
+	bufMu sync.RWMutex
+	buf   *txBuffer
+
 	stopc  chan struct{}
 	startc chan struct{}
 	donec  chan struct{}
 }
 
+// NewDefaultBackend creates a backend at the given path with the default
+// batch interval and batch limit.
+func NewDefaultBackend(path string) Backend {
+	return New(path, defaultBatchInterval, defaultBatchLimit)
+}
+
 func New(path string, d time.Duration, limit int) Backend {
 	db, err := bolt.Open(path, 0600, nil)
 	if err != nil {
@@ -39,6 +80,7 @@ func New(path string, d time.Duration, limit int) Backend {
 		batchInterval: d,
 		batchLimit:    limit,
 		batchTx:       &batchTx{},
+		buf:           newTxBuffer(),
 
 		stopc:  make(chan struct{}),
 		startc: make(chan struct{}),
@@ -57,12 +99,40 @@ func (b *backend) BatchTx() BatchTx {
 	return b.batchTx
 }
 
+// ReadTx begins a read-only tx on the backend. The tx sees the writes of
+// the batch tx made before it begins, including the ones that are not
+// committed yet, which it reads from the buffer of the pending writes.
+// Many read-only txs can run concurrently with each other and with the
+// batch tx, and they never take the batch tx lock.
+// The tx must be ended as soon as possible, since a long running
+// read-only tx blocks the batch tx from growing the database.
+func (b *backend) ReadTx() ReadTx {
+	b.mu.RLock()
+	// begin the tx and take the pending writes between the same commits,
+	// so each write before the tx is either committed or in the buffer.
+	b.bufMu.RLock()
+	tx, err := b.db.Begin(false)
+	if err != nil {
+		log.Fatalf("storage: cannot begin read tx (%s)", err)
+	}
+	buf := b.buf
+	n := buf.len()
+	b.bufMu.RUnlock()
+	return &readTx{tx: tx, buf: buf, n: n, unlock: b.mu.RUnlock}
+}
+
 // force commit the current batching tx.
 func (b *backend) ForceCommit() {
 	b.batchTx.Commit()
 }
 
+func (b *backend) Size() int64 {
+	return atomic.LoadInt64(&b.size)
+}
+
 func (b *backend) Snapshot(w io.Writer) (n int64, err error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	b.db.View(func(tx *bolt.Tx) error {
 		n, err = tx.WriteTo(w)
 		return nil
@@ -70,6 +140,137 @@ func (b *backend) Snapshot(w io.Writer) (n int64, err error) {
 	return n, err
 }
 
+// Defrag copies all the keys of the database into a new database file,
+// and replaces the database with it. The writes of the batch tx are
+// committed before the copy, and new writes are blocked until the
+// replacement is done. It waits for the on-going read-only txs to end.
+func (b *backend) Defrag() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.batchTx.Lock()
+	defer b.batchTx.Unlock()
+
+	// commit the pending writes without beginning a new tx on the
+	// current database.
+	if err := b.batchTx.tx.Commit(); err != nil {
+		log.Fatalf("storage: cannot commit tx (%s)", err)
+	}
+	b.batchTx.tx = nil
+	b.batchTx.pending = 0
+	// begin a new tx on the database that is open when returning
+	defer b.batchTx.commit()
+
+	dbp := b.db.Path()
+	tdbp := dbp + ".tmp"
+	tmpdb, err := bolt.Open(tdbp, 0600, nil)
+	if err != nil {
+		return err
+	}
+	if err = defragdb(b.db, tmpdb, defragLimit); err != nil {
+		tmpdb.Close()
+		os.Remove(tdbp)
+		return err
+	}
+
+	// the database cannot be recovered from any failure below, since the
+	// current database is closed.
+	if err = b.db.Close(); err != nil {
+		log.Fatalf("storage: cannot close database (%s)", err)
+	}
+	if err = tmpdb.Close(); err != nil {
+		log.Fatalf("storage: cannot close defragmented database (%s)", err)
+	}
+	if err = os.Rename(tdbp, dbp); err != nil {
+		log.Fatalf("storage: cannot rename defragmented database (%s)", err)
+	}
+	b.db, err = bolt.Open(dbp, 0600, nil)
+	if err != nil {
+		log.Panicf("backend: cannot open database at %s (%v)", dbp, err)
+	}
+	return nil
+}
+
+// Recover replaces the database with the database file at the given path.
+// The file is moved to the path of the database. The pending writes of the
+// batch tx are committed to the old database before it is closed, and new
+// writes are blocked until the replacement is done.
+func (b *backend) Recover(path string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.batchTx.Lock()
+	defer b.batchTx.Unlock()
+
+	if err := b.batchTx.tx.Commit(); err != nil {
+		log.Fatalf("storage: cannot commit tx (%s)", err)
+	}
+	b.batchTx.tx = nil
+	b.batchTx.pending = 0
+	// begin a new tx on the database that is open when returning
+	defer b.batchTx.commit()
+
+	dbp := b.db.Path()
+	if err := b.db.Close(); err != nil {
+		log.Fatalf("storage: cannot close database (%s)", err)
+	}
+	rerr := os.Rename(path, dbp)
+	// reopen the database even if the rename failed, so the backend
+	// keeps serving the old data.
+	var err error
+	b.db, err = bolt.Open(dbp, 0600, nil)
+	if err != nil {
+		log.Panicf("backend: cannot open database at %s (%v)", dbp, err)
+	}
+	return rerr
+}
+
+// defragdb copies all the buckets and keys of odb into tmpdb, committing
+// the writes to tmpdb every limit keys.
+func defragdb(odb, tmpdb *bolt.DB, limit int) error {
+	tmptx, err := tmpdb.Begin(true)
+	if err != nil {
+		return err
+	}
+
+	err = odb.View(func(tx *bolt.Tx) error {
+		count := 0
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			tmpb, berr := tmptx.CreateBucketIfNotExists(name)
+			if berr != nil {
+				return berr
+			}
+			// the keys are inserted in order, so the pages can be
+			// filled up.
+			tmpb.FillPercent = 0.9
+
+			return b.ForEach(func(k, v []byte) error {
+				count++
+				if count > limit {
+					if err := tmptx.Commit(); err != nil {
+						return err
+					}
+					var err error
+					if tmptx, err = tmpdb.Begin(true); err != nil {
+						return err
+					}
+					if tmpb = tmptx.Bucket(name); tmpb == nil {
+						return fmt.Errorf("storage: bucket %s does not exist", string(name))
+					}
+					tmpb.FillPercent = 0.9
+					count = 1
+				}
+				return tmpb.Put(k, v)
+			})
+		})
+	})
+	if err != nil {
+		tmptx.Rollback()
+		return err
+	}
+	return tmptx.Commit()
+}
+
 func (b *backend) run() {
 	defer close(b.donec)
 
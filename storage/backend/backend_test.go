@@ -1,6 +1,7 @@
 package backend
 
 import (
+	"fmt"
 	"os"
 	"reflect"
 	"testing"
@@ -20,10 +21,247 @@ func TestBackendPut(t *testing.T) {
 	batchTx.UnsafeCreateBucket([]byte("test"))
 
 	batchTx.UnsafePut([]byte("test"), []byte("foo"), v)
-	gv := batchTx.UnsafeRange([]byte("test"), v, nil, -1)
+	_, gv := batchTx.UnsafeRange([]byte("test"), v, nil, -1)
 	if !reflect.DeepEqual(gv[0], v) {
 		t.Errorf("v = %s, want %s", string(gv[0]), string(v))
 	}
 
 	batchTx.Unlock()
 }
+
+func TestBackendReadTx(t *testing.T) {
+	backend := New("test", 10*time.Second, 10000)
+	defer backend.Close()
+	defer os.Remove("test")
+
+	batchTx := backend.BatchTx()
+	batchTx.Lock()
+	batchTx.UnsafeCreateBucket([]byte("test"))
+	batchTx.UnsafePut([]byte("test"), []byte("foo"), []byte("bar"))
+	batchTx.Unlock()
+	backend.ForceCommit()
+
+	readTx := backend.ReadTx()
+	defer readTx.End()
+
+	// the writes after the read tx begins are not visible to it
+	batchTx.Lock()
+	batchTx.UnsafePut([]byte("test"), []byte("foo"), []byte("baz"))
+	batchTx.Unlock()
+	backend.ForceCommit()
+
+	_, gv := readTx.UnsafeRange([]byte("test"), []byte("foo"), nil, 0)
+	if len(gv) != 1 || !reflect.DeepEqual(gv[0], []byte("bar")) {
+		t.Errorf("v = %q, want [bar]", gv)
+	}
+}
+
+func TestBackendReadTxPendingWrites(t *testing.T) {
+	backend := New("test", 10*time.Second, 10000)
+	defer backend.Close()
+	defer os.Remove("test")
+
+	batchTx := backend.BatchTx()
+	batchTx.Lock()
+	batchTx.UnsafeCreateBucket([]byte("test"))
+	batchTx.UnsafePut([]byte("test"), []byte("foo"), []byte("bar"))
+	batchTx.UnsafePut([]byte("test"), []byte("foo1"), []byte("bar1"))
+	batchTx.Unlock()
+	backend.ForceCommit()
+
+	// the writes before the read tx begins are visible to it, even if
+	// they are not committed.
+	batchTx.Lock()
+	batchTx.UnsafePut([]byte("test"), []byte("foo"), []byte("baz"))
+	batchTx.UnsafeDelete([]byte("test"), []byte("foo1"))
+	batchTx.UnsafePut([]byte("test"), []byte("foo2"), []byte("bar2"))
+	batchTx.Unlock()
+
+	readTx := backend.ReadTx()
+	defer readTx.End()
+
+	batchTx.Lock()
+	batchTx.UnsafePut([]byte("test"), []byte("foo3"), []byte("bar3"))
+	batchTx.Unlock()
+
+	_, gv := readTx.UnsafeRange([]byte("test"), []byte("foo"), nil, 0)
+	if len(gv) != 1 || !reflect.DeepEqual(gv[0], []byte("baz")) {
+		t.Errorf("v = %q, want [baz]", gv)
+	}
+	if _, gv = readTx.UnsafeRange([]byte("test"), []byte("foo1"), nil, 0); len(gv) != 0 {
+		t.Errorf("v = %q, want []", gv)
+	}
+	keys, gv := readTx.UnsafeRange([]byte("test"), []byte("foo"), []byte("foo9"), 0)
+	wkeys, wvs := [][]byte{[]byte("foo"), []byte("foo2")}, [][]byte{[]byte("baz"), []byte("bar2")}
+	if !reflect.DeepEqual(keys, wkeys) || !reflect.DeepEqual(gv, wvs) {
+		t.Errorf("range = %q %q, want %q %q", keys, gv, wkeys, wvs)
+	}
+
+	// the commit does not change what the read tx sees
+	backend.ForceCommit()
+	keys, gv = readTx.UnsafeRange([]byte("test"), []byte("foo"), []byte("foo9"), 0)
+	if !reflect.DeepEqual(keys, wkeys) || !reflect.DeepEqual(gv, wvs) {
+		t.Errorf("range = %q %q, want %q %q", keys, gv, wkeys, wvs)
+	}
+}
+
+func TestBackendRangeLimit(t *testing.T) {
+	backend := New("test", 10*time.Second, 10000)
+	defer backend.Close()
+	defer os.Remove("test")
+
+	batchTx := backend.BatchTx()
+	batchTx.Lock()
+	batchTx.UnsafeCreateBucket([]byte("test"))
+	for _, k := range []string{"foo", "foo1", "foo2"} {
+		batchTx.UnsafePut([]byte("test"), []byte(k), []byte("bar"))
+	}
+	batchTx.Unlock()
+	backend.ForceCommit()
+
+	// the pending delete is merged before the limit applies
+	batchTx.Lock()
+	batchTx.UnsafeDelete([]byte("test"), []byte("foo"))
+	batchTx.Unlock()
+	readTx := backend.ReadTx()
+	defer readTx.End()
+
+	tests := []struct {
+		limit int64
+
+		wkeys [][]byte
+	}{
+		{0, [][]byte{[]byte("foo1"), []byte("foo2")}},
+		{1, [][]byte{[]byte("foo1")}},
+		{2, [][]byte{[]byte("foo1"), []byte("foo2")}},
+		{3, [][]byte{[]byte("foo1"), []byte("foo2")}},
+	}
+	for i, tt := range tests {
+		batchTx.Lock()
+		keys, _ := batchTx.UnsafeRange([]byte("test"), []byte("foo"), []byte("foo9"), tt.limit)
+		batchTx.Unlock()
+		if !reflect.DeepEqual(keys, tt.wkeys) {
+			t.Errorf("#%d: batch tx keys = %q, want %q", i, keys, tt.wkeys)
+		}
+		keys, _ = readTx.UnsafeRange([]byte("test"), []byte("foo"), []byte("foo9"), tt.limit)
+		if !reflect.DeepEqual(keys, tt.wkeys) {
+			t.Errorf("#%d: read tx keys = %q, want %q", i, keys, tt.wkeys)
+		}
+	}
+}
+
+func TestBackendDefrag(t *testing.T) {
+	backend := New("test", 10*time.Second, 10000)
+	defer backend.Close()
+	defer os.Remove("test")
+
+	batchTx := backend.BatchTx()
+	batchTx.Lock()
+	batchTx.UnsafeCreateBucket([]byte("test"))
+	for i := 0; i < defragLimit+100; i++ {
+		batchTx.UnsafePut([]byte("test"), []byte(fmt.Sprintf("foo_%d", i)), make([]byte, 128))
+	}
+	batchTx.Unlock()
+	backend.ForceCommit()
+
+	// remove some keys to create free pages in the database file
+	batchTx.Lock()
+	for i := 0; i < 50; i++ {
+		batchTx.UnsafeDelete([]byte("test"), []byte(fmt.Sprintf("foo_%d", i)))
+	}
+	// the pending write is kept by the defragmentation
+	batchTx.UnsafePut([]byte("test"), []byte("more"), []byte("bar"))
+	batchTx.Unlock()
+	backend.ForceCommit()
+
+	size := fileSize(t, "test")
+	if err := backend.Defrag(); err != nil {
+		t.Fatal(err)
+	}
+	if nsize := fileSize(t, "test"); nsize >= size {
+		t.Errorf("size = %d, want < %d", nsize, size)
+	}
+
+	batchTx.Lock()
+	ks, _ := batchTx.UnsafeRange([]byte("test"), []byte("foo"), []byte("foo_999999"), 0)
+	if len(ks) != defragLimit+50 {
+		t.Errorf("len(keys) = %d, want %d", len(ks), defragLimit+50)
+	}
+	_, vs := batchTx.UnsafeRange([]byte("test"), []byte("more"), nil, 0)
+	if len(vs) != 1 || !reflect.DeepEqual(vs[0], []byte("bar")) {
+		t.Errorf("v = %q, want [bar]", vs)
+	}
+	// the batch tx keeps working after the defragmentation
+	batchTx.UnsafePut([]byte("test"), []byte("more"), []byte("baz"))
+	batchTx.Unlock()
+	backend.ForceCommit()
+
+	readTx := backend.ReadTx()
+	_, vs = readTx.UnsafeRange([]byte("test"), []byte("more"), nil, 0)
+	readTx.End()
+	if len(vs) != 1 || !reflect.DeepEqual(vs[0], []byte("baz")) {
+		t.Errorf("v = %q, want [baz]", vs)
+	}
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi.Size()
+}
+
+func TestBackendRecover(t *testing.T) {
+	// the snapshot to recover from
+	sb := New("snap", 10*time.Second, 10000)
+	batchTx := sb.BatchTx()
+	batchTx.Lock()
+	batchTx.UnsafeCreateBucket([]byte("test"))
+	batchTx.UnsafePut([]byte("test"), []byte("foo"), []byte("snap"))
+	batchTx.Unlock()
+	sb.ForceCommit()
+	f, err := os.Create("snap.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = sb.Snapshot(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	sb.Close()
+	os.Remove("snap")
+
+	backend := New("test", 10*time.Second, 10000)
+	defer backend.Close()
+	defer os.Remove("test")
+
+	batchTx = backend.BatchTx()
+	batchTx.Lock()
+	batchTx.UnsafeCreateBucket([]byte("test"))
+	batchTx.UnsafePut([]byte("test"), []byte("foo"), []byte("bar"))
+	batchTx.Unlock()
+
+	if err := backend.Recover("snap.db"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat("snap.db"); !os.IsNotExist(err) {
+		t.Errorf("err = %v, want the snapshot file moved", err)
+	}
+
+	readTx := backend.ReadTx()
+	_, vs := readTx.UnsafeRange([]byte("test"), []byte("foo"), nil, 0)
+	readTx.End()
+	if len(vs) != 1 || !reflect.DeepEqual(vs[0], []byte("snap")) {
+		t.Errorf("v = %q, want [snap]", vs)
+	}
+
+	// the batch tx keeps working after the recovery
+	batchTx.Lock()
+	batchTx.UnsafePut([]byte("test"), []byte("foo"), []byte("baz"))
+	_, vs = batchTx.UnsafeRange([]byte("test"), []byte("foo"), nil, 0)
+	batchTx.Unlock()
+	if len(vs) != 1 || !reflect.DeepEqual(vs[0], []byte("baz")) {
+		t.Errorf("v = %q, want [baz]", vs)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"log"
 	"sync"
+	"sync/atomic"
 
 	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/boltdb/bolt"
 )
@@ -41,6 +42,7 @@ func (t *batchTx) UnsafePut(bucketName []byte, key []byte, value []byte) {
 	if err := bucket.Put(key, value); err != nil {
 		log.Fatalf("storage: cannot put key into bucket (%v)", err)
 	}
+	t.backend.buf.put(bucketName, key, value)
 	t.pending++
 	if t.pending > t.backend.batchLimit {
 		t.commit()
@@ -50,7 +52,13 @@ func (t *batchTx) UnsafePut(bucketName []byte, key []byte, value []byte) {
 
 // before calling unsafeRange, the caller MUST hold the lock on tx.
 func (t *batchTx) UnsafeRange(bucketName []byte, key, endKey []byte, limit int64) (keys [][]byte, vs [][]byte) {
-	bucket := t.tx.Bucket(bucketName)
+	return unsafeRange(t.tx, bucketName, key, endKey, limit)
+}
+
+// unsafeRange returns at most limit keys in [key, endKey) of the given
+// bucket, or all of them if limit is not positive.
+func unsafeRange(tx *bolt.Tx, bucketName []byte, key, endKey []byte, limit int64) (keys [][]byte, vs [][]byte) {
+	bucket := tx.Bucket(bucketName)
 	if bucket == nil {
 		log.Fatalf("storage: bucket %s does not exist", string(bucketName))
 	}
@@ -67,6 +75,9 @@ func (t *batchTx) UnsafeRange(bucketName []byte, key, endKey []byte, limit int64
 	for ck, cv := c.Seek(key); ck != nil && bytes.Compare(ck, endKey) < 0; ck, cv = c.Next() {
 		vs = append(vs, cv)
 		keys = append(keys, ck)
+		if limit > 0 && int64(len(keys)) == limit {
+			break
+		}
 	}
 
 	return keys, vs
@@ -82,6 +93,7 @@ func (t *batchTx) UnsafeDelete(bucketName []byte, key []byte) {
 	if err != nil {
 		log.Fatalf("storage: cannot delete key from bucket (%v)", err)
 	}
+	t.backend.buf.delete(bucketName, key)
 	t.pending++
 	if t.pending > t.backend.batchLimit {
 		t.commit()
@@ -105,10 +117,16 @@ func (t *batchTx) commit() {
 			log.Fatalf("storage: cannot commit tx (%s)", err)
 		}
 	}
+	// the writes are committed, so the read-only txs that begin from now
+	// on read them from the database.
+	t.backend.bufMu.Lock()
+	t.backend.buf = newTxBuffer()
+	t.backend.bufMu.Unlock()
 
 	// begin a new tx
 	t.tx, err = t.backend.db.Begin(true)
 	if err != nil {
 		log.Fatalf("storage: cannot begin tx (%s)", err)
 	}
+	atomic.StoreInt64(&t.backend.size, t.tx.Size())
 }
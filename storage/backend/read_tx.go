@@ -0,0 +1,53 @@
+package backend
+
+import (
+	"log"
+
+	"github.com/coreos/etcd/Godeps/_workspace/src/github.com/boltdb/bolt"
+)
+
+type ReadTx interface {
+	UnsafeRange(bucketName []byte, key, endKey []byte, limit int64) (keys [][]byte, vals [][]byte)
+	End()
+}
+
+type readTx struct {
+	tx *bolt.Tx
+	// buf is the buffer of the pending writes when the tx began, of
+	// which the first n writes are visible to the tx.
+	buf *txBuffer
+	n   int
+	// unlock releases the backend lock held by the tx.
+	unlock func()
+}
+
+// UnsafeRange ranges over the given bucket in the read-only tx. Unlike the
+// batch tx, a read-only tx does not need a lock, but it MUST NOT be used
+// after it ends.
+func (t *readTx) UnsafeRange(bucketName []byte, key, endKey []byte, limit int64) (keys [][]byte, vs [][]byte) {
+	if len(endKey) == 0 {
+		if w, ok := t.buf.get(bucketName, key, t.n); ok {
+			if w.deleted {
+				return nil, nil
+			}
+			return [][]byte{key}, [][]byte{w.value}
+		}
+		return unsafeRange(t.tx, bucketName, key, endKey, 0)
+	}
+	// the limit applies after the merge, since the pending writes can
+	// add keys to the range or delete them from it.
+	keys, vs = unsafeRange(t.tx, bucketName, key, endKey, 0)
+	keys, vs = t.buf.merge(bucketName, key, endKey, t.n, keys, vs)
+	if limit > 0 && int64(len(keys)) > limit {
+		keys, vs = keys[:limit], vs[:limit]
+	}
+	return keys, vs
+}
+
+// End ends the read-only tx.
+func (t *readTx) End() {
+	if err := t.tx.Rollback(); err != nil {
+		log.Fatalf("storage: cannot end read tx (%s)", err)
+	}
+	t.unlock()
+}
@@ -0,0 +1,122 @@
+package backend
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+)
+
+// txBuffer records the writes of the batch tx that are not committed yet,
+// so the read-only txs can see them without taking the batch tx lock.
+// The writes are only appended. A read-only tx sees the writes recorded
+// before it began, which are the first n writes of the buffer at that
+// time.
+type txBuffer struct {
+	mu     sync.RWMutex
+	writes []bufferedWrite
+	// index maps the bucket name and the key to the positions of the
+	// writes of the key in writes, in order.
+	index map[string]map[string][]int
+}
+
+type bufferedWrite struct {
+	bucket  string
+	key     []byte
+	value   []byte
+	deleted bool
+}
+
+func newTxBuffer() *txBuffer {
+	return &txBuffer{index: make(map[string]map[string][]int)}
+}
+
+// put records a write of the key. The key and the value are copied, since
+// the caller may reuse them.
+func (b *txBuffer) put(bucketName, key, value []byte) {
+	b.add(bufferedWrite{
+		bucket: string(bucketName),
+		key:    append([]byte(nil), key...),
+		value:  append([]byte(nil), value...),
+	})
+}
+
+// delete records a deletion of the key.
+func (b *txBuffer) delete(bucketName, key []byte) {
+	b.add(bufferedWrite{
+		bucket:  string(bucketName),
+		key:     append([]byte(nil), key...),
+		deleted: true,
+	})
+}
+
+func (b *txBuffer) add(w bufferedWrite) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	keys, ok := b.index[w.bucket]
+	if !ok {
+		keys = make(map[string][]int)
+		b.index[w.bucket] = keys
+	}
+	keys[string(w.key)] = append(keys[string(w.key)], len(b.writes))
+	b.writes = append(b.writes, w)
+}
+
+// len returns the number of the writes recorded.
+func (b *txBuffer) len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.writes)
+}
+
+// get returns the last of the first n writes of the key. It returns false
+// if there is no such write.
+func (b *txBuffer) get(bucketName, key []byte, n int) (bufferedWrite, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	ps := b.index[string(bucketName)][string(key)]
+	for i := len(ps) - 1; i >= 0; i-- {
+		if ps[i] < n {
+			return b.writes[ps[i]], true
+		}
+	}
+	return bufferedWrite{}, false
+}
+
+// merge applies the first n writes of the keys in [key, endKey) to the
+// given keys and values ranged from the committed state, and returns the
+// result ordered by key.
+func (b *txBuffer) merge(bucketName, key, endKey []byte, n int, keys, vs [][]byte) ([][]byte, [][]byte) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	kvs := make(map[string][]byte, len(keys))
+	for i := range keys {
+		kvs[string(keys[i])] = vs[i]
+	}
+	changed := false
+	for _, w := range b.writes[:n] {
+		if w.bucket != string(bucketName) || bytes.Compare(w.key, key) < 0 || bytes.Compare(w.key, endKey) >= 0 {
+			continue
+		}
+		changed = true
+		if w.deleted {
+			delete(kvs, string(w.key))
+		} else {
+			kvs[string(w.key)] = w.value
+		}
+	}
+	if !changed {
+		return keys, vs
+	}
+
+	sorted := make([]string, 0, len(kvs))
+	for k := range kvs {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	keys, vs = make([][]byte, 0, len(sorted)), make([][]byte, 0, len(sorted))
+	for _, k := range sorted {
+		keys = append(keys, []byte(k))
+		vs = append(vs, kvs[k])
+	}
+	return keys, vs
+}
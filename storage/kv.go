@@ -3,10 +3,14 @@ package storage
 import (
 	"io"
 
+	"github.com/coreos/etcd/lease"
 	"github.com/coreos/etcd/storage/storagepb"
 )
 
 type KV interface {
+	// Rev returns the current revision of the KV.
+	Rev() int64
+
 	// Range gets the keys in the range at rangeRev.
 	// If rangeRev <=0, range gets the keys at currentRev.
 	// If `end` is nil, the request returns the key.
@@ -15,9 +19,11 @@ type KV interface {
 	// If the required rev is compacted, ErrCompacted will be returned.
 	Range(key, end []byte, limit, rangeRev int64) (kvs []storagepb.KeyValue, rev int64, err error)
 
-	// Put puts the given key,value into the store.
+	// Put puts the given key,value into the store. If lease is not
+	// lease.NoLease, the key is attached to the lease, and is deleted when
+	// the lease is revoked. The lease must exist.
 	// A put also increases the rev of the store, and generates one event in the event history.
-	Put(key, value []byte) (rev int64)
+	Put(key, value []byte, lease lease.LeaseID) (rev int64)
 
 	// DeleteRange deletes the given range from the store.
 	// A deleteRange increases the rev of the store if any key in the range exists.
@@ -27,6 +33,12 @@ type KV interface {
 	// if the `end` is not nil, deleteRange deletes the keys in range [key, range_end).
 	DeleteRange(key, end []byte) (n, rev int64)
 
+	// ReadTxnBegin begins a read-only txn at the current revision. Many
+	// read-only txns can run concurrently with each other and with the
+	// on-going txn. The read-only txn must be ended as soon as it is no
+	// longer used.
+	ReadTxnBegin() ReadTxn
+
 	// TxnBegin begins a txn. Only Txn prefixed operation can be executed, others will be blocked
 	// until txn ends. Only one on-going txn is allowed.
 	// TxnBegin returns an int64 txn ID.
@@ -35,7 +47,7 @@ type KV interface {
 	// TxnEnd ends the on-going txn with txn ID. If the on-going txn ID is not matched, error is returned.
 	TxnEnd(txnID int64) error
 	TxnRange(txnID int64, key, end []byte, limit, rangeRev int64) (kvs []storagepb.KeyValue, rev int64, err error)
-	TxnPut(txnID int64, key, value []byte) (rev int64, err error)
+	TxnPut(txnID int64, key, value []byte, lease lease.LeaseID) (rev int64, err error)
 	TxnDeleteRange(txnID int64, key, end []byte) (n, rev int64, err error)
 
 	Compact(rev int64) error
@@ -46,3 +58,80 @@ type KV interface {
 	Restore() error
 	Close() error
 }
+
+// ReadTxn is a read-only txn of a KV. It reads the keys at the revision
+// of the KV when it begins, no matter what is written after that.
+type ReadTxn interface {
+	// Rev returns the revision the read-only txn begins at.
+	Rev() int64
+
+	// Range gets the keys in the range at rangeRev like KV.Range. If
+	// rangeRev <= 0, range gets the keys at Rev. A rangeRev newer than Rev
+	// is a future revision to the read-only txn.
+	Range(key, end []byte, limit, rangeRev int64) (kvs []storagepb.KeyValue, rev int64, err error)
+
+	// End ends the read-only txn.
+	End()
+}
+
+// ConsistentIndexGetter is an interface that wraps the ConsistentIndex method.
+// Consistent index is the offset of an entry in a consistent replicated log.
+type ConsistentIndexGetter interface {
+	// ConsistentIndex returns the consistent index of current executing entry.
+	ConsistentIndex() uint64
+}
+
+// ConsistentKV is a KV that records the consistent index of the last
+// applied write along with the write itself. The application uses the
+// recorded index to skip log entries that have already been applied
+// before a restart.
+type ConsistentKV interface {
+	KV
+
+	// ConsistentIndex returns the consistent index recorded by the last
+	// txn that changed the store.
+	ConsistentIndex() uint64
+}
+
+// WatchableKV is a KV that can be watched.
+type WatchableKV interface {
+	KV
+
+	// Watch watches the events happening or happened in the given range
+	// from startRev.
+	// If `end` is nil, it watches the key.
+	// If `end` is not nil, it watches the keys in range [key, range_end).
+	// If startRev <= 0, it watches the events that happen after the call.
+	// The events happened before the call are replayed from the store
+	// first, and then the new events are delivered as they happen.
+	// If startRev has been compacted, ErrCompacted will be returned.
+	Watch(key, end []byte, startRev int64) (Watcher, error)
+}
+
+// Watcher watches the events on a key range of a WatchableKV.
+type Watcher interface {
+	// Event returns a channel that receives the observed events in the
+	// order of their reversions. Each receive gets all the observed events
+	// that happened at one reversion. The channel is closed when the
+	// watcher is canceled or stopped by an error.
+	Event() <-chan []storagepb.Event
+
+	// Err returns the error that stopped the watcher. It returns
+	// ErrCompacted if the events to deliver were compacted before the
+	// watcher caught up with the store. It returns nil if the watcher is
+	// still running or is canceled.
+	Err() error
+
+	// Cancel stops the watcher and closes its event channel.
+	Cancel()
+}
+
+// ConsistentWatchableKV is a WatchableKV that records the consistent
+// index like a ConsistentKV.
+type ConsistentWatchableKV interface {
+	WatchableKV
+
+	// ConsistentIndex returns the consistent index recorded by the last
+	// txn that changed the store.
+	ConsistentIndex() uint64
+}
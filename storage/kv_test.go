@@ -5,6 +5,8 @@ import (
 	"os"
 	"reflect"
 	"testing"
+
+	"github.com/coreos/etcd/lease"
 )
 
 type kv struct {
@@ -27,7 +29,7 @@ func TestWorkflow(t *testing.T) {
 		for k := 0; k < 100; k++ {
 			key := fmt.Sprintf("bar_%03d_%03d", i, k)
 			val := fmt.Sprintf("foo_%03d_%03d", i, k)
-			s.Put([]byte(key), []byte(val))
+			s.Put([]byte(key), []byte(val), lease.NoLease)
 			wkvs = append(wkvs, kv{k: []byte(key), v: []byte(val)})
 		}
 
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"encoding/binary"
 	"errors"
 	"io"
 	"log"
@@ -9,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/coreos/etcd/lease"
 	"github.com/coreos/etcd/storage/backend"
 	"github.com/coreos/etcd/storage/storagepb"
 )
@@ -21,6 +23,7 @@ var (
 
 	scheduledCompactKeyName = []byte("scheduledCompactRev")
 	finishedCompactKeyName  = []byte("finishedCompactRev")
+	consistentIndexKeyName  = []byte("consistentIndex")
 
 	ErrTxnIDMismatch = errors.New("storage: txn id mismatch")
 	ErrCompacted     = errors.New("storage: required reversion has been compacted")
@@ -30,13 +33,31 @@ var (
 type store struct {
 	mu sync.RWMutex
 
+	// revMu protects currentRev.main and compactMainRev for the read-only
+	// txns, which do not hold mu. Both fields are changed while holding mu
+	// and revMu, so the holder of mu can read them without revMu.
+	revMu sync.RWMutex
+
 	b       backend.Backend
 	kvindex index
 
+	// le tracks the keys attached to leases. It might be nil.
+	le lease.Lessor
+
+	// ig is used to get the consistent index that is saved with
+	// each write txn. It might be nil.
+	ig ConsistentIndexGetter
+
 	currentRev reversion
 	// the main reversion of the last compaction
 	compactMainRev int64
 
+	// changes are the events generated by the on-going txn.
+	changes []storagepb.Event
+	// notify is called with the main reversion and the events of each
+	// txn that changes the store, before the txn ends. It might be nil.
+	notify func(rev int64, evs []storagepb.Event)
+
 	tmu   sync.Mutex // protect the txnID field
 	txnID int64      // tracks the current txnID to verify txn operations
 
@@ -48,10 +69,25 @@ func New(path string) KV {
 	return newStore(path)
 }
 
+// NewConsistent creates a ConsistentKV at the given path. The index
+// returned by ig is saved atomically with every txn that changes the store.
+func NewConsistent(path string, ig ConsistentIndexGetter) ConsistentKV {
+	s := newStore(path)
+	s.ig = ig
+	return s
+}
+
 func newStore(path string) *store {
+	return newStoreWithBackend(backend.New(path, batchInterval, batchLimit), nil)
+}
+
+// newStoreWithBackend creates a store on the given backend. The keys put
+// with a lease are attached to the lease in le if le is not nil.
+func newStoreWithBackend(b backend.Backend, le lease.Lessor) *store {
 	s := &store{
-		b:              backend.New(path, batchInterval, batchLimit),
+		b:              b,
 		kvindex:        newTreeIndex(),
+		le:             le,
 		currentRev:     reversion{},
 		compactMainRev: -1,
 		stopc:          make(chan struct{}),
@@ -64,23 +100,31 @@ func newStore(path string) *store {
 	tx.Unlock()
 	s.b.ForceCommit()
 
+	if s.le != nil {
+		s.le.SetRangeDeleter(s)
+	}
 	return s
 }
 
-func (s *store) Put(key, value []byte) int64 {
+func (s *store) Rev() int64 {
+	s.revMu.RLock()
+	defer s.revMu.RUnlock()
+
+	return s.currentRev.main
+}
+
+func (s *store) Put(key, value []byte, leaseID lease.LeaseID) int64 {
 	id := s.TxnBegin()
-	s.put(key, value, s.currentRev.main+1)
+	s.put(key, value, s.currentRev.main+1, leaseID)
 	s.TxnEnd(id)
 
 	return int64(s.currentRev.main)
 }
 
 func (s *store) Range(key, end []byte, limit, rangeRev int64) (kvs []storagepb.KeyValue, rev int64, err error) {
-	id := s.TxnBegin()
-	kvs, rev, err = s.rangeKeys(key, end, limit, rangeRev)
-	s.TxnEnd(id)
-
-	return kvs, rev, err
+	txn := s.ReadTxnBegin()
+	defer txn.End()
+	return txn.Range(key, end, limit, rangeRev)
 }
 
 func (s *store) DeleteRange(key, end []byte) (n, rev int64) {
@@ -109,9 +153,16 @@ func (s *store) TxnEnd(txnID int64) error {
 	}
 
 	if s.currentRev.sub != 0 {
+		s.saveIndex()
+		s.revMu.Lock()
 		s.currentRev.main += 1
+		s.revMu.Unlock()
+		if s.notify != nil {
+			s.notify(s.currentRev.main, s.changes)
+		}
 	}
 	s.currentRev.sub = 0
+	s.changes = nil
 	s.mu.Unlock()
 	return nil
 }
@@ -125,14 +176,14 @@ func (s *store) TxnRange(txnID int64, key, end []byte, limit, rangeRev int64) (k
 	return s.rangeKeys(key, end, limit, rangeRev)
 }
 
-func (s *store) TxnPut(txnID int64, key, value []byte) (rev int64, err error) {
+func (s *store) TxnPut(txnID int64, key, value []byte, leaseID lease.LeaseID) (rev int64, err error) {
 	s.tmu.Lock()
 	defer s.tmu.Unlock()
 	if txnID != s.txnID {
 		return 0, ErrTxnIDMismatch
 	}
 
-	s.put(key, value, s.currentRev.main+1)
+	s.put(key, value, s.currentRev.main+1, leaseID)
 	return int64(s.currentRev.main + 1), nil
 }
 
@@ -150,6 +201,19 @@ func (s *store) TxnDeleteRange(txnID int64, key, end []byte) (n, rev int64, err
 	return n, rev, nil
 }
 
+// ConsistentIndex returns the consistent index saved by the last txn
+// that changed the store. It returns 0 if no index has been saved.
+func (s *store) ConsistentIndex() uint64 {
+	tx := s.b.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+	_, vs := tx.UnsafeRange(metaBucketName, consistentIndexKeyName, nil, 0)
+	if len(vs) == 0 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(vs[0])
+}
+
 func (s *store) Compact(rev int64) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -157,7 +221,9 @@ func (s *store) Compact(rev int64) error {
 		return ErrCompacted
 	}
 
+	s.revMu.Lock()
 	s.compactMainRev = rev
+	s.revMu.Unlock()
 
 	rbytes := newRevBytes()
 	revToBytes(reversion{main: rev}, rbytes)
@@ -182,6 +248,14 @@ func (s *store) Snapshot(w io.Writer) (int64, error) {
 func (s *store) Restore() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.revMu.Lock()
+	defer s.revMu.Unlock()
+
+	// the store might be restored again after its backend is replaced
+	// by a snapshot, so drop the state restored before.
+	s.kvindex = newTreeIndex()
+	s.currentRev = reversion{}
+	s.compactMainRev = -1
 
 	min, max := newRevBytes(), newRevBytes()
 	revToBytes(reversion{}, min)
@@ -196,6 +270,10 @@ func (s *store) Restore() error {
 		log.Printf("storage: restore compact to %d", s.compactMainRev)
 	}
 
+	// keyToLease records the lease attached to the latest version of
+	// each key.
+	keyToLease := make(map[string]lease.LeaseID)
+
 	// TODO: limit N to reduce max memory usage
 	keys, vals := tx.UnsafeRange(keyBucketName, min, max, 0)
 	for i, key := range keys {
@@ -210,8 +288,14 @@ func (s *store) Restore() error {
 		switch e.Type {
 		case storagepb.PUT:
 			s.kvindex.Restore(e.Kv.Key, reversion{e.Kv.CreateIndex, 0}, rev, e.Kv.Version)
+			if lid := lease.LeaseID(e.Kv.Lease); lid != lease.NoLease {
+				keyToLease[string(e.Kv.Key)] = lid
+			} else {
+				delete(keyToLease, string(e.Kv.Key))
+			}
 		case storagepb.DELETE:
 			s.kvindex.Tombstone(e.Kv.Key, rev)
+			delete(keyToLease, string(e.Kv.Key))
 		default:
 			log.Panicf("storage: unexpected event type %s", e.Type)
 		}
@@ -220,6 +304,14 @@ func (s *store) Restore() error {
 		s.currentRev = rev
 	}
 
+	if s.le != nil {
+		for key, lid := range keyToLease {
+			if err := s.le.Attach(lid, []byte(key)); err != nil {
+				log.Panicf("storage: cannot attach key %q to lease %x: %v", key, lid, err)
+			}
+		}
+	}
+
 	_, scheduledCompactBytes := tx.UnsafeRange(metaBucketName, scheduledCompactKeyName, nil, 0)
 	if len(scheduledCompactBytes) != 0 {
 		scheduledCompact := bytesToRev(scheduledCompactBytes[0]).main
@@ -298,13 +390,22 @@ func (s *store) rangeKeys(key, end []byte, limit, rangeRev int64) (kvs []storage
 	return kvs, rev, nil
 }
 
-func (s *store) put(key, value []byte, rev int64) {
+func (s *store) put(key, value []byte, rev int64, leaseID lease.LeaseID) {
 	c := rev
+	oldLease := lease.NoLease
+
+	tx := s.b.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
 
-	// if the key exists before, use its previous created
-	_, created, ver, err := s.kvindex.Get(key, rev)
+	// if the key exists before, use its previous created and
+	// get its previous lease
+	modified, created, ver, err := s.kvindex.Get(key, rev)
 	if err == nil {
 		c = created.main
+		if s.le != nil {
+			oldLease = s.unsafeLease(tx, modified)
+		}
 	}
 
 	ibytes := newRevBytes()
@@ -319,6 +420,7 @@ func (s *store) put(key, value []byte, rev int64) {
 			CreateIndex: c,
 			ModIndex:    rev,
 			Version:     ver,
+			Lease:       int64(leaseID),
 		},
 	}
 
@@ -327,12 +429,41 @@ func (s *store) put(key, value []byte, rev int64) {
 		log.Fatalf("storage: cannot marshal event: %v", err)
 	}
 
-	tx := s.b.BatchTx()
-	tx.Lock()
-	defer tx.Unlock()
 	tx.UnsafePut(keyBucketName, ibytes, d)
 	s.kvindex.Put(key, reversion{main: rev, sub: s.currentRev.sub})
+	s.changes = append(s.changes, event)
 	s.currentRev.sub += 1
+
+	if oldLease != lease.NoLease {
+		if err := s.le.Detach(oldLease, key); err != nil {
+			log.Panicf("storage: cannot detach key %q from lease %x: %v", key, oldLease, err)
+		}
+	}
+	if leaseID != lease.NoLease {
+		if s.le == nil {
+			log.Panicf("storage: no lessor to attach lease %x", leaseID)
+		}
+		if err := s.le.Attach(leaseID, key); err != nil {
+			log.Panicf("storage: cannot attach key %q to lease %x: %v", key, leaseID, err)
+		}
+	}
+}
+
+// unsafeLease returns the lease attached to the key-value at the given
+// reversion. The caller must hold the lock of tx.
+func (s *store) unsafeLease(tx backend.BatchTx, rev reversion) lease.LeaseID {
+	revbytes := newRevBytes()
+	revToBytes(rev, revbytes)
+
+	_, vs := tx.UnsafeRange(keyBucketName, revbytes, nil, 0)
+	if len(vs) != 1 {
+		log.Fatalf("storage: cannot find rev (%d,%d)", rev.main, rev.sub)
+	}
+	e := &storagepb.Event{}
+	if err := e.Unmarshal(vs[0]); err != nil {
+		log.Fatalf("storage: cannot unmarshal event: %v", err)
+	}
+	return lease.LeaseID(e.Kv.Lease)
 }
 
 func (s *store) deleteRange(key, end []byte, rev int64) int64 {
@@ -356,6 +487,21 @@ func (s *store) deleteRange(key, end []byte, rev int64) int64 {
 	return n
 }
 
+// saveIndex saves the current consistent index into the meta bucket, so
+// that it is committed together with the changes of the on-going txn.
+func (s *store) saveIndex() {
+	if s.ig == nil {
+		return
+	}
+	bs := make([]byte, 8)
+	binary.BigEndian.PutUint64(bs, s.ig.ConsistentIndex())
+
+	tx := s.b.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+	tx.UnsafePut(metaBucketName, consistentIndexKeyName, bs)
+}
+
 func (s *store) delete(key []byte, mainrev int64) bool {
 	grev := mainrev
 	if s.currentRev.sub > 0 {
@@ -393,7 +539,8 @@ func (s *store) delete(key []byte, mainrev int64) bool {
 	event := storagepb.Event{
 		Type: storagepb.DELETE,
 		Kv: &storagepb.KeyValue{
-			Key: key,
+			Key:      key,
+			ModIndex: mainrev,
 		},
 	}
 
@@ -407,6 +554,13 @@ func (s *store) delete(key []byte, mainrev int64) bool {
 	if err != nil {
 		log.Fatalf("storage: cannot tombstone an existing key (%s): %v", string(key), err)
 	}
+	s.changes = append(s.changes, event)
 	s.currentRev.sub += 1
+
+	if lid := lease.LeaseID(e.Kv.Lease); lid != lease.NoLease && s.le != nil {
+		if err := s.le.Detach(lid, key); err != nil {
+			log.Panicf("storage: cannot detach key %q from lease %x: %v", key, lid, err)
+		}
+	}
 	return true
 }
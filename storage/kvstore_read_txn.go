@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"log"
+
+	"github.com/coreos/etcd/storage/backend"
+	"github.com/coreos/etcd/storage/storagepb"
+)
+
+// readTxn is a read-only txn of the store. It does not hold the store
+// lock. It reads the index at its revision, and the key-values from a
+// read-only backend tx.
+type readTxn struct {
+	s  *store
+	tx backend.ReadTx
+
+	rev int64
+}
+
+func (s *store) ReadTxnBegin() ReadTxn {
+	s.revMu.RLock()
+	rev := s.currentRev.main
+	s.revMu.RUnlock()
+
+	return &readTxn{s: s, tx: s.b.ReadTx(), rev: rev}
+}
+
+func (t *readTxn) Rev() int64 { return t.rev }
+
+func (t *readTxn) End() { t.tx.End() }
+
+func (t *readTxn) Range(key, end []byte, limit, rangeRev int64) (kvs []storagepb.KeyValue, rev int64, err error) {
+	if rangeRev > t.rev {
+		return nil, t.rev, ErrFutureRev
+	}
+	rev = rangeRev
+	if rev <= 0 {
+		rev = t.rev
+	}
+	if t.compacted(rev) {
+		return nil, 0, ErrCompacted
+	}
+
+	_, revpairs := t.s.kvindex.Range(key, end, rev)
+	for _, revpair := range revpairs {
+		e, ok := t.event(revpair)
+		if !ok {
+			break
+		}
+		if e.Type == storagepb.PUT {
+			kvs = append(kvs, *e.Kv)
+		}
+		if limit > 0 && len(kvs) >= int(limit) {
+			break
+		}
+	}
+
+	// a compaction that happens during the range might remove the index
+	// or the key-values at rev, so the result is only valid if rev is
+	// still not compacted after the range.
+	if t.compacted(rev) {
+		return nil, 0, ErrCompacted
+	}
+	return kvs, rev, nil
+}
+
+// event gets the event at the given reversion. It returns false if the
+// event has been compacted.
+func (t *readTxn) event(revpair reversion) (*storagepb.Event, bool) {
+	revbytes := newRevBytes()
+	revToBytes(revpair, revbytes)
+
+	// the events at or before the revision of the txn are written before
+	// the backend tx began, so the backend tx sees them whether they are
+	// committed or not. It must not take the batch tx lock, which the
+	// commit holds while it waits for the read-only backend txs to end.
+	_, vs := t.tx.UnsafeRange(keyBucketName, revbytes, nil, 0)
+	if len(vs) != 1 {
+		if t.compacted(revpair.main) {
+			return nil, false
+		}
+		log.Fatalf("storage: range cannot find rev (%d,%d)", revpair.main, revpair.sub)
+	}
+
+	e := &storagepb.Event{}
+	if err := e.Unmarshal(vs[0]); err != nil {
+		log.Fatalf("storage: cannot unmarshal event: %v", err)
+	}
+	return e, true
+}
+
+func (t *readTxn) compacted(rev int64) bool {
+	t.s.revMu.RLock()
+	defer t.s.revMu.RUnlock()
+	return rev <= t.s.compactMainRev
+}
@@ -9,6 +9,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/coreos/etcd/lease"
+	"github.com/coreos/etcd/storage/backend"
 	"github.com/coreos/etcd/storage/storagepb"
 )
 
@@ -16,9 +18,9 @@ func TestRange(t *testing.T) {
 	s := newStore("test")
 	defer os.Remove("test")
 
-	s.Put([]byte("foo"), []byte("bar"))
-	s.Put([]byte("foo1"), []byte("bar1"))
-	s.Put([]byte("foo2"), []byte("bar2"))
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	s.Put([]byte("foo1"), []byte("bar1"), lease.NoLease)
+	s.Put([]byte("foo2"), []byte("bar2"), lease.NoLease)
 	kvs := []storagepb.KeyValue{
 		{Key: []byte("foo"), Value: []byte("bar"), CreateIndex: 1, ModIndex: 1, Version: 1},
 		{Key: []byte("foo1"), Value: []byte("bar1"), CreateIndex: 2, ModIndex: 2, Version: 1},
@@ -68,9 +70,9 @@ func TestRangeBadRev(t *testing.T) {
 	s := newStore("test")
 	defer os.Remove("test")
 
-	s.Put([]byte("foo"), []byte("bar"))
-	s.Put([]byte("foo1"), []byte("bar1"))
-	s.Put([]byte("foo2"), []byte("bar2"))
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	s.Put([]byte("foo1"), []byte("bar1"), lease.NoLease)
+	s.Put([]byte("foo2"), []byte("bar2"), lease.NoLease)
 	if err := s.Compact(3); err != nil {
 		t.Fatalf("compact error (%v)", err)
 	}
@@ -95,9 +97,9 @@ func TestRangeLimit(t *testing.T) {
 	s := newStore("test")
 	defer os.Remove("test")
 
-	s.Put([]byte("foo"), []byte("bar"))
-	s.Put([]byte("foo1"), []byte("bar1"))
-	s.Put([]byte("foo2"), []byte("bar2"))
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	s.Put([]byte("foo1"), []byte("bar1"), lease.NoLease)
+	s.Put([]byte("foo2"), []byte("bar2"), lease.NoLease)
 	s.DeleteRange([]byte("foo1"), nil)
 	kvs := []storagepb.KeyValue{
 		{Key: []byte("foo"), Value: []byte("bar"), CreateIndex: 1, ModIndex: 1, Version: 1},
@@ -153,9 +155,9 @@ func TestSimpleDeleteRange(t *testing.T) {
 	for i, tt := range tests {
 		s := newStore("test")
 
-		s.Put([]byte("foo"), []byte("bar"))
-		s.Put([]byte("foo1"), []byte("bar1"))
-		s.Put([]byte("foo2"), []byte("bar2"))
+		s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+		s.Put([]byte("foo1"), []byte("bar1"), lease.NoLease)
+		s.Put([]byte("foo2"), []byte("bar2"), lease.NoLease)
 
 		n, rev := s.DeleteRange(tt.key, tt.end)
 		if n != tt.wN {
@@ -173,9 +175,9 @@ func TestRangeInSequence(t *testing.T) {
 	s := newStore("test")
 	defer os.Remove("test")
 
-	s.Put([]byte("foo"), []byte("bar"))
-	s.Put([]byte("foo1"), []byte("bar1"))
-	s.Put([]byte("foo2"), []byte("bar2"))
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	s.Put([]byte("foo1"), []byte("bar1"), lease.NoLease)
+	s.Put([]byte("foo2"), []byte("bar2"), lease.NoLease)
 
 	// remove foo
 	n, rev := s.DeleteRange([]byte("foo"), nil)
@@ -244,9 +246,9 @@ func TestOneTxn(t *testing.T) {
 
 	id := s.TxnBegin()
 	for i := 0; i < 3; i++ {
-		s.TxnPut(id, []byte("foo"), []byte("bar"))
-		s.TxnPut(id, []byte("foo1"), []byte("bar1"))
-		s.TxnPut(id, []byte("foo2"), []byte("bar2"))
+		s.TxnPut(id, []byte("foo"), []byte("bar"), lease.NoLease)
+		s.TxnPut(id, []byte("foo1"), []byte("bar1"), lease.NoLease)
+		s.TxnPut(id, []byte("foo2"), []byte("bar2"), lease.NoLease)
 
 		// remove foo
 		n, rev, err := s.TxnDeleteRange(id, []byte("foo"), nil)
@@ -332,15 +334,15 @@ func TestCompaction(t *testing.T) {
 	s := newStore("test")
 	defer os.Remove("test")
 
-	s.Put([]byte("foo"), []byte("bar"))
-	s.Put([]byte("foo1"), []byte("bar1"))
-	s.Put([]byte("foo2"), []byte("bar2"))
-	s.Put([]byte("foo"), []byte("bar11"))
-	s.Put([]byte("foo1"), []byte("bar12"))
-	s.Put([]byte("foo2"), []byte("bar13"))
-	s.Put([]byte("foo1"), []byte("bar14"))
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	s.Put([]byte("foo1"), []byte("bar1"), lease.NoLease)
+	s.Put([]byte("foo2"), []byte("bar2"), lease.NoLease)
+	s.Put([]byte("foo"), []byte("bar11"), lease.NoLease)
+	s.Put([]byte("foo1"), []byte("bar12"), lease.NoLease)
+	s.Put([]byte("foo2"), []byte("bar13"), lease.NoLease)
+	s.Put([]byte("foo1"), []byte("bar14"), lease.NoLease)
 	s.DeleteRange([]byte("foo"), []byte("foo200"))
-	s.Put([]byte("foo4"), []byte("bar4"))
+	s.Put([]byte("foo4"), []byte("bar4"), lease.NoLease)
 
 	err := s.Compact(4)
 	if err != nil {
@@ -394,16 +396,16 @@ func TestRestore(t *testing.T) {
 	s0 := newStore("test")
 	defer os.Remove("test")
 
-	s0.Put([]byte("foo"), []byte("bar"))
-	s0.Put([]byte("foo1"), []byte("bar1"))
-	s0.Put([]byte("foo2"), []byte("bar2"))
-	s0.Put([]byte("foo"), []byte("bar11"))
-	s0.Put([]byte("foo1"), []byte("bar12"))
-	s0.Put([]byte("foo2"), []byte("bar13"))
-	s0.Put([]byte("foo1"), []byte("bar14"))
-	s0.Put([]byte("foo3"), []byte("bar3"))
+	s0.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	s0.Put([]byte("foo1"), []byte("bar1"), lease.NoLease)
+	s0.Put([]byte("foo2"), []byte("bar2"), lease.NoLease)
+	s0.Put([]byte("foo"), []byte("bar11"), lease.NoLease)
+	s0.Put([]byte("foo1"), []byte("bar12"), lease.NoLease)
+	s0.Put([]byte("foo2"), []byte("bar13"), lease.NoLease)
+	s0.Put([]byte("foo1"), []byte("bar14"), lease.NoLease)
+	s0.Put([]byte("foo3"), []byte("bar3"), lease.NoLease)
 	s0.DeleteRange([]byte("foo3"), nil)
-	s0.Put([]byte("foo3"), []byte("bar31"))
+	s0.Put([]byte("foo3"), []byte("bar31"), lease.NoLease)
 	s0.DeleteRange([]byte("foo3"), nil)
 
 	mink := newRevBytes()
@@ -436,9 +438,9 @@ func TestRestoreContinueUnfinishedCompaction(t *testing.T) {
 	s0 := newStore("test")
 	defer os.Remove("test")
 
-	s0.Put([]byte("foo"), []byte("bar"))
-	s0.Put([]byte("foo"), []byte("bar1"))
-	s0.Put([]byte("foo"), []byte("bar2"))
+	s0.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	s0.Put([]byte("foo"), []byte("bar1"), lease.NoLease)
+	s0.Put([]byte("foo"), []byte("bar2"), lease.NoLease)
 
 	// write scheduled compaction, but not do compaction
 	rbytes := newRevBytes()
@@ -472,6 +474,142 @@ func TestRestoreContinueUnfinishedCompaction(t *testing.T) {
 	tx.Unlock()
 }
 
+func TestConsistentIndex(t *testing.T) {
+	var ci fakeConsistentIndex
+	s0 := NewConsistent("test", &ci)
+	defer os.Remove("test")
+
+	if g := s0.ConsistentIndex(); g != 0 {
+		t.Errorf("consistent index = %d, want 0", g)
+	}
+
+	ci = 10
+	s0.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	// a txn without changes does not save the consistent index
+	ci = 11
+	s0.Range([]byte("foo"), nil, 0, 0)
+	if g := s0.ConsistentIndex(); g != 10 {
+		t.Errorf("consistent index = %d, want 10", g)
+	}
+	if g := s0.Rev(); g != 1 {
+		t.Errorf("rev = %d, want 1", g)
+	}
+	s0.Close()
+
+	s1 := NewConsistent("test", &ci)
+	s1.Restore()
+	if g := s1.ConsistentIndex(); g != 10 {
+		t.Errorf("consistent index after restart = %d, want 10", g)
+	}
+	if g := s1.Rev(); g != 1 {
+		t.Errorf("rev after restart = %d, want 1", g)
+	}
+	s1.Close()
+}
+
+func TestReadTxn(t *testing.T) {
+	s := newStore("test")
+	defer os.Remove("test")
+	defer s.Close()
+
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	s.b.ForceCommit()
+	s.Put([]byte("foo1"), []byte("bar1"), lease.NoLease)
+
+	rtxn := s.ReadTxnBegin()
+	defer rtxn.End()
+	if rtxn.Rev() != 2 {
+		t.Errorf("rev = %d, want 2", rtxn.Rev())
+	}
+
+	// a read-only txn does not block the write txn, and does not see its
+	// writes.
+	id := s.TxnBegin()
+	s.TxnPut(id, []byte("foo2"), []byte("bar2"), lease.NoLease)
+	kvs, rev, err := rtxn.Range([]byte("foo"), []byte("foo3"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev != 2 || len(kvs) != 2 {
+		t.Errorf("range = %+v at %d, want 2 keys at 2", kvs, rev)
+	}
+	// a read-only txn that begins during the write txn does not see the
+	// writes of the txn either.
+	kvs, rev, err = s.Range([]byte("foo"), []byte("foo3"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev != 2 || len(kvs) != 2 {
+		t.Errorf("range = %+v at %d, want 2 keys at 2", kvs, rev)
+	}
+	s.TxnEnd(id)
+
+	kvs, _, err = rtxn.Range([]byte("foo"), []byte("foo3"), 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || string(kvs[0].Key) != "foo" {
+		t.Errorf("kvs = %+v, want foo", kvs)
+	}
+	if _, _, err = rtxn.Range([]byte("foo"), nil, 0, 3); err != ErrFutureRev {
+		t.Errorf("err = %v, want %v", err, ErrFutureRev)
+	}
+
+	// the compaction after the read-only txn begins is visible to it
+	if err = s.Compact(2); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = rtxn.Range([]byte("foo"), nil, 0, 0); err != ErrCompacted {
+		t.Errorf("err = %v, want %v", err, ErrCompacted)
+	}
+}
+
+// TestReadTxnRangeDuringRemap tests that the ranges do not block the
+// commits of the batch tx that grow the database and remap it, and are
+// not blocked by them.
+func TestReadTxnRangeDuringRemap(t *testing.T) {
+	s := newStoreWithBackend(backend.New("test", time.Millisecond, 10), nil)
+	defer os.Remove("test")
+
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+
+	donec := make(chan struct{})
+	go func() {
+		defer close(donec)
+		// grow the database to several MB, which is remapped many times
+		v := make([]byte, 4096)
+		for i := 0; i < 2000; i++ {
+			s.Put([]byte("foo"), v, lease.NoLease)
+		}
+	}()
+
+	for {
+		select {
+		case <-donec:
+			s.Close()
+			return
+		default:
+		}
+		rangec := make(chan error, 1)
+		go func() {
+			_, _, err := s.Range([]byte("foo"), nil, 0, 0)
+			rangec <- err
+		}()
+		select {
+		case err := <-rangec:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("range is blocked")
+		}
+	}
+}
+
+type fakeConsistentIndex uint64
+
+func (i *fakeConsistentIndex) ConsistentIndex() uint64 { return uint64(*i) }
+
 func BenchmarkStorePut(b *testing.B) {
 	s := newStore("test")
 	defer os.Remove("test")
@@ -485,6 +623,6 @@ func BenchmarkStorePut(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		s.Put(keys[i], []byte("foo"))
+		s.Put(keys[i], []byte("foo"), lease.NoLease)
 	}
 }
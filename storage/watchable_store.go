@@ -0,0 +1,259 @@
+package storage
+
+import (
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/lease"
+	"github.com/coreos/etcd/storage/backend"
+	"github.com/coreos/etcd/storage/storagepb"
+)
+
+var (
+	// chanBufLen is the length of the buffered event channel of a watcher.
+	// Each element in the channel holds the events of one reversion.
+	chanBufLen = 1024
+	// syncInterval is the interval to replay the events in the backend
+	// to the unsynced watchers.
+	syncInterval = 100 * time.Millisecond
+	// maxEventsPerSync is the max number of events that a sync round reads
+	// from the backend, which bounds the memory usage and the time the
+	// store is blocked. The rest is replayed in the next rounds.
+	maxEventsPerSync = 1024
+)
+
+type watchableStore struct {
+	*store
+
+	// mu protects the watcher sets and the state of the watchers.
+	mu sync.Mutex
+	// unsynced contains the watchers that fall behind the store. They
+	// catch up by replaying the events saved in the backend.
+	unsynced map[*watcher]struct{}
+	// synced contains the watchers that have caught up with the store.
+	// They receive the events as the events happen.
+	synced map[*watcher]struct{}
+}
+
+func NewWatchable(path string) WatchableKV {
+	return newWatchableStore(newStore(path))
+}
+
+// NewConsistentWatchable creates a ConsistentWatchableKV on the given
+// backend. The index returned by ig is saved atomically with every txn
+// that changes the store. The keys put with a lease are attached to the
+// lease in le, which shares the backend with the store.
+func NewConsistentWatchable(b backend.Backend, le lease.Lessor, ig ConsistentIndexGetter) ConsistentWatchableKV {
+	s := newWatchableStore(newStoreWithBackend(b, le))
+	s.store.ig = ig
+	return s
+}
+
+func newWatchableStore(st *store) *watchableStore {
+	s := &watchableStore{
+		store:    st,
+		unsynced: make(map[*watcher]struct{}),
+		synced:   make(map[*watcher]struct{}),
+	}
+	s.store.notify = s.notify
+	s.store.wg.Add(1)
+	go s.syncWatchersLoop()
+	return s
+}
+
+func (s *watchableStore) Watch(key, end []byte, startRev int64) (Watcher, error) {
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	if startRev > 0 && startRev <= s.store.compactMainRev {
+		return nil, ErrCompacted
+	}
+
+	w := &watcher{
+		s:   s,
+		key: key,
+		end: end,
+		ch:  make(chan []storagepb.Event, chanBufLen),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case startRev <= 0:
+		w.cur = s.store.currentRev.main + 1
+		s.synced[w] = struct{}{}
+	case startRev > s.store.currentRev.main:
+		w.cur = startRev
+		s.synced[w] = struct{}{}
+	default:
+		w.cur = startRev
+		s.unsynced[w] = struct{}{}
+	}
+	return w, nil
+}
+
+// Restore restores the store from its backend. The synced watchers are
+// moved to the unsynced set, so they replay the events of the restored
+// backend that they have not seen.
+func (s *watchableStore) Restore() error {
+	if err := s.store.Restore(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for w := range s.synced {
+		delete(s.synced, w)
+		s.unsynced[w] = struct{}{}
+	}
+	return nil
+}
+
+func (s *watchableStore) Close() error {
+	err := s.store.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for w := range s.synced {
+		w.stop(nil)
+	}
+	for w := range s.unsynced {
+		w.stop(nil)
+	}
+	s.synced = make(map[*watcher]struct{})
+	s.unsynced = make(map[*watcher]struct{})
+	return err
+}
+
+// notify delivers the events of the txn at the given main reversion to
+// the synced watchers. A synced watcher that cannot receive the events in
+// time is moved to the unsynced set, and replays the rest of the events
+// from the backend later. It is called with the store lock held.
+func (s *watchableStore) notify(rev int64, evs []storagepb.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for w := range s.synced {
+		if !w.send(rev, evs) {
+			delete(s.synced, w)
+			s.unsynced[w] = struct{}{}
+		}
+	}
+}
+
+func (s *watchableStore) syncWatchersLoop() {
+	defer s.store.wg.Done()
+	for {
+		s.syncWatchers()
+
+		select {
+		case <-time.After(syncInterval):
+		case <-s.store.stopc:
+			return
+		}
+	}
+}
+
+// syncWatchers replays the events saved in the backend to the unsynced
+// watchers, and moves the watchers that have caught up with the store to
+// the synced set. A watcher whose next event has been compacted is
+// stopped with ErrCompacted.
+func (s *watchableStore) syncWatchers() {
+	// block the writes to the store, so no event happens during
+	// the replay.
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	minRev := int64(math.MaxInt64)
+	for w := range s.unsynced {
+		if w.cur <= s.store.compactMainRev {
+			w.stop(ErrCompacted)
+			delete(s.unsynced, w)
+			continue
+		}
+		if w.cur < minRev {
+			minRev = w.cur
+		}
+	}
+	if len(s.unsynced) == 0 {
+		return
+	}
+
+	min, max := newRevBytes(), newRevBytes()
+	revToBytes(reversion{main: minRev}, min)
+	revToBytes(reversion{main: s.store.currentRev.main + 1}, max)
+
+	tx := s.store.b.BatchTx()
+	tx.Lock()
+	keys, vals := tx.UnsafeRange(keyBucketName, min, max, int64(maxEventsPerSync))
+	// the round stops at the last reversion it reads, which is read in
+	// whole so that the events of a txn are never split.
+	partial := len(keys) == maxEventsPerSync
+	if partial {
+		last := bytesToRev(keys[len(keys)-1]).main
+		for len(keys) > 0 && bytesToRev(keys[len(keys)-1]).main == last {
+			keys, vals = keys[:len(keys)-1], vals[:len(vals)-1]
+		}
+		revToBytes(reversion{main: last}, min)
+		revToBytes(reversion{main: last + 1}, max)
+		lkeys, lvals := tx.UnsafeRange(keyBucketName, min, max, 0)
+		keys, vals = append(keys, lkeys...), append(vals, lvals...)
+	}
+	tx.Unlock()
+
+	blocked := make(map[*watcher]bool)
+	send := func(rev int64, evs []storagepb.Event) {
+		for w := range s.unsynced {
+			if !blocked[w] && !w.send(rev, evs) {
+				blocked[w] = true
+			}
+		}
+	}
+
+	// the events are sent to the watchers in groups of reversion
+	var (
+		rev int64
+		evs []storagepb.Event
+	)
+	for i, key := range keys {
+		ev := storagepb.Event{}
+		if err := ev.Unmarshal(vals[i]); err != nil {
+			log.Fatalf("storage: cannot unmarshal event: %v", err)
+		}
+		if r := bytesToRev(key).main; r != rev {
+			if len(evs) != 0 {
+				send(rev, evs)
+			}
+			rev, evs = r, nil
+		}
+		evs = append(evs, ev)
+	}
+	if len(evs) != 0 {
+		send(rev, evs)
+	}
+
+	// the watchers have not caught up with the store until a round
+	// reads to the current reversion.
+	if partial {
+		return
+	}
+	for w := range s.unsynced {
+		if blocked[w] {
+			continue
+		}
+		delete(s.unsynced, w)
+		s.synced[w] = struct{}{}
+	}
+}
+
+// cancel removes the given watcher from the store and stops it.
+func (s *watchableStore) cancel(w *watcher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.synced, w)
+	delete(s.unsynced, w)
+	w.stop(nil)
+}
@@ -0,0 +1,315 @@
+package storage
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/lease"
+	"github.com/coreos/etcd/storage/storagepb"
+)
+
+func TestWatch(t *testing.T) {
+	s := newWatchableStore(newStore("test"))
+	defer os.Remove("test")
+	defer s.Close()
+
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	s.Put([]byte("foo1"), []byte("bar1"), lease.NoLease)
+	s.DeleteRange([]byte("foo1"), nil)
+
+	tests := []struct {
+		key, end []byte
+		startRev int64
+
+		wevs []storagepb.Event
+	}{
+		// watch a key from the first reversion
+		{
+			[]byte("foo"), nil, 1,
+			[]storagepb.Event{
+				{Type: storagepb.PUT, Kv: &storagepb.KeyValue{Key: []byte("foo"), Value: []byte("bar"), CreateIndex: 1, ModIndex: 1, Version: 1}},
+				{Type: storagepb.PUT, Kv: &storagepb.KeyValue{Key: []byte("foo"), Value: []byte("baz"), CreateIndex: 1, ModIndex: 4, Version: 2}},
+			},
+		},
+		// watch a range from the middle reversion
+		{
+			[]byte("foo"), []byte("foo2"), 2,
+			[]storagepb.Event{
+				{Type: storagepb.PUT, Kv: &storagepb.KeyValue{Key: []byte("foo1"), Value: []byte("bar1"), CreateIndex: 2, ModIndex: 2, Version: 1}},
+				{Type: storagepb.DELETE, Kv: &storagepb.KeyValue{Key: []byte("foo1"), ModIndex: 3}},
+				{Type: storagepb.PUT, Kv: &storagepb.KeyValue{Key: []byte("foo"), Value: []byte("baz"), CreateIndex: 1, ModIndex: 4, Version: 2}},
+			},
+		},
+		// watch a key from now on
+		{
+			[]byte("foo"), nil, 0,
+			[]storagepb.Event{
+				{Type: storagepb.PUT, Kv: &storagepb.KeyValue{Key: []byte("foo"), Value: []byte("baz"), CreateIndex: 1, ModIndex: 4, Version: 2}},
+			},
+		},
+		// watch a key from a future reversion
+		{
+			[]byte("foo"), nil, 4,
+			[]storagepb.Event{
+				{Type: storagepb.PUT, Kv: &storagepb.KeyValue{Key: []byte("foo"), Value: []byte("baz"), CreateIndex: 1, ModIndex: 4, Version: 2}},
+			},
+		},
+	}
+
+	var ws []Watcher
+	for i, tt := range tests {
+		w, err := s.Watch(tt.key, tt.end, tt.startRev)
+		if err != nil {
+			t.Fatalf("#%d: watch error (%v)", i, err)
+		}
+		ws = append(ws, w)
+	}
+
+	s.Put([]byte("foo"), []byte("baz"), lease.NoLease)
+
+	for i, tt := range tests {
+		var evs []storagepb.Event
+		for len(evs) < len(tt.wevs) {
+			select {
+			case es := <-ws[i].Event():
+				evs = append(evs, es...)
+			case <-time.After(time.Second):
+				t.Fatalf("#%d: failed to receive events", i)
+			}
+		}
+		if !reflect.DeepEqual(evs, tt.wevs) {
+			t.Errorf("#%d: events = %+v, want %+v", i, evs, tt.wevs)
+		}
+		select {
+		case es := <-ws[i].Event():
+			t.Errorf("#%d: unexpected events %+v", i, es)
+		default:
+		}
+	}
+}
+
+func TestWatchTxn(t *testing.T) {
+	s := newWatchableStore(newStore("test"))
+	defer os.Remove("test")
+	defer s.Close()
+
+	w, err := s.Watch([]byte("foo"), []byte("foo3"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := s.TxnBegin()
+	s.TxnPut(id, []byte("foo1"), []byte("bar1"), lease.NoLease)
+	s.TxnPut(id, []byte("foo2"), []byte("bar2"), lease.NoLease)
+	s.TxnDeleteRange(id, []byte("foo1"), nil)
+	s.TxnEnd(id)
+
+	wevs := []storagepb.Event{
+		{Type: storagepb.PUT, Kv: &storagepb.KeyValue{Key: []byte("foo1"), Value: []byte("bar1"), CreateIndex: 1, ModIndex: 1, Version: 1}},
+		{Type: storagepb.PUT, Kv: &storagepb.KeyValue{Key: []byte("foo2"), Value: []byte("bar2"), CreateIndex: 1, ModIndex: 1, Version: 1}},
+		{Type: storagepb.DELETE, Kv: &storagepb.KeyValue{Key: []byte("foo1"), ModIndex: 1}},
+	}
+	// the events of a txn are received together
+	if evs := <-w.Event(); !reflect.DeepEqual(evs, wevs) {
+		t.Errorf("events = %+v, want %+v", evs, wevs)
+	}
+}
+
+// TestWatchSlowWatcher tests that a watcher that falls behind the store
+// still receives all the events in order.
+func TestWatchSlowWatcher(t *testing.T) {
+	defer func(l int) { chanBufLen = l }(chanBufLen)
+	chanBufLen = 2
+
+	s := newWatchableStore(newStore("test"))
+	defer os.Remove("test")
+	defer s.Close()
+
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	wh, err := s.Watch([]byte("foo"), nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wl, err := s.Watch([]byte("foo"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 9; i++ {
+		s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	}
+
+	for i, w := range []Watcher{wh, wl} {
+		wrev := int64(1)
+		if w == wl {
+			wrev = 2
+		}
+		for ; wrev <= 10; wrev++ {
+			select {
+			case evs := <-w.Event():
+				if len(evs) != 1 || evs[0].Kv.ModIndex != wrev {
+					t.Fatalf("#%d: events = %+v, want one event at rev %d", i, evs, wrev)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("#%d: failed to receive event at rev %d", i, wrev)
+			}
+		}
+	}
+}
+
+// TestWatchSyncBounded tests that an unsynced watcher catches up with
+// the store over several sync rounds when there are more events than
+// a round reads, and receives the events of a txn together.
+func TestWatchSyncBounded(t *testing.T) {
+	defer func(n int) { maxEventsPerSync = n }(maxEventsPerSync)
+	maxEventsPerSync = 2
+
+	s := newWatchableStore(newStore("test"))
+	defer os.Remove("test")
+	defer s.Close()
+
+	for i := 0; i < 5; i++ {
+		s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	}
+	// the txn has more events than a round reads
+	id := s.TxnBegin()
+	for i := 0; i < 3; i++ {
+		s.TxnPut(id, []byte("foo"), []byte("bar"), lease.NoLease)
+	}
+	s.TxnEnd(id)
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+
+	w, err := s.Watch([]byte("foo"), nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wn := []int{1, 1, 1, 1, 1, 3, 1}
+	for i, n := range wn {
+		wrev := int64(i + 1)
+		select {
+		case evs := <-w.Event():
+			if len(evs) != n || evs[0].Kv.ModIndex != wrev {
+				t.Fatalf("#%d: events = %+v, want %d events at rev %d", i, evs, n, wrev)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("#%d: failed to receive events at rev %d", i, wrev)
+		}
+	}
+
+	// the watcher is synced once it has caught up with the store
+	s.syncWatchers()
+	s.mu.Lock()
+	_, ok := s.synced[w.(*watcher)]
+	s.mu.Unlock()
+	if !ok {
+		t.Errorf("watcher is not synced")
+	}
+}
+
+func TestWatchCompacted(t *testing.T) {
+	defer func(l int) { chanBufLen = l }(chanBufLen)
+	chanBufLen = 1
+
+	s := newWatchableStore(newStore("test"))
+	defer os.Remove("test")
+	defer s.Close()
+
+	for i := 0; i < 3; i++ {
+		s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	}
+
+	w, err := s.Watch([]byte("foo"), nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Compact(2)
+
+	if _, err := s.Watch([]byte("foo"), nil, 2); err != ErrCompacted {
+		t.Errorf("watch error = %v, want %v", err, ErrCompacted)
+	}
+	if _, err := s.Watch([]byte("foo"), nil, 3); err != nil {
+		t.Errorf("watch error = %v, want nil", err)
+	}
+
+	// the watcher cannot catch up with the store because the events
+	// it needs have been compacted.
+	for range w.Event() {
+	}
+	if err := w.Err(); err != ErrCompacted {
+		t.Errorf("err = %v, want %v", err, ErrCompacted)
+	}
+}
+
+func TestWatcherCancel(t *testing.T) {
+	s := newWatchableStore(newStore("test"))
+	defer os.Remove("test")
+	defer s.Close()
+
+	w, err := s.Watch([]byte("foo"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Cancel()
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+
+	if _, ok := <-w.Event(); ok {
+		t.Errorf("event channel is not closed")
+	}
+	if err := w.Err(); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+	// cancel twice is fine
+	w.Cancel()
+}
+
+// TestWatchRestore ensures a synced watcher receives the events of the
+// backend the store is restored from.
+func TestWatchRestore(t *testing.T) {
+	s := newWatchableStore(newStore("test"))
+	defer os.Remove("test")
+	defer s.Close()
+
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	w, err := s.Watch([]byte("foo"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the snapshot has one more event than the store
+	ss := newStore("snap")
+	ss.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	ss.Put([]byte("foo"), []byte("baz"), lease.NoLease)
+	f, err := os.Create("snap.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ss.Snapshot(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	ss.Close()
+	os.Remove("snap")
+	defer os.Remove("snap.db")
+
+	if err = s.store.b.Recover("snap.db"); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	if rev := s.Rev(); rev != 2 {
+		t.Errorf("rev = %d, want 2", rev)
+	}
+
+	wev := storagepb.Event{Type: storagepb.PUT, Kv: &storagepb.KeyValue{Key: []byte("foo"), Value: []byte("baz"), CreateIndex: 1, ModIndex: 2, Version: 2}}
+	select {
+	case evs := <-w.Event():
+		if len(evs) != 1 || !reflect.DeepEqual(evs[0], wev) {
+			t.Errorf("evs = %+v, want [%+v]", evs, wev)
+		}
+	case <-time.After(5 * syncInterval):
+		t.Fatal("failed to receive the event of the restored store")
+	}
+}
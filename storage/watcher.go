@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"bytes"
+
+	"github.com/coreos/etcd/storage/storagepb"
+)
+
+// watcher is a Watcher of a watchableStore. Its fields except the key
+// range are protected by the lock of the store.
+type watcher struct {
+	s *watchableStore
+
+	key, end []byte
+	// cur is the main reversion of the next events to deliver.
+	cur int64
+
+	ch      chan []storagepb.Event
+	err     error
+	stopped bool
+}
+
+func (w *watcher) Event() <-chan []storagepb.Event { return w.ch }
+
+func (w *watcher) Err() error {
+	w.s.mu.Lock()
+	defer w.s.mu.Unlock()
+	return w.err
+}
+
+func (w *watcher) Cancel() { w.s.cancel(w) }
+
+// send sends the events happened at the given main reversion that the
+// watcher is interested in to the watcher. It returns false if the
+// watcher cannot receive the events without blocking.
+func (w *watcher) send(rev int64, evs []storagepb.Event) bool {
+	if rev < w.cur {
+		return true
+	}
+	var wevs []storagepb.Event
+	for _, ev := range evs {
+		if w.match(ev.Kv.Key) {
+			wevs = append(wevs, ev)
+		}
+	}
+	if len(wevs) == 0 {
+		w.cur = rev + 1
+		return true
+	}
+	select {
+	case w.ch <- wevs:
+		w.cur = rev + 1
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *watcher) match(key []byte) bool {
+	if w.end == nil {
+		return bytes.Equal(key, w.key)
+	}
+	return bytes.Compare(key, w.key) >= 0 && bytes.Compare(key, w.end) < 0
+}
+
+func (w *watcher) stop(err error) {
+	if w.stopped {
+		return
+	}
+	w.stopped = true
+	w.err = err
+	close(w.ch)
+}
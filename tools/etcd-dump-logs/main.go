@@ -120,6 +120,19 @@ func main() {
 			} else {
 				msg = fmt.Sprintf("%s\tmethod=%s id=%s", msg, r.Type, types.ID(r.NodeID))
 			}
+		case raftpb.EntryConfChangeV2:
+			msg = fmt.Sprintf("%s\tconf2", msg)
+			var r raftpb.ConfChangeV2
+			if err := r.Unmarshal(e.Data); err != nil {
+				msg = fmt.Sprintf("%s\t???", msg)
+				break
+			}
+			if len(r.Changes) == 0 {
+				msg = fmt.Sprintf("%s\tleave-joint", msg)
+			}
+			for _, c := range r.Changes {
+				msg = fmt.Sprintf("%s\tmethod=%s id=%s", msg, c.Type, types.ID(c.NodeID))
+			}
 		}
 		fmt.Println(msg)
 	}